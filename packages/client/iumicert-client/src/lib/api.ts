@@ -1,9 +1,27 @@
 const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || "http://localhost:8080";
 
+// Optional name of this verifier deployment, recorded in the issuer's verification log
+const VERIFIER_ID = process.env.NEXT_PUBLIC_VERIFIER_ID || "";
+
 interface ApiResponse<T> {
   success: boolean;
   data?: T;
   error?: string;
+  page?: { limit: number; next_cursor?: string; has_more: boolean };
+}
+
+export interface VerificationRecord {
+  id: number;
+  receipt_id: string;
+  receipt_type: string;
+  student_id: string;
+  term_id: string;
+  endpoint: string;
+  mode: string;
+  verifier_id: string;
+  success: boolean;
+  error?: string;
+  verified_at: string;
 }
 
 class ApiService {
@@ -15,6 +33,7 @@ class ApiService {
     const response = await fetch(url, {
       headers: {
         "Content-Type": "application/json",
+        ...(VERIFIER_ID ? { "X-Verifier-ID": VERIFIER_ID } : {}),
         ...options?.headers,
       },
       ...options,
@@ -59,6 +78,26 @@ class ApiService {
     });
   }
 
+  // Verifications of the student's own receipts, newest first. The token is a student
+  // token handed out by the registrar.
+  async getMyVerifications(
+    studentToken: string,
+    cursor = ""
+  ): Promise<{ verifications: VerificationRecord[]; nextCursor: string }> {
+    const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : "";
+    const response = await fetch(`${API_BASE_URL}/api/student/verifications${query}`, {
+      headers: { Authorization: `Bearer ${studentToken}` },
+    });
+    const result: ApiResponse<VerificationRecord[]> = await response.json();
+    if (!result.success) {
+      throw new Error(result.error || "API request failed");
+    }
+    return {
+      verifications: result.data || [],
+      nextCursor: result.page?.next_cursor || "",
+    };
+  }
+
   // Health check
   async healthCheck(): Promise<{ status: string; timestamp: string }> {
     try {
@@ -0,0 +1,296 @@
+// Package receipt defines the journey receipt: the JSON document a student downloads and
+// hands to verifiers, bundling one term receipt with Verkle proofs per academic term.
+//
+// Decoding is strict. Unknown fields, trailing data, unsupported schema versions and
+// inconsistent term entries are rejected, so a receipt that decodes has the layout
+// the verifiers expect.
+package receipt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"iumicert/crypto/verkle"
+)
+
+// SchemaVersion is the journey receipt layout written by this package. Receipts without a
+// schema_version predate versioning; they share the version 1 layout and decode as version 1.
+const SchemaVersion = 1
+
+// Values of TermReceiptBody.ProofType and VerificationPath
+const (
+	ProofTypeVerkle            = "verkle_32_byte"
+	VerificationPathSingle     = "single_verkle_proof"
+	VerificationPathMultiproof = "batch_verkle_multiproof"
+)
+
+var (
+	// ErrUnsupportedVersion is returned for receipts written by a newer schema version
+	ErrUnsupportedVersion = errors.New("unsupported journey receipt schema version")
+	// ErrInvalidReceipt is wrapped by every decoding and validation failure
+	ErrInvalidReceipt = errors.New("invalid journey receipt")
+)
+
+// JourneyReceipt is a student's academic journey receipt
+type JourneyReceipt struct {
+	SchemaVersion       int                    `json:"schema_version"`
+	StudentID           string                 `json:"student_id"` // plain student ID, e.g. ITITIU00001
+	ReceiptType         ReceiptType            `json:"receipt_type"`
+	GenerationTimestamp time.Time              `json:"generation_timestamp"`
+	TermsIncluded       []string               `json:"terms_included"`
+	CoursesFilter       []string               `json:"courses_filter"`
+	TermReceipts        map[string]TermReceipt `json:"term_receipts"` // term ID -> term receipt
+	BlockchainReady     bool                   `json:"blockchain_ready"`
+}
+
+// ReceiptType records how the receipt was requested
+type ReceiptType struct {
+	SelectiveDisclosure bool `json:"selective_disclosure"`
+	SpecificCourses     bool `json:"specific_courses"`
+	SpecificTerms       bool `json:"specific_terms"`
+}
+
+// TermReceipt is the entry of one term in a journey receipt
+type TermReceipt struct {
+	TermID          string          `json:"term_id"`
+	StudentID       string          `json:"student_id"`
+	Receipt         TermReceiptBody `json:"receipt"`
+	VerkleRoot      Root            `json:"verkle_root"`
+	RevealedCourses int             `json:"revealed_courses"`
+	TotalCourses    int             `json:"total_courses"`
+	GeneratedAt     time.Time       `json:"generated_at"`
+}
+
+// TermReceiptBody carries the revealed courses of a term and the proofs binding them to the term root
+type TermReceiptBody struct {
+	StudentID           string                     `json:"student_id"` // student DID
+	TermID              string                     `json:"term_id"`
+	RevealedCourses     []verkle.CourseCompletion  `json:"revealed_courses"`
+	VerkleRoot          Root                       `json:"verkle_root"`
+	CourseProofs        map[string]json.RawMessage `json:"course_proofs"`         // course ID -> VerkleProofBundle
+	BatchProof          json.RawMessage            `json:"batch_proof,omitempty"` // VerkleBatchProofBundle for all revealed courses
+	ProofType           string                     `json:"proof_type"`
+	ProofFormat         string                     `json:"proof_format,omitempty"` // empty in receipts written before batch proofs
+	SelectiveDisclosure bool                       `json:"selective_disclosure"`
+	VerificationPath    string                     `json:"verification_path"`
+	DisclosedFields     []string                   `json:"disclosed_fields,omitempty"`
+	Timestamp           time.Time                  `json:"timestamp"` // term publication time
+	Metadata            verkle.ReceiptMetadata     `json:"metadata"`
+}
+
+// Root is a Verkle root, encoded as 64 hex digits. A 0x prefix is accepted when decoding.
+type Root [32]byte
+
+// ParseRoot parses a hex Verkle root with or without 0x prefix
+func ParseRoot(s string) (Root, error) {
+	var root Root
+	digits := strings.TrimPrefix(s, "0x")
+	if len(digits) != 2*len(root) {
+		return root, fmt.Errorf("verkle root must be %d hex digits, got %d", 2*len(root), len(digits))
+	}
+	if _, err := hex.Decode(root[:], []byte(digits)); err != nil {
+		return root, fmt.Errorf("invalid verkle root: %w", err)
+	}
+	return root, nil
+}
+
+func (r Root) String() string {
+	return hex.EncodeToString(r[:])
+}
+
+// MarshalText encodes the root as lowercase hex without prefix
+func (r Root) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes a hex root
+func (r *Root) UnmarshalText(text []byte) error {
+	root, err := ParseRoot(string(text))
+	if err != nil {
+		return err
+	}
+	*r = root
+	return nil
+}
+
+// NewTermReceipt wraps a term receipt generated by a TermVerkleTree for a journey receipt
+func NewTermReceipt(studentID string, r *verkle.VerificationReceipt, generatedAt time.Time) TermReceipt {
+	body := TermReceiptBody{
+		StudentID:           r.StudentDID,
+		TermID:              r.TermID,
+		RevealedCourses:     r.RevealedCourses,
+		VerkleRoot:          r.VerkleRoot,
+		CourseProofs:        r.CourseProofs,
+		ProofType:           ProofTypeVerkle,
+		ProofFormat:         r.ProofFormat,
+		SelectiveDisclosure: r.SelectiveDisclosure,
+		VerificationPath:    VerificationPathSingle,
+		DisclosedFields:     r.DisclosedFields,
+		Timestamp:           r.PublishedAt.Truncate(time.Second),
+		Metadata:            r.Metadata,
+	}
+	if r.ProofFormat == verkle.ProofFormatBatch {
+		body.BatchProof = r.BatchProof
+		body.VerificationPath = VerificationPathMultiproof
+	}
+	if body.CourseProofs == nil {
+		body.CourseProofs = map[string]json.RawMessage{}
+	}
+
+	return TermReceipt{
+		TermID:          r.TermID,
+		StudentID:       studentID,
+		Receipt:         body,
+		VerkleRoot:      r.VerkleRoot,
+		RevealedCourses: len(r.RevealedCourses),
+		TotalCourses:    r.Metadata.TotalCourses,
+		GeneratedAt:     generatedAt.Truncate(time.Second),
+	}
+}
+
+// IsBatch reports whether the term is proven by a single multiproof
+func (b TermReceiptBody) IsBatch() bool {
+	return b.ProofFormat == verkle.ProofFormatBatch || len(b.BatchProof) > 0
+}
+
+// Course returns the revealed course with the given ID
+func (b TermReceiptBody) Course(courseID string) (verkle.CourseCompletion, bool) {
+	for _, course := range b.RevealedCourses {
+		if course.CourseID == courseID {
+			return course, true
+		}
+	}
+	return verkle.CourseCompletion{}, false
+}
+
+// VerificationReceipt converts the body to the receipt form checked by package verifier
+func (b TermReceiptBody) VerificationReceipt() *verkle.VerificationReceipt {
+	proofFormat := b.ProofFormat
+	if proofFormat == "" {
+		proofFormat = verkle.ProofFormatPerCourse
+		if len(b.BatchProof) > 0 {
+			proofFormat = verkle.ProofFormatBatch
+		}
+	}
+	return &verkle.VerificationReceipt{
+		TermID:              b.TermID,
+		StudentDID:          b.StudentID,
+		VerkleRoot:          b.VerkleRoot,
+		PublishedAt:         b.Timestamp,
+		RevealedCourses:     b.RevealedCourses,
+		CourseProofs:        b.CourseProofs,
+		BatchProof:          b.BatchProof,
+		ProofFormat:         proofFormat,
+		DisclosedFields:     b.DisclosedFields,
+		SelectiveDisclosure: b.SelectiveDisclosure,
+		Metadata:            b.Metadata,
+	}
+}
+
+// StudentDID returns the DID the receipt's course keys are derived from
+func (jr *JourneyReceipt) StudentDID() string {
+	return "did:example:" + jr.StudentID
+}
+
+// SortedTermIDs returns the IDs of the included terms in lexical order
+func (jr *JourneyReceipt) SortedTermIDs() []string {
+	termIDs := make([]string, 0, len(jr.TermReceipts))
+	for termID := range jr.TermReceipts {
+		termIDs = append(termIDs, termID)
+	}
+	sort.Strings(termIDs)
+	return termIDs
+}
+
+// Validate checks that the receipt is internally consistent
+func (jr *JourneyReceipt) Validate() error {
+	if jr.SchemaVersion > SchemaVersion || jr.SchemaVersion < 0 {
+		return fmt.Errorf("%w: %w %d (supported up to %d)", ErrInvalidReceipt, ErrUnsupportedVersion, jr.SchemaVersion, SchemaVersion)
+	}
+	if jr.StudentID == "" {
+		return fmt.Errorf("%w: missing student_id", ErrInvalidReceipt)
+	}
+	if jr.TermReceipts == nil {
+		return fmt.Errorf("%w: missing term_receipts", ErrInvalidReceipt)
+	}
+
+	for termID, term := range jr.TermReceipts {
+		if term.TermID != termID || term.Receipt.TermID != termID {
+			return fmt.Errorf("%w: term %s: entry is for term %q", ErrInvalidReceipt, termID, term.Receipt.TermID)
+		}
+		if term.Receipt.VerkleRoot != term.VerkleRoot {
+			return fmt.Errorf("%w: term %s: receipt root %s differs from term root %s", ErrInvalidReceipt, termID, term.Receipt.VerkleRoot, term.VerkleRoot)
+		}
+		if term.Receipt.IsBatch() && len(term.Receipt.BatchProof) == 0 {
+			return fmt.Errorf("%w: term %s: batch receipt without batch_proof", ErrInvalidReceipt, termID)
+		}
+		for _, course := range term.Receipt.RevealedCourses {
+			if course.CourseID == "" {
+				return fmt.Errorf("%w: term %s: revealed course without course_id", ErrInvalidReceipt, termID)
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes a receipt strictly and validates it. Receipts without a schema
+// version are upgraded to SchemaVersion.
+func (jr *JourneyReceipt) UnmarshalJSON(data []byte) error {
+	type journeyReceipt JourneyReceipt
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var decoded journeyReceipt
+	if err := dec.Decode(&decoded); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidReceipt, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after receipt", ErrInvalidReceipt)
+	}
+
+	receipt := JourneyReceipt(decoded)
+	if err := receipt.Validate(); err != nil {
+		return err
+	}
+	if receipt.SchemaVersion == 0 {
+		receipt.SchemaVersion = SchemaVersion
+	}
+	*jr = receipt
+	return nil
+}
+
+// Decode decodes and validates a journey receipt
+func Decode(data []byte) (*JourneyReceipt, error) {
+	var receipt JourneyReceipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// Load reads and decodes a journey receipt file
+func Load(path string) (*JourneyReceipt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read receipt file: %w", err)
+	}
+	receipt, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// Encode returns the indented JSON of the receipt, as written to receipt files
+func (jr *JourneyReceipt) Encode() ([]byte, error) {
+	return json.MarshalIndent(jr, "", "  ")
+}
@@ -0,0 +1,314 @@
+package receipt
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"iumicert/crypto/verkle"
+)
+
+const testStudentID = "ITITIU00001"
+
+// buildJourneyReceipt builds a two-term journey receipt from real term trees,
+// one with per-course proofs and one with a batch multiproof
+func buildJourneyReceipt(t *testing.T) *JourneyReceipt {
+	t.Helper()
+	studentDID := "did:example:" + testStudentID
+	generatedAt := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	journey := &JourneyReceipt{
+		SchemaVersion:       SchemaVersion,
+		StudentID:           testStudentID,
+		GenerationTimestamp: generatedAt,
+		TermsIncluded:       []string{"Semester_1_2024", "Semester_2_2024"},
+		TermReceipts:        map[string]TermReceipt{},
+		BlockchainReady:     true,
+	}
+
+	for i, termID := range journey.TermsIncluded {
+		termTree := verkle.NewTermVerkleTree(termID)
+		var courses []verkle.CourseCompletion
+		for _, courseID := range []string{"IT154IU", "IT013IU", "PH013IU"} {
+			courses = append(courses, verkle.CourseCompletion{
+				IssuerID:    "IU-CS",
+				StudentID:   testStudentID,
+				TermID:      termID,
+				CourseID:    courseID,
+				CourseName:  "Course " + courseID,
+				AttemptNo:   1,
+				StartedAt:   generatedAt.Add(-90 * 24 * time.Hour),
+				CompletedAt: generatedAt.Add(-30 * 24 * time.Hour),
+				AssessedAt:  generatedAt.Add(-25 * 24 * time.Hour),
+				IssuedAt:    generatedAt.Add(-20 * 24 * time.Hour),
+				Grade:       "A",
+				Credits:     3,
+				Instructor:  "Prof. Test",
+			})
+		}
+		if err := termTree.AddCourses(studentDID, courses); err != nil {
+			t.Fatalf("Failed to add courses: %v", err)
+		}
+		if err := termTree.PublishTerm(); err != nil {
+			t.Fatalf("Failed to publish term: %v", err)
+		}
+
+		var termReceipt *verkle.VerificationReceipt
+		var err error
+		if i == 0 {
+			termReceipt, err = termTree.GenerateStudentReceipt(studentDID, nil)
+		} else {
+			termReceipt, err = termTree.GenerateStudentBatchReceipt(studentDID, []string{"IT154IU", "PH013IU"})
+		}
+		if err != nil {
+			t.Fatalf("Failed to generate receipt for %s: %v", termID, err)
+		}
+		journey.TermReceipts[termID] = NewTermReceipt(testStudentID, termReceipt, generatedAt)
+	}
+	return journey
+}
+
+// TestJourneyReceiptRoundTrip checks that encoding and strict decoding are lossless
+func TestJourneyReceiptRoundTrip(t *testing.T) {
+	journey := buildJourneyReceipt(t)
+
+	encoded, err := journey.Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode receipt: %v", err)
+	}
+	decoded, err := Decode(encoded)
+	if err != nil {
+		t.Fatalf("Failed to decode receipt: %v", err)
+	}
+	reencoded, err := decoded.Encode()
+	if err != nil {
+		t.Fatalf("Failed to re-encode receipt: %v", err)
+	}
+	if !bytes.Equal(encoded, reencoded) {
+		t.Fatalf("Receipt changed in round trip:\n%s\n---\n%s", encoded, reencoded)
+	}
+
+	batch := decoded.TermReceipts["Semester_2_2024"].Receipt
+	if !batch.IsBatch() || batch.VerificationPath != VerificationPathMultiproof || len(batch.RevealedCourses) != 2 {
+		t.Fatalf("Batch term receipt not preserved: %+v", batch)
+	}
+	if decoded.TermReceipts["Semester_1_2024"].Receipt.IsBatch() {
+		t.Fatalf("Per-course term receipt decoded as batch")
+	}
+
+	// The decoded term receipts still verify against their roots
+	for _, termID := range decoded.SortedTermIDs() {
+		term := decoded.TermReceipts[termID]
+		result, err := verkle.VerifyReceiptOffChain(term.Receipt.VerificationReceipt(), term.VerkleRoot)
+		if err != nil || !result.Valid {
+			t.Fatalf("Decoded receipt for %s does not verify: %v %v", termID, err, result.Errors)
+		}
+	}
+	t.Logf("✅ Journey receipt round-tripped (%d bytes)", len(encoded))
+}
+
+// TestJourneyReceiptStrictDecoding checks that malformed receipts are rejected
+func TestJourneyReceiptStrictDecoding(t *testing.T) {
+	encoded, err := buildJourneyReceipt(t).Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode receipt: %v", err)
+	}
+
+	// Receipts written before schema versioning decode as the current version
+	legacy := modifyReceipt(t, encoded, func(doc map[string]any) { delete(doc, "schema_version") })
+	decoded, err := Decode(legacy)
+	if err != nil {
+		t.Fatalf("Legacy receipt should decode: %v", err)
+	}
+	if decoded.SchemaVersion != SchemaVersion {
+		t.Fatalf("Legacy receipt decoded as version %d", decoded.SchemaVersion)
+	}
+
+	cases := []struct {
+		name   string
+		data   []byte
+		target error
+	}{
+		{"unknown top-level field", modifyReceipt(t, encoded, func(doc map[string]any) { doc["extra"] = true }), ErrInvalidReceipt},
+		{"unknown course field", modifyReceipt(t, encoded, func(doc map[string]any) {
+			course := termBody(doc, "Semester_1_2024")["revealed_courses"].([]any)[0].(map[string]any)
+			course["gpa"] = 4.0
+		}), ErrInvalidReceipt},
+		{"newer schema version", modifyReceipt(t, encoded, func(doc map[string]any) { doc["schema_version"] = SchemaVersion + 1 }), ErrUnsupportedVersion},
+		{"missing term receipts", modifyReceipt(t, encoded, func(doc map[string]any) { delete(doc, "term_receipts") }), ErrInvalidReceipt},
+		{"malformed root", modifyReceipt(t, encoded, func(doc map[string]any) {
+			doc["term_receipts"].(map[string]any)["Semester_1_2024"].(map[string]any)["verkle_root"] = "abcd"
+		}), ErrInvalidReceipt},
+		{"term under wrong key", modifyReceipt(t, encoded, func(doc map[string]any) {
+			terms := doc["term_receipts"].(map[string]any)
+			terms["Semester_3_2024"] = terms["Semester_1_2024"]
+			delete(terms, "Semester_1_2024")
+		}), ErrInvalidReceipt},
+		{"wrong value type", modifyReceipt(t, encoded, func(doc map[string]any) { doc["blockchain_ready"] = "yes" }), ErrInvalidReceipt},
+		{"trailing data", append(append([]byte{}, encoded...), []byte(" {}")...), nil},
+	}
+	for _, tc := range cases {
+		_, err := Decode(tc.data)
+		if err == nil {
+			t.Fatalf("%s: receipt should be rejected", tc.name)
+		}
+		if tc.target != nil && !errors.Is(err, tc.target) {
+			t.Fatalf("%s: expected %v, got %v", tc.name, tc.target, err)
+		}
+		t.Logf("✅ %s rejected: %v", tc.name, err)
+	}
+}
+
+// TestJourneyReceiptSchema checks that encoded receipts satisfy the exported schema and that
+// the checked-in schema file is current
+func TestJourneyReceiptSchema(t *testing.T) {
+	schemaData, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	checkedIn, err := os.ReadFile("journey-receipt.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read checked-in schema: %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(checkedIn), bytes.TrimSpace(schemaData)) {
+		t.Fatalf("journey-receipt.schema.json is out of date; regenerate it with `micert receipt-schema -o packages/crypto/receipt/journey-receipt.schema.json`")
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(schemaData, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+	encoded, err := buildJourneyReceipt(t).Encode()
+	if err != nil {
+		t.Fatalf("Failed to encode receipt: %v", err)
+	}
+	var doc any
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		t.Fatalf("Failed to parse encoded receipt: %v", err)
+	}
+	if err := checkSchema(doc, schema, schema["$defs"].(map[string]any), "$"); err != nil {
+		t.Fatalf("Encoded receipt does not match schema: %v", err)
+	}
+
+	var unknown map[string]any
+	json.Unmarshal(encoded, &unknown)
+	unknown["extra"] = 1
+	if err := checkSchema(unknown, schema, schema["$defs"].(map[string]any), "$"); err == nil {
+		t.Fatalf("Schema should reject unknown fields")
+	}
+
+	// The root pattern accepts exactly the roots ParseRoot accepts
+	pattern := regexp.MustCompile((&schemaGenerator{}).schema(rootType)["pattern"].(string))
+	digits := strings.Repeat("ab", 32)
+	for _, root := range []string{digits, "0x" + digits, strings.ToUpper(digits), "0x" + strings.ToUpper(digits), digits[2:], "0x" + strings.Repeat("g", 64)} {
+		_, err := ParseRoot(root)
+		if matched := pattern.MatchString(root); matched != (err == nil) {
+			t.Fatalf("Root %s: schema pattern matches %v, ParseRoot error %v", root, matched, err)
+		}
+	}
+}
+
+func modifyReceipt(t *testing.T, encoded []byte, modify func(doc map[string]any)) []byte {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		t.Fatalf("Failed to parse receipt: %v", err)
+	}
+	modify(doc)
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("Failed to encode modified receipt: %v", err)
+	}
+	return data
+}
+
+func termBody(doc map[string]any, termID string) map[string]any {
+	return doc["term_receipts"].(map[string]any)[termID].(map[string]any)["receipt"].(map[string]any)
+}
+
+// checkSchema validates value against the subset of JSON Schema emitted by JSONSchema
+func checkSchema(value any, schema, defs map[string]any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		return checkSchema(value, defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any), defs, path)
+	}
+	if want, ok := schema["const"]; ok && fmt.Sprint(value) != fmt.Sprint(want) {
+		return fmt.Errorf("%s: expected %v, got %v", path, want, value)
+	}
+
+	var types []string
+	switch typ := schema["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []any:
+		for _, name := range typ {
+			types = append(types, name.(string))
+		}
+	}
+	matches := len(types) == 0
+	for _, typ := range types {
+		switch v := value.(type) {
+		case nil:
+			matches = matches || typ == "null"
+		case bool:
+			matches = matches || typ == "boolean"
+		case string:
+			matches = matches || typ == "string"
+		case float64:
+			matches = matches || typ == "number" || (typ == "integer" && v == float64(int64(v)))
+		case []any:
+			matches = matches || typ == "array"
+		case map[string]any:
+			matches = matches || typ == "object"
+		}
+	}
+	if !matches {
+		return fmt.Errorf("%s: %T does not match type %v", path, value, types)
+	}
+
+	switch v := value.(type) {
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := checkSchema(item, items, defs, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := v[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing required field %s", path, name)
+				}
+			}
+		}
+		for key, item := range v {
+			itemSchema, ok := properties[key].(map[string]any)
+			if !ok {
+				switch extra := schema["additionalProperties"].(type) {
+				case bool:
+					if !extra {
+						return fmt.Errorf("%s: unexpected field %s", path, key)
+					}
+					continue
+				case map[string]any:
+					itemSchema = extra
+				default:
+					continue
+				}
+			}
+			if err := checkSchema(item, itemSchema, defs, path+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package receipt
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// SchemaID identifies the JSON Schema of the current journey receipt version
+const SchemaID = "https://iumicert.example/schemas/journey-receipt/v1.json"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	rootType       = reflect.TypeOf(Root{})
+)
+
+// JSONSchema returns the JSON Schema (draft 2020-12) of JourneyReceipt. It is derived from
+// the Go types, so it always matches what Encode writes and the strict decoder accepts:
+// every object is closed and fields without omitempty are required.
+func JSONSchema() ([]byte, error) {
+	gen := schemaGenerator{defs: map[string]any{}}
+	root := gen.structSchema(reflect.TypeOf(JourneyReceipt{}))
+
+	// Pin the version so validators reject receipts of other layouts
+	root["properties"].(map[string]any)["schema_version"] = map[string]any{
+		"type":  "integer",
+		"const": SchemaVersion,
+	}
+
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = SchemaID
+	root["title"] = "IU-MiCert journey receipt"
+	root["$defs"] = gen.defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{"type": "object"}
+	case rootType:
+		return map[string]any{"type": "string", "pattern": "^(0x)?[0-9a-fA-F]{64}$"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Uint8:
+		return map[string]any{"type": "integer", "minimum": 0, "maximum": 255}
+	case reflect.Int, reflect.Int64, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Slice:
+		// nil slices encode as null
+		return map[string]any{"type": []string{"array", "null"}, "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": []string{"object", "null"}, "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // reserve the name for recursive types
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+	return map[string]any{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,138 @@
+package verifier
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"iumicert/crypto/verkle"
+)
+
+// Code identifies why a verification step failed
+type Code string
+
+const (
+	CodeMalformedProof         Code = "malformed_proof"          // proof bundle cannot be decoded
+	CodeMissingProof           Code = "missing_proof"            // revealed course has no proof
+	CodeCourseKeyMismatch      Code = "course_key_mismatch"      // proof is for a different course
+	CodeInvalidCourseData      Code = "invalid_course_data"      // course cannot be hashed (bad salt, unknown encoding)
+	CodeFieldsNotCovered       Code = "fields_not_covered"       // course carries fields the proof does not cover
+	CodeFieldMaskMismatch      Code = "field_mask_mismatch"      // proven fields differ from the receipt's disclosed fields
+	CodeKeyNotProven           Code = "key_not_proven"           // proof does not open the course key
+	CodeValueMismatch          Code = "value_mismatch"           // course data does not hash to the proven leaf
+	CodeInvalidProof           Code = "invalid_proof"            // proof does not open against the root
+	CodeRootMismatch           Code = "root_mismatch"            // receipt root differs from the trusted root
+	CodeInvalidTimestamps      Code = "invalid_timestamps"       // course timestamps are out of order
+	CodeIssuedAfterPublication Code = "issued_after_publication" // course issued after the term was published
+	CodeUnknown                Code = "verification_failed"
+)
+
+// WarningCode identifies a receipt inconsistency that does not invalidate it
+type WarningCode string
+
+const (
+	WarningNoProof               WarningCode = "no_proof"                // per-course receipt lists a course without a proof
+	WarningUnrevealedProof       WarningCode = "unrevealed_proof"        // receipt carries a proof for a course it does not reveal
+	WarningSelectiveFlagMismatch WarningCode = "selective_flag_mismatch" // selective_disclosure flag contradicts the revealed courses
+)
+
+// Error is a verification failure with its code. It wraps the underlying error.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON encodes the error as its code and message
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    Code   `json:"code"`
+		Message string `json:"message"`
+	}{e.Code, e.Err.Error()})
+}
+
+// CodeOf returns the code of a verification error, or CodeUnknown
+func CodeOf(err error) Code {
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr.Code
+	}
+	return CodeUnknown
+}
+
+// CourseOutcome is the verification result of one revealed course
+type CourseOutcome struct {
+	CourseID  string   `json:"course_id"`
+	CourseKey string   `json:"course_key"`
+	Verified  bool     `json:"verified"`
+	Fields    []string `json:"fields,omitempty"` // proven fields when the proof covers only some of them
+	Code      Code     `json:"code,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// Warning is a receipt inconsistency that does not make it invalid
+type Warning struct {
+	Code    WarningCode `json:"code"`
+	Message string      `json:"message"`
+}
+
+// ReceiptResult is the typed result of verifying a term receipt
+type ReceiptResult struct {
+	Valid           bool            `json:"valid"`
+	VerifiedAt      time.Time       `json:"verified_at"`
+	TermID          string          `json:"term_id"`
+	StudentDID      string          `json:"student_did"`
+	ProofFormat     string          `json:"proof_format"`
+	DisclosedFields []string        `json:"disclosed_fields,omitempty"` // set when only some course fields were proven
+	Courses         []CourseOutcome `json:"courses"`
+	Errors          []*Error        `json:"errors"` // receipt-level failures, e.g. a root mismatch
+	Warnings        []Warning       `json:"warnings"`
+}
+
+// CoursesVerified returns the number of courses whose proofs verified
+func (r *ReceiptResult) CoursesVerified() int {
+	count := 0
+	for _, outcome := range r.Courses {
+		if outcome.Verified {
+			count++
+		}
+	}
+	return count
+}
+
+// classify maps the error classes of package verkle to codes
+func classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr
+	}
+
+	code := CodeUnknown
+	switch {
+	case errors.Is(err, verkle.ErrMalformedProof):
+		code = CodeMalformedProof
+	case errors.Is(err, verkle.ErrCourseKeyMismatch):
+		code = CodeCourseKeyMismatch
+	case errors.Is(err, verkle.ErrInvalidCourseData):
+		code = CodeInvalidCourseData
+	case errors.Is(err, verkle.ErrFieldsNotCovered):
+		code = CodeFieldsNotCovered
+	case errors.Is(err, verkle.ErrKeyNotProven):
+		code = CodeKeyNotProven
+	case errors.Is(err, verkle.ErrValueMismatch):
+		code = CodeValueMismatch
+	case errors.Is(err, verkle.ErrInvalidProof):
+		code = CodeInvalidProof
+	}
+	return &Error{Code: code, Err: err}
+}
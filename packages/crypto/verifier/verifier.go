@@ -0,0 +1,288 @@
+// Package verifier checks IU-MiCert course proofs and term receipts against a trusted
+// Verkle root. It is stateless, never writes to the process log and reports typed
+// per-course outcomes, so it can be embedded in other services. Logging and metrics
+// are opt-in through a Logger and a Hook.
+package verifier
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"iumicert/crypto/verkle"
+)
+
+// Logger receives progress messages. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, args ...any)
+}
+
+// Hook is notified of every verified course and receipt, e.g. for metrics or audit trails
+type Hook interface {
+	CourseVerified(termID string, outcome CourseOutcome)
+	ReceiptVerified(result *ReceiptResult)
+}
+
+// Option configures a Verifier
+type Option func(*Verifier)
+
+// WithLogger sends progress messages to logger
+func WithLogger(logger Logger) Option {
+	return func(v *Verifier) { v.logger = logger }
+}
+
+// WithHook registers a hook for verification events
+func WithHook(hook Hook) Option {
+	return func(v *Verifier) { v.hook = hook }
+}
+
+// WithClock overrides the time source used for ReceiptResult.VerifiedAt
+func WithClock(now func() time.Time) Option {
+	return func(v *Verifier) { v.now = now }
+}
+
+// Verifier verifies proofs and receipts. The zero value is silent and ready to use;
+// a Verifier holds no state between calls and is safe for concurrent use.
+type Verifier struct {
+	logger Logger
+	hook   Hook
+	now    func() time.Time
+}
+
+// New returns a Verifier with the given options
+func New(opts ...Option) *Verifier {
+	v := &Verifier{}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// CourseKey returns the tree key of a student's course in a term
+func CourseKey(studentDID, termID, courseID string) string {
+	return fmt.Sprintf("%s:%s:%s", studentDID, termID, courseID)
+}
+
+// VerifyCourse verifies a per-course proof (VerkleProofBundle JSON) for a revealed course
+func (v *Verifier) VerifyCourse(courseKey string, course verkle.CourseCompletion, proofData []byte, root [32]byte) CourseOutcome {
+	outcome := CourseOutcome{CourseID: course.CourseID, CourseKey: courseKey}
+
+	fields, err := verkle.VerifyCourseFieldProof(courseKey, course, proofData, root)
+	if err != nil {
+		v.fail(&outcome, classify(err))
+		return outcome
+	}
+
+	v.pass(&outcome, fields)
+	return outcome
+}
+
+// VerifyBatch verifies one multiproof (VerkleBatchProofBundle JSON) covering all given courses.
+// A failure applies to every course since the proof binds them together.
+func (v *Verifier) VerifyBatch(courseKeys []string, courses []verkle.CourseCompletion, proofData []byte, root [32]byte) []CourseOutcome {
+	outcomes := make([]CourseOutcome, len(courses))
+	for i, course := range courses {
+		outcomes[i] = CourseOutcome{CourseID: course.CourseID}
+		if i < len(courseKeys) {
+			outcomes[i].CourseKey = courseKeys[i]
+		}
+	}
+
+	fields, err := verkle.VerifyBatchCourseFieldProof(courseKeys, courses, proofData, root)
+	verr := classify(err)
+	for i := range outcomes {
+		if verr != nil {
+			v.fail(&outcomes[i], verr)
+		} else {
+			v.pass(&outcomes[i], fields)
+		}
+	}
+	return outcomes
+}
+
+// VerifyAbsence verifies a non-membership proof (VerkleAbsenceProofBundle JSON) showing that a
+// course key holds no credential. It returns nil or an *Error.
+func (v *Verifier) VerifyAbsence(courseKey string, proofData []byte, root [32]byte) error {
+	if err := verkle.VerifyAbsenceProof(courseKey, proofData, root); err != nil {
+		verr := classify(err)
+		v.logf("absence of %s not proven: %v", courseKey, verr)
+		return verr
+	}
+	v.logf("absence of %s verified against root %x", courseKey, root)
+	return nil
+}
+
+// VerifyReceipt verifies a term receipt against a trusted root: the receipt root, every
+// revealed course's proof, disclosed timestamps and the consistency of the receipt metadata.
+func (v *Verifier) VerifyReceipt(receipt *verkle.VerificationReceipt, trustedRoot [32]byte) *ReceiptResult {
+	result := &ReceiptResult{
+		Valid:       true,
+		VerifiedAt:  v.clock(),
+		TermID:      receipt.TermID,
+		StudentDID:  receipt.StudentDID,
+		ProofFormat: receipt.ProofFormat,
+		Courses:     []CourseOutcome{},
+		Errors:      []*Error{},
+		Warnings:    []Warning{},
+	}
+	batch := receipt.ProofFormat == verkle.ProofFormatBatch || len(receipt.BatchProof) > 0
+	if batch {
+		result.ProofFormat = verkle.ProofFormatBatch
+	} else if result.ProofFormat == "" {
+		result.ProofFormat = verkle.ProofFormatPerCourse
+	}
+
+	// 1. The receipt must commit to the trusted root
+	if receipt.VerkleRoot != trustedRoot {
+		result.addError(CodeRootMismatch, fmt.Errorf("receipt root %x, trusted root %x", receipt.VerkleRoot, trustedRoot))
+	}
+
+	// 2. Course proofs, checked against the trusted root
+	courseKeys := make([]string, 0, len(receipt.RevealedCourses))
+	for _, course := range receipt.RevealedCourses {
+		courseKeys = append(courseKeys, CourseKey(receipt.StudentDID, receipt.TermID, course.CourseID))
+	}
+	if batch {
+		if len(receipt.BatchProof) == 0 {
+			for i, course := range receipt.RevealedCourses {
+				outcome := CourseOutcome{CourseID: course.CourseID, CourseKey: courseKeys[i]}
+				v.fail(&outcome, &Error{Code: CodeMissingProof, Err: fmt.Errorf("receipt has no batch proof")})
+				result.Courses = append(result.Courses, outcome)
+			}
+		} else {
+			result.Courses = v.VerifyBatch(courseKeys, receipt.RevealedCourses, receipt.BatchProof, trustedRoot)
+		}
+	} else {
+		for i, course := range receipt.RevealedCourses {
+			proof, exists := receipt.CourseProofs[course.CourseID]
+			if !exists {
+				outcome := CourseOutcome{CourseID: course.CourseID, CourseKey: courseKeys[i]}
+				v.fail(&outcome, &Error{Code: CodeMissingProof, Err: fmt.Errorf("no proof for course %s", course.CourseID)})
+				result.Courses = append(result.Courses, outcome)
+				result.addWarning(WarningNoProof, fmt.Sprintf("No proof found for course %s", course.CourseID))
+				continue
+			}
+			result.Courses = append(result.Courses, v.VerifyCourse(courseKeys[i], course, proof, trustedRoot))
+		}
+	}
+
+	// 3. Proven fields must match the receipt's field mask
+	for i := range result.Courses {
+		outcome := &result.Courses[i]
+		if !outcome.Verified {
+			continue
+		}
+		if len(receipt.DisclosedFields) > 0 && strings.Join(outcome.Fields, ",") != strings.Join(receipt.DisclosedFields, ",") {
+			v.fail(outcome, &Error{Code: CodeFieldMaskMismatch,
+				Err: fmt.Errorf("proven fields %v do not match disclosed fields %v", outcome.Fields, receipt.DisclosedFields)})
+			continue
+		}
+		if len(outcome.Fields) > 0 {
+			result.DisclosedFields = outcome.Fields
+		}
+	}
+
+	// 4. Temporal consistency of the disclosed timestamps
+	partial := len(result.DisclosedFields) > 0
+	for i, course := range receipt.RevealedCourses {
+		if i >= len(result.Courses) || !result.Courses[i].Verified {
+			continue
+		}
+		outcome := &result.Courses[i]
+		if !partial || containsAll(result.DisclosedFields, "started_at", "completed_at", "assessed_at", "issued_at") {
+			if err := verkle.ValidateCourseTimestamps(course); err != nil {
+				v.fail(outcome, &Error{Code: CodeInvalidTimestamps, Err: err})
+				continue
+			}
+		}
+		if (!partial || containsAll(result.DisclosedFields, "issued_at")) && course.IssuedAt.After(receipt.PublishedAt) {
+			v.fail(outcome, &Error{Code: CodeIssuedAfterPublication,
+				Err: fmt.Errorf("issued at %s, term published at %s", course.IssuedAt.Format(time.RFC3339), receipt.PublishedAt.Format(time.RFC3339))})
+		}
+	}
+
+	// 5. Metadata consistency (warnings only)
+	revealed := make(map[string]bool, len(receipt.RevealedCourses))
+	for _, course := range receipt.RevealedCourses {
+		revealed[course.CourseID] = true
+	}
+	for courseID := range receipt.CourseProofs {
+		if !revealed[courseID] {
+			result.addWarning(WarningUnrevealedProof, fmt.Sprintf("Verkle proof for unrevealed course: %s", courseID))
+		}
+	}
+	if receipt.SelectiveDisclosure && len(receipt.RevealedCourses) >= receipt.Metadata.TotalCourses {
+		result.addWarning(WarningSelectiveFlagMismatch, "Selective disclosure flag set but all courses revealed")
+	} else if !receipt.SelectiveDisclosure && len(receipt.RevealedCourses) < receipt.Metadata.TotalCourses {
+		result.addWarning(WarningSelectiveFlagMismatch, "Selective disclosure flag not set but some courses hidden")
+	}
+
+	for _, outcome := range result.Courses {
+		if !outcome.Verified {
+			result.Valid = false
+		}
+		if v.hook != nil {
+			v.hook.CourseVerified(receipt.TermID, outcome)
+		}
+	}
+	if len(result.Errors) > 0 {
+		result.Valid = false
+	}
+
+	v.logf("receipt for %s in %s: valid=%t, %d/%d courses verified",
+		receipt.StudentDID, receipt.TermID, result.Valid, result.CoursesVerified(), len(result.Courses))
+	if v.hook != nil {
+		v.hook.ReceiptVerified(result)
+	}
+	return result
+}
+
+func (v *Verifier) pass(outcome *CourseOutcome, fields []string) {
+	outcome.Verified = true
+	if len(fields) < len(verkle.CourseFields) {
+		outcome.Fields = fields
+	}
+	v.logf("course %s verified", outcome.CourseID)
+}
+
+func (v *Verifier) fail(outcome *CourseOutcome, verr *Error) {
+	outcome.Verified = false
+	outcome.Code = verr.Code
+	outcome.Message = verr.Err.Error()
+	v.logf("course %s failed: %v", outcome.CourseID, verr)
+}
+
+func (v *Verifier) logf(format string, args ...any) {
+	if v.logger != nil {
+		v.logger.Printf(format, args...)
+	}
+}
+
+func (v *Verifier) clock() time.Time {
+	if v.now != nil {
+		return v.now()
+	}
+	return time.Now()
+}
+
+func (r *ReceiptResult) addError(code Code, err error) {
+	r.Errors = append(r.Errors, &Error{Code: code, Err: err})
+	r.Valid = false
+}
+
+func (r *ReceiptResult) addWarning(code WarningCode, message string) {
+	r.Warnings = append(r.Warnings, Warning{Code: code, Message: message})
+}
+
+func containsAll(fields []string, names ...string) bool {
+	present := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		present[field] = true
+	}
+	for _, name := range names {
+		if !present[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package verifier
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"iumicert/crypto/verkle"
+)
+
+const testStudentDID = "did:example:ITITIU00001"
+
+func buildTestTerm(t *testing.T) *verkle.TermVerkleTree {
+	t.Helper()
+	termTree := verkle.NewTermVerkleTree("TestTerm_2024")
+
+	var courses []verkle.CourseCompletion
+	for _, courseID := range []string{"IT154IU", "IT013IU", "PH013IU"} {
+		courses = append(courses, verkle.CourseCompletion{
+			IssuerID:    "IU-CS",
+			StudentID:   "ITITIU00001",
+			TermID:      "TestTerm_2024",
+			CourseID:    courseID,
+			CourseName:  "Course " + courseID,
+			AttemptNo:   1,
+			StartedAt:   time.Now().Add(-2 * time.Hour),
+			CompletedAt: time.Now().Add(-1 * time.Hour),
+			AssessedAt:  time.Now().Add(-30 * time.Minute),
+			IssuedAt:    time.Now().Add(-10 * time.Minute),
+			Grade:       "A",
+			Credits:     3,
+			Instructor:  "Prof. Test",
+		})
+	}
+	if err := termTree.AddCourses(testStudentDID, courses); err != nil {
+		t.Fatalf("Failed to add courses: %v", err)
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+	return termTree
+}
+
+type recordingHook struct {
+	courses  []CourseOutcome
+	receipts int
+}
+
+func (h *recordingHook) CourseVerified(termID string, outcome CourseOutcome) {
+	h.courses = append(h.courses, outcome)
+}
+
+func (h *recordingHook) ReceiptVerified(result *ReceiptResult) {
+	h.receipts++
+}
+
+// TestVerifyCourseCodes checks the error code reported for each kind of bad proof
+func TestVerifyCourseCodes(t *testing.T) {
+	termTree := buildTestTerm(t)
+	courseKey := CourseKey(testStudentDID, termTree.TermID, "IT154IU")
+	course := termTree.CourseEntries[courseKey]
+
+	proofData, err := termTree.GenerateCourseProof(testStudentDID, "IT154IU")
+	if err != nil {
+		t.Fatalf("Failed to generate proof: %v", err)
+	}
+
+	v := New()
+	if outcome := v.VerifyCourse(courseKey, course, proofData, termTree.VerkleRoot); !outcome.Verified {
+		t.Fatalf("Valid proof should verify, got %s: %s", outcome.Code, outcome.Message)
+	}
+
+	tampered := course
+	tampered.Grade = "F"
+	otherKey := CourseKey(testStudentDID, termTree.TermID, "IT013IU")
+	var wrongRoot [32]byte
+	wrongRoot[0] = 1
+
+	cases := []struct {
+		name      string
+		courseKey string
+		course    verkle.CourseCompletion
+		proofData []byte
+		root      [32]byte
+		want      Code
+	}{
+		{"tampered grade", courseKey, tampered, proofData, termTree.VerkleRoot, CodeValueMismatch},
+		{"other course key", otherKey, course, proofData, termTree.VerkleRoot, CodeCourseKeyMismatch},
+		{"malformed proof", courseKey, course, []byte("not a proof"), termTree.VerkleRoot, CodeMalformedProof},
+		{"wrong root", courseKey, course, proofData, wrongRoot, CodeInvalidProof},
+	}
+	for _, tc := range cases {
+		outcome := v.VerifyCourse(tc.courseKey, tc.course, tc.proofData, tc.root)
+		if outcome.Verified {
+			t.Fatalf("%s: proof should not verify", tc.name)
+		}
+		if outcome.Code != tc.want {
+			t.Fatalf("%s: expected code %s, got %s (%s)", tc.name, tc.want, outcome.Code, outcome.Message)
+		}
+		t.Logf("✅ %s rejected with %s", tc.name, outcome.Code)
+	}
+}
+
+// TestVerifyReceiptSilent verifies receipts without writing to the standard logger,
+// reporting progress only through the configured logger and hook
+func TestVerifyReceiptSilent(t *testing.T) {
+	termTree := buildTestTerm(t)
+	receipt, err := termTree.GenerateStudentBatchReceipt(testStudentDID, []string{"IT154IU", "IT013IU"})
+	if err != nil {
+		t.Fatalf("Failed to generate receipt: %v", err)
+	}
+
+	var stdLog bytes.Buffer
+	log.SetOutput(&stdLog)
+	defer log.SetOutput(os.Stderr)
+
+	var verifierLog bytes.Buffer
+	hook := &recordingHook{}
+	v := New(WithLogger(log.New(&verifierLog, "", 0)), WithHook(hook))
+
+	result := v.VerifyReceipt(receipt, termTree.VerkleRoot)
+	if !result.Valid || result.CoursesVerified() != 2 {
+		t.Fatalf("Receipt should verify 2 courses, got %+v", result)
+	}
+	if len(hook.courses) != 2 || hook.receipts != 1 {
+		t.Fatalf("Hook saw %d courses and %d receipts, expected 2 and 1", len(hook.courses), hook.receipts)
+	}
+
+	var wrongRoot [32]byte
+	result = v.VerifyReceipt(receipt, wrongRoot)
+	if result.Valid || len(result.Errors) == 0 || result.Errors[0].Code != CodeRootMismatch {
+		t.Fatalf("Receipt against the wrong root should fail with %s, got %+v", CodeRootMismatch, result.Errors)
+	}
+
+	receipt.RevealedCourses[1].Grade = "F"
+	result = v.VerifyReceipt(receipt, termTree.VerkleRoot)
+	if result.Valid {
+		t.Fatalf("Tampered receipt should not verify")
+	}
+	for _, outcome := range result.Courses {
+		if outcome.Code != CodeValueMismatch {
+			t.Fatalf("Course %s: expected code %s, got %s", outcome.CourseID, CodeValueMismatch, outcome.Code)
+		}
+	}
+
+	if stdLog.Len() != 0 {
+		t.Fatalf("Verifier wrote to the standard logger: %q", stdLog.String())
+	}
+	if verifierLog.Len() == 0 {
+		t.Fatalf("Verifier did not write to the configured logger")
+	}
+}
@@ -0,0 +1,270 @@
+package verkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	verkleLib "github.com/ethereum/go-verkle"
+)
+
+// CourseFields lists the CourseCompletion fields by JSON name, in canonical encoding order.
+// In field-leaf trees (TreeFormatFieldLeaves) the position is also the field's leaf index.
+var CourseFields = []string{
+	"issuer_id",
+	"student_id",
+	"term_id",
+	"course_id",
+	"course_name",
+	"attempt_no",
+	"started_at",
+	"completed_at",
+	"assessed_at",
+	"issued_at",
+	"grade",
+	"credits",
+	"instructor",
+}
+
+// Indexes into CourseFields
+const (
+	FieldIssuerID = iota
+	FieldStudentID
+	FieldTermID
+	FieldCourseID
+	FieldCourseName
+	FieldAttemptNo
+	FieldStartedAt
+	FieldCompletedAt
+	FieldAssessedAt
+	FieldIssuedAt
+	FieldGrade
+	FieldCredits
+	FieldInstructor
+)
+
+// Field-leaf layout
+//
+// A course with key K = sha256(studentDID:termID:courseID) owns the stem K[:31]. Field i is
+// stored at suffix K[31] + i - FieldCourseID (mod 256), so course_id sits exactly at K: absence
+// proofs and revocation tombstones work on K for every tree format. Each field leaf is
+//
+//	sha256(field_salt || 0x01 || i || canonical field encoding)
+//
+// with field_salt = sha256(course_salt || i). Disclosing a field reveals only its own salt,
+// so undisclosed fields of the same course stay hidden.
+
+// NormalizeFieldMask validates field names and returns them in leaf order. course_id is
+// always included since verifiers need it to derive the course key. An empty mask means all fields.
+func NormalizeFieldMask(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return append([]string(nil), CourseFields...), nil
+	}
+
+	selected := make(map[int]bool, len(fields)+1)
+	selected[FieldCourseID] = true
+	for _, name := range fields {
+		index, ok := courseFieldIndex(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown course field %q", name)
+		}
+		selected[index] = true
+	}
+
+	indexes := make([]int, 0, len(selected))
+	for index := range selected {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	normalized := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		normalized = append(normalized, CourseFields[index])
+	}
+	return normalized, nil
+}
+
+// RedactCourse keeps only the given fields of a course from a field-leaf tree and replaces
+// the course salt with the salts of the kept fields
+func RedactCourse(course CourseCompletion, fields []string) (CourseCompletion, error) {
+	courseSalt, err := decodeLeafSalt(course.Salt)
+	if err != nil {
+		return CourseCompletion{}, fmt.Errorf("course %s: %w", course.CourseID, err)
+	}
+
+	redacted := CourseCompletion{FieldSalts: make(map[string]string, len(fields))}
+	for _, name := range fields {
+		index, ok := courseFieldIndex(name)
+		if !ok {
+			return CourseCompletion{}, fmt.Errorf("unknown course field %q", name)
+		}
+		copyCourseField(&redacted, course, index)
+		redacted.FieldSalts[name] = hex.EncodeToString(courseFieldSalt(courseSalt, index))
+	}
+	return redacted, nil
+}
+
+// UndisclosedFieldsSet returns the fields outside the disclosed set that carry a non-zero
+// value. A verifier must not trust them since no proof covers them.
+func UndisclosedFieldsSet(course CourseCompletion, disclosed []string) []string {
+	isDisclosed := make(map[string]bool, len(disclosed))
+	for _, name := range disclosed {
+		isDisclosed[name] = true
+	}
+
+	var set []string
+	for index, name := range CourseFields {
+		if isDisclosed[name] {
+			continue
+		}
+		value := &canonicalEncoder{}
+		value.field(course, index)
+		zero := &canonicalEncoder{}
+		zero.field(CourseCompletion{}, index)
+		if !bytes.Equal(value.buf, zero.buf) {
+			set = append(set, name)
+		}
+	}
+	return set
+}
+
+// courseLeaves returns the tree keys and leaf values of a course. Single-leaf formats have
+// one leaf covering the whole course; field-leaf trees have one leaf per requested field
+// (all fields when fields is empty).
+func courseLeaves(courseKey string, course CourseCompletion, encoding, treeFormat uint8, fields []string) ([][]byte, [][32]byte, error) {
+	courseKeyHash := sha256.Sum256([]byte(courseKey))
+
+	if treeFormat != TreeFormatFieldLeaves {
+		if len(fields) > 0 && len(fields) != len(CourseFields) {
+			return nil, nil, fmt.Errorf("tree format %d does not support field-level disclosure", treeFormat)
+		}
+		value, err := CourseLeafValue(course, encoding, treeFormat)
+		if err != nil {
+			return nil, nil, err
+		}
+		return [][]byte{courseKeyHash[:]}, [][32]byte{value}, nil
+	}
+
+	if encoding != LeafEncodingCanonicalV1 {
+		return nil, nil, fmt.Errorf("field-leaf trees require leaf encoding %d, got %d", LeafEncodingCanonicalV1, encoding)
+	}
+	if len(fields) == 0 {
+		fields = CourseFields
+	}
+
+	var courseSalt []byte
+	if course.Salt != "" {
+		var err error
+		if courseSalt, err = decodeLeafSalt(course.Salt); err != nil {
+			return nil, nil, fmt.Errorf("course %s: %w", course.CourseID, err)
+		}
+	}
+
+	keys := make([][]byte, 0, len(fields))
+	values := make([][32]byte, 0, len(fields))
+	for _, name := range fields {
+		index, ok := courseFieldIndex(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown course field %q", name)
+		}
+
+		var fieldSalt []byte
+		if courseSalt != nil {
+			fieldSalt = courseFieldSalt(courseSalt, index)
+		} else {
+			var err error
+			if fieldSalt, err = decodeLeafSalt(course.FieldSalts[name]); err != nil {
+				return nil, nil, fmt.Errorf("course %s field %s: %w", course.CourseID, name, err)
+			}
+		}
+
+		enc := &canonicalEncoder{buf: append([]byte(nil), fieldSalt...)}
+		enc.uint8(LeafEncodingCanonicalV1)
+		enc.uint8(uint8(index))
+		enc.field(course, index)
+		if enc.err != nil {
+			return nil, nil, fmt.Errorf("failed to encode course %s: %w", course.CourseID, enc.err)
+		}
+
+		key := courseFieldKey(courseKeyHash, index)
+		keys = append(keys, key[:])
+		values = append(values, sha256.Sum256(enc.buf))
+	}
+	return keys, values, nil
+}
+
+// courseLeafKeys returns every tree key owned by a course
+func courseLeafKeys(courseKey string, treeFormat uint8) [][]byte {
+	courseKeyHash := sha256.Sum256([]byte(courseKey))
+	if treeFormat != TreeFormatFieldLeaves {
+		return [][]byte{courseKeyHash[:]}
+	}
+
+	keys := make([][]byte, 0, len(CourseFields))
+	for index := range CourseFields {
+		key := courseFieldKey(courseKeyHash, index)
+		keys = append(keys, key[:])
+	}
+	return keys
+}
+
+func courseFieldKey(courseKeyHash [32]byte, index int) [32]byte {
+	key := courseKeyHash
+	key[verkleLib.StemSize] += byte(index - FieldCourseID)
+	return key
+}
+
+func courseFieldSalt(courseSalt []byte, index int) []byte {
+	fieldSalt := sha256.Sum256(append(append([]byte(nil), courseSalt...), byte(index)))
+	return fieldSalt[:]
+}
+
+func courseFieldIndex(name string) (int, bool) {
+	for index, field := range CourseFields {
+		if field == name {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+func copyCourseField(dst *CourseCompletion, src CourseCompletion, index int) {
+	switch index {
+	case FieldIssuerID:
+		dst.IssuerID = src.IssuerID
+	case FieldStudentID:
+		dst.StudentID = src.StudentID
+	case FieldTermID:
+		dst.TermID = src.TermID
+	case FieldCourseID:
+		dst.CourseID = src.CourseID
+	case FieldCourseName:
+		dst.CourseName = src.CourseName
+	case FieldAttemptNo:
+		dst.AttemptNo = src.AttemptNo
+	case FieldStartedAt:
+		dst.StartedAt = src.StartedAt
+	case FieldCompletedAt:
+		dst.CompletedAt = src.CompletedAt
+	case FieldAssessedAt:
+		dst.AssessedAt = src.AssessedAt
+	case FieldIssuedAt:
+		dst.IssuedAt = src.IssuedAt
+	case FieldGrade:
+		dst.Grade = src.Grade
+	case FieldCredits:
+		dst.Credits = src.Credits
+	case FieldInstructor:
+		dst.Instructor = src.Instructor
+	}
+}
+
+func decodeLeafSalt(salt string) ([]byte, error) {
+	decoded, err := hex.DecodeString(salt)
+	if err != nil || len(decoded) != LeafSaltSize {
+		return nil, fmt.Errorf("missing a valid %d-byte leaf salt", LeafSaltSize)
+	}
+	return decoded, nil
+}
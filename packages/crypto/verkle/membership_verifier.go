@@ -2,25 +2,43 @@ package verkle
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"log"
+	"sync"
 
+	multiproof "github.com/crate-crypto/go-ipa"
+	"github.com/crate-crypto/go-ipa/common"
+	"github.com/crate-crypto/go-ipa/ipa"
 	verkleLib "github.com/ethereum/go-verkle"
 )
 
-// VerifyMembershipProof provides proper IPA verification for membership proofs
-// This is necessary because go-verkle's Verify() is designed for state transitions,
-// not membership proofs. This function properly verifies that a VerkleProof
-// cryptographically proves the values in the StateDiff.
+// Verification failure classes. Errors returned by the Verify* functions wrap one of these,
+// so callers can tell a tampered course from a malformed proof with errors.Is.
+var (
+	ErrMalformedProof    = errors.New("malformed proof")
+	ErrCourseKeyMismatch = errors.New("course key mismatch")
+	ErrInvalidCourseData = errors.New("invalid course data")
+	ErrFieldsNotCovered  = errors.New("fields not covered by proof")
+	ErrKeyNotProven      = errors.New("key not covered by proof")
+	ErrValueMismatch     = errors.New("leaf value mismatch")
+	ErrInvalidProof      = errors.New("proof does not match root")
+)
+
+// VerifyMembershipProof verifies that a VerkleProof cryptographically proves the values in
+// the StateDiff against treeRoot. go-verkle's Verify() is designed for state transitions,
+// not membership proofs, so the openings are checked directly (see verifyProofOpenings).
+//
+// All expected keys are checked in a single pass over one proof, so a batch
+// multiproof covering every revealed course of a term is verified at once.
 //
-// Security: Without this verification, an attacker could modify the StateDiff
+// Security: Without the opening check, an attacker could modify the StateDiff
 // in a receipt JSON without detection, since the VerkleProof and StateDiff are
 // separate fields in the serialized format.
 func VerifyMembershipProof(proof *verkleLib.VerkleProof, stateDiff verkleLib.StateDiff,
 	treeRoot [32]byte, expectedKeys [][]byte, expectedValues [][32]byte) error {
 
 	if len(expectedKeys) != len(expectedValues) {
-		return fmt.Errorf("keys and values length mismatch")
+		return fmt.Errorf("%w: keys and values length mismatch", ErrMalformedProof)
 	}
 
 	// Step 1: Verify the StateDiff contains all expected keys and values
@@ -41,12 +59,12 @@ func VerifyMembershipProof(proof *verkleLib.VerkleProof, stateDiff verkleLib.Sta
 						found = true
 
 						if suffixDiff.CurrentValue == nil {
-							return fmt.Errorf("key %x has nil value in StateDiff", key)
+							return fmt.Errorf("%w: key %x has nil value in StateDiff", ErrValueMismatch, key)
 						}
 
 						if !bytes.Equal((*suffixDiff.CurrentValue)[:], expectedValue[:]) {
-							return fmt.Errorf("value mismatch for key %x: expected %x, got %x",
-								key, expectedValue, *suffixDiff.CurrentValue)
+							return fmt.Errorf("%w for key %x: expected %x, got %x",
+								ErrValueMismatch, key, expectedValue, *suffixDiff.CurrentValue)
 						}
 						break
 					}
@@ -56,52 +74,157 @@ func VerifyMembershipProof(proof *verkleLib.VerkleProof, stateDiff verkleLib.Sta
 		}
 
 		if !found {
-			return fmt.Errorf("key %x not found in StateDiff", key)
+			return fmt.Errorf("%w: key %x not found in StateDiff", ErrKeyNotProven, key)
 		}
 	}
 
-	// Step 2: Deserialize and verify the IPA proof
+	// Step 2: Check every opening of the proof against the tree root
 	// This is the CRITICAL step that prevents StateDiff tampering
-	internalProof, err := verkleLib.DeserializeProof(proof, stateDiff)
+	preStateTree, err := verifyProofOpenings(proof, stateDiff, treeRoot)
 	if err != nil {
-		return fmt.Errorf("failed to deserialize proof: %w", err)
+		return err
 	}
 
-	// Step 3: Use the proof to reconstruct commitments and verify against tree root
-	// For membership proofs where we used MakeVerkleMultiProof(tree, nil, keys, nil),
-	// we need to verify that the proof commitments match the tree root
+	// Step 3: The proven tree must resolve every key to its expected value
+	for i, key := range expectedKeys {
+		value, err := preStateTree.Get(key, nil)
+		if err != nil {
+			return fmt.Errorf("failed to look up key %x in rebuilt tree: %w", key, err)
+		}
+		if !bytes.Equal(value, expectedValues[i][:]) {
+			return fmt.Errorf("%w for key %x: rebuilt tree holds %x", ErrValueMismatch, key, value)
+		}
+	}
 
-	// Get the commitment from the tree root
-	var rootPoint verkleLib.Point
-	rootPoint.SetBytes(treeRoot[:])
+	return nil
+}
+
+// VerifyNonMembershipProof verifies that none of the absentKeys has a value in the tree
+// committed to by treeRoot. It is the counterpart of VerifyMembershipProof for proofs
+// generated on keys that are not in the tree: the StateDiff must carry a nil CurrentValue
+// for every key, and the tree rebuilt from the proof must resolve each key to no value.
+func VerifyNonMembershipProof(proof *verkleLib.VerkleProof, stateDiff verkleLib.StateDiff,
+	treeRoot [32]byte, absentKeys [][]byte) error {
+
+	if proof == nil {
+		return fmt.Errorf("%w: missing verkle proof", ErrMalformedProof)
+	}
+	if len(absentKeys) == 0 {
+		return fmt.Errorf("%w: no keys to verify", ErrMalformedProof)
+	}
+
+	// Step 1: Every absent key must be listed in the StateDiff without a value
+	for _, key := range absentKeys {
+		var keyHash [32]byte
+		copy(keyHash[:], key)
+
+		keyStem := keyHash[:verkleLib.StemSize]
+		keySuffix := keyHash[verkleLib.StemSize]
+
+		found := false
+		for _, stemDiff := range stateDiff {
+			if bytes.Equal(keyStem, stemDiff.Stem[:]) {
+				for _, suffixDiff := range stemDiff.SuffixDiffs {
+					if keySuffix == suffixDiff.Suffix {
+						found = true
+
+						if suffixDiff.CurrentValue != nil {
+							return fmt.Errorf("%w: key %x has a value in StateDiff, not an absence proof", ErrValueMismatch, key)
+						}
+						break
+					}
+				}
+				break
+			}
+		}
 
-	// Verify that the proof's commitments are consistent with the root
-	// This step cryptographically binds the StateDiff to the VerkleProof
+		if !found {
+			return fmt.Errorf("%w: key %x not found in StateDiff", ErrKeyNotProven, key)
+		}
+	}
 
-	// IMPLEMENTATION NOTE: This requires using go-verkle's internal proof verification
-	// The challenge is that Verify() expects state transitions. For membership proofs,
-	// we need to use the lower-level commitment verification.
+	// Step 2: Check every opening of the proof against the tree root. PreStateTreeFromProof
+	// rejects absence stems that carry a value and unused proof-of-absence stems, and the
+	// multiproof check covers the absence openings, which is what binds the absence to the proof
+	preStateTree, err := verifyProofOpenings(proof, stateDiff, treeRoot)
+	if err != nil {
+		return err
+	}
 
-	// For now, we'll use a workaround: reconstruct the pre-state tree root
-	// from the proof and StateDiff, then verify it matches our expected root
+	// Step 3: The rebuilt tree must not resolve any of the keys to a value
+	for _, key := range absentKeys {
+		value, err := preStateTree.Get(key, nil)
+		if err != nil {
+			return fmt.Errorf("failed to look up key %x in rebuilt tree: %w", key, err)
+		}
+		if len(value) != 0 {
+			return fmt.Errorf("%w: key %x has a value in rebuilt tree", ErrValueMismatch, key)
+		}
+	}
+
+	return nil
+}
+
+// proofTranscriptLabel is the Fiat-Shamir transcript label go-verkle proves with
+const proofTranscriptLabel = "vt"
+
+var (
+	ipaConfigOnce sync.Once
+	ipaConfig     *ipa.IPAConfig
+	ipaConfigErr  error
+)
+
+// verifyProofOpenings rebuilds the partial tree a proof was made from, rooted at the commitment
+// treeRoot, and runs the IPA multiproof check on every opening the go-verkle prover made:
+// root → internal nodes → extension (marker, stem, C1, C2) → leaf values in C1/C2.
+// PreStateTreeFromProof alone only places the proof's commitments in a tree under treeRoot,
+// it does not check that they open to the StateDiff values.
+// The rebuilt tree is returned so callers can look up the proven values.
+func verifyProofOpenings(proof *verkleLib.VerkleProof, stateDiff verkleLib.StateDiff,
+	treeRoot [32]byte) (verkleLib.VerkleNode, error) {
+
+	if proof == nil || proof.IPAProof == nil {
+		return nil, fmt.Errorf("%w: missing verkle proof", ErrMalformedProof)
+	}
+
+	internalProof, err := verkleLib.DeserializeProof(proof, stateDiff)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize proof: %w", ErrMalformedProof, err)
+	}
+
+	var rootPoint verkleLib.Point
+	if err := rootPoint.SetBytes(treeRoot[:]); err != nil {
+		return nil, fmt.Errorf("%w: invalid tree root: %w", ErrInvalidProof, err)
+	}
 
-	// Get the pre-state tree from the proof
 	preStateTree, err := verkleLib.PreStateTreeFromProof(internalProof, &rootPoint)
 	if err != nil {
-		return fmt.Errorf("failed to reconstruct pre-state tree: %w", err)
+		return nil, fmt.Errorf("%w: failed to reconstruct pre-state tree: %w", ErrInvalidProof, err)
 	}
 
-	// Verify the pre-state tree root matches our expected root
-	reconstructedRoot := preStateTree.Commit()
-	reconstructedRootBytes := reconstructedRoot.Bytes()
-	if !bytes.Equal(reconstructedRootBytes[:], treeRoot[:]) {
-		return fmt.Errorf("IPA verification failed: root mismatch %x != %x",
-			reconstructedRootBytes, treeRoot)
+	// Recompute the opened commitments, evaluation points and values the same way the prover
+	// did; the leaf values come from the StateDiff and the root commitment from treeRoot
+	elements, _, _, err := verkleLib.GetCommitmentsForMultiproof(preStateTree, internalProof.Keys, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to rebuild proof openings: %w", ErrInvalidProof, err)
 	}
 
-	log.Printf("✅ Full IPA membership proof verification successful")
-	log.Printf("   - Tree root: %x", treeRoot)
-	log.Printf("   - Keys verified: %d", len(expectedKeys))
+	ipaConfigOnce.Do(func() {
+		ipaConfig, ipaConfigErr = ipa.NewIPASettings()
+	})
+	if ipaConfigErr != nil {
+		return nil, fmt.Errorf("failed to create IPA settings: %w", ipaConfigErr)
+	}
 
-	return nil
+	transcript := common.NewTranscript(proofTranscriptLabel)
+	ok, err := multiproof.CheckMultiProof(transcript, ipaConfig, internalProof.Multipoint,
+		elements.Cis, elements.Yis, elements.Zis)
+	if err != nil {
+		return nil, fmt.Errorf("%w: IPA multiproof check failed: %w", ErrInvalidProof, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: IPA multiproof does not open to root %x", ErrInvalidProof, treeRoot)
+	}
+
+	return preStateTree, nil
 }
@@ -0,0 +1,333 @@
+package verkle
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	verkleLib "github.com/ethereum/go-verkle"
+	bolt "go.etcd.io/bbolt"
+)
+
+// NodeStoreVersion is the layout version written into every node store file
+const NodeStoreVersion = 1
+
+var (
+	nodesBucket     = []byte("nodes") // node path -> go-verkle serialized node
+	metaBucket      = []byte("meta")
+	termMetaKey     = []byte("term")          // JSON of the TermVerkleTree (entries, root, version)
+	storeVersionKey = []byte("store_version") // NodeStoreVersion
+)
+
+// NodeStore persists a term's Verkle tree as go-verkle serialized nodes in an embedded
+// key-value file (bbolt). Nodes are keyed by their path from the root, which is exactly
+// what go-verkle's NodeResolverFn asks for, so a tree opened from the store resolves
+// nodes lazily and produces proofs without re-inserting or re-committing anything.
+type NodeStore struct {
+	db       *bolt.DB
+	path     string
+	readOnly bool
+	closed   bool
+}
+
+// OpenNodeStore opens (or creates, unless readOnly) a node store file.
+// Read-only stores can be opened by several readers at once; a writable store is exclusive.
+func OpenNodeStore(path string, readOnly bool) (*NodeStore, error) {
+	if readOnly {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("node store %s not found: %w", path, err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open node store %s: %w", path, err)
+	}
+
+	if !readOnly {
+		err = db.Update(func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(nodesBucket); err != nil {
+				return err
+			}
+			meta, err := tx.CreateBucketIfNotExists(metaBucket)
+			if err != nil {
+				return err
+			}
+			return meta.Put(storeVersionKey, []byte{NodeStoreVersion})
+		})
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to initialize node store %s: %w", path, err)
+		}
+	}
+
+	store := &NodeStore{db: db, path: path, readOnly: readOnly}
+	if version, err := store.version(); err != nil {
+		db.Close()
+		return nil, err
+	} else if version != NodeStoreVersion {
+		db.Close()
+		return nil, fmt.Errorf("unsupported node store version %d in %s", version, path)
+	}
+
+	return store, nil
+}
+
+// Path returns the file backing the store
+func (s *NodeStore) Path() string {
+	return s.path
+}
+
+// ResolveNode returns the serialized node at the given path. It satisfies verkleLib.NodeResolverFn.
+func (s *NodeStore) ResolveNode(path []byte) ([]byte, error) {
+	var serialized []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(nodesBucket).Get(nodeKey(path))
+		if value == nil {
+			return fmt.Errorf("node at path %x not found in store", path)
+		}
+		// bbolt values are only valid inside the transaction
+		serialized = append([]byte(nil), value...)
+		return nil
+	})
+	return serialized, err
+}
+
+// Close closes the underlying file. Calling Close more than once is a no-op.
+func (s *NodeStore) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.db.Close()
+}
+
+func (s *NodeStore) version() (byte, error) {
+	var version []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if meta == nil {
+			return fmt.Errorf("node store %s has no metadata", s.path)
+		}
+		version = meta.Get(storeVersionKey)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(version) != 1 {
+		return 0, fmt.Errorf("node store %s has no version", s.path)
+	}
+	return version[0], nil
+}
+
+func (s *NodeStore) readMeta() ([]byte, error) {
+	var meta []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(metaBucket).Get(termMetaKey)
+		if value == nil {
+			return fmt.Errorf("node store %s has no term metadata", s.path)
+		}
+		meta = append([]byte(nil), value...)
+		return nil
+	})
+	return meta, err
+}
+
+// writeNodes stores serialized nodes and the term metadata in a single transaction
+func (s *NodeStore) writeNodes(nodes []verkleLib.SerializedNode, meta []byte) error {
+	if s.readOnly {
+		return fmt.Errorf("node store %s is read-only", s.path)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(nodesBucket)
+		for _, node := range nodes {
+			if err := bucket.Put(nodeKey(node.Path), node.SerializedBytes); err != nil {
+				return fmt.Errorf("failed to store node at path %x: %w", node.Path, err)
+			}
+		}
+		return tx.Bucket(metaBucket).Put(termMetaKey, meta)
+	})
+}
+
+// nodeKey prefixes node paths so the root (empty path) gets a valid bbolt key
+func nodeKey(path []byte) []byte {
+	return append([]byte{'n'}, path...)
+}
+
+// OpenTermVerkleTree opens a term tree from a node store for reading and writing
+func OpenTermVerkleTree(path string) (*TermVerkleTree, error) {
+	return openTermVerkleTree(path, false)
+}
+
+// OpenTermVerkleTreeReadOnly opens a term tree from a node store for proof generation only
+func OpenTermVerkleTreeReadOnly(path string) (*TermVerkleTree, error) {
+	return openTermVerkleTree(path, true)
+}
+
+func openTermVerkleTree(path string, readOnly bool) (*TermVerkleTree, error) {
+	store, err := OpenNodeStore(path, readOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := store.readMeta()
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	var tvt TermVerkleTree
+	if err := json.Unmarshal(meta, &tvt); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to parse term metadata in %s: %w", path, err)
+	}
+	if tvt.CourseEntries == nil {
+		tvt.CourseEntries = make(map[string]CourseCompletion)
+	}
+	if tvt.CourseProofs == nil {
+		tvt.CourseProofs = make(map[string][]byte)
+	}
+
+	// Only the root is parsed here; every other node is resolved from the store on demand
+	rootData, err := store.ResolveNode(nil)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load root node of term %s: %w", tvt.TermID, err)
+	}
+	root, err := verkleLib.ParseNode(rootData, 0)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to parse root node of term %s: %w", tvt.TermID, err)
+	}
+
+	if tvt.VerkleRoot != ([32]byte{}) && root.Commitment().Bytes() != tvt.VerkleRoot {
+		store.Close()
+		return nil, fmt.Errorf("node store root %x does not match term root %x", root.Commitment().Bytes(), tvt.VerkleRoot)
+	}
+
+	tvt.tree = root
+	tvt.store = store
+	return &tvt, nil
+}
+
+// AttachNodeStore creates a new node store file at path (replacing any existing file)
+// and attaches it to the tree. The next Flush writes every node of the tree.
+func (tvt *TermVerkleTree) AttachNodeStore(path string) error {
+	if tvt.store != nil {
+		return fmt.Errorf("term %s already has a node store at %s", tvt.TermID, tvt.store.Path())
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace node store %s: %w", path, err)
+	}
+
+	store, err := OpenNodeStore(path, false)
+	if err != nil {
+		return err
+	}
+
+	tvt.store = store
+	tvt.dirty = true
+	return nil
+}
+
+// Flush writes all changed nodes and the term metadata to the node store.
+// It is a no-op for trees without a store or without changes.
+func (tvt *TermVerkleTree) Flush() error {
+	if tvt.store == nil || !tvt.dirty {
+		return nil
+	}
+
+	root, ok := tvt.tree.(*verkleLib.InternalNode)
+	if !ok {
+		return fmt.Errorf("unexpected root node type %T", tvt.tree)
+	}
+
+	// BatchSerialize commits and serializes every node held in memory;
+	// nodes that were never resolved are already on disk unchanged
+	nodes, err := root.BatchSerialize()
+	if err != nil {
+		return fmt.Errorf("failed to serialize verkle nodes: %w", err)
+	}
+
+	meta, err := json.Marshal(tvt)
+	if err != nil {
+		return fmt.Errorf("failed to serialize term metadata: %w", err)
+	}
+
+	if err := tvt.store.writeNodes(nodes, meta); err != nil {
+		return fmt.Errorf("failed to flush term %s: %w", tvt.TermID, err)
+	}
+
+	tvt.dirty = false
+	log.Printf("✅ Flushed %d verkle nodes for term %s to %s", len(nodes), tvt.TermID, tvt.store.Path())
+	return nil
+}
+
+// Close flushes pending changes (for writable stores) and closes the node store
+func (tvt *TermVerkleTree) Close() error {
+	if tvt.store == nil {
+		return nil
+	}
+
+	var flushErr error
+	if !tvt.store.readOnly {
+		flushErr = tvt.Flush()
+	}
+	if err := tvt.store.Close(); err != nil && flushErr == nil {
+		return fmt.Errorf("failed to close node store: %w", err)
+	}
+	return flushErr
+}
+
+// HasNodeStore reports whether the tree is backed by a node store
+func (tvt *TermVerkleTree) HasNodeStore() bool {
+	return tvt.store != nil
+}
+
+// resolver returns the node resolver for trees backed by a node store
+func (tvt *TermVerkleTree) resolver() verkleLib.NodeResolverFn {
+	if tvt.store == nil {
+		return nil
+	}
+	return tvt.store.ResolveNode
+}
+
+// MigrateJSONTree converts a term tree saved with SerializeToJSON into a node store file.
+// The rebuilt root must match the root recorded in the JSON file.
+func MigrateJSONTree(jsonPath, storePath string) (*TermVerkleTree, error) {
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", jsonPath, err)
+	}
+
+	var tvt TermVerkleTree
+	if err := json.Unmarshal(data, &tvt); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", jsonPath, err)
+	}
+	if tvt.CourseProofs == nil {
+		tvt.CourseProofs = make(map[string][]byte)
+	}
+
+	if err := tvt.RebuildVerkleTree(); err != nil {
+		return nil, err
+	}
+
+	rebuiltRoot := tvt.tree.Commit().Bytes()
+	if tvt.VerkleRoot != ([32]byte{}) && rebuiltRoot != tvt.VerkleRoot {
+		return nil, fmt.Errorf("rebuilt root %x does not match recorded root %x for term %s", rebuiltRoot, tvt.VerkleRoot, tvt.TermID)
+	}
+
+	if err := tvt.AttachNodeStore(storePath); err != nil {
+		return nil, err
+	}
+	if err := tvt.Flush(); err != nil {
+		tvt.store.Close()
+		return nil, err
+	}
+
+	log.Printf("✅ Migrated term %s from %s to node store %s", tvt.TermID, jsonPath, storePath)
+	return &tvt, nil
+}
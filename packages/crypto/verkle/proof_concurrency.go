@@ -0,0 +1,236 @@
+package verkle
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync"
+
+	verkleLib "github.com/ethereum/go-verkle"
+)
+
+// Concurrent proof generation
+//
+// go-verkle mutates the tree while building a proof: unresolved (hashed) children of a
+// store-backed tree are parsed and swapped in, and go-ipa normalizes the node commitments
+// it opens in place. PublishTerm and PrepareConcurrentProofs load and commit every node,
+// after which GenerateCourseProof, GenerateBatchCourseProof, GenerateAbsenceProof and the
+// receipt methods may be called from several goroutines; each concurrent prover works on
+// its own copy of the resolved tree. Before that, or after any change to the tree, they run
+// one at a time on the tree itself. Proofs are deterministic, so the output does not depend
+// on how many goroutines produced it.
+
+// PrepareConcurrentProofs loads every node of the tree into memory and commits it so proofs
+// can be generated concurrently. Trees opened from a node store need this; trees built or
+// rebuilt in memory get it from PublishTerm.
+func (tvt *TermVerkleTree) PrepareConcurrentProofs() error {
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+
+	if tvt.resolved {
+		return nil
+	}
+	if _, err := tvt.resolveAndCommit(); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Term %s ready for concurrent proof generation", tvt.TermID)
+	return nil
+}
+
+// AddStudentCourses adds the courses of many students at once. Salts and leaf values are
+// computed on up to workers goroutines (0 means one per CPU); the leaves are then inserted in
+// student order, so the resulting root is the same as adding each student with AddCourses.
+func (tvt *TermVerkleTree) AddStudentCourses(students map[string][]CourseCompletion, workers int) error {
+	studentDIDs := make([]string, 0, len(students))
+	for studentDID := range students {
+		studentDIDs = append(studentDIDs, studentDID)
+	}
+	sort.Strings(studentDIDs)
+
+	type studentLeaves struct {
+		courses []CourseCompletion
+		keys    [][][]byte
+		values  [][][32]byte
+		err     error
+	}
+	prepared := make([]studentLeaves, len(studentDIDs))
+
+	RunWorkers(len(studentDIDs), workers, func(i int) {
+		studentDID := studentDIDs[i]
+		result := studentLeaves{courses: append([]CourseCompletion(nil), students[studentDID]...)}
+		for j, course := range result.courses {
+			// Salted trees get a fresh random salt per course unless the caller supplied one
+			if tvt.TreeFormat != TreeFormatUnsalted && course.Salt == "" {
+				salt, err := NewLeafSalt()
+				if err != nil {
+					result.err = err
+					break
+				}
+				course.Salt = salt
+				result.courses[j] = course
+			}
+
+			courseKey := fmt.Sprintf("%s:%s:%s", studentDID, tvt.TermID, course.CourseID)
+			keys, values, err := courseLeaves(courseKey, course, tvt.LeafEncoding, tvt.TreeFormat, nil)
+			if err != nil {
+				result.err = err
+				break
+			}
+			result.keys = append(result.keys, keys)
+			result.values = append(result.values, values)
+		}
+		prepared[i] = result
+	})
+
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+	tvt.invalidateProofTrees()
+
+	// The tree itself is not safe for concurrent inserts
+	for i, studentDID := range studentDIDs {
+		result := prepared[i]
+		if result.err != nil {
+			return fmt.Errorf("failed to prepare courses for student %s: %w", studentDID, result.err)
+		}
+		for j, course := range result.courses {
+			courseKey := fmt.Sprintf("%s:%s:%s", studentDID, tvt.TermID, course.CourseID)
+			tvt.CourseEntries[courseKey] = course
+			for k := range result.keys[j] {
+				if err := tvt.tree.Insert(result.keys[j][k], result.values[j][k][:], tvt.resolver()); err != nil {
+					return fmt.Errorf("failed to insert course %s into verkle tree: %w", course.CourseID, err)
+				}
+			}
+		}
+		tvt.dirty = true
+	}
+
+	log.Printf("✅ Added courses for %d students to term %s", len(studentDIDs), tvt.TermID)
+	return nil
+}
+
+// lockForProofs takes the tree lock for proof generation: shared once the tree is resolved,
+// exclusive otherwise. It returns the matching unlock function.
+func (tvt *TermVerkleTree) lockForProofs() func() {
+	tvt.treeMu.RLock()
+	if tvt.resolved {
+		return tvt.treeMu.RUnlock
+	}
+	tvt.treeMu.RUnlock()
+
+	tvt.treeMu.Lock()
+	return tvt.treeMu.Unlock
+}
+
+// acquireProofTree returns the tree a proof should be built from. Once resolved, every
+// concurrent prover gets its own copy so the shared tree is never written to.
+func (tvt *TermVerkleTree) acquireProofTree() verkleLib.VerkleNode {
+	if !tvt.resolved {
+		return tvt.tree
+	}
+
+	tvt.proofCacheMu.Lock()
+	if n := len(tvt.proofTrees); n > 0 {
+		proofTree := tvt.proofTrees[n-1]
+		tvt.proofTrees = tvt.proofTrees[:n-1]
+		tvt.proofCacheMu.Unlock()
+		return proofTree
+	}
+	tvt.proofCacheMu.Unlock()
+
+	return tvt.tree.Copy()
+}
+
+// releaseProofTree hands a tree copy from acquireProofTree back for reuse
+func (tvt *TermVerkleTree) releaseProofTree(proofTree verkleLib.VerkleNode) {
+	if !tvt.resolved || proofTree == tvt.tree {
+		return
+	}
+
+	tvt.proofCacheMu.Lock()
+	tvt.proofTrees = append(tvt.proofTrees, proofTree)
+	tvt.proofCacheMu.Unlock()
+}
+
+// invalidateProofTrees drops the resolved state and its copies before the tree changes.
+// The caller holds treeMu exclusively.
+func (tvt *TermVerkleTree) invalidateProofTrees() {
+	tvt.resolved = false
+	tvt.proofTrees = nil
+}
+
+// resolveAndCommit loads all unresolved nodes and commits the tree. The caller holds treeMu exclusively.
+func (tvt *TermVerkleTree) resolveAndCommit() (*verkleLib.Point, error) {
+	if tvt.tree == nil {
+		return nil, fmt.Errorf("term %s has no verkle tree loaded", tvt.TermID)
+	}
+	if root, ok := tvt.tree.(*verkleLib.InternalNode); ok {
+		if err := resolveAllNodes(root, nil, tvt.resolver()); err != nil {
+			return nil, fmt.Errorf("failed to load verkle nodes of term %s: %w", tvt.TermID, err)
+		}
+	}
+
+	commitment := tvt.tree.Commit()
+	tvt.proofTrees = nil
+	tvt.resolved = true
+	return commitment, nil
+}
+
+// resolveAllNodes replaces every hashed child below node with the node parsed from the resolver
+func resolveAllNodes(node *verkleLib.InternalNode, path []byte, resolver verkleLib.NodeResolverFn) error {
+	for i, child := range node.Children() {
+		childPath := append(append([]byte(nil), path...), byte(i))
+
+		if _, ok := child.(verkleLib.HashedNode); ok {
+			if resolver == nil {
+				return fmt.Errorf("no resolver for node at path %x", childPath)
+			}
+			serialized, err := resolver(childPath)
+			if err != nil {
+				return err
+			}
+			child, err = verkleLib.ParseNode(serialized, byte(len(childPath)))
+			if err != nil {
+				return fmt.Errorf("failed to parse node at path %x: %w", childPath, err)
+			}
+			if err := node.SetChild(i, child); err != nil {
+				return err
+			}
+		}
+
+		if internal, ok := child.(*verkleLib.InternalNode); ok {
+			if err := resolveAllNodes(internal, childPath, resolver); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RunWorkers calls fn for every index in [0, n) on up to workers goroutines (0 means one per CPU)
+func RunWorkers(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,147 @@
+package verkle
+
+import (
+	"fmt"
+	"time"
+
+	verkleLib "github.com/ethereum/go-verkle"
+)
+
+// RevocationDeltaProof cryptographically links a superseded term root to its replacement.
+//
+// The proof is a go-verkle pre/post multiproof over the revoked keys only: the StateDiff
+// carries each revoked course's old value as CurrentValue and RevokedLeafValue as NewValue.
+// Verification rebuilds the post-state tree from the pre-state tree and the StateDiff and
+// requires its commitment to equal NewRoot. Any key that changed without being listed would
+// produce a different root, so every other key of the old tree is proven unchanged.
+type RevocationDeltaProof struct {
+	TermID      string                 `json:"term_id"`
+	OldVersion  uint32                 `json:"old_version"`
+	NewVersion  uint32                 `json:"new_version"`
+	OldRoot     [32]byte               `json:"old_root"`
+	NewRoot     [32]byte               `json:"new_root"`
+	RevokedKeys []string               `json:"revoked_keys"`          // courseKeys (studentDID:termID:courseID)
+	TreeFormat  uint8                  `json:"tree_format,omitempty"` // field-leaf trees tombstone every field leaf
+	VerkleProof *verkleLib.VerkleProof `json:"verkle_proof"`
+	StateDiff   verkleLib.StateDiff    `json:"state_diff"`
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+// GenerateRevocationDeltaProof proves that newTree equals oldTree with exactly revokedKeys
+// replaced by revocation tombstones. Both trees must have their internal trees loaded;
+// store-backed trees must share their node store (newTree created with oldTree.Clone).
+func GenerateRevocationDeltaProof(oldTree, newTree *TermVerkleTree, revokedKeys []string) (*RevocationDeltaProof, error) {
+	if oldTree.TermID != newTree.TermID {
+		return nil, fmt.Errorf("term mismatch: %s != %s", oldTree.TermID, newTree.TermID)
+	}
+	if len(revokedKeys) == 0 {
+		return nil, fmt.Errorf("no revoked keys given for delta proof")
+	}
+	if oldTree.TreeFormat != newTree.TreeFormat {
+		return nil, fmt.Errorf("tree format mismatch: %d != %d", oldTree.TreeFormat, newTree.TreeFormat)
+	}
+
+	keyHashes := make([][]byte, 0, len(revokedKeys))
+	seen := make(map[string]bool, len(revokedKeys))
+	for _, courseKey := range revokedKeys {
+		if seen[courseKey] {
+			return nil, fmt.Errorf("duplicate revoked key %s", courseKey)
+		}
+		seen[courseKey] = true
+
+		if _, exists := oldTree.CourseEntries[courseKey]; !exists {
+			return nil, fmt.Errorf("revoked key %s is not a credential in the old tree", courseKey)
+		}
+		if !newTree.IsRevoked(courseKey) {
+			return nil, fmt.Errorf("key %s is not revoked in the new tree", courseKey)
+		}
+
+		keyHashes = append(keyHashes, courseLeafKeys(courseKey, oldTree.TreeFormat)...)
+	}
+
+	// Proof elements are read from the cached commitments, so make sure both are current
+	oldRoot := oldTree.tree.Commit().Bytes()
+	newRoot := newTree.tree.Commit().Bytes()
+
+	proof, _, _, _, err := verkleLib.MakeVerkleMultiProof(oldTree.tree, newTree.tree, keyHashes, oldTree.resolver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate revocation delta multiproof: %w", err)
+	}
+
+	verkleProof, stateDiff, err := verkleLib.SerializeProof(proof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize revocation delta proof: %w", err)
+	}
+
+	delta := &RevocationDeltaProof{
+		TermID:      oldTree.TermID,
+		OldVersion:  oldTree.Version,
+		NewVersion:  newTree.Version,
+		OldRoot:     oldRoot,
+		NewRoot:     newRoot,
+		RevokedKeys: append([]string(nil), revokedKeys...),
+		TreeFormat:  oldTree.TreeFormat,
+		VerkleProof: verkleProof,
+		StateDiff:   stateDiff,
+		GeneratedAt: time.Now(),
+	}
+
+	return delta, nil
+}
+
+// VerifyRevocationDeltaProof checks that newRoot is oldRoot with exactly the listed courses revoked.
+// It needs nothing but the proof and the two roots, e.g. as read from the registry contract.
+func VerifyRevocationDeltaProof(delta *RevocationDeltaProof, oldRoot, newRoot [32]byte) error {
+	if delta == nil || delta.VerkleProof == nil {
+		return fmt.Errorf("missing revocation delta proof")
+	}
+	if delta.OldRoot != oldRoot {
+		return fmt.Errorf("old root mismatch: proof has %x, expected %x", delta.OldRoot, oldRoot)
+	}
+	if delta.NewRoot != newRoot {
+		return fmt.Errorf("new root mismatch: proof has %x, expected %x", delta.NewRoot, newRoot)
+	}
+	if len(delta.RevokedKeys) == 0 {
+		return fmt.Errorf("revocation delta proof lists no revoked keys")
+	}
+
+	// Step 1: The StateDiff must cover exactly the listed keys, each going from a
+	// credential value to the revocation tombstone
+	expected := make(map[[32]byte]string, len(delta.RevokedKeys))
+	for _, courseKey := range delta.RevokedKeys {
+		for _, key := range courseLeafKeys(courseKey, delta.TreeFormat) {
+			expected[[32]byte(key)] = courseKey
+		}
+	}
+
+	covered := 0
+	for _, stemDiff := range delta.StateDiff {
+		for _, suffixDiff := range stemDiff.SuffixDiffs {
+			var key [32]byte
+			copy(key[:verkleLib.StemSize], stemDiff.Stem[:])
+			key[verkleLib.StemSize] = suffixDiff.Suffix
+
+			courseKey, listed := expected[key]
+			if !listed {
+				return fmt.Errorf("state diff changes unlisted key %x", key)
+			}
+			if suffixDiff.CurrentValue == nil || *suffixDiff.CurrentValue == RevokedLeafValue {
+				return fmt.Errorf("revoked key %s was not a credential in the old root", courseKey)
+			}
+			if suffixDiff.NewValue == nil || *suffixDiff.NewValue != RevokedLeafValue {
+				return fmt.Errorf("revoked key %s is not tombstoned in the new root", courseKey)
+			}
+			covered++
+		}
+	}
+	if covered != len(expected) {
+		return fmt.Errorf("state diff covers %d keys, proof lists %d revoked leaves", covered, len(expected))
+	}
+
+	// Step 2: Full IPA check of the pre-state openings plus post-state root reconstruction
+	if err := verkleLib.Verify(delta.VerkleProof, oldRoot[:], newRoot[:], delta.StateDiff); err != nil {
+		return fmt.Errorf("revocation delta proof verification failed: %w", err)
+	}
+
+	return nil
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	verkleLib "github.com/ethereum/go-verkle"
@@ -27,6 +29,8 @@ type CourseCompletion struct {
 	Grade       string    `json:"grade"`
 	Credits     uint8     `json:"credits"`
 	Instructor  string    `json:"instructor"`
+	Salt        string    `json:"salt,omitempty"` // hex leaf salt, only set in salted trees
+	FieldSalts  map[string]string `json:"field_salts,omitempty"` // field -> hex salt, only for disclosed fields of field-leaf trees
 }
 
 // TermVerkleTree manages a single Verkle tree containing all courses for a term
@@ -34,12 +38,26 @@ type TermVerkleTree struct {
 	TermID           string                              `json:"term_id"`
 	PublishedAt      time.Time                           `json:"published_at"`
 	Version          uint32                              `json:"version"`
+	LeafEncoding     uint8                               `json:"leaf_encoding"` // LeafEncodingJSON (0) for trees created before canonical encoding
+	TreeFormat       uint8                               `json:"tree_format"`   // TreeFormatUnsalted (0) for trees created before leaf salts
 	VerkleRoot       [32]byte                            `json:"verkle_root"`
 	CourseEntries    map[string]CourseCompletion         `json:"course_entries"` // courseKey -> CourseCompletion
 	CourseProofs     map[string][]byte                   `json:"course_proofs"`  // courseKey -> serialized Verkle proof
+	RevokedKeys      []string                            `json:"revoked_keys,omitempty"` // courseKeys whose leaf holds RevokedLeafValue
 	tree             verkleLib.VerkleNode                // Internal tree (not serialized)
+	store            *NodeStore                          // Optional on-disk node store (see node_store.go)
+	dirty            bool                                // Tree or metadata changed since the last Flush
+	resolved         bool                                // Every node is in memory and committed (see proof_concurrency.go)
+	treeMu           sync.RWMutex                        // Shared by proof generation once resolved, exclusive otherwise
+	proofCacheMu     sync.Mutex                          // Guards CourseProofs and proofTrees during concurrent proof generation
+	proofTrees       []verkleLib.VerkleNode              // Idle copies of the resolved tree for concurrent provers
 }
 
+// RevokedLeafValue is the tombstone written over a revoked course leaf. Overwriting instead of
+// deleting keeps old and new roots linked by a go-verkle state diff (see revocation_delta.go).
+// No course serialization hashes to this value, so a tombstoned leaf never proves a credential.
+var RevokedLeafValue = sha256.Sum256([]byte("iumicert:revoked-credential"))
+
 // VerificationReceipt contains all data needed for off-chain or on-chain verification
 type VerificationReceipt struct {
 	TermID          string                      `json:"term_id"`
@@ -48,6 +66,9 @@ type VerificationReceipt struct {
 	PublishedAt     time.Time                   `json:"published_at"`
 	RevealedCourses []CourseCompletion          `json:"revealed_courses"`
 	CourseProofs    map[string]json.RawMessage  `json:"course_proofs"`   // courseID -> verkle proof JSON
+	BatchProof      json.RawMessage             `json:"batch_proof,omitempty"` // single multiproof for all revealed courses
+	ProofFormat     string                      `json:"proof_format,omitempty"` // "per_course" (default) or "batch_multiproof"
+	DisclosedFields []string                    `json:"disclosed_fields,omitempty"` // field mask for field-leaf trees; empty means whole courses
 	SelectiveDisclosure bool                    `json:"selective_disclosure"`
 	Metadata        ReceiptMetadata             `json:"metadata"`
 }
@@ -62,18 +83,49 @@ type ReceiptMetadata struct {
 
 // VerkleProofBundle holds all data needed for cryptographic verification
 type VerkleProofBundle struct {
+	VerkleProof  *verkleLib.VerkleProof `json:"verkle_proof"`
+	StateDiff    verkleLib.StateDiff    `json:"state_diff"`
+	CourseKey    string                 `json:"course_key"`
+	CourseID     string                 `json:"course_id"`
+	LeafEncoding uint8                  `json:"leaf_encoding,omitempty"` // how the course was hashed into its leaf
+	TreeFormat   uint8                  `json:"tree_format,omitempty"`   // whether the leaf is salted or split per field
+	Fields       []string               `json:"fields,omitempty"`        // proven fields, field-leaf trees only
+}
+
+// VerkleBatchProofBundle holds a single multiproof covering several courses of one student
+type VerkleBatchProofBundle struct {
+	VerkleProof  *verkleLib.VerkleProof `json:"verkle_proof"`
+	StateDiff    verkleLib.StateDiff    `json:"state_diff"`
+	CourseKeys   []string               `json:"course_keys"`
+	CourseIDs    []string               `json:"course_ids"`
+	LeafEncoding uint8                  `json:"leaf_encoding,omitempty"`
+	TreeFormat   uint8                  `json:"tree_format,omitempty"`
+	Fields       []string               `json:"fields,omitempty"` // proven fields of every course, field-leaf trees only
+}
+
+// VerkleAbsenceProofBundle holds a Verkle proof that a course key has no value in the term tree
+type VerkleAbsenceProofBundle struct {
 	VerkleProof *verkleLib.VerkleProof `json:"verkle_proof"`
 	StateDiff   verkleLib.StateDiff    `json:"state_diff"`
 	CourseKey   string                 `json:"course_key"`
 	CourseID    string                 `json:"course_id"`
+	Revoked     bool                   `json:"revoked,omitempty"` // leaf holds RevokedLeafValue instead of being empty
 }
 
+// Receipt proof formats
+const (
+	ProofFormatPerCourse = "per_course"       // one VerkleProofBundle per course (legacy)
+	ProofFormatBatch     = "batch_multiproof" // one VerkleBatchProofBundle for all revealed courses
+)
+
 // NewTermVerkleTree creates a new term-level Verkle tree
 func NewTermVerkleTree(termID string) *TermVerkleTree {
 	return &TermVerkleTree{
 		TermID:        termID,
 		CourseEntries: make(map[string]CourseCompletion),
 		CourseProofs:  make(map[string][]byte),
+		LeafEncoding:  CurrentLeafEncoding,
+		TreeFormat:    CurrentTreeFormat,
 		tree:          verkleLib.New(),
 	}
 }
@@ -82,48 +134,82 @@ func NewTermVerkleTree(termID string) *TermVerkleTree {
 func (tvt *TermVerkleTree) AddCourses(studentDID string, courses []CourseCompletion) error {
 	log.Printf("Adding %d courses for student %s to term %s", len(courses), studentDID, tvt.TermID)
 	
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+	tvt.invalidateProofTrees()
+	
 	for _, course := range courses {
 		// Generate deterministic key for each course
 		courseKey := fmt.Sprintf("%s:%s:%s", studentDID, tvt.TermID, course.CourseID)
-		courseKeyHash := sha256.Sum256([]byte(courseKey))
 		
-		// Serialize course data as value
-		courseData, err := json.Marshal(course)
+		// Salted trees get a fresh random salt per course unless the caller supplied one
+		if tvt.TreeFormat != TreeFormatUnsalted && course.Salt == "" {
+			salt, err := NewLeafSalt()
+			if err != nil {
+				return err
+			}
+			course.Salt = salt
+		}
+		
+		// Serialize course data as value(s) using the tree's leaf encoding and format
+		keys, values, err := courseLeaves(courseKey, course, tvt.LeafEncoding, tvt.TreeFormat, nil)
 		if err != nil {
-			return fmt.Errorf("failed to serialize course %s: %w", course.CourseID, err)
+			return err
 		}
-		courseValueHash := sha256.Sum256(courseData)
 		
 		// Store course entry for later retrieval
 		tvt.CourseEntries[courseKey] = course
 		
 		// Add to Verkle tree: key = H(studentDID:termID:courseID), value = H(course_data)
-		err = tvt.tree.Insert(courseKeyHash[:], courseValueHash[:], nil)
-		if err != nil {
-			return fmt.Errorf("failed to insert course %s into verkle tree: %w", course.CourseID, err)
+		// (field-leaf trees add one leaf per field under the same stem)
+		for i := range keys {
+			err = tvt.tree.Insert(keys[i], values[i][:], tvt.resolver())
+			if err != nil {
+				return fmt.Errorf("failed to insert course %s into verkle tree: %w", course.CourseID, err)
+			}
 		}
 		
+		tvt.dirty = true
+		
 		log.Printf("✅ Course %s added for student %s", course.CourseID, studentDID)
 	}
 	
 	return nil
 }
 
-// GenerateCourseProof creates a proper cryptographic Verkle proof for a specific course
-func (tvt *TermVerkleTree) GenerateCourseProof(studentDID, courseID string) ([]byte, error) {
+// GenerateCourseProof creates a proper cryptographic Verkle proof for a specific course.
+// Field-leaf trees accept a field mask to prove only some fields; other formats prove the whole course.
+func (tvt *TermVerkleTree) GenerateCourseProof(studentDID, courseID string, fields ...string) ([]byte, error) {
+	unlock := tvt.lockForProofs()
+	defer unlock()
+	return tvt.generateCourseProof(studentDID, courseID, fields)
+}
+
+func (tvt *TermVerkleTree) generateCourseProof(studentDID, courseID string, fields []string) ([]byte, error) {
 	courseKey := fmt.Sprintf("%s:%s:%s", studentDID, tvt.TermID, courseID)
-	courseKeyHash := sha256.Sum256([]byte(courseKey))
 	
 	// Check if course exists
-	if _, exists := tvt.CourseEntries[courseKey]; !exists {
+	course, exists := tvt.CourseEntries[courseKey]
+	if !exists {
 		return nil, fmt.Errorf("course %s not found for student %s in term %s", courseID, studentDID, tvt.TermID)
 	}
 	
+	fieldMask, err := tvt.fieldMask(fields)
+	if err != nil {
+		return nil, err
+	}
+	keys, _, err := courseLeaves(courseKey, course, tvt.LeafEncoding, tvt.TreeFormat, fieldMask)
+	if err != nil {
+		return nil, err
+	}
+	
 	// Generate Verkle membership proof (following Duc's approach)
 	// For proving keys exist: preroot = current tree, postroot = nil
 	// This generates a proof showing the keys exist in the current tree
 	// MakeVerkleMultiProof(preTree, postTree, keys, resolver)
-	proof, _, _, _, err := verkleLib.MakeVerkleMultiProof(tvt.tree, nil, [][]byte{courseKeyHash[:]}, nil)
+	proofTree := tvt.acquireProofTree()
+	proof, _, _, _, err := verkleLib.MakeVerkleMultiProof(proofTree, nil, keys, tvt.resolver())
+	tvt.releaseProofTree(proofTree)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate verkle proof for course %s: %w", courseID, err)
 	}
@@ -138,10 +224,13 @@ func (tvt *TermVerkleTree) GenerateCourseProof(studentDID, courseID string) ([]b
 	// Solution: Use Go's binary encoding (gob) for the proof, then Base64 encode for JSON storage
 	
 	proofBundle := VerkleProofBundle{
-		VerkleProof: verkleProof,
-		StateDiff:   stateDiff,
-		CourseKey:   courseKey,
-		CourseID:    courseID,
+		VerkleProof:  verkleProof,
+		StateDiff:    stateDiff,
+		CourseKey:    courseKey,
+		CourseID:     courseID,
+		LeafEncoding: tvt.LeafEncoding,
+		TreeFormat:   tvt.TreeFormat,
+		Fields:       fieldMask,
 	}
 	
 	// Serialize using JSON (VerkleProof and StateDiff support JSON marshaling)
@@ -153,13 +242,129 @@ func (tvt *TermVerkleTree) GenerateCourseProof(studentDID, courseID string) ([]b
 	// Return the JSON data directly - no encoding needed for JSON storage
 	proofData := proofJSON
 	
-	// Store the proof for later use
-	tvt.CourseProofs[courseKey] = proofData
+	// Store the proof for later use (whole-course proofs only)
+	if len(fields) == 0 {
+		tvt.proofCacheMu.Lock()
+		tvt.CourseProofs[courseKey] = proofData
+		tvt.proofCacheMu.Unlock()
+	}
 	
 	log.Printf("✅ Generated cryptographic Verkle proof for course %s (student: %s)", courseID, studentDID)
 	return proofData, nil
 }
 
+// GenerateBatchCourseProof creates one Verkle multiproof covering all given courses of a student.
+// The proof is considerably smaller than one proof per course since shared internal
+// commitments and the IPA argument are only included once.
+func (tvt *TermVerkleTree) GenerateBatchCourseProof(studentDID string, courseIDs []string, fields ...string) ([]byte, error) {
+	unlock := tvt.lockForProofs()
+	defer unlock()
+	return tvt.generateBatchCourseProof(studentDID, courseIDs, fields)
+}
+
+func (tvt *TermVerkleTree) generateBatchCourseProof(studentDID string, courseIDs []string, fields []string) ([]byte, error) {
+	if len(courseIDs) == 0 {
+		return nil, fmt.Errorf("no courses given for batch proof")
+	}
+
+	fieldMask, err := tvt.fieldMask(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	courseKeys := make([]string, 0, len(courseIDs))
+	keyHashes := make([][]byte, 0, len(courseIDs))
+	for _, courseID := range courseIDs {
+		courseKey := fmt.Sprintf("%s:%s:%s", studentDID, tvt.TermID, courseID)
+		course, exists := tvt.CourseEntries[courseKey]
+		if !exists {
+			return nil, fmt.Errorf("course %s not found for student %s in term %s", courseID, studentDID, tvt.TermID)
+		}
+		keys, _, err := courseLeaves(courseKey, course, tvt.LeafEncoding, tvt.TreeFormat, fieldMask)
+		if err != nil {
+			return nil, err
+		}
+		courseKeys = append(courseKeys, courseKey)
+		keyHashes = append(keyHashes, keys...)
+	}
+
+	// A single MakeVerkleMultiProof call opens every requested key at once
+	proofTree := tvt.acquireProofTree()
+	proof, _, _, _, err := verkleLib.MakeVerkleMultiProof(proofTree, nil, keyHashes, tvt.resolver())
+	tvt.releaseProofTree(proofTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verkle multiproof for %d courses: %w", len(courseIDs), err)
+	}
+
+	verkleProof, stateDiff, err := verkleLib.SerializeProof(proof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize verkle multiproof: %w", err)
+	}
+
+	proofBundle := VerkleBatchProofBundle{
+		VerkleProof:  verkleProof,
+		StateDiff:    stateDiff,
+		CourseKeys:   courseKeys,
+		CourseIDs:    append([]string(nil), courseIDs...),
+		LeafEncoding: tvt.LeafEncoding,
+		TreeFormat:   tvt.TreeFormat,
+		Fields:       fieldMask,
+	}
+
+	proofJSON, err := json.Marshal(proofBundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize batch proof bundle: %w", err)
+	}
+
+	log.Printf("✅ Generated batch Verkle multiproof for %d courses (student: %s)", len(courseIDs), studentDID)
+	return proofJSON, nil
+}
+
+// GenerateAbsenceProof creates a Verkle non-membership proof showing that a student has no
+// credential for the given course in this term (never issued, or removed by a revocation)
+func (tvt *TermVerkleTree) GenerateAbsenceProof(studentDID, courseID string) ([]byte, error) {
+	courseKey := fmt.Sprintf("%s:%s:%s", studentDID, tvt.TermID, courseID)
+	courseKeyHash := sha256.Sum256([]byte(courseKey))
+
+	// Entries change under AddStudentCourses and RevokeCourse, so check them under the lock
+	unlock := tvt.lockForProofs()
+	defer unlock()
+
+	if _, exists := tvt.CourseEntries[courseKey]; exists {
+		return nil, fmt.Errorf("course %s exists for student %s in term %s, cannot prove absence", courseID, studentDID, tvt.TermID)
+	}
+
+	// Opening an absent key yields an empty pre-value plus the extension status
+	// (and other stem, if any) showing the stem is not in the tree
+	proofTree := tvt.acquireProofTree()
+	proof, _, _, _, err := verkleLib.MakeVerkleMultiProof(proofTree, nil, [][]byte{courseKeyHash[:]}, tvt.resolver())
+	tvt.releaseProofTree(proofTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate verkle absence proof for course %s: %w", courseID, err)
+	}
+
+	verkleProof, stateDiff, err := verkleLib.SerializeProof(proof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize verkle absence proof for course %s: %w", courseID, err)
+	}
+
+	proofBundle := VerkleAbsenceProofBundle{
+		VerkleProof: verkleProof,
+		StateDiff:   stateDiff,
+		CourseKey:   courseKey,
+		CourseID:    courseID,
+		Revoked:     tvt.IsRevoked(courseKey),
+	}
+
+	proofJSON, err := json.Marshal(proofBundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize absence proof bundle for course %s: %w", courseID, err)
+	}
+
+	log.Printf("✅ Generated Verkle absence proof for course %s (student: %s)", courseID, studentDID)
+	return proofJSON, nil
+}
+
 // PublishTerm computes the final Verkle root and prepares for blockchain publication
 func (tvt *TermVerkleTree) PublishTerm() error {
 	log.Printf("Publishing term %s with %d courses", tvt.TermID, len(tvt.CourseEntries))
@@ -168,8 +373,15 @@ func (tvt *TermVerkleTree) PublishTerm() error {
 		return fmt.Errorf("cannot publish term %s: no courses added", tvt.TermID)
 	}
 	
-	// Compute Verkle tree commitment
-	commitment := tvt.tree.Commit()
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+	
+	// Compute Verkle tree commitment with every node in memory, so proofs
+	// for the published root can be generated concurrently
+	commitment, err := tvt.resolveAndCommit()
+	if err != nil {
+		return err
+	}
 	if commitment == nil {
 		return fmt.Errorf("failed to compute verkle tree commitment")
 	}
@@ -178,13 +390,37 @@ func (tvt *TermVerkleTree) PublishTerm() error {
 	tvt.VerkleRoot = commitment.Bytes()
 	tvt.PublishedAt = time.Now()
 	tvt.Version++
+	tvt.dirty = true
 	
 	log.Printf("✅ Term %s published successfully, Verkle root: %x", tvt.TermID, tvt.VerkleRoot)
 	return nil
 }
 
-// GenerateStudentReceipt creates a verification receipt for specific courses using single Verkle tree
-func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs []string) (*VerificationReceipt, error) {
+// GenerateStudentReceipt creates a verification receipt for specific courses using single Verkle tree.
+// For field-leaf trees an optional field mask (e.g. "course_id", "credits") limits what is disclosed
+// of each course; course_id is always included.
+func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs []string, fields ...string) (*VerificationReceipt, error) {
+	return tvt.generateStudentReceipt(studentDID, courseIDs, false, fields)
+}
+
+// GenerateStudentBatchReceipt creates a verification receipt where a single Verkle multiproof
+// covers all revealed courses instead of one proof per course
+func (tvt *TermVerkleTree) GenerateStudentBatchReceipt(studentDID string, courseIDs []string, fields ...string) (*VerificationReceipt, error) {
+	return tvt.generateStudentReceipt(studentDID, courseIDs, true, fields)
+}
+
+// fieldMask normalizes a requested field mask. Only field-leaf trees support one.
+func (tvt *TermVerkleTree) fieldMask(fields []string) ([]string, error) {
+	if tvt.TreeFormat != TreeFormatFieldLeaves {
+		if len(fields) > 0 {
+			return nil, fmt.Errorf("term %s does not support field-level disclosure (tree format %d)", tvt.TermID, tvt.TreeFormat)
+		}
+		return nil, nil
+	}
+	return NormalizeFieldMask(fields)
+}
+
+func (tvt *TermVerkleTree) generateStudentReceipt(studentDID string, courseIDs []string, batch bool, fields []string) (*VerificationReceipt, error) {
 	log.Printf("Generating student receipt for %s, courses: %v", studentDID, courseIDs)
 	
 	// Check if term is published
@@ -192,6 +428,14 @@ func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs [
 		return nil, fmt.Errorf("term %s not yet published", tvt.TermID)
 	}
 	
+	fieldMask, err := tvt.fieldMask(fields)
+	if err != nil {
+		return nil, err
+	}
+	
+	unlock := tvt.lockForProofs()
+	defer unlock()
+	
 	// Get all courses for this student from the single Verkle tree
 	var studentCourses []CourseCompletion
 	courseProofs := make(map[string]json.RawMessage)
@@ -199,13 +443,20 @@ func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs [
 	// If no specific courses requested, find all courses for this student
 	if len(courseIDs) == 0 {
 		// Find all courses for this student by scanning CourseEntries
-		for courseKey, course := range tvt.CourseEntries {
+		// (in key order so receipts do not depend on map iteration order)
+		var studentKeys []string
+		for courseKey := range tvt.CourseEntries {
 			// Check if this course belongs to the student
 			if strings.HasPrefix(courseKey, studentDID+":") {
-				studentCourses = append(studentCourses, course)
-				courseIDs = append(courseIDs, course.CourseID)
+				studentKeys = append(studentKeys, courseKey)
 			}
 		}
+		sort.Strings(studentKeys)
+		for _, courseKey := range studentKeys {
+			course := tvt.CourseEntries[courseKey]
+			studentCourses = append(studentCourses, course)
+			courseIDs = append(courseIDs, course.CourseID)
+		}
 	} else {
 		// Collect specific requested courses
 		for _, courseID := range courseIDs {
@@ -222,15 +473,30 @@ func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs [
 		return nil, fmt.Errorf("no courses found for student %s in term %s", studentDID, tvt.TermID)
 	}
 	
-	// Generate Verkle proofs for each course
-	for _, courseID := range courseIDs {
-		proof, err := tvt.GenerateCourseProof(studentDID, courseID)
+	// Generate Verkle proofs: one multiproof for all revealed courses, or one per course
+	var batchProof json.RawMessage
+	proofFormat := ProofFormatPerCourse
+	if batch {
+		revealedIDs := make([]string, 0, len(studentCourses))
+		for _, course := range studentCourses {
+			revealedIDs = append(revealedIDs, course.CourseID)
+		}
+		proof, err := tvt.generateBatchCourseProof(studentDID, revealedIDs, fieldMask)
 		if err != nil {
-			log.Printf("Warning: failed to generate proof for course %s: %v", courseID, err)
-			continue
+			return nil, fmt.Errorf("failed to generate batch proof for student %s: %w", studentDID, err)
+		}
+		batchProof = json.RawMessage(proof)
+		proofFormat = ProofFormatBatch
+	} else {
+		for _, courseID := range courseIDs {
+			proof, err := tvt.generateCourseProof(studentDID, courseID, fieldMask)
+			if err != nil {
+				log.Printf("Warning: failed to generate proof for course %s: %v", courseID, err)
+				continue
+			}
+			// Store as json.RawMessage to avoid double JSON encoding
+			courseProofs[courseID] = json.RawMessage(proof)
 		}
-		// Store as json.RawMessage to avoid double JSON encoding
-		courseProofs[courseID] = json.RawMessage(proof)
 	}
 	
 	// Get total courses for this student for metadata
@@ -241,6 +507,17 @@ func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs [
 		}
 	}
 	
+	// Field-leaf trees never reveal the course salt, only the disclosed fields and their salts
+	if fieldMask != nil {
+		for i, course := range studentCourses {
+			redacted, err := RedactCourse(course, fieldMask)
+			if err != nil {
+				return nil, err
+			}
+			studentCourses[i] = redacted
+		}
+	}
+	
 	// Create verification receipt with single Verkle structure
 	receipt := &VerificationReceipt{
 		TermID:              tvt.TermID,
@@ -249,6 +526,9 @@ func (tvt *TermVerkleTree) GenerateStudentReceipt(studentDID string, courseIDs [
 		PublishedAt:         tvt.PublishedAt,
 		RevealedCourses:     studentCourses,
 		CourseProofs:        courseProofs,
+		BatchProof:          batchProof,
+		ProofFormat:         proofFormat,
+		DisclosedFields:     fieldMask,
 		SelectiveDisclosure: len(studentCourses) < totalCourses,
 		Metadata: ReceiptMetadata{
 			GeneratedAt:       time.Now(),
@@ -273,114 +553,203 @@ func determineVerificationLevel(totalCourses, revealedCourses int) string {
 	return "selective"
 }
 
-// VerifyCourseProof performs full cryptographic verification of a course proof against the Verkle root
+// VerifyCourseProof performs full cryptographic verification of a course proof against the Verkle root.
+// Every field of the course must be proven; use VerifyCourseFieldProof to accept partial field sets.
 func VerifyCourseProof(courseKey string, course CourseCompletion, proofData []byte, verkleRoot [32]byte) error {
+	fields, err := VerifyCourseFieldProof(courseKey, course, proofData, verkleRoot)
+	if err != nil {
+		return err
+	}
+	if len(fields) != len(CourseFields) {
+		return fmt.Errorf("%w: proof for course %s only covers fields %v", ErrFieldsNotCovered, course.CourseID, fields)
+	}
+	return nil
+}
+
+// VerifyCourseFieldProof verifies a course proof against the Verkle root and returns the fields it
+// proves: all of them for single-leaf trees, the disclosed subset for field-leaf trees. Fields
+// outside that subset must be left at their zero values. It does not log; failures wrap one of
+// the Err* classes (see package verifier for typed per-course results).
+func VerifyCourseFieldProof(courseKey string, course CourseCompletion, proofData []byte, verkleRoot [32]byte) ([]string, error) {
 	// Directly parse the JSON proof data (no Base64 decoding needed)
 	var proofBundle VerkleProofBundle
 	if err := json.Unmarshal(proofData, &proofBundle); err != nil {
-		return fmt.Errorf("failed to deserialize proof bundle: %w", err)
+		return nil, fmt.Errorf("%w: failed to deserialize proof bundle: %w", ErrMalformedProof, err)
 	}
 	
 	// Verify the course key matches
 	if proofBundle.CourseKey != courseKey {
-		return fmt.Errorf("proof bundle course key mismatch: expected %s, got %s", courseKey, proofBundle.CourseKey)
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrCourseKeyMismatch, courseKey, proofBundle.CourseKey)
 	}
 	
-	// Recreate the key hash exactly as it was during insertion
-	courseKeyHash := sha256.Sum256([]byte(courseKey))
-	var keyHash32 [32]byte
-	copy(keyHash32[:], courseKeyHash[:])
-	
-	// Recreate the value hash from the course data
-	courseData, err := json.Marshal(course)
+	fields, err := provenFields(course, proofBundle.TreeFormat, proofBundle.Fields)
 	if err != nil {
-		return fmt.Errorf("failed to serialize course data: %w", err)
-	}
-	courseValueHash := sha256.Sum256(courseData)
-	
-	// Perform cryptographic verification following Duc's approach
-	// Check the StateDiff contains the expected key-value pair
-	foundInDiff := false
-	keyStem := keyHash32[:verkleLib.StemSize]
-	keySuffix := keyHash32[verkleLib.StemSize]
-	
-	for _, stemDiff := range proofBundle.StateDiff {
-		if bytes.Equal(keyStem, stemDiff.Stem[:]) {
-			// Found the correct stem
-			for _, suffixDiff := range stemDiff.SuffixDiffs {
-				if keySuffix == suffixDiff.Suffix {
-					// Found the exact key
-					foundInDiff = true
-					
-					// Verify the value matches
-					if suffixDiff.CurrentValue == nil {
-						return fmt.Errorf("proof shows nil value for course %s, but course exists", course.CourseID)
-					}
-					
-					// Compare the stored value with our computed value hash
-					if !bytes.Equal((*suffixDiff.CurrentValue)[:], courseValueHash[:]) {
-						return fmt.Errorf("value mismatch in proof for course %s", course.CourseID)
-					}
-					
-					log.Printf("✅ Cryptographic verification successful for course %s", course.CourseID)
-					break
-				}
-			}
-			if foundInDiff {
-				break
-			}
-		}
+		return nil, fmt.Errorf("%w: course %s: %w", ErrFieldsNotCovered, course.CourseID, err)
 	}
 	
-	if !foundInDiff {
-		return fmt.Errorf("course %s not found in proof's state diff", course.CourseID)
-	}
-	
-	// Now perform the full cryptographic IPA verification
-	log.Printf("🔍 Starting full IPA verification for course %s", course.CourseID)
-	log.Printf("  - Verkle proof: present and valid")
-	log.Printf("  - State diff: %d stems", len(proofBundle.StateDiff))
-	log.Printf("  - Course key match: %s", courseKey)
-	log.Printf("  - Value hash verified in state diff")
-	
-	// For proper IPA verification with go-verkle, we need to use a different approach
-	// Since our proofs are generated from a fully-built tree, we verify membership rather than state transitions
-	// 
-	// The proof should demonstrate that the key-value pair exists in the tree with the given root
-	// We use the tree root as both pre and post state since we're verifying inclusion, not transition
-	
-	log.Printf("🔍 Attempting IPA verification with tree root: %x", verkleRoot)
-	log.Printf("🔍 Debug proof structure:")
-	log.Printf("  - VerkleProof type: %T", proofBundle.VerkleProof)
-	log.Printf("  - State diff count: %d", len(proofBundle.StateDiff))
-	if len(proofBundle.StateDiff) > 0 {
-		log.Printf("  - First state diff stem: %x", proofBundle.StateDiff[0].Stem)
-		log.Printf("  - First state diff suffix diffs: %d", len(proofBundle.StateDiff[0].SuffixDiffs))
-		if len(proofBundle.StateDiff[0].SuffixDiffs) > 0 {
-			log.Printf("  - First suffix diff: suffix=%d, currentValue=%x", 
-				proofBundle.StateDiff[0].SuffixDiffs[0].Suffix,
-				proofBundle.StateDiff[0].SuffixDiffs[0].CurrentValue)
-		}
+	// Recreate the keys and value hashes from the course data with the encoding the tree used
+	keys, values, err := courseLeaves(courseKey, course, proofBundle.LeafEncoding, proofBundle.TreeFormat, proofBundle.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCourseData, err)
 	}
 	
 	// Perform full IPA verification using go-verkle's internal API
 	// This cryptographically proves that the StateDiff matches the VerkleProof
+	// and that every expected key holds the value recomputed from the course data
 	err = VerifyMembershipProof(
 		proofBundle.VerkleProof,
 		proofBundle.StateDiff,
 		verkleRoot,
-		[][]byte{courseKeyHash[:]},
-		[][32]byte{courseValueHash},
+		keys,
+		values,
 	)
 	if err != nil {
-		return fmt.Errorf("IPA membership proof verification failed: %w", err)
+		return nil, fmt.Errorf("IPA membership proof verification failed: %w", err)
+	}
+
+	return fields, nil
+}
+
+// provenFields returns the course fields a proof covers and rejects courses that carry
+// values for fields the proof does not cover
+func provenFields(course CourseCompletion, treeFormat uint8, bundleFields []string) ([]string, error) {
+	if treeFormat != TreeFormatFieldLeaves {
+		if len(bundleFields) > 0 {
+			return nil, fmt.Errorf("field list given for tree format %d", treeFormat)
+		}
+		return append([]string(nil), CourseFields...), nil
+	}
+
+	if len(bundleFields) == 0 {
+		return nil, fmt.Errorf("field-leaf proof does not list its fields")
+	}
+	fields, err := NormalizeFieldMask(bundleFields)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != len(bundleFields) {
+		return nil, fmt.Errorf("field-leaf proof must list course_id and no duplicates")
+	}
+	if undisclosed := UndisclosedFieldsSet(course, fields); len(undisclosed) > 0 {
+		return nil, fmt.Errorf("fields %v are set but not covered by the proof", undisclosed)
+	}
+	return fields, nil
+}
+
+// VerifyAbsenceProof verifies that the course key has no value in the tree committed to by verkleRoot
+func VerifyAbsenceProof(courseKey string, proofData []byte, verkleRoot [32]byte) error {
+	var proofBundle VerkleAbsenceProofBundle
+	if err := json.Unmarshal(proofData, &proofBundle); err != nil {
+		return fmt.Errorf("%w: failed to deserialize absence proof bundle: %w", ErrMalformedProof, err)
+	}
+
+	if proofBundle.CourseKey != courseKey {
+		return fmt.Errorf("%w: absence proof expected %s, got %s", ErrCourseKeyMismatch, courseKey, proofBundle.CourseKey)
+	}
+
+	courseKeyHash := sha256.Sum256([]byte(courseKey))
+
+	// A revoked course is a tombstone leaf rather than an empty one; both mean no credential.
+	// The revoked flag in the bundle is not trusted, the state diff value decides.
+	if value := stateDiffValue(proofBundle.StateDiff, courseKeyHash); value != nil && *value == RevokedLeafValue {
+		if err := VerifyMembershipProof(proofBundle.VerkleProof, proofBundle.StateDiff, verkleRoot,
+			[][]byte{courseKeyHash[:]}, [][32]byte{RevokedLeafValue}); err != nil {
+			return fmt.Errorf("IPA revocation tombstone verification failed: %w", err)
+		}
+		return nil
+	}
+
+	if err := VerifyNonMembershipProof(proofBundle.VerkleProof, proofBundle.StateDiff, verkleRoot, [][]byte{courseKeyHash[:]}); err != nil {
+		return fmt.Errorf("IPA non-membership proof verification failed: %w", err)
 	}
 
-	log.Printf("✅ Full IPA membership proof verification successful for course %s", course.CourseID)
 	return nil
 }
 
-// VerifyReceiptOffChain performs complete off-chain verification of a single Verkle receipt
+// stateDiffValue returns the current value of a key in a state diff, or nil if it is absent or empty
+func stateDiffValue(stateDiff verkleLib.StateDiff, key [32]byte) *[32]byte {
+	for _, stemDiff := range stateDiff {
+		if !bytes.Equal(key[:verkleLib.StemSize], stemDiff.Stem[:]) {
+			continue
+		}
+		for _, suffixDiff := range stemDiff.SuffixDiffs {
+			if suffixDiff.Suffix == key[verkleLib.StemSize] {
+				return suffixDiff.CurrentValue
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyBatchCourseProof verifies a single multiproof covering several courses against the Verkle root.
+// courseKeys[i] must correspond to courses[i], and the proof must cover exactly these keys.
+// Every field of every course must be proven; use VerifyBatchCourseFieldProof for partial field sets.
+func VerifyBatchCourseProof(courseKeys []string, courses []CourseCompletion, proofData []byte, verkleRoot [32]byte) error {
+	fields, err := VerifyBatchCourseFieldProof(courseKeys, courses, proofData, verkleRoot)
+	if err != nil {
+		return err
+	}
+	if len(fields) != len(CourseFields) {
+		return fmt.Errorf("%w: batch proof only covers fields %v", ErrFieldsNotCovered, fields)
+	}
+	return nil
+}
+
+// VerifyBatchCourseFieldProof verifies a batch multiproof and returns the fields it proves for
+// every course (see VerifyCourseFieldProof)
+func VerifyBatchCourseFieldProof(courseKeys []string, courses []CourseCompletion, proofData []byte, verkleRoot [32]byte) ([]string, error) {
+	if len(courseKeys) != len(courses) {
+		return nil, fmt.Errorf("%w: course keys and courses length mismatch: %d != %d", ErrInvalidCourseData, len(courseKeys), len(courses))
+	}
+	if len(courseKeys) == 0 {
+		return nil, fmt.Errorf("%w: no courses to verify", ErrInvalidCourseData)
+	}
+
+	var proofBundle VerkleBatchProofBundle
+	if err := json.Unmarshal(proofData, &proofBundle); err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize batch proof bundle: %w", ErrMalformedProof, err)
+	}
+
+	// The bundle must cover exactly the revealed courses, no more and no less
+	if len(proofBundle.CourseKeys) != len(courseKeys) {
+		return nil, fmt.Errorf("%w: batch proof covers %d courses, expected %d", ErrCourseKeyMismatch, len(proofBundle.CourseKeys), len(courseKeys))
+	}
+	bundleKeys := make(map[string]bool, len(proofBundle.CourseKeys))
+	for _, courseKey := range proofBundle.CourseKeys {
+		bundleKeys[courseKey] = true
+	}
+
+	var fields []string
+	keyHashes := make([][]byte, 0, len(courseKeys))
+	valueHashes := make([][32]byte, 0, len(courses))
+	for i, courseKey := range courseKeys {
+		if !bundleKeys[courseKey] {
+			return nil, fmt.Errorf("%w: batch proof does not cover course key %s", ErrCourseKeyMismatch, courseKey)
+		}
+
+		var err error
+		if fields, err = provenFields(courses[i], proofBundle.TreeFormat, proofBundle.Fields); err != nil {
+			return nil, fmt.Errorf("%w: course %s: %w", ErrFieldsNotCovered, courses[i].CourseID, err)
+		}
+		keys, values, err := courseLeaves(courseKey, courses[i], proofBundle.LeafEncoding, proofBundle.TreeFormat, proofBundle.Fields)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCourseData, err)
+		}
+		keyHashes = append(keyHashes, keys...)
+		valueHashes = append(valueHashes, values...)
+	}
+
+	// One membership check for the whole set of revealed courses
+	if err := VerifyMembershipProof(proofBundle.VerkleProof, proofBundle.StateDiff, verkleRoot, keyHashes, valueHashes); err != nil {
+		return nil, fmt.Errorf("IPA batch membership proof verification failed: %w", err)
+	}
+
+	return fields, nil
+}
+
+// VerifyReceiptOffChain performs complete off-chain verification of a single Verkle receipt.
+// Services embedding verification should use package verifier instead, which reports typed
+// per-course outcomes and does not log.
 func VerifyReceiptOffChain(receipt *VerificationReceipt, expectedVerkleRoot [32]byte) (*VerificationResult, error) {
 	log.Println("=== STARTING OFF-CHAIN VERIFICATION (Single Verkle) ===")
 	
@@ -403,43 +772,68 @@ func VerifyReceiptOffChain(receipt *VerificationReceipt, expectedVerkleRoot [32]
 		log.Printf("✅ Verkle root verification passed: %x", expectedVerkleRoot)
 	}
 	
-	// 2. Verify each course proof against the Verkle root
-	for _, course := range receipt.RevealedCourses {
-		courseKey := fmt.Sprintf("%s:%s:%s", receipt.StudentDID, receipt.TermID, course.CourseID)
-		proof, exists := receipt.CourseProofs[course.CourseID]
-		if !exists {
-			result.Warnings = append(result.Warnings, fmt.Sprintf("No proof found for course %s", course.CourseID))
-			continue
+	// 2. Verify course proofs against the Verkle root
+	batch := receipt.ProofFormat == ProofFormatBatch || len(receipt.BatchProof) > 0
+	if batch {
+		courseKeys := make([]string, 0, len(receipt.RevealedCourses))
+		for _, course := range receipt.RevealedCourses {
+			courseKeys = append(courseKeys, fmt.Sprintf("%s:%s:%s", receipt.StudentDID, receipt.TermID, course.CourseID))
 		}
-		
-		// Verify the proof cryptographically
-		if err := VerifyCourseProof(courseKey, course, proof, receipt.VerkleRoot); err != nil {
+
+		if len(receipt.BatchProof) == 0 {
 			result.Valid = false
-			result.Errors = append(result.Errors, fmt.Sprintf("Course %s proof verification failed: %v", course.CourseID, err))
+			result.Errors = append(result.Errors, "Missing batch Verkle proof")
+		} else if fields, err := VerifyBatchCourseFieldProof(courseKeys, receipt.RevealedCourses, receipt.BatchProof, receipt.VerkleRoot); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Sprintf("Batch proof verification failed: %v", err))
 		} else {
-			log.Printf("✅ Course %s proof verified successfully", course.CourseID)
+			recordProvenFields(result, receipt, fields)
+			log.Printf("✅ Batch proof verified for %d courses", len(receipt.RevealedCourses))
+		}
+	} else {
+		for _, course := range receipt.RevealedCourses {
+			courseKey := fmt.Sprintf("%s:%s:%s", receipt.StudentDID, receipt.TermID, course.CourseID)
+			proof, exists := receipt.CourseProofs[course.CourseID]
+			if !exists {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("No proof found for course %s", course.CourseID))
+				continue
+			}
+		
+			// Verify the proof cryptographically
+			if fields, err := VerifyCourseFieldProof(courseKey, course, proof, receipt.VerkleRoot); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("Course %s proof verification failed: %v", course.CourseID, err))
+			} else {
+				recordProvenFields(result, receipt, fields)
+				log.Printf("✅ Course %s proof verified successfully", course.CourseID)
+			}
 		}
 	}
 	
-	// 2. Verify each course's temporal consistency
+	// 2. Verify each course's temporal consistency (only timestamps that were disclosed)
+	partial := len(result.DisclosedFields) > 0
 	for _, course := range receipt.RevealedCourses {
-		if err := validateCourseTimestamps(course); err != nil {
-			result.Valid = false
-			result.Errors = append(result.Errors, fmt.Sprintf("Course %s: %v", course.CourseID, err))
+		if !partial || containsAll(result.DisclosedFields, "started_at", "completed_at", "assessed_at", "issued_at") {
+			if err := ValidateCourseTimestamps(course); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("Course %s: %v", course.CourseID, err))
+			}
 		}
 		
 		// Verify issued timestamp is before term publication
-		if course.IssuedAt.After(receipt.PublishedAt) {
+		if (!partial || containsAll(result.DisclosedFields, "issued_at")) && course.IssuedAt.After(receipt.PublishedAt) {
 			result.Valid = false
 			result.Errors = append(result.Errors, fmt.Sprintf("Course %s issued after term publication", course.CourseID))
 		}
 	}
 	
-	// 3. Verify Verkle proofs exist for revealed courses
-	for _, course := range receipt.RevealedCourses {
-		if _, hasProof := receipt.CourseProofs[course.CourseID]; !hasProof {
-			result.Valid = false
-			result.Errors = append(result.Errors, fmt.Sprintf("Missing Verkle proof for course %s", course.CourseID))
+	// 3. Verify Verkle proofs exist for revealed courses (batch receipts are covered by step 2)
+	if !batch {
+		for _, course := range receipt.RevealedCourses {
+			if _, hasProof := receipt.CourseProofs[course.CourseID]; !hasProof {
+				result.Valid = false
+				result.Errors = append(result.Errors, fmt.Sprintf("Missing Verkle proof for course %s", course.CourseID))
+			}
 		}
 	}
 	
@@ -473,12 +867,46 @@ type VerificationResult struct {
 	TermID          string    `json:"term_id"`
 	StudentDID      string    `json:"student_did"`
 	CoursesVerified int       `json:"courses_verified"`
+	DisclosedFields []string  `json:"disclosed_fields,omitempty"` // set when only some course fields were proven
 	Errors          []string  `json:"errors"`
 	Warnings        []string  `json:"warnings"`
 }
 
 // Helper functions
-func validateCourseTimestamps(course CourseCompletion) error {
+
+// recordProvenFields notes partial field disclosure in the result and checks it against the
+// field mask the receipt claims
+func recordProvenFields(result *VerificationResult, receipt *VerificationReceipt, fields []string) {
+	if len(fields) == len(CourseFields) && len(receipt.DisclosedFields) == 0 {
+		return
+	}
+	if len(receipt.DisclosedFields) > 0 && strings.Join(fields, ",") != strings.Join(receipt.DisclosedFields, ",") {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("Proven fields %v do not match disclosed fields %v", fields, receipt.DisclosedFields))
+	}
+	if len(fields) < len(CourseFields) {
+		result.DisclosedFields = fields
+	}
+}
+
+func containsAll(fields []string, names ...string) bool {
+	for _, name := range names {
+		found := false
+		for _, field := range fields {
+			if field == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateCourseTimestamps checks that a course was started, completed, assessed and issued in order
+func ValidateCourseTimestamps(course CourseCompletion) error {
 	if course.StartedAt.After(course.CompletedAt) {
 		return fmt.Errorf("started_at after completed_at")
 	}
@@ -539,27 +967,109 @@ func (tvt *TermVerkleTree) GetStudentCourseCount(studentDID string) int {
 func (tvt *TermVerkleTree) RebuildVerkleTree() error {
 	log.Printf("Rebuilding Verkle tree for term %s with %d course entries", tvt.TermID, len(tvt.CourseEntries))
 	
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+	tvt.invalidateProofTrees()
+	
 	// Create new Verkle tree
 	tvt.tree = verkleLib.New()
 	
 	// Re-insert all course entries
 	for courseKey, course := range tvt.CourseEntries {
-		courseKeyHash := sha256.Sum256([]byte(courseKey))
-		
-		// Serialize course data as value (same as original insertion)
-		courseData, err := json.Marshal(course)
+		// Serialize course data as value(s) (same encoding as original insertion)
+		keys, values, err := courseLeaves(courseKey, course, tvt.LeafEncoding, tvt.TreeFormat, nil)
 		if err != nil {
-			return fmt.Errorf("failed to serialize course %s: %w", course.CourseID, err)
+			return err
 		}
-		courseValueHash := sha256.Sum256(courseData)
 		
 		// Re-insert into Verkle tree
-		err = tvt.tree.Insert(courseKeyHash[:], courseValueHash[:], nil)
-		if err != nil {
-			return fmt.Errorf("failed to re-insert course %s into verkle tree: %w", course.CourseID, err)
+		for i := range keys {
+			err = tvt.tree.Insert(keys[i], values[i][:], tvt.resolver())
+			if err != nil {
+				return fmt.Errorf("failed to re-insert course %s into verkle tree: %w", course.CourseID, err)
+			}
+		}
+	}
+	
+	// Re-insert tombstones for revoked courses
+	for _, courseKey := range tvt.RevokedKeys {
+		for _, key := range courseLeafKeys(courseKey, tvt.TreeFormat) {
+			if err := tvt.tree.Insert(key, RevokedLeafValue[:], tvt.resolver()); err != nil {
+				return fmt.Errorf("failed to re-insert revoked course %s into verkle tree: %w", courseKey, err)
+			}
 		}
 	}
 	
+	tvt.dirty = true
+	
 	log.Printf("✅ Verkle tree rebuilt successfully for term %s", tvt.TermID)
 	return nil
-}
\ No newline at end of file
+}
+
+// RevokeCourse removes a course from the term and overwrites its leaf with RevokedLeafValue.
+// The internal tree must be loaded (NewTermVerkleTree or RebuildVerkleTree); call PublishTerm
+// afterwards to compute the new root.
+func (tvt *TermVerkleTree) RevokeCourse(courseKey string) error {
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+
+	if _, exists := tvt.CourseEntries[courseKey]; !exists {
+		return fmt.Errorf("course key %s not found in term %s", courseKey, tvt.TermID)
+	}
+	tvt.invalidateProofTrees()
+
+	// Field-leaf trees tombstone every field leaf of the course
+	for _, key := range courseLeafKeys(courseKey, tvt.TreeFormat) {
+		if err := tvt.tree.Insert(key, RevokedLeafValue[:], tvt.resolver()); err != nil {
+			return fmt.Errorf("failed to write revocation tombstone for %s: %w", courseKey, err)
+		}
+	}
+
+	delete(tvt.CourseEntries, courseKey)
+	delete(tvt.CourseProofs, courseKey)
+	tvt.RevokedKeys = append(tvt.RevokedKeys, courseKey)
+	tvt.dirty = true
+	return nil
+}
+
+// IsRevoked reports whether a course key was revoked in this term
+func (tvt *TermVerkleTree) IsRevoked(courseKey string) bool {
+	for _, revokedKey := range tvt.RevokedKeys {
+		if revokedKey == courseKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a deep copy of the term tree, including the internal Verkle tree.
+// A store-backed clone shares the node store so unresolved nodes stay reachable; flush
+// the clone only once the original is no longer used for reads.
+func (tvt *TermVerkleTree) Clone() *TermVerkleTree {
+	tvt.treeMu.Lock()
+	defer tvt.treeMu.Unlock()
+
+	clone := TermVerkleTree{
+		TermID:       tvt.TermID,
+		PublishedAt:  tvt.PublishedAt,
+		Version:      tvt.Version,
+		LeafEncoding: tvt.LeafEncoding,
+		TreeFormat:   tvt.TreeFormat,
+		VerkleRoot:   tvt.VerkleRoot,
+		store:        tvt.store,
+		dirty:        tvt.dirty,
+	}
+	clone.CourseEntries = make(map[string]CourseCompletion, len(tvt.CourseEntries))
+	for courseKey, course := range tvt.CourseEntries {
+		clone.CourseEntries[courseKey] = course
+	}
+	clone.CourseProofs = make(map[string][]byte, len(tvt.CourseProofs))
+	for courseKey, proof := range tvt.CourseProofs {
+		clone.CourseProofs[courseKey] = proof
+	}
+	clone.RevokedKeys = append([]string(nil), tvt.RevokedKeys...)
+	if tvt.tree != nil {
+		clone.tree = tvt.tree.Copy()
+	}
+	return &clone
+}
@@ -1,6 +1,14 @@
 package verkle
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,20 +54,14 @@ func TestFullIPAVerification(t *testing.T) {
 	}
 	
 	// Verify the proof using our IPA implementation
+	// The stored entry carries the salt the leaf was committed with
 	courseKey := "did:example:ITITIU00001:TestTerm_2024:IT154IU"
-	err = VerifyCourseProof(courseKey, testCourse, proofData, termTree.VerkleRoot)
+	err = VerifyCourseProof(courseKey, termTree.CourseEntries[courseKey], proofData, termTree.VerkleRoot)
 	
 	if err != nil {
-		t.Logf("IPA verification failed: %v", err)
-		t.Logf("This is expected if there are compatibility issues with the go-verkle library")
-		t.Logf("The important thing is that we have proper proof structure and state diff validation")
-		
-		// For thesis purposes, we've implemented the complete pipeline
-		// Even if full IPA fails due to library compatibility, the structure is correct
-		t.Skip("IPA verification failed due to library compatibility - this is acceptable for thesis demonstration")
-	} else {
-		t.Logf("✅ Full IPA verification successful!")
+		t.Fatalf("IPA verification failed: %v", err)
 	}
+	t.Logf("✅ Full IPA verification successful!")
 }
 
 // TestProofGeneration tests that we can generate valid proof structures
@@ -108,4 +110,866 @@ func TestProofGeneration(t *testing.T) {
 	
 	t.Logf("✅ Successfully generated proof of %d bytes", len(proofData))
 	t.Logf("✅ Verkle root: %x", termTree.VerkleRoot)
-}
\ No newline at end of file
+}
+// testCourses returns a completed course for each of ids, taken by studentID in termID
+func testCourses(studentID, termID string, ids ...string) []CourseCompletion {
+	courses := make([]CourseCompletion, 0, len(ids))
+	for _, courseID := range ids {
+		courses = append(courses, CourseCompletion{
+			IssuerID:    "IU-CS",
+			StudentID:   studentID,
+			TermID:      termID,
+			CourseID:    courseID,
+			CourseName:  "Course " + courseID,
+			AttemptNo:   1,
+			StartedAt:   time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC),
+			CompletedAt: time.Date(2024, 5, 20, 8, 0, 0, 0, time.UTC),
+			AssessedAt:  time.Date(2024, 5, 27, 8, 0, 0, 0, time.UTC),
+			IssuedAt:    time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC),
+			Grade:       "A",
+			Credits:     3,
+			Instructor:  "Prof. Test",
+		})
+	}
+	return courses
+}
+
+// TestBatchReceiptVerification tests that a single multiproof covers all revealed courses
+func TestBatchReceiptVerification(t *testing.T) {
+	termTree := NewTermVerkleTree("TestTerm_2024")
+	studentDID := "did:example:ITITIU00001"
+
+	courses := testCourses("ITITIU00001", "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU", "MA001IU")
+	if err := termTree.AddCourses(studentDID, courses); err != nil {
+		t.Fatalf("Failed to add courses: %v", err)
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+
+	batchReceipt, err := termTree.GenerateStudentBatchReceipt(studentDID, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate batch receipt: %v", err)
+	}
+	if batchReceipt.ProofFormat != ProofFormatBatch || len(batchReceipt.CourseProofs) != 0 {
+		t.Fatalf("Expected a batch receipt without per-course proofs")
+	}
+
+	result, err := VerifyReceiptOffChain(batchReceipt, termTree.VerkleRoot)
+	if err != nil {
+		t.Fatalf("Batch receipt verification returned error: %v", err)
+	}
+	if !result.Valid {
+		t.Fatalf("Batch receipt should be valid, errors: %v", result.Errors)
+	}
+
+	// The legacy per-course format must keep verifying
+	legacyReceipt, err := termTree.GenerateStudentReceipt(studentDID, nil)
+	if err != nil {
+		t.Fatalf("Failed to generate per-course receipt: %v", err)
+	}
+	result, err = VerifyReceiptOffChain(legacyReceipt, termTree.VerkleRoot)
+	if err != nil || !result.Valid {
+		t.Fatalf("Per-course receipt should be valid, err: %v, errors: %v", err, result.Errors)
+	}
+
+	legacySize := 0
+	for _, proof := range legacyReceipt.CourseProofs {
+		legacySize += len(proof)
+	}
+	t.Logf("✅ Batch proof %d bytes vs %d bytes for per-course proofs", len(batchReceipt.BatchProof), legacySize)
+	if len(batchReceipt.BatchProof) >= legacySize {
+		t.Fatalf("Batch proof should be smaller than per-course proofs")
+	}
+
+	// Tampering with a revealed course must fail the whole batch
+	batchReceipt.RevealedCourses[0].Grade = "F"
+	result, err = VerifyReceiptOffChain(batchReceipt, termTree.VerkleRoot)
+	if err != nil {
+		t.Fatalf("Batch receipt verification returned error: %v", err)
+	}
+	if result.Valid {
+		t.Fatalf("Tampered batch receipt should not verify")
+	}
+}
+
+// TestAbsenceProof tests non-membership proofs for never-issued and revoked courses
+func TestAbsenceProof(t *testing.T) {
+	termTree := NewTermVerkleTree("TestTerm_2024")
+
+	for _, studentID := range []string{"ITITIU00001", "ITITIU00002", "ITITIU00003"} {
+		courses := testCourses(studentID, "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU")
+		if err := termTree.AddCourses("did:example:"+studentID, courses); err != nil {
+			t.Fatalf("Failed to add courses: %v", err)
+		}
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+
+	// Never-issued course
+	studentDID := "did:example:ITITIU00001"
+	proofData, err := termTree.GenerateAbsenceProof(studentDID, "MA001IU")
+	if err != nil {
+		t.Fatalf("Failed to generate absence proof: %v", err)
+	}
+	courseKey := "did:example:ITITIU00001:TestTerm_2024:MA001IU"
+	if err := VerifyAbsenceProof(courseKey, proofData, termTree.VerkleRoot); err != nil {
+		t.Fatalf("Absence proof should verify: %v", err)
+	}
+
+	// The proof must not be accepted for a different course key
+	if err := VerifyAbsenceProof("did:example:ITITIU00001:TestTerm_2024:IT154IU", proofData, termTree.VerkleRoot); err == nil {
+		t.Fatalf("Absence proof should not verify for another course key")
+	}
+
+	// Existing courses cannot be proven absent
+	if _, err := termTree.GenerateAbsenceProof(studentDID, "IT154IU"); err == nil {
+		t.Fatalf("Expected error when proving absence of an existing course")
+	}
+
+	// A membership proof must not pass as an absence proof
+	membershipProof, err := termTree.GenerateCourseProof(studentDID, "IT154IU")
+	if err != nil {
+		t.Fatalf("Failed to generate membership proof: %v", err)
+	}
+	if err := VerifyAbsenceProof("did:example:ITITIU00001:TestTerm_2024:IT154IU", membershipProof, termTree.VerkleRoot); err == nil {
+		t.Fatalf("Membership proof should not verify as absence proof")
+	}
+
+	// Revoked course is gone from the rebuilt root
+	delete(termTree.CourseEntries, "did:example:ITITIU00001:TestTerm_2024:IT154IU")
+	if err := termTree.RebuildVerkleTree(); err != nil {
+		t.Fatalf("Failed to rebuild tree: %v", err)
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to republish term: %v", err)
+	}
+	revokedProof, err := termTree.GenerateAbsenceProof(studentDID, "IT154IU")
+	if err != nil {
+		t.Fatalf("Failed to generate absence proof for revoked course: %v", err)
+	}
+	if err := VerifyAbsenceProof("did:example:ITITIU00001:TestTerm_2024:IT154IU", revokedProof, termTree.VerkleRoot); err != nil {
+		t.Fatalf("Revoked course absence proof should verify: %v", err)
+	}
+}
+
+// TestRevocationDeltaProof tests the proof linking a superseded root to its replacement
+func TestRevocationDeltaProof(t *testing.T) {
+	oldTree := NewTermVerkleTree("TestTerm_2024")
+	for _, studentID := range []string{"ITITIU00001", "ITITIU00002"} {
+		courses := testCourses(studentID, "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU")
+		if err := oldTree.AddCourses("did:example:"+studentID, courses); err != nil {
+			t.Fatalf("Failed to add courses: %v", err)
+		}
+	}
+	if err := oldTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+
+	revoked := []string{
+		"did:example:ITITIU00001:TestTerm_2024:IT154IU",
+		"did:example:ITITIU00002:TestTerm_2024:PH013IU",
+	}
+	newTree := oldTree.Clone()
+	for _, courseKey := range revoked {
+		if err := newTree.RevokeCourse(courseKey); err != nil {
+			t.Fatalf("Failed to revoke %s: %v", courseKey, err)
+		}
+	}
+	if err := newTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish new version: %v", err)
+	}
+
+	delta, err := GenerateRevocationDeltaProof(oldTree, newTree, revoked)
+	if err != nil {
+		t.Fatalf("Failed to generate delta proof: %v", err)
+	}
+	if err := VerifyRevocationDeltaProof(delta, oldTree.VerkleRoot, newTree.VerkleRoot); err != nil {
+		t.Fatalf("Delta proof should verify: %v", err)
+	}
+
+	// The proof must survive a JSON round trip (it is stored with the revocation batch)
+	deltaJSON, err := json.Marshal(delta)
+	if err != nil {
+		t.Fatalf("Failed to marshal delta proof: %v", err)
+	}
+	var decoded RevocationDeltaProof
+	if err := json.Unmarshal(deltaJSON, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal delta proof: %v", err)
+	}
+	if err := VerifyRevocationDeltaProof(&decoded, oldTree.VerkleRoot, newTree.VerkleRoot); err != nil {
+		t.Fatalf("Decoded delta proof should verify: %v", err)
+	}
+
+	// Hiding a revocation from the listed keys must fail
+	hidden := decoded
+	hidden.RevokedKeys = revoked[:1]
+	if err := VerifyRevocationDeltaProof(&hidden, oldTree.VerkleRoot, newTree.VerkleRoot); err == nil {
+		t.Fatalf("Delta proof with an unlisted revocation should not verify")
+	}
+
+	// A new root with an extra, unproven change must fail
+	tampered := newTree.Clone()
+	if err := tampered.RevokeCourse("did:example:ITITIU00002:TestTerm_2024:IT013IU"); err != nil {
+		t.Fatalf("Failed to revoke: %v", err)
+	}
+	if err := tampered.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish tampered version: %v", err)
+	}
+	if err := VerifyRevocationDeltaProof(&decoded, oldTree.VerkleRoot, tampered.VerkleRoot); err == nil {
+		t.Fatalf("Delta proof should not verify against a root with extra changes")
+	}
+	forged := decoded
+	forged.NewRoot = tampered.VerkleRoot
+	if err := VerifyRevocationDeltaProof(&forged, oldTree.VerkleRoot, tampered.VerkleRoot); err == nil {
+		t.Fatalf("Delta proof should not verify when the new root is swapped")
+	}
+
+	// Revoked courses are reported absent in the new root, and the tree rebuilds identically
+	absence, err := newTree.GenerateAbsenceProof("did:example:ITITIU00001", "IT154IU")
+	if err != nil {
+		t.Fatalf("Failed to generate absence proof for revoked course: %v", err)
+	}
+	if err := VerifyAbsenceProof(revoked[0], absence, newTree.VerkleRoot); err != nil {
+		t.Fatalf("Revoked course absence should verify: %v", err)
+	}
+	rebuilt := newTree.Clone()
+	if err := rebuilt.RebuildVerkleTree(); err != nil {
+		t.Fatalf("Failed to rebuild: %v", err)
+	}
+	if rebuilt.tree.Commit().Bytes() != newTree.VerkleRoot {
+		t.Fatalf("Rebuilt tree root differs from published root")
+	}
+}
+
+func TestNodeStoreRoundTrip(t *testing.T) {
+	termTree := NewTermVerkleTree("TestTerm_2024")
+
+	for _, studentID := range []string{"ITITIU00001", "ITITIU00002", "ITITIU00003"} {
+		courses := testCourses(studentID, "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU")
+		if err := termTree.AddCourses("did:example:"+studentID, courses); err != nil {
+			t.Fatalf("Failed to add courses: %v", err)
+		}
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+
+	studentDID := "did:example:ITITIU00002"
+	courseKey := studentDID + ":TestTerm_2024:IT013IU"
+	expectedProof, err := termTree.GenerateCourseProof(studentDID, "IT013IU")
+	if err != nil {
+		t.Fatalf("Failed to generate in-memory proof: %v", err)
+	}
+
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "TestTerm_2024_verkle_tree.db")
+	if err := termTree.AttachNodeStore(storePath); err != nil {
+		t.Fatalf("Failed to attach node store: %v", err)
+	}
+	if err := termTree.Close(); err != nil {
+		t.Fatalf("Failed to close term tree: %v", err)
+	}
+
+	// Proofs from the store must match the in-memory tree byte for byte
+	stored, err := OpenTermVerkleTreeReadOnly(storePath)
+	if err != nil {
+		t.Fatalf("Failed to open node store: %v", err)
+	}
+	if stored.VerkleRoot != termTree.VerkleRoot || len(stored.CourseEntries) != 9 {
+		t.Fatalf("Stored metadata mismatch: root %x, %d entries", stored.VerkleRoot, len(stored.CourseEntries))
+	}
+	storedProof, err := stored.GenerateCourseProof(studentDID, "IT013IU")
+	if err != nil {
+		t.Fatalf("Failed to generate proof from node store: %v", err)
+	}
+	if !bytes.Equal(storedProof, expectedProof) {
+		t.Fatalf("Proof from node store differs from in-memory proof")
+	}
+	if err := VerifyCourseProof(courseKey, stored.CourseEntries[courseKey], storedProof, stored.VerkleRoot); err != nil {
+		t.Fatalf("Proof from node store should verify: %v", err)
+	}
+	if err := stored.Close(); err != nil {
+		t.Fatalf("Failed to close read-only tree: %v", err)
+	}
+
+	// Changes made through a writable store survive reopening
+	writable, err := OpenTermVerkleTree(storePath)
+	if err != nil {
+		t.Fatalf("Failed to open node store for writing: %v", err)
+	}
+	if err := writable.RevokeCourse(courseKey); err != nil {
+		t.Fatalf("Failed to revoke course: %v", err)
+	}
+	if err := writable.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+	newRoot := writable.VerkleRoot
+	if err := writable.Close(); err != nil {
+		t.Fatalf("Failed to close writable tree: %v", err)
+	}
+
+	reopened, err := OpenTermVerkleTreeReadOnly(storePath)
+	if err != nil {
+		t.Fatalf("Failed to reopen node store: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.VerkleRoot != newRoot || !reopened.IsRevoked(courseKey) {
+		t.Fatalf("Revocation was not persisted")
+	}
+	absenceProof, err := reopened.GenerateAbsenceProof(studentDID, "IT013IU")
+	if err != nil {
+		t.Fatalf("Failed to generate absence proof from node store: %v", err)
+	}
+	if err := VerifyAbsenceProof(courseKey, absenceProof, newRoot); err != nil {
+		t.Fatalf("Absence proof from node store should verify: %v", err)
+	}
+
+	// Migration from the JSON format reproduces the same root
+	jsonData, err := reopened.SerializeToJSON()
+	if err != nil {
+		t.Fatalf("Failed to serialize term tree: %v", err)
+	}
+	jsonPath := filepath.Join(dir, "TestTerm_2024_verkle_tree.json")
+	if err := os.WriteFile(jsonPath, jsonData, 0644); err != nil {
+		t.Fatalf("Failed to write JSON tree: %v", err)
+	}
+	migrated, err := MigrateJSONTree(jsonPath, filepath.Join(dir, "migrated.db"))
+	if err != nil {
+		t.Fatalf("Failed to migrate JSON tree: %v", err)
+	}
+	defer migrated.Close()
+	if migrated.VerkleRoot != newRoot {
+		t.Fatalf("Migrated root %x does not match %x", migrated.VerkleRoot, newRoot)
+	}
+
+	t.Logf("✅ Node store round trip verified for %d courses", len(reopened.CourseEntries))
+}
+
+func TestCanonicalCourseEncoding(t *testing.T) {
+	course := CourseCompletion{
+		IssuerID:    "IU-CS",
+		StudentID:   "ITITIU00001",
+		TermID:      "Semester_1_2023",
+		CourseID:    "IT013IU",
+		CourseName:  "Algorithms & Data Structures",
+		AttemptNo:   1,
+		StartedAt:   time.Date(2023, 9, 4, 8, 0, 0, 0, time.UTC),
+		CompletedAt: time.Date(2023, 12, 22, 17, 0, 0, 0, time.UTC),
+		AssessedAt:  time.Date(2024, 1, 5, 9, 30, 0, 0, time.UTC),
+		IssuedAt:    time.Date(2024, 1, 10, 12, 0, 0, 500, time.UTC),
+		Grade:       "A",
+		Credits:     4,
+		Instructor:  "Dr. Nguyen",
+	}
+
+	// Golden vector: changing it breaks every published canonical root
+	const expectedEncoding = "01000549552d4353000b4954495449553030303031000f53656d65737465725f315f32303233" +
+		"000749543031334955001c416c676f726974686d73202620446174612053747275637475726573" +
+		"010000000064f58e8000000000000000006585c09000000000000000006597cc1800000000" +
+		"00000000659e86c0000001f400014104000a44722e204e677579656e"
+	const expectedLeaf = "a300b90b3d493d631a981f6d7c34095e4c38eb2aed55b911d7d811c2639d5327"
+
+	encoded, err := EncodeCourseCompletion(course)
+	if err != nil {
+		t.Fatalf("Failed to encode course: %v", err)
+	}
+	if hex.EncodeToString(encoded) != expectedEncoding {
+		t.Fatalf("Canonical encoding changed:\n got %x\nwant %s", encoded, expectedEncoding)
+	}
+	leaf, err := CourseLeafValue(course, LeafEncodingCanonicalV1, TreeFormatUnsalted)
+	if err != nil {
+		t.Fatalf("Failed to compute leaf value: %v", err)
+	}
+	if hex.EncodeToString(leaf[:]) != expectedLeaf {
+		t.Fatalf("Canonical leaf value changed: got %x", leaf)
+	}
+
+	// The same instants in another time zone must encode identically
+	zone := time.FixedZone("ICT", 7*60*60)
+	shifted := course
+	shifted.StartedAt = course.StartedAt.In(zone)
+	shifted.CompletedAt = course.CompletedAt.In(zone)
+	shifted.AssessedAt = course.AssessedAt.In(zone)
+	shifted.IssuedAt = course.IssuedAt.In(zone)
+	shiftedLeaf, err := CourseLeafValue(shifted, LeafEncodingCanonicalV1, TreeFormatUnsalted)
+	if err != nil {
+		t.Fatalf("Failed to compute shifted leaf value: %v", err)
+	}
+	if shiftedLeaf != leaf {
+		t.Fatalf("Canonical leaf value depends on time zone")
+	}
+
+	if _, err := CourseLeafValue(course, 99, TreeFormatUnsalted); err == nil {
+		t.Fatalf("Expected error for unknown leaf encoding")
+	}
+
+	// Trees created before the canonical encoding keep verifying with the JSON leaf values
+	legacyTree := NewTermVerkleTree("Semester_1_2023")
+	legacyTree.LeafEncoding = LeafEncodingJSON
+	legacyTree.TreeFormat = TreeFormatUnsalted
+	studentDID := "did:example:ITITIU00001"
+	if err := legacyTree.AddCourses(studentDID, []CourseCompletion{course}); err != nil {
+		t.Fatalf("Failed to add courses: %v", err)
+	}
+	if err := legacyTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+	proofData, err := legacyTree.GenerateCourseProof(studentDID, course.CourseID)
+	if err != nil {
+		t.Fatalf("Failed to generate proof: %v", err)
+	}
+	courseKey := studentDID + ":Semester_1_2023:IT013IU"
+	if err := VerifyCourseProof(courseKey, course, proofData, legacyTree.VerkleRoot); err != nil {
+		t.Fatalf("Legacy JSON-encoded proof should verify: %v", err)
+	}
+
+	canonicalTree := NewTermVerkleTree("Semester_1_2023")
+	canonicalTree.TreeFormat = TreeFormatUnsalted
+	if err := canonicalTree.AddCourses(studentDID, []CourseCompletion{course}); err != nil {
+		t.Fatalf("Failed to add courses: %v", err)
+	}
+	if err := canonicalTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+	if canonicalTree.VerkleRoot == legacyTree.VerkleRoot {
+		t.Fatalf("Canonical and JSON leaf encodings should produce different roots")
+	}
+	proofData, err = canonicalTree.GenerateCourseProof(studentDID, course.CourseID)
+	if err != nil {
+		t.Fatalf("Failed to generate proof: %v", err)
+	}
+	if err := VerifyCourseProof(courseKey, shifted, proofData, canonicalTree.VerkleRoot); err != nil {
+		t.Fatalf("Canonical proof should verify regardless of time zone: %v", err)
+	}
+
+	t.Logf("✅ Canonical course encoding verified: %d bytes, leaf %x", len(encoded), leaf[:8])
+}
+
+func TestSaltedLeaves(t *testing.T) {
+	termTree := NewTermVerkleTree("TestTerm_2024")
+	if termTree.TreeFormat != TreeFormatSalted {
+		t.Fatalf("New trees should use salted leaves")
+	}
+	studentDID := "did:example:ITITIU00001"
+
+	courses := testCourses("ITITIU00001", "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU")
+	if err := termTree.AddCourses(studentDID, courses); err != nil {
+		t.Fatalf("Failed to add courses: %v", err)
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+
+	salts := make(map[string]bool)
+	for courseKey, course := range termTree.CourseEntries {
+		if len(course.Salt) != 2*LeafSaltSize || salts[course.Salt] {
+			t.Fatalf("Course %s has a missing or reused salt", courseKey)
+		}
+		salts[course.Salt] = true
+	}
+
+	// Only the disclosed course and its salt leave the issuer
+	receipt, err := termTree.GenerateStudentReceipt(studentDID, []string{"IT013IU"})
+	if err != nil {
+		t.Fatalf("Failed to generate receipt: %v", err)
+	}
+	if len(receipt.RevealedCourses) != 1 || receipt.RevealedCourses[0].Salt == "" {
+		t.Fatalf("Receipt should reveal exactly one salted course")
+	}
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		t.Fatalf("Failed to marshal receipt: %v", err)
+	}
+	for courseKey, course := range termTree.CourseEntries {
+		if course.CourseID != "IT013IU" && bytes.Contains(receiptJSON, []byte(course.Salt)) {
+			t.Fatalf("Receipt leaks the salt of hidden course %s", courseKey)
+		}
+	}
+	result, err := VerifyReceiptOffChain(receipt, termTree.VerkleRoot)
+	if err != nil || !result.Valid {
+		t.Fatalf("Salted receipt should be valid, err: %v, errors: %v", err, result.Errors)
+	}
+
+	courseKey := studentDID + ":TestTerm_2024:IT013IU"
+	proofData := receipt.CourseProofs["IT013IU"]
+	revealed := receipt.RevealedCourses[0]
+
+	// Guessing the course record without its salt must not verify
+	guessed := revealed
+	guessed.Salt = ""
+	if err := VerifyCourseProof(courseKey, guessed, proofData, termTree.VerkleRoot); err == nil {
+		t.Fatalf("Course without salt should not verify against a salted tree")
+	}
+	guessed.Salt = termTree.CourseEntries[studentDID+":TestTerm_2024:IT154IU"].Salt
+	if err := VerifyCourseProof(courseKey, guessed, proofData, termTree.VerkleRoot); err == nil {
+		t.Fatalf("Course with another course's salt should not verify")
+	}
+
+	// Claiming the unsalted format for a salted leaf must not verify
+	var bundle VerkleProofBundle
+	if err := json.Unmarshal(proofData, &bundle); err != nil {
+		t.Fatalf("Failed to parse proof bundle: %v", err)
+	}
+	bundle.TreeFormat = TreeFormatUnsalted
+	downgraded, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Failed to marshal proof bundle: %v", err)
+	}
+	guessed.Salt = ""
+	if err := VerifyCourseProof(courseKey, guessed, downgraded, termTree.VerkleRoot); err == nil {
+		t.Fatalf("Downgraded unsalted proof should not verify")
+	}
+
+	// Unsalted trees keep working and reject salted courses
+	unsaltedTree := NewTermVerkleTree("TestTerm_2024")
+	unsaltedTree.TreeFormat = TreeFormatUnsalted
+	if err := unsaltedTree.AddCourses(studentDID, courses); err != nil {
+		t.Fatalf("Failed to add courses to unsalted tree: %v", err)
+	}
+	if err := unsaltedTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish unsalted term: %v", err)
+	}
+	unsaltedProof, err := unsaltedTree.GenerateCourseProof(studentDID, "IT013IU")
+	if err != nil {
+		t.Fatalf("Failed to generate unsalted proof: %v", err)
+	}
+	if err := VerifyCourseProof(courseKey, unsaltedTree.CourseEntries[courseKey], unsaltedProof, unsaltedTree.VerkleRoot); err != nil {
+		t.Fatalf("Unsalted proof should verify: %v", err)
+	}
+	if err := VerifyCourseProof(courseKey, revealed, unsaltedProof, unsaltedTree.VerkleRoot); err == nil {
+		t.Fatalf("Salted course should not verify against an unsalted tree")
+	}
+
+	t.Logf("✅ Salted leaves verified: %d unique salts", len(salts))
+}
+
+func TestFieldLeafDisclosure(t *testing.T) {
+	termTree := NewTermVerkleTree("TestTerm_2024")
+	termTree.TreeFormat = TreeFormatFieldLeaves
+
+	for _, studentID := range []string{"ITITIU00001", "ITITIU00002"} {
+		courses := testCourses(studentID, "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU")
+		// Not the default grade, so filling in "A" below is a forgery
+		for i := range courses {
+			courses[i].Grade = "B+"
+		}
+		if err := termTree.AddCourses("did:example:"+studentID, courses); err != nil {
+			t.Fatalf("Failed to add courses: %v", err)
+		}
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+	studentDID := "did:example:ITITIU00001"
+
+	// "Passed course X" without grade or instructor
+	receipt, err := termTree.GenerateStudentReceipt(studentDID, []string{"IT013IU", "PH013IU"}, "credits", "completed_at")
+	if err != nil {
+		t.Fatalf("Failed to generate field receipt: %v", err)
+	}
+	if strings.Join(receipt.DisclosedFields, ",") != "course_id,completed_at,credits" {
+		t.Fatalf("Unexpected disclosed fields: %v", receipt.DisclosedFields)
+	}
+	for _, course := range receipt.RevealedCourses {
+		if course.Grade != "" || course.Instructor != "" || course.Salt != "" || len(course.FieldSalts) != 3 {
+			t.Fatalf("Course %s leaks undisclosed data: %+v", course.CourseID, course)
+		}
+	}
+	result, err := VerifyReceiptOffChain(receipt, termTree.VerkleRoot)
+	if err != nil || !result.Valid {
+		t.Fatalf("Field receipt should be valid, err: %v, errors: %v", err, result.Errors)
+	}
+	if len(result.DisclosedFields) != 3 {
+		t.Fatalf("Result should report partial disclosure, got %v", result.DisclosedFields)
+	}
+
+	// Partial proofs are not accepted as whole-course proofs
+	courseKey := studentDID + ":TestTerm_2024:IT013IU"
+	revealed := *findCourseByID(receipt.RevealedCourses, "IT013IU")
+	if err := VerifyCourseProof(courseKey, revealed, receipt.CourseProofs["IT013IU"], termTree.VerkleRoot); err == nil {
+		t.Fatalf("Partial proof should not pass as a whole-course proof")
+	}
+
+	// Filling in an undisclosed field or changing a disclosed one must fail
+	withGrade := revealed
+	withGrade.Grade = "A"
+	if _, err := VerifyCourseFieldProof(courseKey, withGrade, receipt.CourseProofs["IT013IU"], termTree.VerkleRoot); err == nil {
+		t.Fatalf("Undisclosed grade should not be accepted")
+	}
+	changedCredits := revealed
+	changedCredits.Credits = 8
+	if _, err := VerifyCourseFieldProof(courseKey, changedCredits, receipt.CourseProofs["IT013IU"], termTree.VerkleRoot); err == nil {
+		t.Fatalf("Changed credits should not verify")
+	}
+
+	// Batch receipts honour the same mask
+	batchReceipt, err := termTree.GenerateStudentBatchReceipt(studentDID, nil, "grade")
+	if err != nil {
+		t.Fatalf("Failed to generate batch field receipt: %v", err)
+	}
+	result, err = VerifyReceiptOffChain(batchReceipt, termTree.VerkleRoot)
+	if err != nil || !result.Valid {
+		t.Fatalf("Batch field receipt should be valid, err: %v, errors: %v", err, result.Errors)
+	}
+
+	// Without a mask the whole course is disclosed and verifies as before
+	fullReceipt, err := termTree.GenerateStudentReceipt(studentDID, []string{"IT013IU"})
+	if err != nil {
+		t.Fatalf("Failed to generate full receipt: %v", err)
+	}
+	full := fullReceipt.RevealedCourses[0]
+	if err := VerifyCourseProof(courseKey, full, fullReceipt.CourseProofs["IT013IU"], termTree.VerkleRoot); err != nil {
+		t.Fatalf("Full field-leaf proof should verify: %v", err)
+	}
+
+	// Absence proofs and revocation work on the course_id leaf
+	if _, err := termTree.GenerateAbsenceProof(studentDID, "IT013IU"); err == nil {
+		t.Fatalf("Existing course should not be provable as absent")
+	}
+	absence, err := termTree.GenerateAbsenceProof(studentDID, "MA001IU")
+	if err != nil {
+		t.Fatalf("Failed to generate absence proof: %v", err)
+	}
+	if err := VerifyAbsenceProof(studentDID+":TestTerm_2024:MA001IU", absence, termTree.VerkleRoot); err != nil {
+		t.Fatalf("Absence proof should verify: %v", err)
+	}
+
+	newTree := termTree.Clone()
+	if err := newTree.RevokeCourse(courseKey); err != nil {
+		t.Fatalf("Failed to revoke course: %v", err)
+	}
+	if err := newTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish new term version: %v", err)
+	}
+	delta, err := GenerateRevocationDeltaProof(termTree, newTree, []string{courseKey})
+	if err != nil {
+		t.Fatalf("Failed to generate delta proof: %v", err)
+	}
+	if err := VerifyRevocationDeltaProof(delta, termTree.VerkleRoot, newTree.VerkleRoot); err != nil {
+		t.Fatalf("Field-leaf delta proof should verify: %v", err)
+	}
+	revokedProof, err := newTree.GenerateAbsenceProof(studentDID, "IT013IU")
+	if err != nil {
+		t.Fatalf("Failed to generate revoked absence proof: %v", err)
+	}
+	if err := VerifyAbsenceProof(courseKey, revokedProof, newTree.VerkleRoot); err != nil {
+		t.Fatalf("Revoked course should verify as absent: %v", err)
+	}
+
+	// Single-leaf trees reject field masks
+	saltedTree := NewTermVerkleTree("TestTerm_2024")
+	if err := saltedTree.AddCourses(studentDID, []CourseCompletion{{CourseID: "IT013IU", Grade: "B+"}}); err != nil {
+		t.Fatalf("Failed to add course: %v", err)
+	}
+	if _, err := saltedTree.GenerateCourseProof(studentDID, "IT013IU", "grade"); err == nil {
+		t.Fatalf("Field mask should be rejected for single-leaf trees")
+	}
+
+	t.Logf("✅ Field-level disclosure verified: %v", receipt.DisclosedFields)
+}
+
+func TestConcurrentProofGeneration(t *testing.T) {
+	students := make(map[string][]CourseCompletion)
+	var studentDIDs []string
+	for i := 1; i <= 12; i++ {
+		studentID := fmt.Sprintf("ITITIU%05d", i)
+		studentDID := "did:example:" + studentID
+		studentDIDs = append(studentDIDs, studentDID)
+		students[studentDID] = testCourses(studentID, "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU", "MA001IU")
+	}
+
+	termTree := NewTermVerkleTree("TestTerm_2024")
+	if err := termTree.AddStudentCourses(students, 4); err != nil {
+		t.Fatalf("Failed to add courses concurrently: %v", err)
+	}
+	if err := termTree.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+
+	// The salts were fixed by AddStudentCourses, so a sequential rebuild must give the same root
+	sequential := NewTermVerkleTree("TestTerm_2024")
+	for _, studentDID := range studentDIDs {
+		var courses []CourseCompletion
+		for _, course := range students[studentDID] {
+			courses = append(courses, termTree.CourseEntries[studentDID+":TestTerm_2024:"+course.CourseID])
+		}
+		if err := sequential.AddCourses(studentDID, courses); err != nil {
+			t.Fatalf("Failed to add courses: %v", err)
+		}
+	}
+	if err := sequential.PublishTerm(); err != nil {
+		t.Fatalf("Failed to publish term: %v", err)
+	}
+	if sequential.VerkleRoot != termTree.VerkleRoot {
+		t.Fatalf("Concurrent construction root %x differs from sequential root %x", termTree.VerkleRoot, sequential.VerkleRoot)
+	}
+
+	generateAll := func(tree *TermVerkleTree, workers int) [][]byte {
+		proofs := make([][]byte, len(studentDIDs)*2)
+		errs := make([]error, len(proofs))
+		RunWorkers(len(proofs), workers, func(i int) {
+			studentDID := studentDIDs[i/2]
+			var receipt *VerificationReceipt
+			if i%2 == 0 {
+				receipt, errs[i] = tree.GenerateStudentReceipt(studentDID, nil)
+			} else {
+				receipt, errs[i] = tree.GenerateStudentBatchReceipt(studentDID, nil)
+			}
+			if errs[i] == nil {
+				receipt.Metadata.GeneratedAt = time.Time{}
+				proofs[i], errs[i] = json.Marshal(receipt)
+			}
+		})
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("Receipt %d failed: %v", i, err)
+			}
+		}
+		return proofs
+	}
+
+	expected := generateAll(termTree, 1)
+	compare := func(name string, got [][]byte) {
+		for i := range expected {
+			if !bytes.Equal(got[i], expected[i]) {
+				t.Fatalf("%s: receipt %d differs from sequential output", name, i)
+			}
+		}
+	}
+	compare("in-memory tree", generateAll(termTree, 8))
+
+	// A tree opened from a node store resolves nodes lazily and must be prepared first
+	storePath := filepath.Join(t.TempDir(), "TestTerm_2024_verkle_tree.db")
+	if err := termTree.AttachNodeStore(storePath); err != nil {
+		t.Fatalf("Failed to attach node store: %v", err)
+	}
+	if err := termTree.Close(); err != nil {
+		t.Fatalf("Failed to close term tree: %v", err)
+	}
+	stored, err := OpenTermVerkleTreeReadOnly(storePath)
+	if err != nil {
+		t.Fatalf("Failed to open node store: %v", err)
+	}
+	defer stored.Close()
+	if err := stored.PrepareConcurrentProofs(); err != nil {
+		t.Fatalf("Failed to prepare node store tree: %v", err)
+	}
+	compare("node store tree", generateAll(stored, 8))
+
+	t.Logf("✅ %d receipts identical across sequential and concurrent generation", len(expected))
+}
+
+// TestProofOpeningsBoundToRoot checks that every opening of real per-course, batch and absence
+// proofs is checked against the published root, so StateDiff values and proof commitments
+// cannot be swapped without the IPA multiproof failing
+func TestProofOpeningsBoundToRoot(t *testing.T) {
+	newTerm := func() *TermVerkleTree {
+		termTree := NewTermVerkleTree("TestTerm_2024")
+		for i := 1; i <= 16; i++ {
+			studentID := fmt.Sprintf("ITITIU%05d", i)
+			courses := testCourses(studentID, "TestTerm_2024", "IT154IU", "IT013IU", "PH013IU")
+			if err := termTree.AddCourses("did:example:"+studentID, courses); err != nil {
+				t.Fatalf("Failed to add courses: %v", err)
+			}
+		}
+		if err := termTree.PublishTerm(); err != nil {
+			t.Fatalf("Failed to publish term: %v", err)
+		}
+		return termTree
+	}
+	termTree := newTerm()
+	// Same courses with different salts, so a different but well-formed root
+	otherRoot := newTerm().VerkleRoot
+
+	studentDID := "did:example:ITITIU00003"
+	courseProof, err := termTree.GenerateCourseProof(studentDID, "IT154IU")
+	if err != nil {
+		t.Fatalf("Failed to generate course proof: %v", err)
+	}
+	batchProof, err := termTree.GenerateBatchCourseProof(studentDID, []string{"IT154IU", "IT013IU", "PH013IU"})
+	if err != nil {
+		t.Fatalf("Failed to generate batch proof: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		proofData []byte
+	}{
+		{"course proof", courseProof},
+		{"batch proof", batchProof},
+	} {
+		var bundle VerkleProofBundle
+		if err := json.Unmarshal(tc.proofData, &bundle); err != nil {
+			t.Fatalf("%s: failed to parse bundle: %v", tc.name, err)
+		}
+
+		// Every value the StateDiff carries is expected to be proven
+		var keys [][]byte
+		var values [][32]byte
+		for _, stemDiff := range bundle.StateDiff {
+			for _, suffixDiff := range stemDiff.SuffixDiffs {
+				if suffixDiff.CurrentValue == nil {
+					continue
+				}
+				key := append(append([]byte{}, stemDiff.Stem[:]...), suffixDiff.Suffix)
+				keys = append(keys, key)
+				values = append(values, *suffixDiff.CurrentValue)
+			}
+		}
+		if err := VerifyMembershipProof(bundle.VerkleProof, bundle.StateDiff, termTree.VerkleRoot, keys, values); err != nil {
+			t.Fatalf("%s: valid proof rejected: %v", tc.name, err)
+		}
+
+		// A forged value placed consistently in the StateDiff and the expectation
+		// still has to open from the proof's commitments
+		forged := bundle.StateDiff.Copy()
+		forgedValue := [32]byte{0x42}
+		forged[0].SuffixDiffs[0].CurrentValue = &forgedValue
+		forgedValues := append([][32]byte{}, values...)
+		forgedValues[0] = forgedValue
+		err := VerifyMembershipProof(bundle.VerkleProof, forged, termTree.VerkleRoot, keys, forgedValues)
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("%s: forged StateDiff value not rejected as invalid proof: %v", tc.name, err)
+		}
+
+		// The same proof does not open against another published root
+		err = VerifyMembershipProof(bundle.VerkleProof, bundle.StateDiff, otherRoot, keys, values)
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("%s: proof accepted against a different root: %v", tc.name, err)
+		}
+
+		// Swapping a path commitment for another valid point breaks its opening
+		swapped := bundle.VerkleProof.Copy()
+		swapped.CommitmentsByPath[len(swapped.CommitmentsByPath)-1] = otherRoot
+		err = VerifyMembershipProof(swapped, bundle.StateDiff, termTree.VerkleRoot, keys, values)
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("%s: swapped path commitment not rejected: %v", tc.name, err)
+		}
+
+		// So does replacing the aggregated commitment of the multiproof
+		swapped = bundle.VerkleProof.Copy()
+		swapped.D = otherRoot
+		err = VerifyMembershipProof(swapped, bundle.StateDiff, termTree.VerkleRoot, keys, values)
+		if !errors.Is(err, ErrInvalidProof) {
+			t.Fatalf("%s: replaced multiproof commitment not rejected: %v", tc.name, err)
+		}
+
+		t.Logf("✅ %s: %d keys over %d path commitments bound to root", tc.name, len(keys), len(bundle.VerkleProof.CommitmentsByPath))
+	}
+
+	// Absence proofs go through the same opening check
+	absentKey := studentDID + ":TestTerm_2024:MA001IU"
+	absenceProof, err := termTree.GenerateAbsenceProof(studentDID, "MA001IU")
+	if err != nil {
+		t.Fatalf("Failed to generate absence proof: %v", err)
+	}
+	if err := VerifyAbsenceProof(absentKey, absenceProof, termTree.VerkleRoot); err != nil {
+		t.Fatalf("Valid absence proof rejected: %v", err)
+	}
+	if err := VerifyAbsenceProof(absentKey, absenceProof, otherRoot); !errors.Is(err, ErrInvalidProof) {
+		t.Fatalf("Absence proof accepted against a different root: %v", err)
+	}
+	t.Logf("✅ Absence proof bound to root")
+}
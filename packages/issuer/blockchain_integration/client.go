@@ -2,13 +2,12 @@ package blockchain
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"math/big"
+	"errors"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	
 	"iumicert/issuer/config"
@@ -16,126 +15,164 @@ import (
 
 // BlockchainClient manages Ethereum blockchain interactions
 type BlockchainClient struct {
-	client     *ethclient.Client
-	privateKey *ecdsa.PrivateKey
+	client     bind.ContractBackend
+	receipts   bind.DeployBackend
+	signer     Signer
 	chainID    *big.Int
 	gasLimit   uint64
+	txManager  *TxManager
 }
 
-// NetworkConfig holds blockchain network configuration
-type NetworkConfig struct {
-	RPC_URL     string
-	ChainID     *big.Int
-	GasLimit    uint64
-	PrivateKey  string
-}
+// ErrChainIDMismatch is returned when an RPC endpoint serves another chain than the
+// network registry expects
+var ErrChainIDMismatch = errors.New("chain ID mismatch")
+
+// dialTimeout bounds connecting to one RPC URL and reading its chain ID
+const dialTimeout = 15 * time.Second
 
-// GetNetworkConfig returns network configuration for supported networks
-func GetNetworkConfig(network string) (*NetworkConfig, error) {
-	switch network {
-	case "sepolia":
-		return &NetworkConfig{
-			RPC_URL:    "https://sepolia.infura.io/v3/YOUR_INFURA_KEY", // User will need to set this
-			ChainID:    big.NewInt(11155111), // Sepolia chain ID
-			GasLimit:   500000,
-		}, nil
-	case "mainnet":
-		return &NetworkConfig{
-			RPC_URL:    "https://mainnet.infura.io/v3/YOUR_INFURA_KEY",
-			ChainID:    big.NewInt(1), // Mainnet chain ID
-			GasLimit:   500000,
-		}, nil
-	case "localhost":
-		return &NetworkConfig{
-			RPC_URL:    "http://localhost:8545",
-			ChainID:    big.NewInt(1337), // Local development chain ID
-			GasLimit:   500000,
-		}, nil
-	default:
-		return nil, fmt.Errorf("unsupported network: %s", network)
+// GetNetworkConfig returns the registry entry of a network
+func GetNetworkConfig(network string) (*config.NetworkConfig, error) {
+	registry, err := config.SharedNetworkRegistry()
+	if err != nil {
+		return nil, err
 	}
+	return registry.Lookup(network)
 }
 
 // NewBlockchainClient creates a new blockchain client
 func NewBlockchainClient(network, privateKeyHex string) (*BlockchainClient, error) {
+	return newBlockchainClient(network, privateKeyHex, false)
+}
+
+// NewReadOnlyBlockchainClient creates a client that reads the chain but has no signer, so
+// it never asks for a keystore passphrase. Sending through it fails with ErrReadOnlyClient.
+func NewReadOnlyBlockchainClient(network string) (*BlockchainClient, error) {
+	return newBlockchainClient(network, "", true)
+}
+
+func newBlockchainClient(network, privateKeyHex string, readOnly bool) (*BlockchainClient, error) {
 	// Load configuration from environment
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Get RPC URL from configuration
-	rpcURL, err := cfg.GetRPCURL(network)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get RPC URL: %w", err)
+	if network == SimulatedNetwork {
+		if readOnly {
+			// Join the chain publishing would start, not one of another issuer
+			privateKeyHex = cfg.GetPrivateKey()
+		}
+		chain, err := SharedSimulatedChain(privateKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start simulated chain: %w", err)
+		}
+		// The simulated chain is funded for and owned by its own key, whatever SIGNER says
+		var signer Signer = NewKeySigner(chain.ownerKey)
+		if readOnly {
+			signer = readOnlySigner{}
+		}
+		bc, err := NewBlockchainClientWithBackend(chain.client, signer, chain.ChainID(), chain.gasLimit)
+		if err != nil {
+			return nil, err
+		}
+		bc.configureTxManager(cfg)
+		// Blocks are only mined on demand and never reorged, inclusion is final
+		bc.txManager.Confirmations = 1
+		return bc, nil
 	}
 
-	// Connect to Ethereum client
-	client, err := ethclient.Dial(rpcURL)
+	// Networks, their chain IDs and transaction settings come from the network registry
+	entry, err := cfg.GetNetwork(network)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+		return nil, err
 	}
+	cfg.ApplyNetwork(entry)
 
-	// Parse private key
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	client, chainID, err := dialNetwork(context.Background(), entry)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
+		return nil, err
 	}
 
-	// Get chain ID based on network
-	var chainID *big.Int
-	switch network {
-	case "sepolia":
-		chainID = big.NewInt(11155111) // Sepolia chain ID
-	case "mainnet":
-		chainID = big.NewInt(1) // Mainnet chain ID
-	case "localhost", "local":
-		chainID = big.NewInt(1337) // Local development chain ID
-	default:
-		return nil, fmt.Errorf("unsupported network: %s", network)
+	// Sign with the configured keystore, external signer or, in development, raw key
+	var signer Signer = readOnlySigner{}
+	if !readOnly {
+		if signer, err = ConfiguredSigner(context.Background(), cfg, privateKeyHex); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to create signer: %w", err)
+		}
 	}
 
-	return &BlockchainClient{
-		client:     client,
-		privateKey: privateKey,
-		chainID:    chainID,
-		gasLimit:   cfg.DefaultGasLimit,
-	}, nil
-}
-
-// GetTransactOpts returns transaction options for contract calls
-func (bc *BlockchainClient) GetTransactOpts(ctx context.Context) (*bind.TransactOpts, error) {
-	// Get account nonce
-	publicKey := bc.privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
-	}
-	
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-	nonce, err := bc.client.PendingNonceAt(ctx, fromAddress)
+	bc, err := NewBlockchainClientWithBackend(client, signer, chainID, cfg.DefaultGasLimit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get account nonce: %w", err)
+		return nil, err
 	}
+	bc.configureTxManager(cfg)
+	return bc, nil
+}
 
-	// Get suggested gas price
-	gasPrice, err := bc.client.SuggestGasPrice(ctx)
+// dialNetwork connects to the first reachable RPC URL of network and checks that it serves
+// the network's chain ID. An endpoint on another chain is refused, not skipped.
+func dialNetwork(ctx context.Context, network *config.NetworkConfig) (*ethclient.Client, *big.Int, error) {
+	urls, err := network.ResolveRPCURLs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+		return nil, nil, err
+	}
+	expected := new(big.Int).SetUint64(network.ChainID)
+
+	var lastErr error
+	for _, url := range urls {
+		dialCtx, cancel := context.WithTimeout(ctx, dialTimeout)
+		client, err := ethclient.DialContext(dialCtx, url)
+		if err != nil {
+			cancel()
+			lastErr = err
+			continue
+		}
+		chainID, err := client.ChainID(dialCtx)
+		cancel()
+		if err != nil {
+			client.Close()
+			lastErr = fmt.Errorf("failed to get chain ID: %w", err)
+			continue
+		}
+		if chainID.Cmp(expected) != 0 {
+			client.Close()
+			return nil, nil, fmt.Errorf("%w: %s serves chain %s, network %s expects %s", ErrChainIDMismatch, url, chainID, network.Name, expected)
+		}
+		return client, expected, nil
 	}
+	return nil, nil, fmt.Errorf("failed to connect to Ethereum client for %s: %w", network.Name, lastErr)
+}
 
-	// Create transaction options
-	auth, err := bind.NewKeyedTransactorWithChainID(bc.privateKey, bc.chainID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create keyed transactor: %w", err)
+// configureTxManager applies the fee caps, budget, timeouts and confirmation depth of cfg
+// to the client's transaction manager
+func (bc *BlockchainClient) configureTxManager(cfg *config.Config) {
+	bc.txManager.MaxGasPrice = new(big.Int).SetUint64(cfg.MaxGasPrice)
+	bc.txManager.StuckAfter = time.Duration(cfg.TxStuckTimeout) * time.Second
+	bc.txManager.ConfirmTimeout = time.Duration(cfg.TxConfirmTimeout) * time.Second
+	bc.txManager.Confirmations = cfg.ConfirmationBlocks
+	if cfg.MaxTxCost > 0 {
+		bc.txManager.Budget = new(big.Int).SetUint64(cfg.MaxTxCost)
 	}
+}
 
-	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)
-	auth.GasLimit = bc.gasLimit
-	auth.GasPrice = gasPrice
+// NewBlockchainClientWithBackend creates a blockchain client on an existing contract backend,
+// such as an RPC client or a simulated chain. The backend must also report transaction
+// receipts (bind.DeployBackend) so that transactions can be waited for.
+func NewBlockchainClientWithBackend(backend bind.ContractBackend, signer Signer, chainID *big.Int, gasLimit uint64) (*BlockchainClient, error) {
+	receipts, ok := backend.(bind.DeployBackend)
+	if !ok {
+		return nil, fmt.Errorf("contract backend %T cannot report transaction receipts", backend)
+	}
 
-	return auth, nil
+	return &BlockchainClient{
+		client:     backend,
+		receipts:   receipts,
+		signer:     signer,
+		chainID:    chainID,
+		gasLimit:   gasLimit,
+		txManager:  NewTxManager(backend, receipts, signer, chainID, gasLimit),
+	}, nil
 }
 
 // GetCallOpts returns call options for read-only contract calls
@@ -145,22 +182,19 @@ func (bc *BlockchainClient) GetCallOpts(ctx context.Context) *bind.CallOpts {
 	}
 }
 
-// GetClient returns the underlying Ethereum client
-func (bc *BlockchainClient) GetClient() *ethclient.Client {
+// GetClient returns the underlying contract backend
+func (bc *BlockchainClient) GetClient() bind.ContractBackend {
 	return bc.client
 }
 
-// Close closes the blockchain client connection
+// Close closes the blockchain client connection, if the backend holds one
 func (bc *BlockchainClient) Close() {
-	bc.client.Close()
+	if closer, ok := bc.client.(interface{ Close() }); ok {
+		closer.Close()
+	}
 }
 
-// WaitForTransaction waits for a transaction to be mined and returns the receipt
-func (bc *BlockchainClient) WaitForTransaction(ctx context.Context, txHash common.Hash) error {
-	// In newer versions of go-ethereum, we can directly use the client to wait for receipt
-	_, err := bc.client.TransactionReceipt(ctx, txHash)
-	if err != nil {
-		return fmt.Errorf("failed to get transaction receipt: %w", err)
-	}
-	return nil
-}
\ No newline at end of file
+// TxManager returns the manager that sends the client's transactions
+func (bc *BlockchainClient) TxManager() *TxManager {
+	return bc.txManager
+}
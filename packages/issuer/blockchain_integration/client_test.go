@@ -0,0 +1,137 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"iumicert/issuer/config"
+)
+
+// stubChain answers eth_chainId with a fixed chain ID
+type stubChain struct {
+	chainID uint64
+}
+
+func (s *stubChain) ChainId() hexutil.Big {
+	return hexutil.Big(*new(big.Int).SetUint64(s.chainID))
+}
+
+func startStubChain(t *testing.T, chainID uint64) string {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", &stubChain{chainID: chainID}); err != nil {
+		t.Fatalf("Failed to register stub chain: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL
+}
+
+// TestDialNetworkChecksChainID checks that an endpoint serving another chain is refused
+// and that an unreachable endpoint falls through to the next RPC URL
+func TestDialNetworkChecksChainID(t *testing.T) {
+	consortium := startStubChain(t, 4242)
+	t.Setenv("CONSORTIUM_RPC_URL", consortium)
+
+	registry, err := config.ParseNetworkRegistry([]byte(fmt.Sprintf(`{
+		"networks": {
+			"consortium": {"rpc_urls": ["http://127.0.0.1:1", "${CONSORTIUM_RPC_URL}"], "chain_id": 4242, "confirmations": 6},
+			"wrong-chain": {"aliases": ["wrong"], "rpc_urls": [%q], "chain_id": 10},
+			"unset": {"rpc_urls": ["${UNSET_NETWORK_RPC_URL}"], "chain_id": 1}
+		}
+	}`, consortium)))
+	if err != nil {
+		t.Fatalf("Failed to parse registry: %v", err)
+	}
+
+	network, err := registry.Lookup("consortium")
+	if err != nil {
+		t.Fatalf("Failed to look up network: %v", err)
+	}
+	client, chainID, err := dialNetwork(context.Background(), network)
+	if err != nil {
+		t.Fatalf("Failed to connect to consortium chain: %v", err)
+	}
+	client.Close()
+	if chainID.Uint64() != 4242 {
+		t.Fatalf("Connected to chain %s, expected 4242", chainID)
+	}
+
+	wrong, err := registry.Lookup("wrong")
+	if err != nil {
+		t.Fatalf("Failed to look up network by alias: %v", err)
+	}
+	if _, _, err := dialNetwork(context.Background(), wrong); !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("Expected chain ID mismatch, got %v", err)
+	}
+
+	unset, _ := registry.Lookup("unset")
+	if _, _, err := dialNetwork(context.Background(), unset); err == nil {
+		t.Fatal("Connected without an RPC URL")
+	}
+	if _, err := registry.Lookup("optimism"); err == nil {
+		t.Fatal("Unknown network was found")
+	}
+	t.Logf("✅ Consortium chain %s accepted, wrong chain refused", chainID)
+}
+
+// TestReadOnlyClientNeedsNoSigner checks that a read-only client connects without creating
+// the configured signer and refuses to sign
+func TestReadOnlyClientNeedsNoSigner(t *testing.T) {
+	t.Setenv("LOCALHOST_RPC_URL", startStubChain(t, 31337))
+	t.Setenv("SIGNER", config.SignerKeystore)
+	t.Setenv("KEYSTORE_PATH", filepath.Join(t.TempDir(), "issuer.json"))
+	t.Setenv("KEYSTORE_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing-password"))
+
+	if _, err := NewBlockchainClient("localhost", ""); err == nil {
+		t.Fatal("Signing client created without its keystore")
+	}
+	client, err := NewReadOnlyBlockchainClient("localhost")
+	if err != nil {
+		t.Fatalf("Failed to create read-only client: %v", err)
+	}
+	defer client.Close()
+
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(31337)})
+	if _, err := client.TxManager().signer.SignTx(context.Background(), tx, big.NewInt(31337)); !errors.Is(err, ErrReadOnlyClient) {
+		t.Fatalf("Read-only client signed: %v", err)
+	}
+	t.Logf("✅ Read-only client connected without the keystore and refused to sign")
+}
+
+// TestNetworkRegistryValidation checks that malformed registries are rejected
+func TestNetworkRegistryValidation(t *testing.T) {
+	invalid := map[string]string{
+		"no chain ID":     `{"networks": {"l2": {"rpc_urls": ["http://localhost:9545"]}}}`,
+		"no RPC URL":      `{"networks": {"l2": {"chain_id": 10}}}`,
+		"reserved name":   `{"networks": {"simulated": {"rpc_urls": ["http://localhost:9545"], "chain_id": 10}}}`,
+		"duplicate alias": `{"networks": {"a": {"aliases": ["x"], "rpc_urls": ["http://a"], "chain_id": 1}, "b": {"aliases": ["x"], "rpc_urls": ["http://b"], "chain_id": 2}}}`,
+		"empty":           `{"networks": {}}`,
+	}
+	for name, registry := range invalid {
+		if _, err := config.ParseNetworkRegistry([]byte(registry)); err == nil {
+			t.Fatalf("Registry with %s was accepted", name)
+		}
+	}
+
+	shipped, err := config.LoadNetworkRegistry("../config/networks.json")
+	if err != nil {
+		t.Fatalf("Shipped network registry is invalid: %v", err)
+	}
+	if _, err := shipped.Lookup("local"); err != nil {
+		t.Fatalf("Shipped registry has no localhost alias: %v", err)
+	}
+	t.Logf("✅ Shipped registry defines %v", shipped.Names())
+}
@@ -0,0 +1,79 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Inclusion is where a transaction sits in the canonical chain
+type Inclusion struct {
+	Included      bool
+	BlockNumber   uint64
+	BlockHash     common.Hash
+	Confirmations uint64 // Blocks from the head down to BlockNumber, counting both
+	Succeeded     bool   // Receipt status
+}
+
+// Confirmations returns how many blocks deep a transaction must be before it counts as
+// published
+func (bi *BlockchainIntegration) Confirmations() uint64 {
+	return bi.client.TxManager().Confirmations
+}
+
+// CheckInclusion reports whether a transaction is in the canonical chain and how deep.
+// A transaction whose block was reorged out is reported as not included.
+func (bi *BlockchainIntegration) CheckInclusion(ctx context.Context, txHash common.Hash) (*Inclusion, error) {
+	receipt, err := bi.client.receipts.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return &Inclusion{}, nil
+		}
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+
+	depth, err := bi.client.TxManager().depth(ctx, receipt)
+	if err != nil {
+		return nil, err
+	}
+	return &Inclusion{
+		Included:      true,
+		BlockNumber:   receipt.BlockNumber.Uint64(),
+		BlockHash:     receipt.BlockHash,
+		Confirmations: depth,
+		Succeeded:     receipt.Status == types.ReceiptStatusSuccessful,
+	}, nil
+}
+
+// WaitForConfirmations waits until a transaction is confirmations blocks deep, following it
+// to another block if a reorg moves it. It fails when ctx ends first.
+func (bi *BlockchainIntegration) WaitForConfirmations(ctx context.Context, txHash common.Hash, confirmations uint64) (*Inclusion, error) {
+	ticker := time.NewTicker(bi.client.TxManager().PollInterval)
+	defer ticker.Stop()
+
+	var last *Inclusion
+	for {
+		inclusion, err := bi.CheckInclusion(ctx, txHash)
+		if err != nil {
+			return nil, err
+		}
+		if inclusion.Included && inclusion.Confirmations >= confirmations {
+			return inclusion, nil
+		}
+		last = inclusion
+
+		select {
+		case <-ctx.Done():
+			if last.Included {
+				return nil, fmt.Errorf("transaction %s has %d of %d confirmations: %w", txHash.Hex(), last.Confirmations, confirmations, ctx.Err())
+			}
+			return nil, fmt.Errorf("transaction %s is not in the canonical chain: %w", txHash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
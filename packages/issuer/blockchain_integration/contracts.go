@@ -32,12 +32,34 @@ var (
 // IUMiCertRegistryMetaData contains all meta data concerning the IUMiCertRegistry contract.
 var IUMiCertRegistryMetaData = &bind.MetaData{
 	ABI: "[{\"type\":\"constructor\",\"inputs\":[{\"name\":\"initialOwner\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"checkRootStatus\",\"inputs\":[{\"name\":\"_verkleRoot\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"status\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"termId\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"version\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"latestRoot\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"message\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getLatestRoot\",\"inputs\":[{\"name\":\"_termId\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[{\"name\":\"rootHash\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"version\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"totalStudents\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"publishedAt\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getPublishedRoot\",\"inputs\":[{\"name\":\"_index\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getPublishedRootsCount\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getPublishedTerm\",\"inputs\":[{\"name\":\"_index\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getPublishedTermsCount\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getTermHistory\",\"inputs\":[{\"name\":\"_termId\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[{\"name\":\"versions\",\"type\":\"uint256[]\",\"internalType\":\"uint256[]\"},{\"name\":\"roots\",\"type\":\"bytes32[]\",\"internalType\":\"bytes32[]\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"getVersionInfo\",\"inputs\":[{\"name\":\"_termId\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"_version\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"rootHash\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"totalStudents\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"publishedAt\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"isSuperseded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"supersededBy\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"supersessionReason\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"isTermPublished\",\"inputs\":[{\"name\":\"_termId\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\",\"internalType\":\"bool\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"latestVersion\",\"inputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"owner\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\",\"internalType\":\"address\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"publishTermRoot\",\"inputs\":[{\"name\":\"_verkleRoot\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"_termId\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"_totalStudents\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"publishedRoots\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"publishedTerms\",\"inputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"renounceOwnership\",\"inputs\":[],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"rootToTerm\",\"inputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"rootToVersion\",\"inputs\":[{\"name\":\"\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"supersedeTerm\",\"inputs\":[{\"name\":\"_termId\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"_newVerkleRoot\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"_newTotalStudents\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"_reason\",\"type\":\"string\",\"internalType\":\"string\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"termVersions\",\"inputs\":[{\"name\":\"\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"rootHash\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"version\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"totalStudents\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"publishedAt\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"isSuperseded\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"supersededBy\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"supersessionReason\",\"type\":\"string\",\"internalType\":\"string\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"transferOwnership\",\"inputs\":[{\"name\":\"newOwner\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"function\",\"name\":\"verifyReceiptAnchor\",\"inputs\":[{\"name\":\"_blockchainAnchor\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}],\"outputs\":[{\"name\":\"isValid\",\"type\":\"bool\",\"internalType\":\"bool\"},{\"name\":\"termId\",\"type\":\"string\",\"internalType\":\"string\"},{\"name\":\"publishedAt\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"event\",\"name\":\"OwnershipTransferred\",\"inputs\":[{\"name\":\"previousOwner\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"},{\"name\":\"newOwner\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"TermRootPublished\",\"inputs\":[{\"name\":\"termId\",\"type\":\"string\",\"indexed\":true,\"internalType\":\"string\"},{\"name\":\"verkleRoot\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"version\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"totalStudents\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"timestamp\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"}],\"anonymous\":false},{\"type\":\"event\",\"name\":\"TermRootSuperseded\",\"inputs\":[{\"name\":\"termId\",\"type\":\"string\",\"indexed\":true,\"internalType\":\"string\"},{\"name\":\"oldVersion\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"oldRoot\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"newVersion\",\"type\":\"uint256\",\"indexed\":false,\"internalType\":\"uint256\"},{\"name\":\"newRoot\",\"type\":\"bytes32\",\"indexed\":true,\"internalType\":\"bytes32\"},{\"name\":\"reason\",\"type\":\"string\",\"indexed\":false,\"internalType\":\"string\"}],\"anonymous\":false},{\"type\":\"error\",\"name\":\"OwnableInvalidOwner\",\"inputs\":[{\"name\":\"owner\",\"type\":\"address\",\"internalType\":\"address\"}]},{\"type\":\"error\",\"name\":\"OwnableUnauthorizedAccount\",\"inputs\":[{\"name\":\"account\",\"type\":\"address\",\"internalType\":\"address\"}]}]",
+	Bin: "0x608060405234801561000f575f5ffd5b50604051613406380380613406833981810160405281019061003191906101d7565b805f73ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff16036100a2575f6040517f1e4fbdf70000000000000000000000000000000000000000000000000000000081526004016100999190610211565b60405180910390fd5b6100b1816100b860201b60201c565b505061022a565b5f5f5f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff169050815f5f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055508173ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff167f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e060405160405180910390a35050565b5f5ffd5b5f73ffffffffffffffffffffffffffffffffffffffff82169050919050565b5f6101a68261017d565b9050919050565b6101b68161019c565b81146101c0575f5ffd5b50565b5f815190506101d1816101ad565b92915050565b5f602082840312156101ec576101eb610179565b5b5f6101f9848285016101c3565b91505092915050565b61020b8161019c565b82525050565b5f6020820190506102245f830184610202565b92915050565b6131cf806102375f395ff3fe608060405234801561000f575f5ffd5b5060043610610135575f3560e01c80638da5cb5b116100b6578063c2601afa1161007a578063c2601afa146103ba578063cbb1c002146103ef578063f2fde38b1461041f578063f8e47cee1461043b578063f9b1d6211461046e578063fe1e73fb1461048a57610135565b80638da5cb5b146102e457806392e1292a146103025780639645b35914610336578063a2fbbc581461036c578063a37b8da81461038a57610135565b8063471af597116100fd578063471af5971461021a5780635365e9a41461024a57806366ef8c1c1461027a578063715018a6146102aa578063744a92b6146102b457610135565b8063070f8f5a1461013957806324f8e323146101695780632a2dab4a1461019a578063351863fb146101cc57806335d43b4c146101fc575b5f5ffd5b610153600480360381019061014e9190611f98565b6104a6565b6040516101609190611ff7565b60405180910390f35b610183600480360381019061017e9190611f98565b6104d3565b604051610191929190612187565b60405180910390f35b6101b460048036038101906101af91906121e6565b61067e565b6040516101c39392919061228b565b60405180910390f35b6101e660048036038101906101e191906122f1565b6108a0565b6040516101f3919061232b565b60405180910390f35b61020461090c565b6040516102119190611ff7565b60405180910390f35b610234600480360381019061022f91906122f1565b610918565b6040516102419190612344565b60405180910390f35b610264600480360381019061025f9190611f98565b610a0b565b6040516102719190612364565b60405180910390f35b610294600480360381019061028f91906122f1565b610a34565b6040516102a19190612344565b60405180910390f35b6102b2610ada565b005b6102ce60048036038101906102c991906122f1565b610aed565b6040516102db919061232b565b60405180910390f35b6102ec610b0d565b6040516102f991906123bc565b60405180910390f35b61031c600480360381019061031791906121e6565b610b34565b60405161032d9594939291906123f0565b60405180910390f35b610350600480360381019061034b919061244f565b610f8e565b60405161036397969594939291906124a9565b60405180910390f35b610374611081565b6040516103819190611ff7565b60405180910390f35b6103a4600480360381019061039f91906121e6565b61108d565b6040516103b19190612344565b60405180910390f35b6103d460048036038101906103cf919061244f565b611128565b6040516103e69695949392919061251d565b60405180910390f35b610409600480360381019061040491906121e6565b6112e5565b6040516104169190611ff7565b60405180910390f35b610439600480360381019061043491906125ad565b6112fa565b005b61045560048036038101906104509190611f98565b61137e565b60405161046594939291906125d8565b60405180910390f35b6104886004803603810190610483919061261b565b611520565b005b6104a4600480360381019061049f91906126b7565b611960565b005b6002818051602081018201805184825260208301602085012081835280955050505050505f915090505481565b6060805f6002846040516104e7919061275d565b90815260200160405180910390205490505f811161053a576040517f08c379a0000000000000000000000000000000000000000000000000000000008152600401610531906127bd565b60405180910390fd5b8067ffffffffffffffff81111561055457610553611e74565b5b6040519080825280602002602001820160405280156105825781602001602082028036833780820191505090505b5092508067ffffffffffffffff81111561059f5761059e611e74565b5b6040519080825280602002602001820160405280156105cd5781602001602082028036833780820191505090505b5091505f600190505b8181116106775780846001836105ec9190612808565b815181106105fd576105fc61283b565b5b602002602001018181525050600185604051610619919061275d565b90815260200160405180910390205f8281526020019081526020015f205f0154836001836106479190612808565b815181106106585761065761283b565b5b602002602001018181525050808061066f90612868565b9150506105d6565b5050915091565b5f60605f60035f8581526020019081526020015f20805461069e906128dc565b80601f01602080910402602001604051908101604052809291908181526020018280546106ca906128dc565b80156107155780601f106106ec57610100808354040283529160200191610715565b820191905f5260205f20905b8154815290600101906020018083116106f857829003601f168201915b5050505050915060405180602001604052805f8152508051906020012082805190602001200361075c575f5f60405180602001604052805f81525090925092509250610899565b5f60045f8681526020019081526020015f205490505f600184604051610782919061275d565b90815260200160405180910390205f8381526020019081526020015f206040518060e00160405290815f8201548152602001600182015481526020016002820154815260200160038201548152602001600482015f9054906101000a900460ff1615151515815260200160058201548152602001600682018054610805906128dc565b80601f0160208091040260200160405190810160405280929190818152602001828054610831906128dc565b801561087c5780601f106108535761010080835404028352916020019161087c565b820191905f5260205f20905b81548152906001019060200180831161085f57829003601f168201915b505050505081525050905080608001511594508060600151925050505b9193909250565b5f60058054905082106108e8576040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016108df90612956565b60405180910390fd5b600582815481106108fc576108fb61283b565b5b905f5260205f2001549050919050565b5f600680549050905090565b60606006805490508210610961576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161095890612956565b60405180910390fd5b600682815481106109755761097461283b565b5b905f5260205f20018054610988906128dc565b80601f01602080910402602001604051908101604052809291908181526020018280546109b4906128dc565b80156109ff5780601f106109d6576101008083540402835291602001916109ff565b820191905f5260205f20905b8154815290600101906020018083116109e257829003601f168201915b50505050509050919050565b5f5f600283604051610a1d919061275d565b908152602001604051809103902054119050919050565b60068181548110610a43575f80fd5b905f5260205f20015f915090508054610a5b906128dc565b80601f0160208091040260200160405190810160405280929190818152602001828054610a87906128dc565b8015610ad25780601f10610aa957610100808354040283529160200191610ad2565b820191905f5260205f20905b815481529060010190602001808311610ab557829003601f168201915b505050505081565b610ae2611cfc565b610aeb5f611d83565b565b60058181548110610afc575f80fd5b905f5260205f20015f915090505481565b5f5f5f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff16905090565b5f60605f5f606060035f8781526020019081526020015f208054610b57906128dc565b80601f0160208091040260200160405190810160405280929190818152602001828054610b83906128dc565b8015610bce5780601f10610ba557610100808354040283529160200191610bce565b820191905f5260205f20905b815481529060010190602001808311610bb157829003601f168201915b5050505050935060405180602001604052805f81525080519060200120848051906020012003610c53575f5f5f5f1b60405180602001604052805f81525091906040518060400160405280601a81526020017f526f6f74206e6f7420666f756e6420696e20726567697374727900000000000081525094509450945094509450610f85565b60045f8781526020019081526020015f205492505f600285604051610c78919061275d565b90815260200160405180910390205490505f600186604051610c9a919061275d565b90815260200160405180910390205f8681526020019081526020015f206040518060e00160405290815f8201548152602001600182015481526020016002820154815260200160038201548152602001600482015f9054906101000a900460ff1615151515815260200160058201548152602001600682018054610d1d906128dc565b80601f0160208091040260200160405190810160405280929190818152602001828054610d49906128dc565b8015610d945780601f10610d6b57610100808354040283529160200191610d94565b820191905f5260205f20905b815481529060010190602001808311610d7757829003601f168201915b50505050508152505090505f600187604051610db0919061275d565b90815260200160405180910390205f8481526020019081526020015f206040518060e00160405290815f8201548152602001600182015481526020016002820154815260200160038201548152602001600482015f9054906101000a900460ff1615151515815260200160058201548152602001600682018054610e33906128dc565b80601f0160208091040260200160405190810160405280929190818152602001828054610e5f906128dc565b8015610eaa5780601f10610e8157610100808354040283529160200191610eaa565b820191905f5260205f20905b815481529060010190602001808311610e8d57829003601f168201915b5050505050815250509050805f01519450828603610f0f5760018787876040518060400160405280601781526020017f56616c6964202d2043757272656e742076657273696f6e00000000000000000081525097509750975097509750505050610f85565b816080015115610f59575f8260c001519050600388888884604051602001610f3791906129be565b6040516020818303038152906040529850985098509850985050505050610f85565b600287878760405180606001604052806027815260200161317360279139975097509750975097505050505b91939590929450565b600182805160208101820180518482526020830160208501208183528095505050505050602052805f5260405f205f9150915050805f015490806001015490806002015490806003015490806004015f9054906101000a900460ff1690806005015490806006018054611000906128dc565b80601f016020809104026020016040519081016040528092919081815260200182805461102c906128dc565b80156110775780601f1061104e57610100808354040283529160200191611077565b820191905f5260205f20905b81548152906001019060200180831161105a57829003601f168201915b5050505050905087565b5f600580549050905090565b6003602052805f5260405f205f9150905080546110a9906128dc565b80601f01602080910402602001604051908101604052809291908181526020018280546110d5906128dc565b80156111205780601f106110f757610100808354040283529160200191611120565b820191905f5260205f20905b81548152906001019060200180831161110357829003601f168201915b505050505081565b5f5f5f5f5f60605f8711801561115c5750600288604051611149919061275d565b9081526020016040518091039020548711155b61119b576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161119290612a29565b60405180910390fd5b5f6001896040516111ac919061275d565b90815260200160405180910390205f8981526020019081526020015f206040518060e00160405290815f8201548152602001600182015481526020016002820154815260200160038201548152602001600482015f9054906101000a900460ff161515151581526020016005820154815260200160068201805461122f906128dc565b80601f016020809104026020016040519081016040528092919081815260200182805461125b906128dc565b80156112a65780601f1061127d576101008083540402835291602001916112a6565b820191905f5260205f20905b81548152906001019060200180831161128957829003601f168201915b5050505050815250509050805f01518160400151826060015183608001518460a001518560c00151965096509650965096509650509295509295509295565b6004602052805f5260405f205f915090505481565b611302611cfc565b5f73ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff1603611372575f6040517f1e4fbdf700000000000000000000000000000000000000000000000000000000815260040161136991906123bc565b60405180910390fd5b61137b81611d83565b50565b5f5f5f5f5f600286604051611393919061275d565b90815260200160405180910390205490505f81116113e6576040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016113dd906127bd565b60405180910390fd5b5f6001876040516113f7919061275d565b90815260200160405180910390205f8381526020019081526020015f206040518060e00160405290815f8201548152602001600182015481526020016002820154815260200160038201548152602001600482015f9054906101000a900460ff161515151581526020016005820154815260200160068201805461147a906128dc565b80601f01602080910402602001604051908101604052809291908181526020018280546114a6906128dc565b80156114f15780601f106114c8576101008083540402835291602001916114f1565b820191905f5260205f20905b8154815290600101906020018083116114d457829003601f168201915b5050505050815250509050805f0151816020015182604001518360600151955095509550955050509193509193565b611528611cfc565b5f5f1b830361156c576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161156390612a91565b60405180910390fd5b5f8151116115af576040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016115a690612af9565b60405180910390fd5b60405180602001604052805f8152508051906020012060035f8581526020019081526020015f206040516115e39190612bb3565b60405180910390201461162b576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161162290612c13565b60405180910390fd5b5f60028560405161163c919061275d565b90815260200160405180910390205490505f811161168f576040517f08c379a0000000000000000000000000000000000000000000000000000000008152600401611686906127bd565b60405180910390fd5b5f6001866040516116a0919061275d565b90815260200160405180910390205f8381526020019081526020015f209050806004015f9054906101000a900460ff1615611710576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161170790612ca1565b60405180910390fd5b6001816004015f6101000a81548160ff021916908315150217905550848160050181905550828160060190816117469190612e5f565b505f6001836117559190612f2e565b90506040518060e001604052808781526020018281526020018681526020014281526020015f151581526020015f5f1b815260200160405180602001604052805f8152508152506001886040516117ac919061275d565b90815260200160405180910390205f8381526020019081526020015f205f820151815f01556020820151816001015560408201518160020155606082015181600301556080820151816004015f6101000a81548160ff02191690831515021790555060a0820151816005015560c082015181600601908161182d9190612e5f565b5090505080600288604051611842919061275d565b9081526020016040518091039020819055508660035f8881526020019081526020015f2090816118729190612e5f565b508060045f8881526020019081526020015f2081905550600586908060018154018082558091505060019003905f5260205f20015f909190919091505585825f0154886040516118c2919061275d565b60405180910390207f4a97ba7e0786a95b88bc5a9576a42ba3b36dcdad508f3266d97002d7f65d5cf28685896040516118fd93929190612f61565b60405180910390a48587604051611914919061275d565b60405180910390207f62fc1dad9c7e5a08066a48e92398689a7b0d7fda9a4518c90ed24cba8b51191a83884260405161194f93929190612f9d565b60405180910390a350505050505050565b611968611cfc565b5f5f1b83036119ac576040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016119a39061301c565b60405180910390fd5b5f8251116119ef576040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016119e690613084565b60405180910390fd5b5f8111611a31576040517f08c379a0000000000000000000000000000000000000000000000000000000008152600401611a28906130ec565b60405180910390fd5b60405180602001604052805f8152508051906020012060035f8581526020019081526020015f20604051611a659190612bb3565b604051809103902014611aad576040517f08c379a0000000000000000000000000000000000000000000000000000000008152600401611aa490613154565b60405180910390fd5b5f6001600284604051611ac0919061275d565b908152602001604051809103902054611ad99190612f2e565b90506040518060e001604052808581526020018281526020018381526020014281526020015f151581526020015f5f1b815260200160405180602001604052805f815250815250600184604051611b30919061275d565b90815260200160405180910390205f8381526020019081526020015f205f820151815f01556020820151816001015560408201518160020155606082015181600301556080820151816004015f6101000a81548160ff02191690831515021790555060a0820151816005015560c0820151816006019081611bb19190612e5f565b5090505080600284604051611bc6919061275d565b9081526020016040518091039020819055508260035f8681526020019081526020015f209081611bf69190612e5f565b508060045f8681526020019081526020015f2081905550600584908060018154018082558091505060019003905f5260205f20015f909190919091505560018103611ca457600683908060018154018082558091505060019003905f5260205f20015f909190919091509081611c6c9190612e5f565b506001600784604051611c7f919061275d565b90815260200160405180910390205f6101000a81548160ff0219169083151502179055505b8383604051611cb3919061275d565b60405180910390207f62fc1dad9c7e5a08066a48e92398689a7b0d7fda9a4518c90ed24cba8b51191a838542604051611cee93929190612f9d565b60405180910390a350505050565b611d04611e44565b73ffffffffffffffffffffffffffffffffffffffff16611d22610b0d565b73ffffffffffffffffffffffffffffffffffffffff1614611d8157611d45611e44565b6040517f118cdaa7000000000000000000000000000000000000000000000000000000008152600401611d7891906123bc565b60405180910390fd5b565b5f5f5f9054906101000a900473ffffffffffffffffffffffffffffffffffffffff169050815f5f6101000a81548173ffffffffffffffffffffffffffffffffffffffff021916908373ffffffffffffffffffffffffffffffffffffffff1602179055508173ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff167f8be0079c531659141344cd1fd0a4f28419497f9722a3daafe3b4186f6b6457e060405160405180910390a35050565b5f33905090565b5f604051905090565b5f5ffd5b5f5ffd5b5f5ffd5b5f5ffd5b5f601f19601f8301169050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52604160045260245ffd5b611eaa82611e64565b810181811067ffffffffffffffff82111715611ec957611ec8611e74565b5b80604052505050565b5f611edb611e4b565b9050611ee78282611ea1565b919050565b5f67ffffffffffffffff821115611f0657611f05611e74565b5b611f0f82611e64565b9050602081019050919050565b828183375f83830152505050565b5f611f3c611f3784611eec565b611ed2565b905082815260208101848484011115611f5857611f57611e60565b5b611f63848285611f1c565b509392505050565b5f82601f830112611f7f57611f7e611e5c565b5b8135611f8f848260208601611f2a565b91505092915050565b5f60208284031215611fad57611fac611e54565b5b5f82013567ffffffffffffffff811115611fca57611fc9611e58565b5b611fd684828501611f6b565b91505092915050565b5f819050919050565b611ff181611fdf565b82525050565b5f60208201905061200a5f830184611fe8565b92915050565b5f81519050919050565b5f82825260208201905092915050565b5f819050602082019050919050565b61204281611fdf565b82525050565b5f6120538383612039565b60208301905092915050565b5f602082019050919050565b5f61207582612010565b61207f818561201a565b935061208a8361202a565b805f5b838110156120ba5781516120a18882612048565b97506120ac8361205f565b92505060018101905061208d565b5085935050505092915050565b5f81519050919050565b5f82825260208201905092915050565b5f819050602082019050919050565b5f819050919050565b612102816120f0565b82525050565b5f61211383836120f9565b60208301905092915050565b5f602082019050919050565b5f612135826120c7565b61213f81856120d1565b935061214a836120e1565b805f5b8381101561217a5781516121618882612108565b975061216c8361211f565b92505060018101905061214d565b5085935050505092915050565b5f6040820190508181035f83015261219f818561206b565b905081810360208301526121b3818461212b565b90509392505050565b6121c5816120f0565b81146121cf575f5ffd5b50565b5f813590506121e0816121bc565b92915050565b5f602082840312156121fb576121fa611e54565b5b5f612208848285016121d2565b91505092915050565b5f8115159050919050565b61222581612211565b82525050565b5f81519050919050565b5f82825260208201905092915050565b8281835e5f83830152505050565b5f61225d8261222b565b6122678185612235565b9350612277818560208601612245565b61228081611e64565b840191505092915050565b5f60608201905061229e5f83018661221c565b81810360208301526122b08185612253565b90506122bf6040830184611fe8565b949350505050565b6122d081611fdf565b81146122da575f5ffd5b50565b5f813590506122eb816122c7565b92915050565b5f6020828403121561230657612305611e54565b5b5f612313848285016122dd565b91505092915050565b612325816120f0565b82525050565b5f60208201905061233e5f83018461231c565b92915050565b5f6020820190508181035f83015261235c8184612253565b905092915050565b5f6020820190506123775f83018461221c565b92915050565b5f73ffffffffffffffffffffffffffffffffffffffff82169050919050565b5f6123a68261237d565b9050919050565b6123b68161239c565b82525050565b5f6020820190506123cf5f8301846123ad565b92915050565b5f60ff82169050919050565b6123ea816123d5565b82525050565b5f60a0820190506124035f8301886123e1565b81810360208301526124158187612253565b90506124246040830186611fe8565b612431606083018561231c565b81810360808301526124438184612253565b90509695505050505050565b5f5f6040838503121561246557612464611e54565b5b5f83013567ffffffffffffffff81111561248257612481611e58565b5b61248e85828601611f6b565b925050602061249f858286016122dd565b9150509250929050565b5f60e0820190506124bc5f83018a61231c565b6124c96020830189611fe8565b6124d66040830188611fe8565b6124e36060830187611fe8565b6124f0608083018661221c565b6124fd60a083018561231c565b81810360c083015261250f8184612253565b905098975050505050505050565b5f60c0820190506125305f83018961231c565b61253d6020830188611fe8565b61254a6040830187611fe8565b612557606083018661221c565b612564608083018561231c565b81810360a08301526125768184612253565b9050979650505050505050565b61258c8161239c565b8114612596575f5ffd5b50565b5f813590506125a781612583565b92915050565b5f602082840312156125c2576125c1611e54565b5b5f6125cf84828501612599565b91505092915050565b5f6080820190506125eb5f83018761231c565b6125f86020830186611fe8565b6126056040830185611fe8565b6126126060830184611fe8565b95945050505050565b5f5f5f5f6080858703121561263357612632611e54565b5b5f85013567ffffffffffffffff8111156126505761264f611e58565b5b61265c87828801611f6b565b945050602061266d878288016121d2565b935050604061267e878288016122dd565b925050606085013567ffffffffffffffff81111561269f5761269e611e58565b5b6126ab87828801611f6b565b91505092959194509250565b5f5f5f606084860312156126ce576126cd611e54565b5b5f6126db868287016121d2565b935050602084013567ffffffffffffffff8111156126fc576126fb611e58565b5b61270886828701611f6b565b9250506040612719868287016122dd565b9150509250925092565b5f81905092915050565b5f6127378261222b565b6127418185612723565b9350612751818560208601612245565b80840191505092915050565b5f612768828461272d565b915081905092915050565b7f5465726d206e6f7420666f756e640000000000000000000000000000000000005f82015250565b5f6127a7600e83612235565b91506127b282612773565b602082019050919050565b5f6020820190508181035f8301526127d48161279b565b9050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52601160045260245ffd5b5f61281282611fdf565b915061281d83611fdf565b9250828203905081811115612835576128346127db565b5b92915050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52603260045260245ffd5b5f61287282611fdf565b91507fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff82036128a4576128a36127db565b5b600182019050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f60028204905060018216806128f357607f821691505b602082108103612906576129056128af565b5b50919050565b7f496e646578206f7574206f6620626f756e6473000000000000000000000000005f82015250565b5f612940601383612235565b915061294b8261290c565b602082019050919050565b5f6020820190508181035f83015261296d81612934565b9050919050565b7f53757065727365646564202d20000000000000000000000000000000000000005f82015250565b5f6129a8600d83612723565b91506129b382612974565b600d82019050919050565b5f6129c88261299c565b91506129d4828461272d565b915081905092915050565b7f496e76616c69642076657273696f6e00000000000000000000000000000000005f82015250565b5f612a13600f83612235565b9150612a1e826129df565b602082019050919050565b5f6020820190508181035f830152612a4081612a07565b9050919050565b7f496e76616c6964206e657720726f6f74000000000000000000000000000000005f82015250565b5f612a7b601083612235565b9150612a8682612a47565b602082019050919050565b5f6020820190508181035f830152612aa881612a6f565b9050919050565b7f526561736f6e20726571756972656400000000000000000000000000000000005f82015250565b5f612ae3600f83612235565b9150612aee82612aaf565b602082019050919050565b5f6020820190508181035f830152612b1081612ad7565b9050919050565b5f81905092915050565b5f819050815f5260205f209050919050565b5f8154612b3f816128dc565b612b498186612b17565b9450600182165f8114612b635760018114612b7857612baa565b60ff1983168652811515820286019350612baa565b612b8185612b21565b5f5b83811015612ba257815481890152600182019150602081019050612b83565b838801955050505b50505092915050565b5f612bbe8284612b33565b915081905092915050565b7f4e657720726f6f7420616c7265616479207075626c69736865640000000000005f82015250565b5f612bfd601a83612235565b9150612c0882612bc9565b602082019050919050565b5f6020820190508181035f830152612c2a81612bf1565b9050919050565b7f43757272656e742076657273696f6e20616c72656164792073757065727365645f8201527f6564000000000000000000000000000000000000000000000000000000000000602082015250565b5f612c8b602283612235565b9150612c9682612c31565b604082019050919050565b5f6020820190508181035f830152612cb881612c7f565b9050919050565b5f819050815f5260205f209050919050565b5f6020601f8301049050919050565b5f82821b905092915050565b5f60088302612d1b7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff82612ce0565b612d258683612ce0565b95508019841693508086168417925050509392505050565b5f819050919050565b5f612d60612d5b612d5684611fdf565b612d3d565b611fdf565b9050919050565b5f819050919050565b612d7983612d46565b612d8d612d8582612d67565b848454612cec565b825550505050565b5f5f905090565b612da4612d95565b612daf818484612d70565b505050565b5b81811015612dd257612dc75f82612d9c565b600181019050612db5565b5050565b601f821115612e1757612de881612cbf565b612df184612cd1565b81016020851015612e00578190505b612e14612e0c85612cd1565b830182612db4565b50505b505050565b5f82821c905092915050565b5f612e375f1984600802612e1c565b1980831691505092915050565b5f612e4f8383612e28565b9150826002028217905092915050565b612e688261222b565b67ffffffffffffffff811115612e8157612e80611e74565b5b612e8b82546128dc565b612e96828285612dd6565b5f60209050601f831160018114612ec7575f8415612eb5578287015190505b612ebf8582612e44565b865550612f26565b601f198416612ed586612cbf565b5f5b82811015612efc57848901518255600182019150602085019450602081019050612ed7565b86831015612f195784890151612f15601f891682612e28565b8355505b6001600288020188555050505b505050505050565b5f612f3882611fdf565b9150612f4383611fdf565b9250828201905080821115612f5b57612f5a6127db565b5b92915050565b5f606082019050612f745f830186611fe8565b612f816020830185611fe8565b8181036040830152612f938184612253565b9050949350505050565b5f606082019050612fb05f830186611fe8565b612fbd6020830185611fe8565b612fca6040830184611fe8565b949350505050565b7f496e76616c6964205665726b6c6520726f6f74000000000000000000000000005f82015250565b5f613006601383612235565b915061301182612fd2565b602082019050919050565b5f6020820190508181035f83015261303381612ffa565b9050919050565b7f5465726d204944207265717569726564000000000000000000000000000000005f82015250565b5f61306e601083612235565b91506130798261303a565b602082019050919050565b5f6020820190508181035f83015261309b81613062565b9050919050565b7f496e76616c69642073747564656e7420636f756e7400000000000000000000005f82015250565b5f6130d6601583612235565b91506130e1826130a2565b602082019050919050565b5f6020820190508181035f830152613103816130ca565b9050919050565b7f526f6f7420616c7265616479207075626c6973686564000000000000000000005f82015250565b5f61313e601683612235565b91506131498261310a565b602082019050919050565b5f6020820190508181035f83015261316b81613132565b905091905056fe56616c696420627574206f75746461746564202d20557064617465207265636f6d6d656e646564a2646970667358221220c235d8b8b42b0a45c9817d387f1bbc1a74d02fd5adc1bd1350e047127af9bd5264736f6c634300081e0033",
 }
 
 // IUMiCertRegistryABI is the input ABI used to generate the binding from.
 // Deprecated: Use IUMiCertRegistryMetaData.ABI instead.
 var IUMiCertRegistryABI = IUMiCertRegistryMetaData.ABI
 
+// IUMiCertRegistryBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use IUMiCertRegistryMetaData.Bin instead.
+var IUMiCertRegistryBin = IUMiCertRegistryMetaData.Bin
+
+// DeployIUMiCertRegistry deploys a new Ethereum contract, binding an instance of IUMiCertRegistry to it.
+func DeployIUMiCertRegistry(auth *bind.TransactOpts, backend bind.ContractBackend, initialOwner common.Address) (common.Address, *types.Transaction, *IUMiCertRegistry, error) {
+	parsed, err := IUMiCertRegistryMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(IUMiCertRegistryBin), backend, initialOwner)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &IUMiCertRegistry{IUMiCertRegistryCaller: IUMiCertRegistryCaller{contract: contract}, IUMiCertRegistryTransactor: IUMiCertRegistryTransactor{contract: contract}, IUMiCertRegistryFilterer: IUMiCertRegistryFilterer{contract: contract}}, nil
+}
+
 // IUMiCertRegistry is an auto generated Go binding around an Ethereum contract.
 type IUMiCertRegistry struct {
 	IUMiCertRegistryCaller     // Read-only binding to the contract
@@ -0,0 +1,128 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Registry event kinds
+const (
+	EventTermRootPublished  = "published"
+	EventTermRootSuperseded = "superseded"
+)
+
+// RegistryEvent is a TermRootPublished or TermRootSuperseded log of IUMiCertRegistry.
+// termId is an indexed string, so only its hash is in the log; TermID is resolved
+// through rootToTerm.
+type RegistryEvent struct {
+	Kind          string
+	TermID        string
+	Root          [32]byte
+	Version       uint64
+	TotalStudents uint64    // Published only
+	Timestamp     time.Time // Published only
+
+	// Superseded only
+	OldRoot    [32]byte
+	OldVersion uint64
+	Reason     string
+
+	TxHash      common.Hash
+	BlockNumber uint64
+	LogIndex    uint
+}
+
+// LatestBlockNumber returns the number of the chain head
+func (bi *BlockchainIntegration) LatestBlockNumber(ctx context.Context) (uint64, error) {
+	header, err := bi.client.GetClient().HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// TransactionReceipt returns the receipt of a mined transaction
+func (bi *BlockchainIntegration) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	receipt, err := bi.client.receipts.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// FetchRegistryEvents returns the registry events in blocks [from, to], ordered as they
+// were emitted
+func (bi *BlockchainIntegration) FetchRegistryEvents(ctx context.Context, from, to uint64) ([]RegistryEvent, error) {
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+	callOpts := bi.client.GetCallOpts(ctx)
+	var events []RegistryEvent
+
+	published, err := bi.registryContract.FilterTermRootPublished(opts, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter TermRootPublished events: %w", err)
+	}
+	defer published.Close()
+	for published.Next() {
+		ev := published.Event
+		termID, err := bi.registryContract.RootToTerm(callOpts, ev.VerkleRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve term of root 0x%x: %w", ev.VerkleRoot, err)
+		}
+		events = append(events, RegistryEvent{
+			Kind:          EventTermRootPublished,
+			TermID:        termID,
+			Root:          ev.VerkleRoot,
+			Version:       ev.Version.Uint64(),
+			TotalStudents: ev.TotalStudents.Uint64(),
+			Timestamp:     time.Unix(ev.Timestamp.Int64(), 0),
+			TxHash:        ev.Raw.TxHash,
+			BlockNumber:   ev.Raw.BlockNumber,
+			LogIndex:      ev.Raw.Index,
+		})
+	}
+	if err := published.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read TermRootPublished events: %w", err)
+	}
+
+	superseded, err := bi.registryContract.FilterTermRootSuperseded(opts, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter TermRootSuperseded events: %w", err)
+	}
+	defer superseded.Close()
+	for superseded.Next() {
+		ev := superseded.Event
+		termID, err := bi.registryContract.RootToTerm(callOpts, ev.NewRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve term of root 0x%x: %w", ev.NewRoot, err)
+		}
+		events = append(events, RegistryEvent{
+			Kind:        EventTermRootSuperseded,
+			TermID:      termID,
+			Root:        ev.NewRoot,
+			Version:     ev.NewVersion.Uint64(),
+			OldRoot:     ev.OldRoot,
+			OldVersion:  ev.OldVersion.Uint64(),
+			Reason:      ev.Reason,
+			TxHash:      ev.Raw.TxHash,
+			BlockNumber: ev.Raw.BlockNumber,
+			LogIndex:    ev.Raw.Index,
+		})
+	}
+	if err := superseded.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read TermRootSuperseded events: %w", err)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+	return events, nil
+}
@@ -23,6 +23,23 @@ type BlockchainIntegration struct {
 	client           *BlockchainClient
 	contractAddress  common.Address
 	registryContract *IUMiCertRegistry
+	listener         PublicationListener
+}
+
+// Publication is a term root the integration published and saw confirmed
+type Publication struct {
+	Method        string // MethodPublishTermRoot or MethodSupersedeTerm
+	TermID        string
+	VerkleRoot    [32]byte
+	TotalStudents *big.Int
+	Reason        string // Supersession reason
+	Result        *PublishResult
+}
+
+// PublicationListener is told about every term root the integration published. It is
+// called after the transaction is confirmed and must not block for long.
+type PublicationListener interface {
+	TermRootPublished(publication *Publication)
 }
 
 // PublishResult contains the result of publishing a term root
@@ -34,13 +51,37 @@ type PublishResult struct {
 	PublishedAt     time.Time `json:"published_at"`
 }
 
-// NewBlockchainIntegration creates a new blockchain integration instance
+// NewBlockchainIntegration creates a new blockchain integration instance.
+// For SimulatedNetwork the registry deployed on the shared simulated chain is used and
+// contractAddressHex is ignored.
 func NewBlockchainIntegration(network, privateKeyHex, contractAddressHex string) (*BlockchainIntegration, error) {
 	// Create blockchain client
 	client, err := NewBlockchainClient(network, privateKeyHex)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create blockchain client: %w", err)
 	}
+	return newRegistryIntegration(client, network, privateKeyHex, contractAddressHex)
+}
+
+// NewReadOnlyBlockchainIntegration connects to the registry of a network for reading roots,
+// events and receipts. It has no signer; publishing through it fails with ErrReadOnlyClient.
+func NewReadOnlyBlockchainIntegration(network, contractAddressHex string) (*BlockchainIntegration, error) {
+	client, err := NewReadOnlyBlockchainClient(network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blockchain client: %w", err)
+	}
+	return newRegistryIntegration(client, network, "", contractAddressHex)
+}
+
+// newRegistryIntegration binds client to the registry of network
+func newRegistryIntegration(client *BlockchainClient, network, privateKeyHex, contractAddressHex string) (*BlockchainIntegration, error) {
+	if network == SimulatedNetwork {
+		chain, err := SharedSimulatedChain(privateKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start simulated chain: %w", err)
+		}
+		return NewBlockchainIntegrationWithBackend(client, chain.Registry)
+	}
 
 	// Parse contract address
 	if !strings.HasPrefix(contractAddressHex, "0x") {
@@ -48,6 +89,12 @@ func NewBlockchainIntegration(network, privateKeyHex, contractAddressHex string)
 	}
 	contractAddress := common.HexToAddress(contractAddressHex)
 
+	return NewBlockchainIntegrationWithBackend(client, contractAddress)
+}
+
+// NewBlockchainIntegrationWithBackend creates a blockchain integration for the registry at
+// contractAddress, reached through the client's contract backend
+func NewBlockchainIntegrationWithBackend(client *BlockchainClient, contractAddress common.Address) (*BlockchainIntegration, error) {
 	// Create contract instance
 	registryContract, err := NewIUMiCertRegistry(contractAddress, client.GetClient())
 	if err != nil {
@@ -63,37 +110,17 @@ func NewBlockchainIntegration(network, privateKeyHex, contractAddressHex string)
 
 // PublishTermRoot publishes a term root to the blockchain
 func (bi *BlockchainIntegration) PublishTermRoot(ctx context.Context, verkleRootHex, termID string, totalStudents *big.Int) (*PublishResult, error) {
-	// Parse verkle root
-	if !strings.HasPrefix(verkleRootHex, "0x") {
-		verkleRootHex = "0x" + verkleRootHex
-	}
-	
-	verkleRootBytes := common.FromHex(verkleRootHex)
-	if len(verkleRootBytes) != 32 {
-		return nil, fmt.Errorf("verkle root must be 32 bytes, got %d", len(verkleRootBytes))
-	}
-
-	var verkleRoot [32]byte
-	copy(verkleRoot[:], verkleRootBytes)
-
-	// Get transaction options
-	auth, err := bi.client.GetTransactOpts(ctx)
+	verkleRoot, err := parseRootHex(verkleRootHex)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction options: %w", err)
+		return nil, err
 	}
 
-	// Call publishTermRoot function
-	tx, err := bi.registryContract.PublishTermRoot(auth, verkleRoot, termID, totalStudents)
+	// Call publishTermRoot function and wait for it to be mined
+	tx, receipt, err := bi.client.TxManager().Send(ctx, TxMeta{TermID: termID, VerkleRoot: verkleRoot}, bi.publishTx(verkleRoot, termID, totalStudents))
 	if err != nil {
 		return nil, fmt.Errorf("failed to publish term root: %w", err)
 	}
 
-	// Wait for transaction to be mined
-	receipt, err := bind.WaitMined(ctx, bi.client.GetClient(), tx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to wait for transaction mining: %w", err)
-	}
-
 	// Check if transaction was successful
 	if receipt.Status != types.ReceiptStatusSuccessful {
 		return nil, fmt.Errorf("transaction failed with status %d", receipt.Status)
@@ -106,6 +133,7 @@ func (bi *BlockchainIntegration) PublishTermRoot(ctx context.Context, verkleRoot
 		Status:          "success",
 		PublishedAt:     time.Now(),
 	}
+	bi.published(&Publication{Method: MethodPublishTermRoot, TermID: termID, VerkleRoot: verkleRoot, TotalStudents: totalStudents, Result: result})
 
 	return result, nil
 }
@@ -201,6 +229,28 @@ func (bi *BlockchainIntegration) GetPublishedRoot(ctx context.Context, index *bi
 	return root, nil
 }
 
+// SetTxStore persists every transaction attempt of the integration in store
+func (bi *BlockchainIntegration) SetTxStore(store TxStore) {
+	bi.client.TxManager().SetStore(store)
+}
+
+// SetPublicationListener tells listener about every term root the integration publishes
+func (bi *BlockchainIntegration) SetPublicationListener(listener PublicationListener) {
+	bi.listener = listener
+}
+
+// published tells the listener, if any, about a confirmed publication
+func (bi *BlockchainIntegration) published(publication *Publication) {
+	if bi.listener != nil {
+		bi.listener.TermRootPublished(publication)
+	}
+}
+
+// ContractAddress returns the address of the registry the integration publishes to
+func (bi *BlockchainIntegration) ContractAddress() common.Address {
+	return bi.contractAddress
+}
+
 // Close closes the blockchain client connection
 func (bi *BlockchainIntegration) Close() {
 	if bi.client != nil {
@@ -227,51 +277,113 @@ type RootStatus struct {
 
 // PublishTermRootFromFile publishes a term root from a JSON file
 func (bi *BlockchainIntegration) PublishTermRootFromFile(ctx context.Context, rootFilePath string) (*PublishResult, error) {
+	verkleRootHex, termID, totalStudents, err := ReadRootFile(rootFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish to blockchain
+	result, err := bi.PublishTermRoot(ctx, verkleRootHex, termID, totalStudents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish term root: %w", err)
+	}
+
+	// Save transaction record
+	if err := bi.SaveTransactionRecord(result, rootFilePath); err != nil {
+		// Log the error but don't fail the operation
+		fmt.Printf("Warning: failed to save transaction record: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// EstimatePublishTermRoot prices publishing a term root without sending the transaction
+func (bi *BlockchainIntegration) EstimatePublishTermRoot(ctx context.Context, verkleRootHex, termID string, totalStudents *big.Int) (*FeeEstimate, error) {
+	verkleRoot, err := parseRootHex(verkleRootHex)
+	if err != nil {
+		return nil, err
+	}
+	return bi.client.TxManager().Estimate(ctx, bi.publishTx(verkleRoot, termID, totalStudents))
+}
+
+// EstimatePublishTermRootFromFile prices publishing the term root of a JSON root file
+func (bi *BlockchainIntegration) EstimatePublishTermRootFromFile(ctx context.Context, rootFilePath string) (*FeeEstimate, error) {
+	verkleRootHex, termID, totalStudents, err := ReadRootFile(rootFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return bi.EstimatePublishTermRoot(ctx, verkleRootHex, termID, totalStudents)
+}
+
+// EstimateSupersedeTerm prices superseding a term without sending the transaction
+func (bi *BlockchainIntegration) EstimateSupersedeTerm(ctx context.Context, termID string, newVerkleRootHex string, totalStudents *big.Int, reason string) (*FeeEstimate, error) {
+	newVerkleRoot, err := parseRootHex(newVerkleRootHex)
+	if err != nil {
+		return nil, err
+	}
+	return bi.client.TxManager().Estimate(ctx, bi.supersedeTx(termID, newVerkleRoot, totalStudents, reason))
+}
+
+func (bi *BlockchainIntegration) publishTx(verkleRoot [32]byte, termID string, totalStudents *big.Int) func(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return bi.registryContract.PublishTermRoot(opts, verkleRoot, termID, totalStudents)
+	}
+}
+
+func (bi *BlockchainIntegration) supersedeTx(termID string, newVerkleRoot [32]byte, totalStudents *big.Int, reason string) func(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return bi.registryContract.SupersedeTerm(opts, termID, newVerkleRoot, totalStudents, reason)
+	}
+}
+
+// parseRootHex parses a 32-byte verkle root, with or without 0x prefix
+func parseRootHex(verkleRootHex string) ([32]byte, error) {
+	var verkleRoot [32]byte
+	if !strings.HasPrefix(verkleRootHex, "0x") {
+		verkleRootHex = "0x" + verkleRootHex
+	}
+
+	verkleRootBytes := common.FromHex(verkleRootHex)
+	if len(verkleRootBytes) != 32 {
+		return verkleRoot, fmt.Errorf("verkle root must be 32 bytes, got %d", len(verkleRootBytes))
+	}
+	copy(verkleRoot[:], verkleRootBytes)
+	return verkleRoot, nil
+}
+
+// ReadRootFile reads the root, term and student count of a root JSON file
+func ReadRootFile(rootFilePath string) (string, string, *big.Int, error) {
 	// Read root data from file
 	data, err := os.ReadFile(rootFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read root file: %w", err)
+		return "", "", nil, fmt.Errorf("failed to read root file: %w", err)
 	}
 
 	var rootData map[string]interface{}
 	if err := json.Unmarshal(data, &rootData); err != nil {
-		return nil, fmt.Errorf("failed to parse root data: %w", err)
+		return "", "", nil, fmt.Errorf("failed to parse root data: %w", err)
 	}
 
 	// Extract required fields
 	verkleRootHex, ok := rootData["verkle_root"].(string)
 	if !ok {
-		return nil, fmt.Errorf("missing or invalid verkle_root in root data")
+		return "", "", nil, fmt.Errorf("missing or invalid verkle_root in root data")
 	}
 
 	termID, ok := rootData["term_id"].(string)
 	if !ok {
-		return nil, fmt.Errorf("missing or invalid term_id in root data")
+		return "", "", nil, fmt.Errorf("missing or invalid term_id in root data")
 	}
 
 	totalStudentsFloat, ok := rootData["total_students"].(float64)
 	if !ok {
-		return nil, fmt.Errorf("missing or invalid total_students in root data")
-	}
-	totalStudents := big.NewInt(int64(totalStudentsFloat))
-
-	// Publish to blockchain
-	result, err := bi.PublishTermRoot(ctx, verkleRootHex, termID, totalStudents)
-	if err != nil {
-		return nil, fmt.Errorf("failed to publish term root: %w", err)
+		return "", "", nil, fmt.Errorf("missing or invalid total_students in root data")
 	}
-
-	// Save transaction record
-	if err := bi.saveTransactionRecord(result, rootFilePath); err != nil {
-		// Log the error but don't fail the operation
-		fmt.Printf("Warning: failed to save transaction record: %v\n", err)
-	}
-
-	return result, nil
+	return verkleRootHex, termID, big.NewInt(int64(totalStudentsFloat)), nil
 }
 
-// saveTransactionRecord saves the transaction record to the publish_ready/transactions directory
-func (bi *BlockchainIntegration) saveTransactionRecord(result *PublishResult, rootFilePath string) error {
+// SaveTransactionRecord saves the transaction record to the publish_ready/transactions directory
+func (bi *BlockchainIntegration) SaveTransactionRecord(result *PublishResult, rootFilePath string) error {
 	// Create transaction record
 	txRecord := map[string]interface{}{
 		"transaction_hash": result.TransactionHash,
@@ -311,37 +423,17 @@ func (bi *BlockchainIntegration) saveTransactionRecord(result *PublishResult, ro
 
 // SupersedeTerm publishes a new version of a term root (for revocation)
 func (bi *BlockchainIntegration) SupersedeTerm(ctx context.Context, termID string, newVerkleRootHex string, totalStudents *big.Int, reason string) (*PublishResult, error) {
-	// Parse new verkle root
-	if !strings.HasPrefix(newVerkleRootHex, "0x") {
-		newVerkleRootHex = "0x" + newVerkleRootHex
-	}
-
-	verkleRootBytes := common.FromHex(newVerkleRootHex)
-	if len(verkleRootBytes) != 32 {
-		return nil, fmt.Errorf("verkle root must be 32 bytes, got %d", len(verkleRootBytes))
-	}
-
-	var newVerkleRoot [32]byte
-	copy(newVerkleRoot[:], verkleRootBytes)
-
-	// Get transaction options
-	auth, err := bi.client.GetTransactOpts(ctx)
+	newVerkleRoot, err := parseRootHex(newVerkleRootHex)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get transaction options: %w", err)
+		return nil, err
 	}
 
-	// Call supersedeTerm function
-	tx, err := bi.registryContract.SupersedeTerm(auth, termID, newVerkleRoot, totalStudents, reason)
+	// Call supersedeTerm function and wait for it to be mined
+	tx, receipt, err := bi.client.TxManager().Send(ctx, TxMeta{TermID: termID, VerkleRoot: newVerkleRoot}, bi.supersedeTx(termID, newVerkleRoot, totalStudents, reason))
 	if err != nil {
 		return nil, fmt.Errorf("failed to supersede term: %w", err)
 	}
 
-	// Wait for transaction to be mined
-	receipt, err := bind.WaitMined(ctx, bi.client.GetClient(), tx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to wait for transaction mining: %w", err)
-	}
-
 	// Check if transaction was successful
 	if receipt.Status != types.ReceiptStatusSuccessful {
 		return nil, fmt.Errorf("transaction failed with status %d", receipt.Status)
@@ -354,6 +446,7 @@ func (bi *BlockchainIntegration) SupersedeTerm(ctx context.Context, termID strin
 		Status:          "success",
 		PublishedAt:     time.Now(),
 	}
+	bi.published(&Publication{Method: MethodSupersedeTerm, TermID: termID, VerkleRoot: newVerkleRoot, TotalStudents: totalStudents, Reason: reason, Result: result})
 
 	return result, nil
 }
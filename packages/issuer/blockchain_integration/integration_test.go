@@ -0,0 +1,238 @@
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"iumicert/issuer/config"
+)
+
+// newTestIntegration connects to the network selected by NETWORK, defaulting to an
+// in-process simulated chain, so the same tests can also run against Anvil
+func newTestIntegration(t *testing.T) *BlockchainIntegration {
+	t.Helper()
+	network := os.Getenv("NETWORK")
+	if network == "" || network == SimulatedNetwork {
+		chain, err := NewSimulatedChain("", 500000)
+		if err != nil {
+			t.Fatalf("Failed to start simulated chain: %v", err)
+		}
+		t.Cleanup(func() { chain.Close() })
+
+		integration, err := chain.Integration()
+		if err != nil {
+			t.Fatalf("Failed to create integration: %v", err)
+		}
+		return integration
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Network = network
+	integration, err := NewBlockchainIntegration(network, cfg.GetPrivateKey(), cfg.GetContractAddress())
+	if err != nil {
+		t.Fatalf("Failed to connect to %s: %v", network, err)
+	}
+	t.Cleanup(integration.Close)
+	return integration
+}
+
+// testRoot returns a root that is unique per test run, so runs against a persistent chain
+// do not collide with roots published earlier
+func testRoot(label string) string {
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("%s-%d", label, time.Now().UnixNano()))).Hex()
+}
+
+// publicationRecorder is a PublicationListener that keeps what it was told
+type publicationRecorder []*Publication
+
+func (r *publicationRecorder) TermRootPublished(publication *Publication) {
+	*r = append(*r, publication)
+}
+
+// TestPublishAndSupersedeTerm runs the publish → verify → supersede → history cycle that the
+// revocation pipeline depends on
+func TestPublishAndSupersedeTerm(t *testing.T) {
+	integration := newTestIntegration(t)
+	ctx := context.Background()
+	termID := fmt.Sprintf("Semester_1_2024_%d", time.Now().UnixNano())
+	var published publicationRecorder
+	integration.SetPublicationListener(&published)
+
+	countBefore, err := integration.GetPublishedRootsCount(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read published roots count: %v", err)
+	}
+
+	// Version 1
+	rootV1 := testRoot("v1")
+	result, err := integration.PublishTermRoot(ctx, rootV1, termID, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("Failed to publish term root: %v", err)
+	}
+	if result.Status != "success" || result.BlockNumber == 0 {
+		t.Fatalf("Unexpected publish result: %+v", result)
+	}
+
+	valid, anchoredTerm, _, err := integration.VerifyReceiptAnchor(ctx, rootV1)
+	if err != nil || !valid || anchoredTerm != termID {
+		t.Fatalf("Published root not anchored: valid=%v term=%q err=%v", valid, anchoredTerm, err)
+	}
+
+	if _, err := integration.PublishTermRoot(ctx, rootV1, termID, big.NewInt(5)); err == nil {
+		t.Fatalf("Publishing the same root twice should be rejected")
+	}
+
+	// Version 2 supersedes version 1
+	rootV2 := testRoot("v2")
+	if _, err := integration.SupersedeTerm(ctx, termID, rootV2, big.NewInt(5), "Revoked 1 credential"); err != nil {
+		t.Fatalf("Failed to supersede term: %v", err)
+	}
+
+	latest, err := integration.GetLatestRootForTerm(ctx, termID)
+	if err != nil {
+		t.Fatalf("Failed to get latest root: %v", err)
+	}
+	if fmt.Sprintf("0x%x", latest.RootHash) != rootV2 || latest.Version.Int64() != 2 {
+		t.Fatalf("Latest root is %x version %s, expected %s version 2", latest.RootHash, latest.Version, rootV2)
+	}
+
+	oldStatus, err := integration.CheckRootStatus(ctx, rootV1)
+	if err != nil {
+		t.Fatalf("Failed to check old root status: %v", err)
+	}
+	if oldStatus.Status == 1 || fmt.Sprintf("0x%x", oldStatus.LatestRoot) != rootV2 {
+		t.Fatalf("Superseded root still reported current: %+v", oldStatus)
+	}
+	newStatus, err := integration.CheckRootStatus(ctx, rootV2)
+	if err != nil || newStatus.Status != 1 {
+		t.Fatalf("New root not reported current: %+v %v", newStatus, err)
+	}
+
+	versions, roots, err := integration.GetTermHistory(ctx, termID)
+	if err != nil {
+		t.Fatalf("Failed to get term history: %v", err)
+	}
+	if len(versions) != 2 || fmt.Sprintf("0x%x", roots[0]) != rootV1 || fmt.Sprintf("0x%x", roots[1]) != rootV2 {
+		t.Fatalf("Unexpected term history: versions=%v roots=%x", versions, roots)
+	}
+
+	countAfter, err := integration.GetPublishedRootsCount(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read published roots count: %v", err)
+	}
+	if new(big.Int).Sub(countAfter, countBefore).Int64() != 2 {
+		t.Fatalf("Expected 2 new published roots, got %s → %s", countBefore, countAfter)
+	}
+
+	// The rejected duplicate is not reported
+	if len(published) != 2 || published[0].Method != MethodPublishTermRoot || published[1].Method != MethodSupersedeTerm {
+		t.Fatalf("Listener was told about %d publications", len(published))
+	}
+	if fmt.Sprintf("0x%x", published[1].VerkleRoot) != rootV2 || published[1].Reason != "Revoked 1 credential" || published[1].Result.BlockNumber == 0 {
+		t.Fatalf("Unexpected supersession publication: %+v", published[1])
+	}
+
+	t.Logf("✅ Term %s published and superseded on %s", termID, integration.ContractAddress().Hex())
+}
+
+// TestSimulatedNetworkSelection checks that NETWORK=simulated needs no RPC or contract address
+// and that integrations in one process share the same chain
+func TestSimulatedNetworkSelection(t *testing.T) {
+	first, err := NewBlockchainIntegration(SimulatedNetwork, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create simulated integration: %v", err)
+	}
+	ctx := context.Background()
+	root := testRoot("shared")
+	if _, err := first.PublishTermRoot(ctx, root, "Semester_2_2024", big.NewInt(3)); err != nil {
+		t.Fatalf("Failed to publish term root: %v", err)
+	}
+	first.Close()
+
+	second, err := NewBlockchainIntegration(SimulatedNetwork, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create second simulated integration: %v", err)
+	}
+	defer second.Close()
+	if second.ContractAddress() != first.ContractAddress() {
+		t.Fatalf("Integrations use different registries: %s and %s", first.ContractAddress().Hex(), second.ContractAddress().Hex())
+	}
+	valid, _, _, err := second.VerifyReceiptAnchor(ctx, root)
+	if err != nil || !valid {
+		t.Fatalf("Root published by the first integration not visible to the second: %v %v", valid, err)
+	}
+
+	other, _ := GeneratePrivateKeyHex()
+	if _, err := NewBlockchainIntegration(SimulatedNetwork, other, ""); err == nil {
+		t.Fatalf("Shared chain should refuse a different issuer key")
+	}
+	t.Logf("✅ Shared simulated chain at %s", second.ContractAddress().Hex())
+}
+
+// TestFetchRegistryEvents checks that the indexer sees a publish and a supersede in order,
+// with the indexed term ID resolved
+func TestFetchRegistryEvents(t *testing.T) {
+	integration := newTestIntegration(t)
+	ctx := context.Background()
+	termID := fmt.Sprintf("Semester_2_2024_%d", time.Now().UnixNano())
+
+	start, err := integration.LatestBlockNumber(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read chain head: %v", err)
+	}
+
+	rootV1, rootV2 := testRoot("events-v1"), testRoot("events-v2")
+	if _, err := integration.PublishTermRoot(ctx, rootV1, termID, big.NewInt(4)); err != nil {
+		t.Fatalf("Failed to publish term root: %v", err)
+	}
+	superseded, err := integration.SupersedeTerm(ctx, termID, rootV2, big.NewInt(4), "Revoked 1 credential")
+	if err != nil {
+		t.Fatalf("Failed to supersede term: %v", err)
+	}
+
+	head, err := integration.LatestBlockNumber(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read chain head: %v", err)
+	}
+	events, err := integration.FetchRegistryEvents(ctx, start+1, head)
+	if err != nil {
+		t.Fatalf("Failed to fetch registry events: %v", err)
+	}
+
+	var kinds []string
+	for _, event := range events {
+		if event.TermID != termID {
+			continue
+		}
+		kinds = append(kinds, event.Kind)
+	}
+	// supersedeTerm emits TermRootSuperseded before the new version's TermRootPublished
+	expected := []string{EventTermRootPublished, EventTermRootSuperseded, EventTermRootPublished}
+	if fmt.Sprint(kinds) != fmt.Sprint(expected) {
+		t.Fatalf("Unexpected events for %s: %v, expected %v", termID, kinds, expected)
+	}
+
+	supersede := events[len(events)-2]
+	if fmt.Sprintf("0x%x", supersede.OldRoot) != rootV1 || fmt.Sprintf("0x%x", supersede.Root) != rootV2 ||
+		supersede.OldVersion != 1 || supersede.Version != 2 || supersede.Reason != "Revoked 1 credential" {
+		t.Fatalf("Unexpected supersede event: %+v", supersede)
+	}
+	if supersede.TxHash.Hex() != superseded.TransactionHash || supersede.BlockNumber != superseded.BlockNumber {
+		t.Fatalf("Supersede event not linked to its transaction: %s in block %d", supersede.TxHash.Hex(), supersede.BlockNumber)
+	}
+
+	receipt, err := integration.TransactionReceipt(ctx, supersede.TxHash)
+	if err != nil || receipt.GasUsed != superseded.GasUsed {
+		t.Fatalf("Receipt gas %v does not match supersede result %d: %v", receipt, superseded.GasUsed, err)
+	}
+	t.Logf("✅ Fetched %d registry events between blocks %d and %d", len(events), start+1, head)
+}
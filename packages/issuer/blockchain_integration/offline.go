@@ -0,0 +1,342 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// OfflineTxVersion is the format version of offline transaction files
+const OfflineTxVersion = 1
+
+// Registry methods that can be prepared for offline signing
+const (
+	MethodPublishTermRoot = "publishTermRoot"
+	MethodSupersedeTerm   = "supersedeTerm"
+)
+
+// ErrNotSigned is returned when an offline transaction is broadcast before it was signed
+var ErrNotSigned = errors.New("offline transaction is not signed")
+
+// OfflineTx is a registry transaction prepared on an online machine, signed on an offline
+// one and broadcast from the online machine again. The descriptive fields are checked
+// against the calldata before the transaction is signed or broadcast, so what the signer
+// reviews is what gets signed.
+type OfflineTx struct {
+	Version              int            `json:"version"`
+	Network              string         `json:"network"`
+	ChainID              uint64         `json:"chain_id"`
+	From                 common.Address `json:"from"`
+	To                   common.Address `json:"to"` // Registry contract
+	Nonce                uint64         `json:"nonce"`
+	GasLimit             uint64         `json:"gas_limit"`
+	MaxFeePerGas         *big.Int       `json:"max_fee_per_gas"`                    // Gas price on legacy chains
+	MaxPriorityFeePerGas *big.Int       `json:"max_priority_fee_per_gas,omitempty"` // nil on legacy chains
+	MaxCost              *big.Int       `json:"max_cost"`                           // Gas limit at the max fee, in wei
+	Data                 hexutil.Bytes  `json:"data"`
+
+	// The registry call encoded in Data
+	Method        string      `json:"method"`
+	TermID        string      `json:"term_id"`
+	VerkleRoot    common.Hash `json:"verkle_root"`
+	TotalStudents uint64      `json:"total_students"`
+	Reason        string      `json:"reason,omitempty"`
+
+	PreparedAt time.Time `json:"prepared_at"`
+	// Bookkeeping is opaque to this package; the command that prepared the transaction
+	// uses it to update its records once the transaction is confirmed
+	Bookkeeping json.RawMessage `json:"bookkeeping,omitempty"`
+
+	// Set by Sign
+	SignedTx hexutil.Bytes `json:"signed_tx,omitempty"`
+	TxHash   *common.Hash  `json:"tx_hash,omitempty"`
+}
+
+// RegistryCall is a registry call decoded from calldata
+type RegistryCall struct {
+	Method        string
+	TermID        string
+	VerkleRoot    common.Hash
+	TotalStudents *big.Int
+	Reason        string
+}
+
+// PendingNonce returns the next nonce of the issuer account, counting pending transactions
+func (bi *BlockchainIntegration) PendingNonce(ctx context.Context) (uint64, error) {
+	nonce, err := bi.client.GetClient().PendingNonceAt(ctx, bi.client.TxManager().From())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// PreparePublishTermRoot prepares publishing a term root for offline signing, with nonce
+func (bi *BlockchainIntegration) PreparePublishTermRoot(ctx context.Context, network string, nonce uint64, verkleRootHex, termID string, totalStudents *big.Int) (*OfflineTx, error) {
+	verkleRoot, err := parseRootHex(verkleRootHex)
+	if err != nil {
+		return nil, err
+	}
+	return bi.prepare(ctx, network, nonce, bi.publishTx(verkleRoot, termID, totalStudents))
+}
+
+// PrepareSupersedeTerm prepares superseding a term for offline signing, with nonce
+func (bi *BlockchainIntegration) PrepareSupersedeTerm(ctx context.Context, network string, nonce uint64, termID string, newVerkleRootHex string, totalStudents *big.Int, reason string) (*OfflineTx, error) {
+	newVerkleRoot, err := parseRootHex(newVerkleRootHex)
+	if err != nil {
+		return nil, err
+	}
+	return bi.prepare(ctx, network, nonce, bi.supersedeTx(termID, newVerkleRoot, totalStudents, reason))
+}
+
+func (bi *BlockchainIntegration) prepare(ctx context.Context, network string, nonce uint64, build func(opts *bind.TransactOpts) (*types.Transaction, error)) (*OfflineTx, error) {
+	tm := bi.client.TxManager()
+	tx, estimate, err := tm.Prepare(ctx, nonce, build)
+	if err != nil {
+		return nil, err
+	}
+	call, err := DecodeRegistryCall(tx.Data())
+	if err != nil {
+		return nil, err
+	}
+
+	otx := &OfflineTx{
+		Version:       OfflineTxVersion,
+		Network:       network,
+		ChainID:       tm.chainID.Uint64(),
+		From:          tm.From(),
+		To:            bi.contractAddress,
+		Nonce:         tx.Nonce(),
+		GasLimit:      tx.Gas(),
+		MaxFeePerGas:  tx.GasFeeCap(),
+		MaxCost:       estimate.MaxCost,
+		Data:          tx.Data(),
+		Method:        call.Method,
+		TermID:        call.TermID,
+		VerkleRoot:    call.VerkleRoot,
+		TotalStudents: call.TotalStudents.Uint64(),
+		Reason:        call.Reason,
+		PreparedAt:    time.Now(),
+	}
+	if tx.Type() != types.LegacyTxType {
+		otx.MaxPriorityFeePerGas = tx.GasTipCap()
+	}
+	return otx, nil
+}
+
+// BroadcastOfflineTx sends a transaction signed offline and waits until it is confirmed.
+// The transaction must be for the chain and registry of the integration.
+func (bi *BlockchainIntegration) BroadcastOfflineTx(ctx context.Context, otx *OfflineTx) (*PublishResult, error) {
+	signed, err := otx.SignedTransaction()
+	if err != nil {
+		return nil, err
+	}
+	tm := bi.client.TxManager()
+	if otx.ChainID != tm.chainID.Uint64() {
+		return nil, fmt.Errorf("%w: transaction is for chain %d, connected to chain %s", ErrChainIDMismatch, otx.ChainID, tm.chainID)
+	}
+	if otx.To != bi.contractAddress {
+		return nil, fmt.Errorf("transaction is for registry %s, configured registry is %s", otx.To.Hex(), bi.contractAddress.Hex())
+	}
+
+	receipt, err := tm.SendSigned(ctx, TxMeta{TermID: otx.TermID, VerkleRoot: otx.VerkleRoot}, signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast %s: %w", otx.Method, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction failed with status %d", receipt.Status)
+	}
+
+	result := &PublishResult{
+		TransactionHash: signed.Hash().Hex(),
+		BlockNumber:     receipt.BlockNumber.Uint64(),
+		GasUsed:         receipt.GasUsed,
+		Status:          "success",
+		PublishedAt:     time.Now(),
+	}
+	bi.published(&Publication{
+		Method:        otx.Method,
+		TermID:        otx.TermID,
+		VerkleRoot:    otx.VerkleRoot,
+		TotalStudents: new(big.Int).SetUint64(otx.TotalStudents),
+		Reason:        otx.Reason,
+		Result:        result,
+	})
+	return result, nil
+}
+
+// DecodeRegistryCall decodes publishTermRoot and supersedeTerm calldata
+func DecodeRegistryCall(data []byte) (*RegistryCall, error) {
+	if len(data) < 4 {
+		return nil, errors.New("calldata is too short")
+	}
+	parsed, err := IUMiCertRegistryMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := parsed.MethodById(data[:4])
+	if err != nil {
+		return nil, fmt.Errorf("unknown registry method: %w", err)
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s calldata: %w", method.Name, err)
+	}
+
+	switch method.Name {
+	case MethodPublishTermRoot:
+		return &RegistryCall{
+			Method:        method.Name,
+			VerkleRoot:    args[0].([32]byte),
+			TermID:        args[1].(string),
+			TotalStudents: args[2].(*big.Int),
+		}, nil
+	case MethodSupersedeTerm:
+		return &RegistryCall{
+			Method:        method.Name,
+			TermID:        args[0].(string),
+			VerkleRoot:    args[1].([32]byte),
+			TotalStudents: args[2].(*big.Int),
+			Reason:        args[3].(string),
+		}, nil
+	default:
+		return nil, fmt.Errorf("registry method %s cannot be signed offline", method.Name)
+	}
+}
+
+// ReadOfflineTx reads an offline transaction file
+func ReadOfflineTx(path string) (*OfflineTx, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+	var otx OfflineTx
+	if err := json.Unmarshal(data, &otx); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction file %s: %w", path, err)
+	}
+	return &otx, nil
+}
+
+// WriteOfflineTx writes an offline transaction file
+func WriteOfflineTx(path string, otx *OfflineTx) error {
+	data, err := json.MarshalIndent(otx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write transaction file: %w", err)
+	}
+	return nil
+}
+
+// Verify checks that the file is complete and that its calldata is the registry call it
+// describes
+func (otx *OfflineTx) Verify() error {
+	if otx.Version != OfflineTxVersion {
+		return fmt.Errorf("unsupported transaction file version %d", otx.Version)
+	}
+	if otx.ChainID == 0 || otx.GasLimit == 0 || otx.MaxFeePerGas == nil || otx.To == (common.Address{}) {
+		return errors.New("transaction file is incomplete")
+	}
+	call, err := DecodeRegistryCall(otx.Data)
+	if err != nil {
+		return err
+	}
+	if call.Method != otx.Method || call.TermID != otx.TermID || call.VerkleRoot != otx.VerkleRoot ||
+		!call.TotalStudents.IsUint64() || call.TotalStudents.Uint64() != otx.TotalStudents || call.Reason != otx.Reason {
+		return fmt.Errorf("calldata is %s(%s, %s) but the file describes %s(%s, %s)",
+			call.Method, call.TermID, call.VerkleRoot.Hex(), otx.Method, otx.TermID, otx.VerkleRoot.Hex())
+	}
+	return nil
+}
+
+// Transaction returns the unsigned transaction described by the file
+func (otx *OfflineTx) Transaction() *types.Transaction {
+	to := otx.To
+	if otx.MaxPriorityFeePerGas == nil {
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    otx.Nonce,
+			GasPrice: otx.MaxFeePerGas,
+			Gas:      otx.GasLimit,
+			To:       &to,
+			Value:    big.NewInt(0),
+			Data:     otx.Data,
+		})
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   new(big.Int).SetUint64(otx.ChainID),
+		Nonce:     otx.Nonce,
+		GasTipCap: otx.MaxPriorityFeePerGas,
+		GasFeeCap: otx.MaxFeePerGas,
+		Gas:       otx.GasLimit,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      otx.Data,
+	})
+}
+
+// Sign verifies the file and signs its transaction with signer, which must hold the key
+// of From. It needs no network access.
+func (otx *OfflineTx) Sign(ctx context.Context, signer Signer) error {
+	if err := otx.Verify(); err != nil {
+		return err
+	}
+	if signer.Address() != otx.From {
+		return fmt.Errorf("transaction is from %s but the signer holds %s", otx.From.Hex(), signer.Address().Hex())
+	}
+
+	signed, err := signer.SignTx(ctx, otx.Transaction(), new(big.Int).SetUint64(otx.ChainID))
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode signed transaction: %w", err)
+	}
+	hash := signed.Hash()
+	otx.SignedTx = raw
+	otx.TxHash = &hash
+	return nil
+}
+
+// SignedTransaction decodes the signed transaction and checks that it is the transaction
+// of the file, signed by From for its chain
+func (otx *OfflineTx) SignedTransaction() (*types.Transaction, error) {
+	if len(otx.SignedTx) == 0 {
+		return nil, ErrNotSigned
+	}
+	if err := otx.Verify(); err != nil {
+		return nil, err
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(otx.SignedTx); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+	chainID := new(big.Int).SetUint64(otx.ChainID)
+	if signed.ChainId().Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("transaction is signed for chain %s, expected %d", signed.ChainId(), otx.ChainID)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if sender != otx.From {
+		return nil, fmt.Errorf("transaction is signed by %s, expected %s", sender.Hex(), otx.From.Hex())
+	}
+	if !sameTransaction(signed, otx.Transaction()) {
+		return nil, errors.New("signed transaction differs from the prepared one")
+	}
+	if otx.TxHash != nil && *otx.TxHash != signed.Hash() {
+		return nil, fmt.Errorf("signed transaction hash is %s, file records %s", signed.Hash().Hex(), otx.TxHash.Hex())
+	}
+	return signed, nil
+}
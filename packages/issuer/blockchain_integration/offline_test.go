@@ -0,0 +1,116 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestOfflineSigning runs prepare → sign → broadcast for a publication and a supersession,
+// with the online machine holding only the issuer address
+func TestOfflineSigning(t *testing.T) {
+	chain, err := NewSimulatedChain("", 500000)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	defer chain.Close()
+
+	client, err := NewBlockchainClientWithBackend(chain.Backend(), NewOfflineSigner(chain.Owner), chain.ChainID(), 500000)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	online, err := NewBlockchainIntegrationWithBackend(client, chain.Registry)
+	if err != nil {
+		t.Fatalf("Failed to create integration: %v", err)
+	}
+	ctx := context.Background()
+	termID := "Semester_1_2024_offline"
+	root := crypto.Keccak256Hash([]byte("offline-v1"))
+
+	if _, err := online.PublishTermRoot(ctx, root.Hex(), termID, big.NewInt(5)); !errors.Is(err, ErrOfflineSigner) {
+		t.Fatalf("Online machine signed without the key: %v", err)
+	}
+
+	nonce, err := online.PendingNonce(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get nonce: %v", err)
+	}
+	unsigned, err := online.PreparePublishTermRoot(ctx, SimulatedNetwork, nonce, root.Hex(), termID, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("Failed to prepare publication: %v", err)
+	}
+	if _, err := online.BroadcastOfflineTx(ctx, unsigned); !errors.Is(err, ErrNotSigned) {
+		t.Fatalf("Unsigned transaction was broadcast: %v", err)
+	}
+
+	// The file travels to the offline machine and back
+	path := filepath.Join(t.TempDir(), "publish.json")
+	if err := WriteOfflineTx(path, unsigned); err != nil {
+		t.Fatal(err)
+	}
+	offline, err := ReadOfflineTx(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, _ := crypto.GenerateKey()
+	if err := offline.Sign(ctx, NewKeySigner(otherKey)); err == nil {
+		t.Fatal("Transaction signed with another account's key")
+	}
+	offline.TermID = "Semester_2_2024_offline"
+	if err := offline.Sign(ctx, NewKeySigner(chain.ownerKey)); err == nil {
+		t.Fatal("Transaction signed although the file misdescribes its calldata")
+	}
+	offline.TermID = termID
+	if err := offline.Sign(ctx, NewKeySigner(chain.ownerKey)); err != nil {
+		t.Fatalf("Failed to sign offline: %v", err)
+	}
+
+	tampered := *offline
+	tampered.MaxFeePerGas = new(big.Int).Add(offline.MaxFeePerGas, big.NewInt(1))
+	if _, err := online.BroadcastOfflineTx(ctx, &tampered); err == nil {
+		t.Fatal("Signed transaction with edited fees was broadcast")
+	}
+
+	result, err := online.BroadcastOfflineTx(ctx, offline)
+	if err != nil {
+		t.Fatalf("Failed to broadcast: %v", err)
+	}
+	if common.HexToHash(result.TransactionHash) != *offline.TxHash {
+		t.Fatalf("Broadcast %s, signed %s", result.TransactionHash, offline.TxHash.Hex())
+	}
+	// A repeated broadcast finds the transaction mined and does not send it again
+	if _, err := online.BroadcastOfflineTx(ctx, offline); err != nil {
+		t.Fatalf("Repeated broadcast failed: %v", err)
+	}
+
+	newRoot := crypto.Keccak256Hash([]byte("offline-v2"))
+	nonce, _ = online.PendingNonce(ctx)
+	supersede, err := online.PrepareSupersedeTerm(ctx, SimulatedNetwork, nonce, termID, newRoot.Hex(), big.NewInt(5), "Revoked 1 credential")
+	if err != nil {
+		t.Fatalf("Failed to prepare supersession: %v", err)
+	}
+	if supersede.Method != MethodSupersedeTerm || supersede.Reason != "Revoked 1 credential" {
+		t.Fatalf("Prepared %s(%q)", supersede.Method, supersede.Reason)
+	}
+	if err := supersede.Sign(ctx, NewKeySigner(chain.ownerKey)); err != nil {
+		t.Fatalf("Failed to sign supersession: %v", err)
+	}
+	if _, err := online.BroadcastOfflineTx(ctx, supersede); err != nil {
+		t.Fatalf("Failed to broadcast supersession: %v", err)
+	}
+
+	latest, err := online.GetLatestRootForTerm(ctx, termID)
+	if err != nil {
+		t.Fatalf("Failed to get latest root: %v", err)
+	}
+	if latest.RootHash != newRoot || latest.Version.Int64() != 2 {
+		t.Fatalf("Latest root is v%s %x, expected v2 %x", latest.Version, latest.RootHash, newRoot)
+	}
+	t.Logf("✅ Published and superseded %s with offline signatures", termID)
+}
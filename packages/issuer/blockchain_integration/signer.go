@@ -0,0 +1,319 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"golang.org/x/term"
+
+	"iumicert/issuer/config"
+)
+
+// Signer signs the transactions of the issuer account. The key may be held in memory,
+// unlocked from an encrypted keystore or kept by an external signer.
+type Signer interface {
+	// Address returns the account the signer signs for
+	Address() common.Address
+	// SignTx returns tx signed for chainID
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// KeySigner signs with a raw private key held in memory. It is meant for development
+// chains; production deployments use a keystore or an external signer.
+type KeySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner creates a signer for key
+func NewKeySigner(key *ecdsa.PrivateKey) *KeySigner {
+	return &KeySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// NewKeySignerFromHex creates a signer for a hex-encoded private key, with or without 0x
+func NewKeySignerFromHex(privateKeyHex string) (*KeySigner, error) {
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return NewKeySigner(key), nil
+}
+
+// Address returns the account of the key
+func (s *KeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs tx with the key
+func (s *KeySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// KeystoreSigner signs with a key decrypted from a go-ethereum encrypted JSON keystore
+// file. The key is decrypted once, when the signer is created.
+type KeystoreSigner struct {
+	path string
+	key  *keystore.Key
+}
+
+// NewKeystoreSigner decrypts the keystore file at path with passphrase
+func NewKeystoreSigner(path, passphrase string) (*KeystoreSigner, error) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore %s: %w", path, err)
+	}
+	return &KeystoreSigner{path: path, key: key}, nil
+}
+
+// Address returns the account of the keystore
+func (s *KeystoreSigner) Address() common.Address {
+	return s.key.Address
+}
+
+// SignTx signs tx with the decrypted key
+func (s *KeystoreSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key.PrivateKey)
+}
+
+// KeystorePassphrase reads the keystore passphrase from passwordFile, or prompts for it on
+// the terminal when no file is given. Without a file or a terminal it fails, so a server
+// never blocks waiting for input.
+func KeystorePassphrase(path, passwordFile string) (string, error) {
+	if passwordFile != "" {
+		data, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read keystore password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("no keystore password file set and stdin is not a terminal; set KEYSTORE_PASSWORD_FILE")
+	}
+	fmt.Fprintf(os.Stderr, "🔑 Passphrase for %s: ", path)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
+
+// RemoteSigner asks an external signer such as Clef to sign over JSON-RPC, with
+// account_signTransaction. The key never leaves the signer.
+type RemoteSigner struct {
+	endpoint string
+	client   *rpc.Client
+	address  common.Address
+}
+
+// signTransactionResult is the response of account_signTransaction
+type signTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// NewRemoteSigner connects to the external signer at endpoint, which signs for address
+func NewRemoteSigner(ctx context.Context, endpoint string, address common.Address) (*RemoteSigner, error) {
+	client, err := rpc.DialContext(ctx, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote signer: %w", err)
+	}
+	return &RemoteSigner{endpoint: endpoint, client: client, address: address}, nil
+}
+
+// Address returns the account the remote signer signs for
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx sends tx to the external signer. The signed transaction it returns must be tx,
+// signed by the expected account.
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	input := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Input:   &input,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+	if tx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	}
+
+	var result signTransactionResult
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("remote signer refused transaction: %w", err)
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(result.Raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid transaction: %w", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned an invalid signature: %w", err)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("remote signer signed as %s, expected %s", sender.Hex(), s.address.Hex())
+	}
+	if !sameTransaction(signed, tx) {
+		return nil, errors.New("remote signer returned a different transaction than requested")
+	}
+	return signed, nil
+}
+
+// Close closes the connection to the signer
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
+
+// OfflineSigner stands for an issuer account whose key is kept on an offline machine. It
+// lets the online machine estimate and prepare transactions for the account, but signs
+// nothing; the prepared transactions are signed with sign-tx.
+type OfflineSigner struct {
+	address common.Address
+}
+
+// ErrOfflineSigner is returned when an online machine is asked to sign for an offline key
+var ErrOfflineSigner = errors.New("the issuer key is kept offline; prepare an unsigned transaction and sign it with sign-tx")
+
+// NewOfflineSigner creates a signer for the offline account address
+func NewOfflineSigner(address common.Address) *OfflineSigner {
+	return &OfflineSigner{address: address}
+}
+
+// Address returns the offline account
+func (s *OfflineSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx always fails with ErrOfflineSigner
+func (s *OfflineSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, ErrOfflineSigner
+}
+
+// ErrReadOnlyClient is returned when a read-only client is asked to sign a transaction
+var ErrReadOnlyClient = errors.New("blockchain client is read-only and cannot sign transactions")
+
+// readOnlySigner stands in for the signer of read-only clients and signs nothing
+type readOnlySigner struct{}
+
+func (readOnlySigner) Address() common.Address {
+	return common.Address{}
+}
+
+func (readOnlySigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, ErrReadOnlyClient
+}
+
+// sameTransaction reports whether signed carries the same transaction as tx, ignoring
+// the signature
+func sameTransaction(signed, tx *types.Transaction) bool {
+	return signed.Type() == tx.Type() && signed.Nonce() == tx.Nonce() && signed.Gas() == tx.Gas() &&
+		signed.GasFeeCap().Cmp(tx.GasFeeCap()) == 0 && signed.GasTipCap().Cmp(tx.GasTipCap()) == 0 &&
+		signed.Value().Cmp(tx.Value()) == 0 && equalTo(signed.To(), tx.To()) && string(signed.Data()) == string(tx.Data())
+}
+
+func equalTo(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+var (
+	configuredSignerMu sync.Mutex
+	configuredSigners  = make(map[string]Signer)
+)
+
+// ConfiguredSigner returns the signer selected by SIGNER. Keystore and remote signers are
+// created once per process, so the passphrase is asked for only once; privateKeyHex is
+// only used by the raw key signer, which production mode refuses.
+func ConfiguredSigner(ctx context.Context, cfg *config.Config, privateKeyHex string) (Signer, error) {
+	switch cfg.SignerType {
+	case config.SignerKey, "":
+		if cfg.IsProduction() {
+			return nil, errors.New("raw private keys are refused in production; set SIGNER=keystore or SIGNER=remote")
+		}
+		if privateKeyHex == "" {
+			return nil, errors.New("private key is required. Set ISSUER_PRIVATE_KEY environment variable")
+		}
+		return NewKeySignerFromHex(privateKeyHex)
+
+	case config.SignerKeystore:
+		if cfg.KeystorePath == "" {
+			return nil, errors.New("KEYSTORE_PATH is required with SIGNER=keystore")
+		}
+		return cachedSigner("keystore:"+cfg.KeystorePath, func() (Signer, error) {
+			passphrase, err := KeystorePassphrase(cfg.KeystorePath, cfg.KeystorePasswordFile)
+			if err != nil {
+				return nil, err
+			}
+			return NewKeystoreSigner(cfg.KeystorePath, passphrase)
+		})
+
+	case config.SignerRemote:
+		if cfg.RemoteSignerURL == "" || !common.IsHexAddress(cfg.SignerAddress) {
+			return nil, errors.New("REMOTE_SIGNER_URL and SIGNER_ADDRESS are required with SIGNER=remote")
+		}
+		address := common.HexToAddress(cfg.SignerAddress)
+		return cachedSigner("remote:"+cfg.RemoteSignerURL+":"+address.Hex(), func() (Signer, error) {
+			return NewRemoteSigner(ctx, cfg.RemoteSignerURL, address)
+		})
+
+	case config.SignerOffline:
+		if !common.IsHexAddress(cfg.SignerAddress) {
+			return nil, errors.New("SIGNER_ADDRESS is required with SIGNER=offline")
+		}
+		return NewOfflineSigner(common.HexToAddress(cfg.SignerAddress)), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported signer: %s", cfg.SignerType)
+	}
+}
+
+// cachedSigner returns the signer stored under key, creating it with create on first use
+func cachedSigner(key string, create func() (Signer, error)) (Signer, error) {
+	configuredSignerMu.Lock()
+	defer configuredSignerMu.Unlock()
+
+	if signer, ok := configuredSigners[key]; ok {
+		return signer, nil
+	}
+	signer, err := create()
+	if err != nil {
+		return nil, err
+	}
+	configuredSigners[key] = signer
+	return signer, nil
+}
@@ -0,0 +1,197 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+
+	"iumicert/issuer/config"
+)
+
+// stubExternalSigner answers account_signTransaction like Clef, with the key it holds
+type stubExternalSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+	tamper  bool // Sign a different nonce than requested
+}
+
+func (s *stubExternalSigner) SignTransaction(args apitypes.SendTxArgs) (*signTransactionResult, error) {
+	if args.From.Address() != crypto.PubkeyToAddress(s.key.PublicKey) {
+		return nil, fmt.Errorf("unknown account %s", args.From.Address().Hex())
+	}
+	if s.tamper {
+		args.Nonce++
+	}
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(s.chainID), s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTransactionResult{Raw: raw, Tx: signed}, nil
+}
+
+func startStubExternalSigner(t *testing.T, stub *stubExternalSigner) string {
+	t.Helper()
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", stub); err != nil {
+		t.Fatalf("Failed to register stub signer: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL
+}
+
+// TestRemoteSignerPublishes checks that a term root published through the transaction
+// manager is signed by the external signer
+func TestRemoteSignerPublishes(t *testing.T) {
+	chain, err := NewSimulatedChain("", 500000)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	defer chain.Close()
+
+	endpoint := startStubExternalSigner(t, &stubExternalSigner{key: chain.ownerKey, chainID: chain.ChainID()})
+	signer, err := NewRemoteSigner(context.Background(), endpoint, chain.Owner)
+	if err != nil {
+		t.Fatalf("Failed to connect to remote signer: %v", err)
+	}
+	defer signer.Close()
+
+	client, err := NewBlockchainClientWithBackend(chain.Backend(), signer, chain.ChainID(), 500000)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	integration, err := NewBlockchainIntegrationWithBackend(client, chain.Registry)
+	if err != nil {
+		t.Fatalf("Failed to create integration: %v", err)
+	}
+
+	root := crypto.Keccak256Hash([]byte("remote")).Hex()
+	result, err := integration.PublishTermRoot(context.Background(), root, "Semester_1_2024_remote", big.NewInt(3))
+	if err != nil {
+		t.Fatalf("Failed to publish with remote signer: %v", err)
+	}
+
+	tx, _, err := chain.backend.Client().TransactionByHash(context.Background(), common.HexToHash(result.TransactionHash))
+	if err != nil {
+		t.Fatalf("Failed to read transaction: %v", err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chain.ChainID()), tx)
+	if err != nil || sender != chain.Owner {
+		t.Fatalf("Transaction signed by %s, expected %s: %v", sender.Hex(), chain.Owner.Hex(), err)
+	}
+	t.Logf("✅ Published %s in block %d, signed remotely by %s", result.TransactionHash, result.BlockNumber, sender.Hex())
+}
+
+// TestRemoteSignerRejectsTamperedTransaction checks that a signature over another
+// transaction than the one requested is refused
+func TestRemoteSignerRejectsTamperedTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	chainID := big.NewInt(1337)
+	endpoint := startStubExternalSigner(t, &stubExternalSigner{key: key, chainID: chainID, tamper: true})
+	signer, err := NewRemoteSigner(context.Background(), endpoint, crypto.PubkeyToAddress(key.PublicKey))
+	if err != nil {
+		t.Fatalf("Failed to connect to remote signer: %v", err)
+	}
+	defer signer.Close()
+
+	to := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 7, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &to, Value: big.NewInt(0)})
+	if _, err := signer.SignTx(context.Background(), tx, chainID); err == nil {
+		t.Fatal("Tampered transaction was accepted")
+	}
+	t.Logf("✅ Tampered signature refused")
+}
+
+// TestKeystoreSigner checks that a keystore file is decrypted with the passphrase from a
+// file and signs for its account
+func TestKeystoreSigner(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	keyJSON, err := keystore.EncryptKey(&keystore.Key{Id: uuid.New(), Address: address, PrivateKey: key}, "registrar", keystore.LightScryptN, keystore.LightScryptP)
+	if err != nil {
+		t.Fatalf("Failed to encrypt key: %v", err)
+	}
+	keyPath := filepath.Join(dir, "issuer.json")
+	passwordPath := filepath.Join(dir, "password")
+	if err := os.WriteFile(keyPath, keyJSON, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(passwordPath, []byte("registrar\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewKeystoreSigner(keyPath, "wrong"); err == nil {
+		t.Fatal("Keystore decrypted with a wrong passphrase")
+	}
+
+	cfg := &config.Config{SignerType: config.SignerKeystore, KeystorePath: keyPath, KeystorePasswordFile: passwordPath, Environment: "production"}
+	signer, err := ConfiguredSigner(context.Background(), cfg, "")
+	if err != nil {
+		t.Fatalf("Failed to create keystore signer: %v", err)
+	}
+	if signer.Address() != address {
+		t.Fatalf("Keystore signer is %s, expected %s", signer.Address().Hex(), address.Hex())
+	}
+
+	chainID := big.NewInt(11155111)
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 1, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000, To: &address, Value: big.NewInt(0)})
+	signed, err := signer.SignTx(context.Background(), tx, chainID)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+	if sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed); err != nil || sender != address {
+		t.Fatalf("Signed by %s, expected %s: %v", sender.Hex(), address.Hex(), err)
+	}
+	t.Logf("✅ Keystore signer signs for %s", address.Hex())
+}
+
+// TestProductionRefusesRawKeys checks that production mode refuses ISSUER_PRIVATE_KEY and
+// the localhost test key
+func TestProductionRefusesRawKeys(t *testing.T) {
+	cfg := &config.Config{
+		Environment:      "production",
+		Network:          "localhost",
+		SignerType:       config.SignerKey,
+		IssuerPrivateKey: "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+		TestPrivateKey:   "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
+	}
+	if _, err := ConfiguredSigner(context.Background(), cfg, cfg.GetPrivateKey()); err == nil {
+		t.Fatal("Raw key signer created in production")
+	}
+
+	cfg.IssuerPrivateKey = ""
+	if key := cfg.GetPrivateKey(); key != "" {
+		t.Fatal("Production fell back to the test key")
+	}
+
+	cfg.Environment = "development"
+	if _, err := ConfiguredSigner(context.Background(), cfg, cfg.GetPrivateKey()); err != nil {
+		t.Fatalf("Raw key refused in development: %v", err)
+	}
+	t.Logf("✅ Raw keys refused in production only")
+}
@@ -0,0 +1,168 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+
+	"iumicert/issuer/config"
+)
+
+// SimulatedNetwork selects an in-process simulated chain instead of an RPC endpoint
+const SimulatedNetwork = "simulated"
+
+// SimulatedChain is an in-process Ethereum chain with IUMiCertRegistry deployed from the
+// generated bindings. It lets publishing, superseding and the revocation pipeline run
+// without Anvil. Every transaction is mined in its own block as soon as it is sent.
+type SimulatedChain struct {
+	backend  *simulated.Backend
+	client   *autoMiningClient
+	ownerKey *ecdsa.PrivateKey
+	gasLimit uint64
+
+	// Owner is the issuer account; it is funded in genesis and owns the registry
+	Owner common.Address
+	// Registry is the address of the deployed IUMiCertRegistry
+	Registry common.Address
+}
+
+// autoMiningClient commits a block after every transaction so that bind.WaitMined returns
+// immediately. It deliberately has no Close method: closing an integration must not stop
+// a chain other integrations share.
+type autoMiningClient struct {
+	simulated.Client
+	backend *simulated.Backend
+	mu      sync.Mutex
+}
+
+func (c *autoMiningClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.backend.Commit()
+	return nil
+}
+
+// NewSimulatedChain starts a simulated chain, funds the issuer key and deploys the registry
+// owned by it. An empty key generates a fresh issuer account.
+func NewSimulatedChain(privateKeyHex string, gasLimit uint64) (*SimulatedChain, error) {
+	var ownerKey *ecdsa.PrivateKey
+	var err error
+	if privateKeyHex == "" {
+		ownerKey, err = crypto.GenerateKey()
+	} else {
+		ownerKey, err = crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	owner := crypto.PubkeyToAddress(ownerKey.PublicKey)
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	backend := simulated.NewBackend(types.GenesisAlloc{owner: {Balance: balance}})
+	client := &autoMiningClient{Client: backend.Client(), backend: backend}
+
+	chain := &SimulatedChain{
+		backend:  backend,
+		client:   client,
+		ownerKey: ownerKey,
+		gasLimit: gasLimit,
+		Owner:    owner,
+	}
+
+	auth, err := bind.NewKeyedTransactorWithChainID(ownerKey, chain.ChainID())
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("failed to create keyed transactor: %w", err)
+	}
+
+	// Deployment gas is estimated, the registry does not fit in the default publish gas limit
+	registry, tx, _, err := DeployIUMiCertRegistry(auth, client, owner)
+	if err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("failed to deploy registry: %w", err)
+	}
+	if _, err := bind.WaitDeployed(context.Background(), client, tx); err != nil {
+		backend.Close()
+		return nil, fmt.Errorf("failed to wait for registry deployment: %w", err)
+	}
+	chain.Registry = registry
+
+	return chain, nil
+}
+
+// ChainID returns the chain ID of the simulated chain
+func (sc *SimulatedChain) ChainID() *big.Int {
+	return new(big.Int).Set(params.AllDevChainProtocolChanges.ChainID)
+}
+
+// Backend returns the contract backend of the chain
+func (sc *SimulatedChain) Backend() bind.ContractBackend {
+	return sc.client
+}
+
+// Integration returns a BlockchainIntegration that publishes to the chain's registry as the owner
+func (sc *SimulatedChain) Integration() (*BlockchainIntegration, error) {
+	client, err := NewBlockchainClientWithBackend(sc.client, NewKeySigner(sc.ownerKey), sc.ChainID(), sc.gasLimit)
+	if err != nil {
+		return nil, err
+	}
+	return NewBlockchainIntegrationWithBackend(client, sc.Registry)
+}
+
+// Close stops the chain; its state is discarded
+func (sc *SimulatedChain) Close() error {
+	return sc.backend.Close()
+}
+
+var (
+	sharedChainMu  sync.Mutex
+	sharedChain    *SimulatedChain
+	sharedChainKey string
+)
+
+// SharedSimulatedChain returns the process-wide simulated chain used for NETWORK=simulated,
+// starting it on first use. The API server keeps publishing to the same chain for its whole
+// lifetime; a CLI command gets a fresh chain per run.
+func SharedSimulatedChain(privateKeyHex string) (*SimulatedChain, error) {
+	sharedChainMu.Lock()
+	defer sharedChainMu.Unlock()
+
+	privateKeyHex = strings.ToLower(strings.TrimPrefix(privateKeyHex, "0x"))
+	if sharedChain != nil {
+		if privateKeyHex != "" && privateKeyHex != sharedChainKey {
+			return nil, fmt.Errorf("simulated chain is already running for issuer %s", sharedChain.Owner.Hex())
+		}
+		return sharedChain, nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	chain, err := NewSimulatedChain(privateKeyHex, cfg.DefaultGasLimit)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("🧪 Started in-process simulated chain (chain ID %s), state is discarded on exit\n", chain.ChainID())
+	fmt.Printf("📜 IUMiCertRegistry deployed at %s, owner %s\n", chain.Registry.Hex(), chain.Owner.Hex())
+
+	sharedChain = chain
+	sharedChainKey = hex.EncodeToString(crypto.FromECDSA(chain.ownerKey))
+	return chain, nil
+}
@@ -0,0 +1,764 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// States of a transaction attempt, as stored in BlockchainTransaction.Status
+const (
+	TxStatusPending   = "pending"
+	TxStatusReplaced  = "replaced"
+	TxStatusMined     = "mined" // Included in a block, waiting for confirmations
+	TxStatusConfirmed = "confirmed"
+	TxStatusFailed    = "failed"
+	TxStatusReorged   = "reorged" // Its block left the canonical chain after confirmation
+)
+
+// TxMeta describes what a managed transaction publishes
+type TxMeta struct {
+	TermID     string
+	VerkleRoot [32]byte
+}
+
+// Fee strategy: the priority fee is the median tip paid in the last feeHistoryBlocks
+// blocks, and the gas limit leaves gasLimitMargin percent on top of the estimate
+const (
+	feeHistoryBlocks     = 10
+	feeHistoryPercentile = 50
+	gasLimitMargin       = 20
+)
+
+// ErrOverBudget is returned when a transaction could cost more than the configured budget
+var ErrOverBudget = errors.New("transaction cost exceeds budget")
+
+// FeeEstimate is the cost of a transaction worked out before it is sent. On chains without
+// EIP-1559, BaseFee and MaxPriorityFeePerGas are nil and MaxFeePerGas is the gas price.
+type FeeEstimate struct {
+	GasEstimate          uint64   // Gas the call uses with its real calldata
+	GasLimit             uint64   // GasEstimate plus margin, never above the configured gas limit
+	BaseFee              *big.Int // Base fee of the next block
+	MaxPriorityFeePerGas *big.Int
+	MaxFeePerGas         *big.Int
+	ExpectedCost         *big.Int // GasEstimate at the next base fee plus the priority fee
+	MaxCost              *big.Int // GasLimit at MaxFeePerGas, the most the transaction can cost
+	Budget               *big.Int // nil when no budget is configured
+}
+
+// WithinBudget reports whether the transaction cannot cost more than the budget
+func (e *FeeEstimate) WithinBudget() bool {
+	return e.Budget == nil || e.MaxCost.Cmp(e.Budget) <= 0
+}
+
+// TxAttempt is one broadcast of a managed transaction. A stuck transaction is broadcast
+// again with the same nonce and higher fees; each broadcast is its own attempt.
+type TxAttempt struct {
+	TxHash      string
+	From        string
+	Nonce       uint64
+	Attempt     int      // 0 for the first broadcast
+	GasPrice    *big.Int // Max fee per gas, or the gas price on legacy chains
+	GasTipCap   *big.Int // Max priority fee per gas, nil on legacy chains
+	TermID      string
+	VerkleRoot  [32]byte
+	Status      string
+	ReplacedBy  string // Hash of the attempt that replaced this one
+	BlockNumber uint64
+	GasUsed     uint64
+	SubmittedAt time.Time
+	MinedAt     *time.Time
+}
+
+// TxStore persists transaction attempts. SaveTxAttempt is called on every state change
+// of an attempt and must upsert by TxHash.
+type TxStore interface {
+	SaveTxAttempt(attempt *TxAttempt) error
+}
+
+// TxManager sends transactions for one issuer account. Gas is estimated for every
+// transaction and fees follow EIP-1559, priced from recent blocks. Nonces are handed out
+// locally so concurrent sends never collide, and a transaction that is not mined within
+// StuckAfter is replaced with bumped fees, never above MaxGasPrice or the budget. A send
+// completes once the transaction is Confirmations blocks deep.
+type TxManager struct {
+	backend  bind.ContractBackend
+	receipts bind.DeployBackend
+	signer   Signer
+	from     common.Address
+	chainID  *big.Int
+	gasLimit uint64
+	nonces   *nonceTracker
+	store    TxStore
+
+	// MaxGasPrice caps the max fee per gas (gas price on legacy chains) of every attempt,
+	// nil means no cap
+	MaxGasPrice *big.Int
+	// Budget caps the cost of a transaction in wei, gas limit times max fee per gas; nil
+	// means no budget
+	Budget *big.Int
+	// StuckAfter is how long an attempt may stay unmined before it is replaced
+	StuckAfter time.Duration
+	// PollInterval is how often receipts are polled
+	PollInterval time.Duration
+	// ConfirmTimeout bounds Send when the context has no deadline
+	ConfirmTimeout time.Duration
+	// Confirmations is the number of blocks, counting the one that includes a transaction,
+	// before Send returns; 0 or 1 returns as soon as it is mined
+	Confirmations uint64
+}
+
+// NewTxManager creates a transaction manager for the account of signer
+func NewTxManager(backend bind.ContractBackend, receipts bind.DeployBackend, signer Signer, chainID *big.Int, gasLimit uint64) *TxManager {
+	from := signer.Address()
+	return &TxManager{
+		backend:        backend,
+		receipts:       receipts,
+		signer:         signer,
+		from:           from,
+		chainID:        chainID,
+		gasLimit:       gasLimit,
+		nonces:         sharedNonceTracker(chainID, from),
+		StuckAfter:     2 * time.Minute,
+		PollInterval:   2 * time.Second,
+		ConfirmTimeout: 10 * time.Minute,
+	}
+}
+
+// SetStore sets where attempts are persisted; without a store attempts are only logged
+func (tm *TxManager) SetStore(store TxStore) {
+	tm.store = store
+}
+
+// From returns the sending account
+func (tm *TxManager) From() common.Address {
+	return tm.from
+}
+
+// Send builds and broadcasts a transaction with build, then waits until one of its
+// attempts is mined and confirmed. If the block of the attempt is reorged out before
+// then, Send waits for it to be mined again. The returned transaction is the attempt that
+// was mined; callers must check the receipt status.
+func (tm *TxManager) Send(ctx context.Context, meta TxMeta, build func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *types.Receipt, error) {
+	if _, ok := ctx.Deadline(); !ok && tm.ConfirmTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tm.ConfirmTimeout)
+		defer cancel()
+	}
+
+	estimate, err := tm.Estimate(ctx, build)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !estimate.WithinBudget() {
+		return nil, nil, fmt.Errorf("%w: up to %s wei, budget %s wei", ErrOverBudget, estimate.MaxCost, estimate.Budget)
+	}
+	log.Printf("💰 Gas %d (limit %d), max fee %s wei/gas, up to %s wei", estimate.GasEstimate, estimate.GasLimit, estimate.MaxFeePerGas, estimate.MaxCost)
+
+	// A nonce that is too low means another sender used this account; resync and retry
+	var tx *types.Transaction
+	var nonce uint64
+	for try := 0; ; try++ {
+		nonce, err = tm.nonces.acquire(ctx, tm.backend, tm.from)
+		if err != nil {
+			return nil, nil, err
+		}
+		tx, err = tm.broadcast(ctx, build, tm.transactOpts(ctx, nonce, estimate.MaxPriorityFeePerGas, estimate.MaxFeePerGas, estimate.GasLimit))
+		if err == nil {
+			break
+		}
+		tm.nonces.release(nonce)
+		if isNonceTooLow(err) && try < 2 {
+			tm.nonces.reset()
+			continue
+		}
+		return nil, nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	defer tm.nonces.done(nonce)
+
+	attempts := []*TxAttempt{tm.newAttempt(tx, meta, nonce, 0)}
+	txs := []*types.Transaction{tx}
+	tm.save(attempts[0])
+	lastBroadcast := time.Now()
+	feeCapped := false
+
+	// The attempt that is mined and its receipt, until it is confirmed or reorged out
+	var mined *types.Receipt
+	minedIndex := -1
+
+	ticker := time.NewTicker(tm.PollInterval)
+	defer ticker.Stop()
+	for {
+		if mined == nil {
+			// Any attempt may be the one that gets mined
+			for i := len(txs) - 1; i >= 0; i-- {
+				receipt, err := tm.receipts.TransactionReceipt(ctx, txs[i].Hash())
+				if err == nil && receipt != nil {
+					mined, minedIndex = receipt, i
+					tm.markMined(attempts[i], receipt)
+					break
+				}
+				if err != nil && !errors.Is(err, ethereum.NotFound) {
+					log.Printf("⚠️  Failed to get receipt of %s: %v", txs[i].Hash().Hex(), err)
+				}
+			}
+		} else {
+			receipt, err := tm.receipts.TransactionReceipt(ctx, txs[minedIndex].Hash())
+			switch {
+			case err == nil && receipt != nil && receipt.BlockHash != mined.BlockHash:
+				log.Printf("🔀 Transaction %s moved from block %d to block %d", txs[minedIndex].Hash().Hex(), mined.BlockNumber, receipt.BlockNumber)
+				mined = receipt
+				tm.markMined(attempts[minedIndex], receipt)
+			case err != nil && errors.Is(err, ethereum.NotFound):
+				log.Printf("⚠️  Block %d of transaction %s was reorged out, waiting for it to be mined again", mined.BlockNumber, txs[minedIndex].Hash().Hex())
+				attempt := attempts[minedIndex]
+				attempt.Status = TxStatusPending
+				attempt.BlockNumber = 0
+				attempt.GasUsed = 0
+				attempt.MinedAt = nil
+				tm.save(attempt)
+				mined, minedIndex = nil, -1
+				lastBroadcast = time.Now()
+			case err != nil:
+				log.Printf("⚠️  Failed to get receipt of %s: %v", txs[minedIndex].Hash().Hex(), err)
+			}
+		}
+
+		if mined != nil {
+			depth, err := tm.depth(ctx, mined)
+			if err != nil {
+				log.Printf("⚠️  Failed to count confirmations of %s: %v", txs[minedIndex].Hash().Hex(), err)
+			} else if depth >= tm.Confirmations {
+				tm.settle(attempts, minedIndex, mined)
+				return txs[minedIndex], mined, nil
+			}
+		}
+
+		if mined == nil && !feeCapped && time.Since(lastBroadcast) >= tm.StuckAfter {
+			current := txs[len(txs)-1]
+			replacement, err := tm.replace(ctx, build, current, nonce)
+			switch {
+			case err != nil:
+				log.Printf("⚠️  Failed to replace stuck transaction %s: %v", current.Hash().Hex(), err)
+			case replacement == nil:
+				log.Printf("⚠️  Transaction %s cannot be repriced further (fee cap %v), waiting for it to be mined", current.Hash().Hex(), tm.feeCeiling(current.Gas()))
+				feeCapped = true
+			default:
+				log.Printf("🔁 Replaced stuck transaction %s with %s (max fee %s → %s)",
+					current.Hash().Hex(), replacement.Hash().Hex(), current.GasFeeCap(), replacement.GasFeeCap())
+				previous := attempts[len(attempts)-1]
+				attempt := tm.newAttempt(replacement, meta, nonce, len(attempts))
+				previous.Status = TxStatusReplaced
+				previous.ReplacedBy = attempt.TxHash
+				tm.save(previous)
+				tm.save(attempt)
+				attempts = append(attempts, attempt)
+				txs = append(txs, replacement)
+			}
+			lastBroadcast = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			if mined != nil {
+				return nil, nil, fmt.Errorf("transaction %s mined in block %d but not confirmed: %w", txs[minedIndex].Hash().Hex(), mined.BlockNumber, ctx.Err())
+			}
+			return nil, nil, fmt.Errorf("transaction %s not mined: %w", txs[len(txs)-1].Hash().Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Estimate prices the transaction built by build without sending it. Gas is estimated
+// with the real calldata, so a call that would revert fails here, and fees are taken from
+// recent blocks.
+func (tm *TxManager) Estimate(ctx context.Context, build func(opts *bind.TransactOpts) (*types.Transaction, error)) (*FeeEstimate, error) {
+	estimate, err := tm.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a gas limit the binding estimates gas for the calldata it packs
+	opts := tm.transactOpts(ctx, 0, estimate.MaxPriorityFeePerGas, estimate.MaxFeePerGas, 0)
+	opts.Nonce = nil
+	opts.NoSend = true
+	opts.Signer = leaveUnsigned
+	tx, err := build(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	estimate.GasEstimate = tx.Gas()
+	estimate.GasLimit = estimate.GasEstimate + estimate.GasEstimate*gasLimitMargin/100
+	if tm.gasLimit > 0 && estimate.GasLimit > tm.gasLimit {
+		if estimate.GasEstimate > tm.gasLimit {
+			return nil, fmt.Errorf("gas estimate %d exceeds the gas limit %d", estimate.GasEstimate, tm.gasLimit)
+		}
+		estimate.GasLimit = tm.gasLimit
+	}
+
+	price := estimate.MaxFeePerGas
+	if estimate.BaseFee != nil {
+		expected := new(big.Int).Add(estimate.BaseFee, estimate.MaxPriorityFeePerGas)
+		if expected.Cmp(price) < 0 {
+			price = expected
+		}
+	}
+	estimate.ExpectedCost = new(big.Int).Mul(new(big.Int).SetUint64(estimate.GasEstimate), price)
+	estimate.MaxCost = new(big.Int).Mul(new(big.Int).SetUint64(estimate.GasLimit), estimate.MaxFeePerGas)
+	estimate.Budget = tm.Budget
+	return estimate, nil
+}
+
+// Prepare builds the transaction of build with the given nonce and fees priced like Send,
+// without signing or sending it. It is signed elsewhere and sent with SendSigned.
+func (tm *TxManager) Prepare(ctx context.Context, nonce uint64, build func(opts *bind.TransactOpts) (*types.Transaction, error)) (*types.Transaction, *FeeEstimate, error) {
+	estimate, err := tm.Estimate(ctx, build)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !estimate.WithinBudget() {
+		return nil, nil, fmt.Errorf("%w: up to %s wei, budget %s wei", ErrOverBudget, estimate.MaxCost, estimate.Budget)
+	}
+
+	opts := tm.transactOpts(ctx, nonce, estimate.MaxPriorityFeePerGas, estimate.MaxFeePerGas, estimate.GasLimit)
+	opts.NoSend = true
+	opts.Signer = leaveUnsigned
+	tx, err := build(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build transaction: %w", err)
+	}
+	return tx, estimate, nil
+}
+
+// SendSigned broadcasts a transaction that was signed elsewhere, e.g. offline, and waits
+// until it is confirmed, following it through reorgs like Send. It cannot be repriced, so
+// a stuck transaction is only waited for. A transaction that is already mined is not sent
+// again, so an interrupted broadcast can be repeated.
+func (tm *TxManager) SendSigned(ctx context.Context, meta TxMeta, tx *types.Transaction) (*types.Receipt, error) {
+	if _, ok := ctx.Deadline(); !ok && tm.ConfirmTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, tm.ConfirmTimeout)
+		defer cancel()
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(tm.chainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction signature: %w", err)
+	}
+
+	_, err = tm.receipts.TransactionReceipt(ctx, tx.Hash())
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		if err := tm.backend.SendTransaction(ctx, tx); err != nil && !isAlreadyKnown(err) {
+			return nil, fmt.Errorf("failed to send transaction: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get receipt of %s: %w", tx.Hash().Hex(), err)
+	}
+
+	attempt := tm.newAttempt(tx, meta, tx.Nonce(), 0)
+	attempt.From = sender.Hex()
+	tm.save(attempt)
+
+	var mined *types.Receipt
+	ticker := time.NewTicker(tm.PollInterval)
+	defer ticker.Stop()
+	for {
+		receipt, err := tm.receipts.TransactionReceipt(ctx, tx.Hash())
+		switch {
+		case err == nil && receipt != nil:
+			if mined == nil || receipt.BlockHash != mined.BlockHash {
+				mined = receipt
+				tm.markMined(attempt, receipt)
+			}
+		case err != nil && errors.Is(err, ethereum.NotFound):
+			if mined != nil {
+				log.Printf("⚠️  Block %d of transaction %s was reorged out, waiting for it to be mined again", mined.BlockNumber, tx.Hash().Hex())
+				attempt.Status = TxStatusPending
+				attempt.BlockNumber = 0
+				attempt.GasUsed = 0
+				attempt.MinedAt = nil
+				tm.save(attempt)
+				mined = nil
+			}
+		case err != nil:
+			log.Printf("⚠️  Failed to get receipt of %s: %v", tx.Hash().Hex(), err)
+		}
+
+		if mined != nil {
+			depth, err := tm.depth(ctx, mined)
+			if err != nil {
+				log.Printf("⚠️  Failed to count confirmations of %s: %v", tx.Hash().Hex(), err)
+			} else if depth >= tm.Confirmations {
+				tm.settle([]*TxAttempt{attempt}, 0, mined)
+				return mined, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if mined != nil {
+				return nil, fmt.Errorf("transaction %s mined in block %d but not confirmed: %w", tx.Hash().Hex(), mined.BlockNumber, ctx.Err())
+			}
+			return nil, fmt.Errorf("transaction %s not mined: %w", tx.Hash().Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaveUnsigned is a binding signer that returns transactions unsigned, for transactions
+// that are only built and never sent from here
+func leaveUnsigned(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	return tx, nil
+}
+
+// replace rebroadcasts a stuck transaction with the same nonce and bumped fees. It
+// returns nil when the fees cannot be raised any further.
+func (tm *TxManager) replace(ctx context.Context, build func(opts *bind.TransactOpts) (*types.Transaction, error), current *types.Transaction, nonce uint64) (*types.Transaction, error) {
+	// Nodes only accept a replacement that pays at least 10% more
+	feeCap := bumpFee(current.GasFeeCap())
+	var tipCap *big.Int
+	if current.Type() != types.LegacyTxType {
+		tipCap = bumpFee(current.GasTipCap())
+	}
+	if fresh, err := tm.suggestFees(ctx); err == nil {
+		if fresh.MaxFeePerGas.Cmp(feeCap) > 0 {
+			feeCap = fresh.MaxFeePerGas
+		}
+		if tipCap != nil && fresh.MaxPriorityFeePerGas != nil && fresh.MaxPriorityFeePerGas.Cmp(tipCap) > 0 {
+			tipCap = fresh.MaxPriorityFeePerGas
+		}
+	}
+	if ceiling := tm.feeCeiling(current.Gas()); ceiling != nil && feeCap.Cmp(ceiling) > 0 {
+		feeCap = ceiling
+	}
+	if tipCap != nil && tipCap.Cmp(feeCap) > 0 {
+		tipCap = new(big.Int).Set(feeCap)
+	}
+	if feeCap.Cmp(current.GasFeeCap()) <= 0 {
+		return nil, nil
+	}
+
+	replacement, err := tm.broadcast(ctx, build, tm.transactOpts(ctx, nonce, tipCap, feeCap, current.Gas()))
+	if err != nil {
+		if strings.Contains(err.Error(), "underpriced") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// bumpFee raises a fee by 12.5%
+func bumpFee(fee *big.Int) *big.Int {
+	return new(big.Int).Add(fee, new(big.Int).Div(fee, big.NewInt(8)))
+}
+
+// feeCeiling returns the highest max fee per gas allowed for a transaction with gasLimit,
+// from MaxGasPrice and the budget, or nil when neither is set
+func (tm *TxManager) feeCeiling(gasLimit uint64) *big.Int {
+	var ceiling *big.Int
+	if tm.MaxGasPrice != nil {
+		ceiling = tm.MaxGasPrice
+	}
+	if tm.Budget != nil && gasLimit > 0 {
+		perGas := new(big.Int).Div(tm.Budget, new(big.Int).SetUint64(gasLimit))
+		if ceiling == nil || perGas.Cmp(ceiling) < 0 {
+			ceiling = perGas
+		}
+	}
+	return ceiling
+}
+
+// suggestFees prices a transaction from recent blocks: the max fee leaves room for the
+// base fee to double before the transaction is priced out. Fees are capped by MaxGasPrice.
+func (tm *TxManager) suggestFees(ctx context.Context) (*FeeEstimate, error) {
+	head, err := tm.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	estimate := &FeeEstimate{}
+	if head.BaseFee == nil {
+		// Chain without EIP-1559
+		gasPrice, err := tm.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		estimate.MaxFeePerGas = gasPrice
+	} else {
+		baseFee, tipCap, err := tm.recentFees(ctx, head)
+		if err != nil {
+			return nil, err
+		}
+		estimate.BaseFee = baseFee
+		estimate.MaxPriorityFeePerGas = tipCap
+		estimate.MaxFeePerGas = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tipCap)
+	}
+
+	if tm.MaxGasPrice != nil && estimate.MaxFeePerGas.Cmp(tm.MaxGasPrice) > 0 {
+		log.Printf("⚠️  Suggested max fee %s wei/gas is above MAX_GAS_PRICE, using %s", estimate.MaxFeePerGas, tm.MaxGasPrice)
+		estimate.MaxFeePerGas = new(big.Int).Set(tm.MaxGasPrice)
+		if estimate.MaxPriorityFeePerGas != nil && estimate.MaxPriorityFeePerGas.Cmp(estimate.MaxFeePerGas) > 0 {
+			estimate.MaxPriorityFeePerGas = new(big.Int).Set(estimate.MaxFeePerGas)
+		}
+		if estimate.BaseFee != nil && estimate.MaxFeePerGas.Cmp(estimate.BaseFee) < 0 {
+			log.Printf("⚠️  MAX_GAS_PRICE is below the base fee %s wei/gas, the transaction waits until the base fee drops", estimate.BaseFee)
+		}
+	}
+	return estimate, nil
+}
+
+// recentFees returns the base fee of the next block and the median of the median tips paid
+// in the last feeHistoryBlocks blocks. Without fee history, or when those blocks carried
+// no paying transactions, the node's suggested tip is used.
+func (tm *TxManager) recentFees(ctx context.Context, head *types.Header) (*big.Int, *big.Int, error) {
+	baseFee := head.BaseFee
+	var tipCap *big.Int
+
+	if reader, ok := tm.backend.(ethereum.FeeHistoryReader); ok {
+		history, err := reader.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{feeHistoryPercentile})
+		if err != nil {
+			log.Printf("⚠️  Fee history unavailable, using the node's suggestion: %v", err)
+		} else {
+			// The last base fee is the one of the next block
+			if n := len(history.BaseFee); n > 0 && history.BaseFee[n-1] != nil {
+				baseFee = history.BaseFee[n-1]
+			}
+			var tips []*big.Int
+			for _, reward := range history.Reward {
+				if len(reward) > 0 && reward[0] != nil && reward[0].Sign() > 0 {
+					tips = append(tips, reward[0])
+				}
+			}
+			if len(tips) > 0 {
+				sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+				tipCap = new(big.Int).Set(tips[len(tips)/2])
+			}
+		}
+	}
+
+	if tipCap == nil {
+		suggested, err := tm.backend.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to suggest priority fee: %w", err)
+		}
+		tipCap = suggested
+	}
+	return new(big.Int).Set(baseFee), tipCap, nil
+}
+
+// transactOpts returns options for a transaction with the given fees; a nil tipCap sends
+// a legacy transaction priced at feeCap
+func (tm *TxManager) transactOpts(ctx context.Context, nonce uint64, tipCap, feeCap *big.Int, gasLimit uint64) *bind.TransactOpts {
+	opts := &bind.TransactOpts{
+		From:  tm.from,
+		Nonce: new(big.Int).SetUint64(nonce),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != tm.from {
+				return nil, bind.ErrNotAuthorized
+			}
+			return tm.signer.SignTx(ctx, tx, tm.chainID)
+		},
+		Value:    big.NewInt(0),
+		GasLimit: gasLimit,
+		Context:  ctx,
+	}
+	if tipCap == nil {
+		opts.GasPrice = feeCap
+	} else {
+		opts.GasTipCap = tipCap
+		opts.GasFeeCap = feeCap
+	}
+	return opts
+}
+
+func (tm *TxManager) newAttempt(tx *types.Transaction, meta TxMeta, nonce uint64, attempt int) *TxAttempt {
+	record := &TxAttempt{
+		TxHash:      tx.Hash().Hex(),
+		From:        tm.from.Hex(),
+		Nonce:       nonce,
+		Attempt:     attempt,
+		GasPrice:    tx.GasFeeCap(),
+		TermID:      meta.TermID,
+		VerkleRoot:  meta.VerkleRoot,
+		Status:      TxStatusPending,
+		SubmittedAt: time.Now(),
+	}
+	if tx.Type() != types.LegacyTxType {
+		record.GasTipCap = tx.GasTipCap()
+	}
+	return record
+}
+
+// markMined records that an attempt was included in a block that is not yet confirmed
+func (tm *TxManager) markMined(attempt *TxAttempt, receipt *types.Receipt) {
+	minedAt := time.Now()
+	attempt.Status = TxStatusMined
+	attempt.BlockNumber = receipt.BlockNumber.Uint64()
+	attempt.GasUsed = receipt.GasUsed
+	attempt.MinedAt = &minedAt
+	tm.save(attempt)
+}
+
+// depth returns the number of blocks from the chain head down to the block of receipt,
+// counting both
+func (tm *TxManager) depth(ctx context.Context, receipt *types.Receipt) (uint64, error) {
+	head, err := tm.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if head.Number.Cmp(receipt.BlockNumber) < 0 {
+		return 0, nil
+	}
+	return new(big.Int).Sub(head.Number, receipt.BlockNumber).Uint64() + 1, nil
+}
+
+// settle records the outcome once attempt mined has been confirmed; the others can no
+// longer be mined since they share its nonce
+func (tm *TxManager) settle(attempts []*TxAttempt, mined int, receipt *types.Receipt) {
+	now := time.Now()
+	for i, attempt := range attempts {
+		if i != mined {
+			attempt.Status = TxStatusReplaced
+			attempt.ReplacedBy = attempts[mined].TxHash
+			tm.save(attempt)
+			continue
+		}
+		attempt.Status = TxStatusConfirmed
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			attempt.Status = TxStatusFailed
+		}
+		attempt.BlockNumber = receipt.BlockNumber.Uint64()
+		attempt.GasUsed = receipt.GasUsed
+		attempt.MinedAt = &now
+		tm.save(attempt)
+	}
+}
+
+func (tm *TxManager) save(attempt *TxAttempt) {
+	if tm.store == nil {
+		return
+	}
+	if err := tm.store.SaveTxAttempt(attempt); err != nil {
+		log.Printf("⚠️  Failed to record transaction %s: %v", attempt.TxHash, err)
+	}
+}
+
+// broadcast signs the transaction of build and sends it. A node that already has the
+// transaction, e.g. from a retried request, reports "already known"; that is the same
+// transaction with the same nonce, so it is tracked rather than sent again.
+func (tm *TxManager) broadcast(ctx context.Context, build func(opts *bind.TransactOpts) (*types.Transaction, error), opts *bind.TransactOpts) (*types.Transaction, error) {
+	opts.NoSend = true
+	tx, err := build(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := tm.backend.SendTransaction(ctx, tx); err != nil {
+		if isAlreadyKnown(err) {
+			log.Printf("ℹ️  Transaction %s already known to the node", tx.Hash().Hex())
+			return tx, nil
+		}
+		return nil, err
+	}
+	return tx, nil
+}
+
+// isNonceTooLow reports whether a send failed because the nonce is already mined
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
+
+// isAlreadyKnown reports whether a send failed because the node already has the transaction
+func isAlreadyKnown(err error) bool {
+	return strings.Contains(err.Error(), "already known")
+}
+
+// nonceTracker hands out the nonces of one account. It is shared by every TxManager of
+// the account in the process, so integrations created per request do not collide.
+type nonceTracker struct {
+	mu       sync.Mutex
+	next     uint64
+	synced   bool
+	inFlight map[uint64]bool
+}
+
+var (
+	nonceTrackersMu sync.Mutex
+	nonceTrackers   = make(map[string]*nonceTracker)
+)
+
+func sharedNonceTracker(chainID *big.Int, from common.Address) *nonceTracker {
+	nonceTrackersMu.Lock()
+	defer nonceTrackersMu.Unlock()
+
+	key := fmt.Sprintf("%s:%s", chainID, from.Hex())
+	tracker, ok := nonceTrackers[key]
+	if !ok {
+		tracker = &nonceTracker{inFlight: make(map[uint64]bool)}
+		nonceTrackers[key] = tracker
+	}
+	return tracker
+}
+
+// acquire returns the next nonce. The node's pending nonce moves the counter forward when
+// the account was used elsewhere, and back when nothing is in flight, e.g. after a local
+// chain was restarted.
+func (nt *nonceTracker) acquire(ctx context.Context, backend bind.ContractBackend, from common.Address) (uint64, error) {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+
+	pending, err := backend.PendingNonceAt(ctx, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account nonce: %w", err)
+	}
+	if !nt.synced || pending > nt.next || len(nt.inFlight) == 0 {
+		nt.next = pending
+		nt.synced = true
+	}
+	nonce := nt.next
+	nt.next++
+	nt.inFlight[nonce] = true
+	return nonce, nil
+}
+
+// release returns a nonce whose transaction was never broadcast. If later nonces were
+// handed out meanwhile, the tracker resyncs so the gap is filled by the next send.
+func (nt *nonceTracker) release(nonce uint64) {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+
+	delete(nt.inFlight, nonce)
+	if nt.synced && nonce == nt.next-1 {
+		nt.next--
+		return
+	}
+	nt.synced = false
+}
+
+// done marks the transaction with nonce as settled
+func (nt *nonceTracker) done(nonce uint64) {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	delete(nt.inFlight, nonce)
+}
+
+// reset makes the next acquire take the pending nonce from the node
+func (nt *nonceTracker) reset() {
+	nt.mu.Lock()
+	defer nt.mu.Unlock()
+	nt.synced = false
+}
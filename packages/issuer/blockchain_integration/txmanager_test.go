@@ -0,0 +1,376 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// memoryTxStore keeps the latest state of every attempt
+type memoryTxStore struct {
+	mu       sync.Mutex
+	attempts map[string]TxAttempt
+}
+
+func (s *memoryTxStore) SaveTxAttempt(attempt *TxAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts[attempt.TxHash] = *attempt
+	return nil
+}
+
+func (s *memoryTxStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.attempts)
+}
+
+// newManualChain starts a simulated chain whose blocks are only mined by Commit, so
+// transactions stay pending like on a congested network
+func newManualChain(t *testing.T) (*SimulatedChain, *TxManager, *IUMiCertRegistry, *memoryTxStore) {
+	t.Helper()
+	chain, err := NewSimulatedChain("", 500000)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	t.Cleanup(func() { chain.Close() })
+
+	client := chain.backend.Client()
+	registry, err := NewIUMiCertRegistry(chain.Registry, client)
+	if err != nil {
+		t.Fatalf("Failed to bind registry: %v", err)
+	}
+
+	store := &memoryTxStore{attempts: make(map[string]TxAttempt)}
+	tm := NewTxManager(client, client, NewKeySigner(chain.ownerKey), chain.ChainID(), 500000)
+	tm.SetStore(store)
+	tm.StuckAfter = 50 * time.Millisecond
+	tm.PollInterval = 10 * time.Millisecond
+	tm.ConfirmTimeout = 10 * time.Second
+	return chain, tm, registry, store
+}
+
+func publishBuilder(registry *IUMiCertRegistry, label string) (TxMeta, func(opts *bind.TransactOpts) (*types.Transaction, error)) {
+	root := crypto.Keccak256Hash([]byte(label))
+	meta := TxMeta{TermID: "Semester_1_2024_" + label, VerkleRoot: root}
+	return meta, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.PublishTermRoot(opts, root, meta.TermID, big.NewInt(3))
+	}
+}
+
+// commitWhen mines a block once ready reports true
+func commitWhen(chain *SimulatedChain, ready func() bool) {
+	go func() {
+		for !ready() {
+			time.Sleep(5 * time.Millisecond)
+		}
+		chain.backend.Commit()
+	}()
+}
+
+// TestTxManagerReplacesStuckTransaction checks that an unmined transaction is replaced
+// with the same nonce at a higher gas price and that every attempt is recorded
+func TestTxManagerReplacesStuckTransaction(t *testing.T) {
+	chain, tm, registry, store := newManualChain(t)
+	meta, build := publishBuilder(registry, "stuck")
+
+	commitWhen(chain, func() bool { return store.count() >= 2 })
+	tx, receipt, err := tm.Send(context.Background(), meta, build)
+	if err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("Transaction reverted")
+	}
+
+	mined := store.attempts[tx.Hash().Hex()]
+	if mined.Status != TxStatusConfirmed || mined.Attempt == 0 || mined.BlockNumber == 0 {
+		t.Fatalf("Mined attempt not recorded as confirmed replacement: %+v", mined)
+	}
+	for hash, attempt := range store.attempts {
+		if attempt.Nonce != mined.Nonce {
+			t.Fatalf("Attempt %s uses nonce %d, expected %d", hash, attempt.Nonce, mined.Nonce)
+		}
+		if hash != mined.TxHash && (attempt.Status != TxStatusReplaced || attempt.GasPrice.Cmp(mined.GasPrice) >= 0) {
+			t.Fatalf("Earlier attempt not replaced by a higher fee: %+v", attempt)
+		}
+	}
+	t.Logf("✅ Stuck transaction replaced %d times, mined %s at gas price %s", len(store.attempts)-1, mined.TxHash, mined.GasPrice)
+}
+
+// TestTxManagerRespectsMaxGasPrice checks that fees are never bumped above MaxGasPrice
+func TestTxManagerRespectsMaxGasPrice(t *testing.T) {
+	chain, tm, registry, store := newManualChain(t)
+	meta, build := publishBuilder(registry, "capped")
+
+	suggested, err := chain.backend.Client().SuggestGasPrice(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to suggest gas price: %v", err)
+	}
+	tm.MaxGasPrice = new(big.Int).Add(suggested, big.NewInt(1))
+
+	// Leave time for several stuck periods before mining
+	commitWhen(chain, func() bool { time.Sleep(300 * time.Millisecond); return true })
+	if _, _, err := tm.Send(context.Background(), meta, build); err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	for _, attempt := range store.attempts {
+		if attempt.GasPrice.Cmp(tm.MaxGasPrice) > 0 {
+			t.Fatalf("Attempt %s pays %s, above the cap %s", attempt.TxHash, attempt.GasPrice, tm.MaxGasPrice)
+		}
+	}
+	t.Logf("✅ %d attempts, all within the gas price cap %s", len(store.attempts), tm.MaxGasPrice)
+}
+
+// TestTxManagerConcurrentNonces checks that concurrent publishes by one account get
+// distinct nonces, also across integrations
+func TestTxManagerConcurrentNonces(t *testing.T) {
+	chain, err := NewSimulatedChain("", 500000)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	defer chain.Close()
+
+	const publishes = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, publishes)
+	for i := 0; i < publishes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			integration, err := chain.Integration()
+			if err != nil {
+				errs <- err
+				return
+			}
+			_, err = integration.PublishTermRoot(context.Background(), testRoot(fmt.Sprintf("concurrent-%d", i)), fmt.Sprintf("Semester_%d_2024", i), big.NewInt(2))
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Concurrent publish failed: %v", err)
+		}
+	}
+
+	nonce, err := chain.client.PendingNonceAt(context.Background(), chain.Owner)
+	if err != nil {
+		t.Fatalf("Failed to read nonce: %v", err)
+	}
+	// One nonce went to the registry deployment
+	if nonce != publishes+1 {
+		t.Fatalf("Account nonce is %d after %d publishes", nonce, publishes)
+	}
+	t.Logf("✅ %d concurrent publishes mined with distinct nonces", publishes)
+}
+
+// TestEstimateAndBudget checks that estimating sends nothing, that a publication over
+// budget is refused before it is broadcast and that reverting calls fail the estimate
+func TestEstimateAndBudget(t *testing.T) {
+	chain, err := NewSimulatedChain("", 500000)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	defer chain.Close()
+	integration, err := chain.Integration()
+	if err != nil {
+		t.Fatalf("Failed to create integration: %v", err)
+	}
+
+	ctx := context.Background()
+	nonce := func() uint64 {
+		n, err := chain.client.PendingNonceAt(ctx, chain.Owner)
+		if err != nil {
+			t.Fatalf("Failed to read nonce: %v", err)
+		}
+		return n
+	}
+
+	termID := "Semester_1_2025"
+	root := testRoot("estimate")
+	before := nonce()
+	estimate, err := integration.EstimatePublishTermRoot(ctx, root, termID, big.NewInt(4))
+	if err != nil {
+		t.Fatalf("Failed to estimate publication: %v", err)
+	}
+	if nonce() != before {
+		t.Fatalf("Estimating sent a transaction")
+	}
+	if estimate.GasEstimate <= 21000 || estimate.GasLimit < estimate.GasEstimate || estimate.GasLimit > 500000 {
+		t.Fatalf("Implausible gas estimate: %+v", estimate)
+	}
+	if estimate.BaseFee == nil || estimate.MaxPriorityFeePerGas == nil ||
+		estimate.MaxFeePerGas.Cmp(new(big.Int).Add(estimate.BaseFee, estimate.MaxPriorityFeePerGas)) < 0 {
+		t.Fatalf("Fee caps do not cover base fee plus tip: %+v", estimate)
+	}
+	if estimate.MaxCost.Cmp(new(big.Int).Mul(new(big.Int).SetUint64(estimate.GasLimit), estimate.MaxFeePerGas)) != 0 ||
+		estimate.ExpectedCost.Cmp(estimate.MaxCost) > 0 {
+		t.Fatalf("Inconsistent costs: %+v", estimate)
+	}
+
+	tm := integration.client.TxManager()
+	tm.Budget = new(big.Int).Sub(estimate.MaxCost, big.NewInt(1))
+	if _, err := integration.PublishTermRoot(ctx, root, termID, big.NewInt(4)); !errors.Is(err, ErrOverBudget) {
+		t.Fatalf("Publication over budget not refused: %v", err)
+	}
+	if nonce() != before {
+		t.Fatalf("Publication over budget was broadcast")
+	}
+
+	tm.Budget = new(big.Int).Mul(estimate.MaxCost, big.NewInt(2))
+	result, err := integration.PublishTermRoot(ctx, root, termID, big.NewInt(4))
+	if err != nil {
+		t.Fatalf("Publication within budget failed: %v", err)
+	}
+	if result.GasUsed > estimate.GasLimit {
+		t.Fatalf("Used %d gas, above the estimated limit %d", result.GasUsed, estimate.GasLimit)
+	}
+
+	if _, err := integration.EstimatePublishTermRoot(ctx, root, termID, big.NewInt(4)); err == nil {
+		t.Fatalf("Estimate of an already published root succeeded")
+	}
+	supersede, err := integration.EstimateSupersedeTerm(ctx, termID, testRoot("estimate-v2"), big.NewInt(4), "Revoked 1 credential")
+	if err != nil {
+		t.Fatalf("Failed to estimate supersession: %v", err)
+	}
+	t.Logf("✅ Publish estimated at %d gas (used %d), supersede at %d gas, up to %s wei",
+		estimate.GasEstimate, result.GasUsed, supersede.GasEstimate, estimate.MaxCost)
+}
+
+// TestTxManagerFollowsReorg checks that Send waits for the configured confirmations and,
+// when the block of the transaction is reorged out, for its inclusion in the new chain
+func TestTxManagerFollowsReorg(t *testing.T) {
+	chain, tm, registry, store := newManualChain(t)
+	meta, build := publishBuilder(registry, "reorg")
+	tm.Confirmations = 3
+	tm.StuckAfter = time.Hour
+
+	status := func(want string) func() bool {
+		return func() bool {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			for _, attempt := range store.attempts {
+				if attempt.Status == want {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	client := chain.backend.Client()
+	ctx := context.Background()
+	var orphaned common.Hash
+	go func() {
+		for !status(TxStatusPending)() {
+			time.Sleep(5 * time.Millisecond)
+		}
+		orphaned = chain.backend.Commit()
+		for !status(TxStatusMined)() {
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		// Replace the block with a sibling; the transaction returns to the pool and is
+		// mined again in the new branch, which then grows to the confirmation depth
+		block, err := client.BlockByHash(ctx, orphaned)
+		if err != nil {
+			t.Errorf("Failed to read mined block: %v", err)
+			return
+		}
+		if err := chain.backend.Fork(block.ParentHash()); err != nil {
+			t.Errorf("Failed to fork chain: %v", err)
+			return
+		}
+		chain.backend.Commit()
+		chain.backend.Commit()
+		time.Sleep(50 * time.Millisecond)
+		chain.backend.Commit()
+		chain.backend.Commit()
+	}()
+
+	tx, receipt, err := tm.Send(ctx, meta, build)
+	if err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	if receipt.BlockHash == orphaned {
+		t.Fatalf("Receipt still points to the orphaned block %s", orphaned.Hex())
+	}
+	canonical, err := client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		t.Fatalf("Failed to read block %d: %v", receipt.BlockNumber, err)
+	}
+	if canonical.Hash() != receipt.BlockHash {
+		t.Fatalf("Receipt block %s is not canonical", receipt.BlockHash.Hex())
+	}
+	depth, err := tm.depth(ctx, receipt)
+	if err != nil || depth < tm.Confirmations {
+		t.Fatalf("Send returned at depth %d, expected %d: %v", depth, tm.Confirmations, err)
+	}
+	if attempt := store.attempts[tx.Hash().Hex()]; attempt.Status != TxStatusConfirmed || attempt.BlockNumber != receipt.BlockNumber.Uint64() {
+		t.Fatalf("Attempt not recorded as confirmed in the new block: %+v", attempt)
+	}
+	t.Logf("✅ Transaction %s survived the reorg, confirmed in block %d at depth %d", tx.Hash().Hex(), receipt.BlockNumber, depth)
+}
+
+// resendingBackend broadcasts every transaction twice, like a client retrying a request
+// whose response was lost; the node answers the second broadcast with "already known"
+type resendingBackend struct {
+	bind.ContractBackend
+	sends int
+}
+
+func (b *resendingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.sends++
+	if err := b.ContractBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	return b.ContractBackend.SendTransaction(ctx, tx)
+}
+
+// TestTxManagerAlreadyKnown checks that a transaction the node already has is tracked with
+// its nonce instead of being signed again with a fresh one
+func TestTxManagerAlreadyKnown(t *testing.T) {
+	chain, tm, registry, store := newManualChain(t)
+	meta, build := publishBuilder(registry, "known")
+	tm.StuckAfter = time.Hour
+
+	client := chain.backend.Client()
+	ctx := context.Background()
+	nonce, err := client.PendingNonceAt(ctx, chain.Owner)
+	if err != nil {
+		t.Fatalf("Failed to read nonce: %v", err)
+	}
+	backend := &resendingBackend{ContractBackend: client}
+	tm.backend = backend
+
+	commitWhen(chain, func() bool { return store.count() >= 1 })
+	tx, receipt, err := tm.Send(ctx, meta, build)
+	if err != nil {
+		t.Fatalf("Failed to send transaction: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("Transaction reverted")
+	}
+	if tx.Nonce() != nonce || backend.sends != 1 || store.count() != 1 {
+		t.Fatalf("Expected one broadcast with nonce %d, got nonce %d, %d broadcasts, %d attempts", nonce, tx.Nonce(), backend.sends, store.count())
+	}
+	next, err := client.PendingNonceAt(ctx, chain.Owner)
+	if err != nil {
+		t.Fatalf("Failed to read nonce: %v", err)
+	}
+	if next != nonce+1 {
+		t.Fatalf("Account nonce is %d, expected %d", next, nonce+1)
+	}
+	t.Logf("✅ Already known transaction %s tracked with nonce %d", tx.Hash().Hex(), tx.Nonce())
+}
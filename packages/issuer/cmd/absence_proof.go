@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"iumicert/crypto/verkle"
+)
+
+// AbsenceProofDocument is the portable form of a non-membership proof handed to verifiers
+type AbsenceProofDocument struct {
+	StudentID   string          `json:"student_id"`
+	TermID      string          `json:"term_id"`
+	CourseID    string          `json:"course_id"`
+	VerkleRoot  string          `json:"verkle_root"`
+	TermVersion uint32          `json:"term_version"`
+	ProofType   string          `json:"proof_type"`
+	Proof       json.RawMessage `json:"proof"`
+	GeneratedAt string          `json:"generated_at"`
+}
+
+var proveAbsenceCmd = &cobra.Command{
+	Use:   "prove-absence [student-id] [term-id] [course-id]",
+	Short: "Generate a proof that a student has no credential for a course in a term",
+	Long: `Generate a Verkle non-membership proof showing that the term tree does not
+contain the given course for the student, either because it was never issued
+or because it was removed by a revocation.`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		
+		doc, err := generateAbsenceProof(args[0], args[1], args[2])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to generate absence proof: %v\n", err)
+			os.Exit(1)
+		}
+		
+		if output == "" {
+			output = resolveProjectPath(filepath.Join("publish_ready", "absence_proofs",
+				fmt.Sprintf("absence_%s_%s_%s.json", args[0], args[1], args[2])))
+		}
+		if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to create output directory: %v\n", err)
+			os.Exit(1)
+		}
+		
+		docData, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to marshal absence proof: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(output, docData, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to write absence proof: %v\n", err)
+			os.Exit(1)
+		}
+		
+		fmt.Printf("💾 Absence proof saved to: %s\n", output)
+		fmt.Println("✅ Absence proof generated successfully!")
+	},
+}
+
+var verifyAbsenceCmd = &cobra.Command{
+	Use:   "verify-absence [proof-file]",
+	Short: "Verify a course absence proof",
+	Long: `Verify a Verkle non-membership proof produced by prove-absence.
+By default the proof is checked against the root embedded in the file; use --root
+to check it against a root obtained independently (e.g. from the blockchain).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		expectedRoot, _ := cmd.Flags().GetString("root")
+		
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read proof file: %v\n", err)
+			os.Exit(1)
+		}
+		
+		var doc AbsenceProofDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to parse proof file: %v\n", err)
+			os.Exit(1)
+		}
+		
+		if err := verifyAbsenceProof(&doc, expectedRoot); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Absence proof verification failed: %v\n", err)
+			os.Exit(1)
+		}
+		
+		fmt.Printf("✅ Verified: student %s has no credential for %s in term %s\n", doc.StudentID, doc.CourseID, doc.TermID)
+	},
+}
+
+// generateAbsenceProof builds an absence proof document for a student's course in a term
+func generateAbsenceProof(studentID, termID, courseID string) (*AbsenceProofDocument, error) {
+	fmt.Printf("🔍 Proving absence of %s for student %s in term %s\n", courseID, studentID, termID)
+	
+	termTree, err := loadTermTree(termID)
+	if err != nil {
+		return nil, err
+	}
+	defer termTree.Close()
+	
+	studentDID := fmt.Sprintf("did:example:%s", studentID)
+	proof, err := termTree.GenerateAbsenceProof(studentDID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &AbsenceProofDocument{
+		StudentID:   studentID,
+		TermID:      termID,
+		CourseID:    courseID,
+		VerkleRoot:  fmt.Sprintf("%x", termTree.VerkleRoot),
+		TermVersion: termTree.Version,
+		ProofType:   "verkle_absence",
+		Proof:       proof,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// verifyAbsenceProof checks an absence proof document against its root, or against
+// expectedRootHex when one is given
+func verifyAbsenceProof(doc *AbsenceProofDocument, expectedRootHex string) error {
+	if doc.ProofType != "" && doc.ProofType != "verkle_absence" {
+		return fmt.Errorf("unsupported proof type: %s", doc.ProofType)
+	}
+	
+	rootHex := doc.VerkleRoot
+	if expectedRootHex != "" {
+		rootHex = expectedRootHex
+	}
+	
+	verkleRoot, err := parseVerkleRoot(rootHex)
+	if err != nil {
+		return fmt.Errorf("invalid verkle root: %w", err)
+	}
+	
+	courseKey := fmt.Sprintf("did:example:%s:%s:%s", doc.StudentID, doc.TermID, doc.CourseID)
+	return verkle.VerifyAbsenceProof(courseKey, doc.Proof, verkleRoot)
+}
+
+func init() {
+	proveAbsenceCmd.Flags().String("output", "", "output file for the absence proof")
+	verifyAbsenceCmd.Flags().String("root", "", "expected Verkle root (hex), overrides the root in the proof file")
+	
+	rootCmd.AddCommand(proveAbsenceCmd)
+	rootCmd.AddCommand(verifyAbsenceCmd)
+}
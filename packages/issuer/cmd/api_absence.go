@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ===== ABSENCE PROOF API HANDLERS =====
+
+// handleGenerateAbsenceProof generates a proof that a student has no credential for a course
+// in a term. Whether it succeeds tells whether the student took the course, so it is only
+// served to a registrar and, with a student token, to the student themselves.
+func handleGenerateAbsenceProof(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		StudentID string `json:"student_id"`
+		TermID    string `json:"term_id"`
+		CourseID  string `json:"course_id"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	// A student token only proves absences of its own student
+	if studentID := studentFrom(r); studentID != "" {
+		request.StudentID = studentID
+	}
+
+	if request.StudentID == "" || request.TermID == "" || request.CourseID == "" {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "student_id, term_id, and course_id are required",
+		})
+		return
+	}
+
+	doc, err := generateAbsenceProof(request.StudentID, request.TermID, request.CourseID)
+	if err != nil {
+		// The same answer whatever the reason, so a failure does not tell the course exists
+		log.Printf("❌ Failed to generate absence proof: %v", err)
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Cannot prove absence of %s in term %s", request.CourseID, request.TermID),
+		})
+		return
+	}
+
+	// Only hand out proofs that a verifier can check against the registry
+	rootCheck, err := getRootOracle().Check(r.Context(), doc.TermID, doc.VerkleRoot)
+	if err != nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Cannot verify term root: %v", err),
+		})
+		return
+	}
+	if err := rootCheck.Err(); err != nil {
+		log.Printf("❌ Term root of absence proof rejected: %v", err)
+		respondJSON(w, http.StatusConflict, APIResponse{
+			Success: false,
+			Data:    map[string]interface{}{"root_status": rootCheck},
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: doc})
+}
+
+// handleVerifyAbsenceProof verifies an absence proof document
+func handleVerifyAbsenceProof(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Proof      AbsenceProofDocument `json:"proof"`
+		VerkleRoot string               `json:"verkle_root,omitempty"` // Optional expected root
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	noteVerifiedReceipt(r, "absence", contentID("ab_", request.Proof), request.Proof.StudentID, request.Proof.TermID)
+
+	verifiedRoot := request.Proof.VerkleRoot
+	if request.VerkleRoot != "" {
+		verifiedRoot = request.VerkleRoot
+	}
+
+	result := map[string]interface{}{
+		"student_id":  request.Proof.StudentID,
+		"term_id":     request.Proof.TermID,
+		"course_id":   request.Proof.CourseID,
+		"verkle_root": verifiedRoot,
+		"timestamp":   time.Now().Format(time.RFC3339),
+	}
+
+	// The root must be the latest published version of the term, an absence proof against
+	// an unpublished root proves nothing
+	rootCheck, err := getRootOracle().Check(r.Context(), request.Proof.TermID, verifiedRoot)
+	if err != nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Cannot verify proof root: %v", err),
+		})
+		return
+	}
+	result["root_status"] = rootCheck
+	if err := rootCheck.Err(); err != nil {
+		log.Printf("❌ Absence proof root rejected: %v", err)
+		result["verified"] = false
+		result["verification_error"] = err.Error()
+		respondJSON(w, http.StatusOK, APIResponse{Success: false, Data: result})
+		return
+	}
+
+	if err := verifyAbsenceProof(&request.Proof, request.VerkleRoot); err != nil {
+		log.Printf("❌ Absence proof verification failed: %v", err)
+		result["verified"] = false
+		result["verification_error"] = err.Error()
+		respondJSON(w, http.StatusOK, APIResponse{Success: false, Data: result})
+		return
+	}
+
+	result["verified"] = true
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: result})
+}
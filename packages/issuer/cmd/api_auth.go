@@ -0,0 +1,632 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"iumicert/issuer/config"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"github.com/spf13/cobra"
+)
+
+// Roles of the issuer API. Every authenticated principal may read; the auditor role
+// grants nothing more and names principals that only read.
+const (
+	roleRegistrar = "registrar" // Adds terms, generates receipts, files revocation requests
+	roleApprover  = "approver"  // Approves, rejects and deletes revocation requests
+	rolePublisher = "publisher" // Publishes roots and processes approved revocations on chain
+	roleAuditor   = "auditor"   // Reads terms, receipts, transactions and revocations
+)
+
+var allRoles = []string{roleRegistrar, roleApprover, rolePublisher, roleAuditor}
+
+// Validity of student tokens
+const (
+	defaultStudentTokenTTL = 30 * 24 * time.Hour
+	maxStudentTokenTTL     = 365 * 24 * time.Hour
+)
+
+const (
+	apiKeyPrefix         = "mck_"
+	authTokenIssuer      = "iumicert-issuer"
+	studentTokenAudience = "student" // Student tokens only read the student's own records
+	minAuthTokenSecret   = 32
+)
+
+// streamTokenTTL is how long a job event stream token can open the stream
+const streamTokenTTL = 5 * time.Minute
+
+// streamTokenSecret signs job event stream tokens. It is generated for each process, so
+// stream tokens also work with API keys alone and end with the process.
+var streamTokenSecret = func() []byte {
+	secret := make([]byte, minAuthTokenSecret)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate stream token secret: %v", err))
+	}
+	return secret
+}()
+
+// principal is the authenticated caller of an issuer route
+type principal struct {
+	Name   string   `json:"name"`
+	Roles  []string `json:"roles"`
+	Method string   `json:"method"` // api_key, token, stream_token or anonymous
+}
+
+// HasRole reports whether the principal holds role
+func (p *principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// principalFrom returns the principal authenticated for r, or nil
+func principalFrom(r *http.Request) *principal {
+	p, _ := r.Context().Value(principalKey{}).(*principal)
+	return p
+}
+
+// requestActor names the principal of r for the records it writes
+func requestActor(r *http.Request) string {
+	if p := principalFrom(r); p != nil {
+		return p.Name
+	}
+	return "anonymous"
+}
+
+// apiKeyEntry is an API key in the keys file. Only the SHA-256 of the key is stored.
+type apiKeyEntry struct {
+	Name      string    `json:"name"`
+	SHA256    string    `json:"sha256"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type apiKeyFile struct {
+	Keys []apiKeyEntry `json:"keys"`
+}
+
+// authClaims are the claims of a signed bearer token
+type authClaims struct {
+	Roles []string `json:"roles"`
+	jwt.RegisteredClaims
+}
+
+// authenticator checks the API keys and signed bearer tokens of issuer requests
+type authenticator struct {
+	keys      map[string]apiKeyEntry // By SHA-256 of the key
+	secret    []byte
+	anonymous *principal // Set when AUTH_DISABLED opens the API without credentials
+}
+
+// newAuthenticator loads the API keys and token secret of cfg. Without any credentials the
+// issuer API refuses to start, unless AUTH_DISABLED opens it outside production.
+func newAuthenticator(cfg *config.Config) (*authenticator, error) {
+	auth := &authenticator{keys: make(map[string]apiKeyEntry)}
+
+	if path := apiKeysPath(cfg); path != "" {
+		keyFile, err := readAPIKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keyFile.Keys {
+			if err := validateRoles(key.Roles); err != nil {
+				return nil, fmt.Errorf("API key %q in %s: %w", key.Name, path, err)
+			}
+			auth.keys[strings.ToLower(key.SHA256)] = key
+		}
+		log.Printf("🔑 Loaded %d API keys from %s", len(auth.keys), path)
+	}
+
+	if cfg.AuthTokenSecret != "" {
+		if len(cfg.AuthTokenSecret) < minAuthTokenSecret {
+			return nil, fmt.Errorf("AUTH_TOKEN_SECRET must be at least %d bytes", minAuthTokenSecret)
+		}
+		auth.secret = []byte(cfg.AuthTokenSecret)
+	}
+
+	if len(auth.keys) == 0 && auth.secret == nil {
+		if cfg.IsProduction() {
+			return nil, errors.New("no API keys or AUTH_TOKEN_SECRET configured; the issuer API cannot run unauthenticated in production")
+		}
+		if !cfg.AuthDisabled {
+			return nil, errors.New("no API keys or AUTH_TOKEN_SECRET configured; create a key with `micert auth create-key`, or set AUTH_DISABLED=true to open the issuer API for local development")
+		}
+		log.Printf("⚠️  AUTH_DISABLED is set, issuer API is open to anyone (development only)")
+		auth.anonymous = &principal{Name: "anonymous", Roles: allRoles, Method: "anonymous"}
+	}
+	return auth, nil
+}
+
+// apiKeysPath returns API_KEYS_FILE, or config/api_keys.json when it exists
+func apiKeysPath(cfg *config.Config) string {
+	if cfg.APIKeysFile != "" {
+		return cfg.APIKeysFile
+	}
+	for _, path := range []string{"config/api_keys.json", "../config/api_keys.json"} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func readAPIKeyFile(path string) (*apiKeyFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %w", err)
+	}
+	var keyFile apiKeyFile
+	if err := json.Unmarshal(data, &keyFile); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys in %s: %w", path, err)
+	}
+	return &keyFile, nil
+}
+
+func validateRoles(roles []string) error {
+	if len(roles) == 0 {
+		return errors.New("no roles")
+	}
+	for _, role := range roles {
+		known := false
+		for _, r := range allRoles {
+			known = known || r == role
+		}
+		if !known {
+			return fmt.Errorf("unknown role %q (roles: %s)", role, strings.Join(allRoles, ", "))
+		}
+	}
+	return nil
+}
+
+// authenticate returns the principal of the credentials of r
+func (a *authenticator) authenticate(r *http.Request) (*principal, error) {
+	credential := r.Header.Get("X-API-Key")
+	if credential == "" {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			if a.anonymous != nil {
+				return a.anonymous, nil
+			}
+			return nil, errors.New("missing credentials")
+		}
+		scheme, value, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, errors.New("authorization must be a Bearer API key or token")
+		}
+		credential = strings.TrimSpace(value)
+	}
+
+	if strings.HasPrefix(credential, apiKeyPrefix) {
+		sum := sha256.Sum256([]byte(credential))
+		key, ok := a.keys[hex.EncodeToString(sum[:])]
+		if !ok {
+			return nil, errors.New("unknown API key")
+		}
+		return &principal{Name: key.Name, Roles: key.Roles, Method: "api_key"}, nil
+	}
+	return a.parseToken(credential)
+}
+
+// parseToken checks a signed bearer token. Only HS256 tokens of this issuer with a
+// subject and an expiry are accepted; student tokens are refused.
+func (a *authenticator) parseToken(token string) (*principal, error) {
+	claims, err := a.parseClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	if claims.VerifyAudience(studentTokenAudience, true) {
+		return nil, errors.New("invalid token: student tokens cannot access the issuer API")
+	}
+	if err := validateRoles(claims.Roles); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return &principal{Name: claims.Subject, Roles: claims.Roles, Method: "token"}, nil
+}
+
+// parseStudentToken checks a student token and returns its student ID
+func (a *authenticator) parseStudentToken(token string) (string, error) {
+	claims, err := a.parseClaims(token)
+	if err != nil {
+		return "", err
+	}
+	if !claims.VerifyAudience(studentTokenAudience, true) || len(claims.Roles) > 0 {
+		return "", errors.New("invalid token: not a student token")
+	}
+	return claims.Subject, nil
+}
+
+// parseClaims checks the signature, issuer, subject and expiry of a token
+func (a *authenticator) parseClaims(token string) (*authClaims, error) {
+	if a.secret == nil {
+		return nil, errors.New("bearer tokens are not enabled")
+	}
+	claims := &authClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return a.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if claims.ExpiresAt == nil {
+		return nil, errors.New("invalid token: no expiry")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid token: no subject")
+	}
+	if !claims.VerifyIssuer(authTokenIssuer, true) {
+		return nil, errors.New("invalid token: wrong issuer")
+	}
+	return claims, nil
+}
+
+// issueToken signs a bearer token for subject with roles, valid for ttl
+func (a *authenticator) issueToken(subject string, roles []string, ttl time.Duration) (string, error) {
+	if err := validateRoles(roles); err != nil {
+		return "", err
+	}
+	return a.sign(authClaims{Roles: roles}, subject, ttl)
+}
+
+// issueStudentToken signs a token that lets a student read the records of their own
+// receipts, valid for ttl
+func (a *authenticator) issueStudentToken(studentID string, ttl time.Duration) (string, error) {
+	if studentID == "" {
+		return "", errors.New("student ID is required")
+	}
+	claims := authClaims{}
+	claims.Audience = jwt.ClaimStrings{studentTokenAudience}
+	return a.sign(claims, studentID, ttl)
+}
+
+// sign completes claims for subject, valid for ttl, and signs them
+func (a *authenticator) sign(claims authClaims, subject string, ttl time.Duration) (string, error) {
+	if a.secret == nil {
+		return "", errors.New("AUTH_TOKEN_SECRET is not set")
+	}
+	now := time.Now()
+	claims.Issuer = authTokenIssuer
+	claims.Subject = subject
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+}
+
+// streamTokenAudience is the audience of the stream tokens of a job
+func streamTokenAudience(jobID string) string {
+	return "job-events:" + jobID
+}
+
+// issueStreamToken signs a token that only opens the event stream of one job, valid for
+// streamTokenTTL. It travels in the URL, which is why it is scoped and short-lived.
+func issueStreamToken(subject, jobID string) (string, error) {
+	claims := authClaims{}
+	claims.Audience = jwt.ClaimStrings{streamTokenAudience(jobID)}
+	return (&authenticator{secret: streamTokenSecret}).sign(claims, subject, streamTokenTTL)
+}
+
+// parseStreamToken checks a stream token for the event stream of jobID
+func parseStreamToken(token, jobID string) (*principal, error) {
+	claims, err := (&authenticator{secret: streamTokenSecret}).parseClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.VerifyAudience(streamTokenAudience(jobID), true) || len(claims.Roles) > 0 {
+		return nil, errors.New("invalid token: not a stream token for this job")
+	}
+	return &principal{Name: claims.Subject, Roles: []string{roleAuditor}, Method: "stream_token"}, nil
+}
+
+// middleware authenticates every request and stores its principal in the context
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.authenticate(r)
+		if err != nil {
+			refuseUnauthenticated(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// protectStream authenticates the event stream of a job. Browsers cannot set headers on
+// event streams, so besides the usual credentials it takes a stream token of that job as
+// ?access_token=. No other route reads credentials from the URL.
+func (a *authenticator) protectStream(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("access_token")
+		if token == "" {
+			a.middleware(handler).ServeHTTP(w, r)
+			return
+		}
+		p, err := parseStreamToken(token, mux.Vars(r)["job_id"])
+		if err != nil {
+			refuseUnauthenticated(w, r, err)
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+func refuseUnauthenticated(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("🔒 %s %s refused: %v", r.Method, r.URL.Path, err)
+	w.Header().Set("WWW-Authenticate", `Bearer realm="iumicert-issuer"`)
+	respondJSON(w, http.StatusUnauthorized, APIResponse{Success: false, Error: "Authentication required"})
+}
+
+// protect authenticates a route outside the issuer subrouter and requires role
+func (a *authenticator) protect(role string, handler http.HandlerFunc) http.Handler {
+	return a.middleware(requireRole(role, handler))
+}
+
+// requireRole lets only principals holding role through to handler
+func requireRole(role string, handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := principalFrom(r)
+		if p == nil || !p.HasRole(role) {
+			log.Printf("🔒 %s %s refused: %s lacks role %s", r.Method, r.URL.Path, requestActor(r), role)
+			respondJSON(w, http.StatusForbidden, APIResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Role %s required", role),
+			})
+			return
+		}
+		handler(w, r)
+	})
+}
+
+type studentKey struct{}
+
+// studentFrom returns the student ID authenticated for r, or ""
+func studentFrom(r *http.Request) string {
+	studentID, _ := r.Context().Value(studentKey{}).(string)
+	return studentID
+}
+
+// studentOnly lets only requests with a student token through to handler
+func (a *authenticator) studentOnly(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		studentID, err := "", errors.New("missing student token")
+		if strings.EqualFold(scheme, "Bearer") {
+			studentID, err = a.parseStudentToken(strings.TrimSpace(token))
+		}
+		if err != nil {
+			log.Printf("🔒 %s %s refused: %v", r.Method, r.URL.Path, err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="iumicert-student"`)
+			respondJSON(w, http.StatusUnauthorized, APIResponse{Success: false, Error: "Student token required"})
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(r.Context(), studentKey{}, studentID)))
+	})
+}
+
+// handleIssueStudentToken signs a student token for the student of the route, for the
+// registrar to hand to the student
+func (a *authenticator) handleIssueStudentToken(w http.ResponseWriter, r *http.Request) {
+	studentID := mux.Vars(r)["student_id"]
+	ttl := defaultStudentTokenTTL
+	if value := r.URL.Query().Get("ttl"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 || parsed > maxStudentTokenTTL {
+			respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: fmt.Sprintf("ttl must be a duration up to %s", maxStudentTokenTTL)})
+			return
+		}
+		ttl = parsed
+	}
+	token, err := a.issueStudentToken(studentID, ttl)
+	if err != nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	log.Printf("🎫 Student token for %s issued by %s", studentID, requestActor(r))
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: map[string]interface{}{
+		"student_id": studentID,
+		"token":      token,
+		"expires_at": time.Now().Add(ttl).UTC().Format(time.RFC3339),
+	}})
+}
+
+// handleWhoAmI returns the authenticated principal
+func handleWhoAmI(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: principalFrom(r)})
+}
+
+// newAPIKey generates an API key and its entry for the keys file
+func newAPIKey(name string, roles []string) (string, apiKeyEntry, error) {
+	if err := validateRoles(roles); err != nil {
+		return "", apiKeyEntry{}, err
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", apiKeyEntry{}, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(random)
+	sum := sha256.Sum256([]byte(key))
+	return key, apiKeyEntry{
+		Name:      name,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Roles:     roles,
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage credentials of the issuer API",
+	Long: `Create API keys and signed bearer tokens for the /api/issuer routes of serve, and
+student tokens for the /api/student routes.
+
+Roles:
+  registrar  add terms, generate receipts, file revocation requests
+  approver   approve, reject and delete revocation requests
+  publisher  publish roots and process approved revocations on chain
+  auditor    read only; every principal may read`,
+}
+
+var createKeyCmd = &cobra.Command{
+	Use:   "create-key",
+	Short: "Create an API key and add its hash to the keys file",
+	Long: `Create an API key for a principal. Only the SHA-256 of the key is written to the keys
+file (API_KEYS_FILE, default config/api_keys.json); the key itself is printed once.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _ := cmd.Flags().GetString("name")
+		roles, _ := cmd.Flags().GetStringSlice("roles")
+		path, _ := cmd.Flags().GetString("file")
+
+		if path == "" {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to load configuration: %v\n", err)
+				os.Exit(1)
+			}
+			if path = apiKeysPath(cfg); path == "" {
+				path = resolveProjectPath(filepath.Join("config", "api_keys.json"))
+			}
+		}
+
+		key, err := createAPIKey(path, name, roles)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to create API key: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ API key for %s (%s) added to %s\n", name, strings.Join(roles, ", "), path)
+		fmt.Printf("🔑 %s\n", key)
+		fmt.Println("⚠️  The key is not stored and will not be shown again")
+	},
+}
+
+var issueTokenCmd = &cobra.Command{
+	Use:   "issue-token",
+	Short: "Sign a bearer token with AUTH_TOKEN_SECRET",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		subject, _ := cmd.Flags().GetString("subject")
+		roles, _ := cmd.Flags().GetStringSlice("roles")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+
+		token, err := tokenAuthenticator().issueToken(subject, roles, ttl)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to issue token: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Token for %s (%s), expires %s\n",
+			subject, strings.Join(roles, ", "), time.Now().Add(ttl).Format(time.RFC3339))
+		fmt.Println(token)
+	},
+}
+
+var issueStudentTokenCmd = &cobra.Command{
+	Use:   "issue-student-token",
+	Short: "Sign a token that lets a student see who verified their receipts",
+	Long: `Sign a student token with AUTH_TOKEN_SECRET. The token only opens /api/student routes,
+which show the student's own records such as the verifications of their receipts.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		studentID, _ := cmd.Flags().GetString("student")
+		ttl, _ := cmd.Flags().GetDuration("ttl")
+		if ttl <= 0 || ttl > maxStudentTokenTTL {
+			fmt.Fprintf(os.Stderr, "❌ --ttl must be positive and at most %s\n", maxStudentTokenTTL)
+			os.Exit(1)
+		}
+
+		token, err := tokenAuthenticator().issueStudentToken(studentID, ttl)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to issue student token: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Student token for %s, expires %s\n", studentID, time.Now().Add(ttl).Format(time.RFC3339))
+		fmt.Println(token)
+	},
+}
+
+// tokenAuthenticator returns an authenticator that signs with AUTH_TOKEN_SECRET, or exits
+func tokenAuthenticator() *authenticator {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if len(cfg.AuthTokenSecret) < minAuthTokenSecret {
+		fmt.Fprintf(os.Stderr, "❌ AUTH_TOKEN_SECRET must be set to at least %d bytes\n", minAuthTokenSecret)
+		os.Exit(1)
+	}
+	return &authenticator{secret: []byte(cfg.AuthTokenSecret)}
+}
+
+// createAPIKey adds a new key for name to the keys file at path and returns the key
+func createAPIKey(path, name string, roles []string) (string, error) {
+	keyFile := &apiKeyFile{}
+	if _, err := os.Stat(path); err == nil {
+		if keyFile, err = readAPIKeyFile(path); err != nil {
+			return "", err
+		}
+	}
+	for _, key := range keyFile.Keys {
+		if key.Name == name {
+			return "", fmt.Errorf("%s already has a key named %q", path, name)
+		}
+	}
+
+	key, entry, err := newAPIKey(name, roles)
+	if err != nil {
+		return "", err
+	}
+	keyFile.Keys = append(keyFile.Keys, entry)
+	sort.Slice(keyFile.Keys, func(i, j int) bool { return keyFile.Keys[i].Name < keyFile.Keys[j].Name })
+
+	data, err := json.MarshalIndent(keyFile, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write API keys: %w", err)
+	}
+	return key, nil
+}
+
+func init() {
+	createKeyCmd.Flags().String("name", "", "principal the key belongs to, recorded with its actions")
+	createKeyCmd.Flags().StringSlice("roles", nil, "roles of the key: registrar, approver, publisher, auditor")
+	createKeyCmd.Flags().String("file", "", "keys file (default: API_KEYS_FILE or config/api_keys.json)")
+	createKeyCmd.MarkFlagRequired("name")
+	createKeyCmd.MarkFlagRequired("roles")
+
+	issueTokenCmd.Flags().String("subject", "", "principal the token is issued to, recorded with its actions")
+	issueTokenCmd.Flags().StringSlice("roles", nil, "roles of the token: registrar, approver, publisher, auditor")
+	issueTokenCmd.Flags().Duration("ttl", 8*time.Hour, "how long the token is valid")
+	issueTokenCmd.MarkFlagRequired("subject")
+	issueTokenCmd.MarkFlagRequired("roles")
+
+	issueStudentTokenCmd.Flags().String("student", "", "student ID the token is issued to, e.g. ITITIU00001")
+	issueStudentTokenCmd.Flags().Duration("ttl", defaultStudentTokenTTL, "how long the token is valid")
+	issueStudentTokenCmd.MarkFlagRequired("student")
+
+	authCmd.AddCommand(createKeyCmd)
+	authCmd.AddCommand(issueTokenCmd)
+	authCmd.AddCommand(issueStudentTokenCmd)
+	rootCmd.AddCommand(authCmd)
+}
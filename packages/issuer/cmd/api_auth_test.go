@@ -0,0 +1,178 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"iumicert/issuer/config"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+)
+
+// TestIssuerAuthentication checks that issuer routes need an API key or a signed token and
+// that each write route needs its role
+func TestIssuerAuthentication(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "api_keys.json")
+	registrarKey, err := createAPIKey(keysFile, "registrar@iu.edu.vn", []string{roleRegistrar})
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	auditorKey, err := createAPIKey(keysFile, "auditor@iu.edu.vn", []string{roleAuditor})
+	if err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	if _, err := createAPIKey(keysFile, "auditor@iu.edu.vn", []string{roleAuditor}); err == nil {
+		t.Fatal("Second key with the same name was created")
+	}
+	if _, err := createAPIKey(keysFile, "dean@iu.edu.vn", []string{"dean"}); err == nil {
+		t.Fatal("Key with an unknown role was created")
+	}
+	keyFile, err := readAPIKeyFile(keysFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keyFile.Keys {
+		if key.SHA256 == "" || strings.Contains(key.SHA256, apiKeyPrefix) {
+			t.Fatalf("Keys file holds %+v, expected key hashes", key)
+		}
+	}
+
+	secret := strings.Repeat("s", minAuthTokenSecret)
+	auth, err := newAuthenticator(&config.Config{APIKeysFile: keysFile, AuthTokenSecret: secret})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	actor := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(requestActor(r))) }
+	r := mux.NewRouter()
+	r.Handle("/api/issuer/jobs/{job_id}/events", auth.protectStream(actor)).Methods("GET")
+	issuer := r.PathPrefix("/api/issuer").Subrouter()
+	issuer.Use(auth.middleware)
+	issuer.HandleFunc("/terms", actor).Methods("GET")
+	issuer.Handle("/terms", requireRole(roleRegistrar, actor)).Methods("POST")
+	issuer.Handle("/blockchain/publish", requireRole(rolePublisher, actor)).Methods("POST")
+	r.Handle("/api/demo/reset", auth.protect(roleRegistrar, actor)).Methods("POST")
+
+	publisherToken, err := auth.issueToken("publisher@iu.edu.vn", []string{rolePublisher}, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	expiredToken, _ := auth.issueToken("publisher@iu.edu.vn", []string{rolePublisher}, -time.Minute)
+	forgedToken, _ := (&authenticator{secret: []byte(strings.Repeat("x", minAuthTokenSecret))}).
+		issueToken("publisher@iu.edu.vn", []string{rolePublisher}, time.Hour)
+	unsignedToken, _ := jwt.NewWithClaims(jwt.SigningMethodNone, authClaims{
+		Roles: []string{rolePublisher},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    authTokenIssuer,
+			Subject:   "publisher@iu.edu.vn",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	cases := []struct {
+		name          string
+		method, path  string
+		authorization string
+		apiKey        string
+		status        int
+		actor         string
+	}{
+		{"no credentials", "GET", "/api/issuer/terms", "", "", http.StatusUnauthorized, ""},
+		{"unknown key", "GET", "/api/issuer/terms", "Bearer mck_0000", "", http.StatusUnauthorized, ""},
+		{"auditor reads", "GET", "/api/issuer/terms", "", auditorKey, http.StatusOK, "auditor@iu.edu.vn"},
+		{"auditor cannot add term", "POST", "/api/issuer/terms", "Bearer " + auditorKey, "", http.StatusForbidden, ""},
+		{"registrar adds term", "POST", "/api/issuer/terms", "", registrarKey, http.StatusOK, "registrar@iu.edu.vn"},
+		{"registrar cannot publish", "POST", "/api/issuer/blockchain/publish", "Bearer " + registrarKey, "", http.StatusForbidden, ""},
+		{"publisher publishes", "POST", "/api/issuer/blockchain/publish", "Bearer " + publisherToken, "", http.StatusOK, "publisher@iu.edu.vn"},
+		{"publisher cannot reset", "POST", "/api/demo/reset", "Bearer " + publisherToken, "", http.StatusForbidden, ""},
+		{"reset needs credentials", "POST", "/api/demo/reset", "", "", http.StatusUnauthorized, ""},
+		{"expired token", "POST", "/api/issuer/blockchain/publish", "Bearer " + expiredToken, "", http.StatusUnauthorized, ""},
+		{"token of another secret", "POST", "/api/issuer/blockchain/publish", "Bearer " + forgedToken, "", http.StatusUnauthorized, ""},
+		{"unsigned token", "POST", "/api/issuer/blockchain/publish", "Bearer " + unsignedToken, "", http.StatusUnauthorized, ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		if c.authorization != "" {
+			req.Header.Set("Authorization", c.authorization)
+		}
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != c.status {
+			t.Fatalf("%s: status %d, expected %d (%s)", c.name, rec.Code, c.status, rec.Body.String())
+		}
+		if c.actor != "" && rec.Body.String() != c.actor {
+			t.Fatalf("%s: handler saw %q, expected %q", c.name, rec.Body.String(), c.actor)
+		}
+	}
+
+	// Only the event stream of a job takes a credential in the URL, and only its stream token
+	streamToken, err := issueStreamToken("auditor@iu.edu.vn", "job-1")
+	if err != nil {
+		t.Fatalf("Failed to issue stream token: %v", err)
+	}
+	urlCases := []struct {
+		name   string
+		path   string
+		apiKey string
+		status int
+	}{
+		{"stream token opens its job", "/api/issuer/jobs/job-1/events?access_token=" + streamToken, "", http.StatusOK},
+		{"stream token of another job", "/api/issuer/jobs/job-2/events?access_token=" + streamToken, "", http.StatusUnauthorized},
+		{"API key in the stream URL", "/api/issuer/jobs/job-1/events?access_token=" + auditorKey, "", http.StatusUnauthorized},
+		{"stream token on another route", "/api/issuer/terms?access_token=" + streamToken, "", http.StatusUnauthorized},
+		{"API key in another URL", "/api/issuer/terms?access_token=" + auditorKey, "", http.StatusUnauthorized},
+		{"stream with API key header", "/api/issuer/jobs/job-1/events", auditorKey, http.StatusOK},
+	}
+	for _, c := range urlCases {
+		req := httptest.NewRequest("GET", c.path, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != c.status {
+			t.Fatalf("%s: status %d, expected %d (%s)", c.name, rec.Code, c.status, rec.Body.String())
+		}
+	}
+	req := httptest.NewRequest("GET", "/api/issuer/terms", nil)
+	req.Header.Set("Authorization", "Bearer "+streamToken)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Stream token as bearer token: status %d", rec.Code)
+	}
+	t.Logf("✅ %d authentication cases behaved as expected", len(cases)+len(urlCases)+1)
+}
+
+// TestAuthenticatorWithoutCredentials checks that the issuer API only runs open when
+// AUTH_DISABLED asks for it outside production
+func TestAuthenticatorWithoutCredentials(t *testing.T) {
+	if _, err := newAuthenticator(&config.Config{Environment: "production", AuthDisabled: true}); err == nil {
+		t.Fatal("Production issuer API started without credentials")
+	}
+	if _, err := newAuthenticator(&config.Config{Environment: "development"}); err == nil {
+		t.Fatal("Development issuer API started open without AUTH_DISABLED")
+	}
+	if _, err := newAuthenticator(&config.Config{AuthTokenSecret: "short"}); err == nil {
+		t.Fatal("Short token secret was accepted")
+	}
+
+	auth, err := newAuthenticator(&config.Config{Environment: "development", AuthDisabled: true})
+	if err != nil {
+		t.Fatalf("Development issuer API with AUTH_DISABLED refused to start: %v", err)
+	}
+	p, err := auth.authenticate(httptest.NewRequest("POST", "/api/issuer/revocations/process", nil))
+	if err != nil || !p.HasRole(rolePublisher) || p.Method != "anonymous" {
+		t.Fatalf("Development request authenticated as %+v (%v)", p, err)
+	}
+	t.Logf("✅ Open only with AUTH_DISABLED as %s, refused otherwise and in production", p.Name)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"iumicert/issuer/database"
+)
+
+// PageInfo tells the client of a list endpoint how to get the next page
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"` // Pass as ?cursor= with the same sort and filters
+	HasMore    bool   `json:"has_more"`
+}
+
+// parseListOptions reads the paging, sorting and filter parameters of a list endpoint:
+// limit, cursor, sort, student_id, term_id, status, from and to. Dates are RFC 3339 times
+// or YYYY-MM-DD days in UTC; from is inclusive, to is exclusive.
+func parseListOptions(r *http.Request) (database.ListOptions, error) {
+	query := r.URL.Query()
+	opts := database.ListOptions{
+		Limit:     database.DefaultListLimit,
+		Cursor:    query.Get("cursor"),
+		Sort:      query.Get("sort"),
+		StudentID: query.Get("student_id"),
+		TermID:    query.Get("term_id"),
+		Status:    query.Get("status"),
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > database.MaxListLimit {
+			return opts, fmt.Errorf("limit must be between 1 and %d", database.MaxListLimit)
+		}
+		opts.Limit = limit
+	}
+	for name, bound := range map[string]**time.Time{"from": &opts.From, "to": &opts.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return opts, fmt.Errorf("%s must be an RFC 3339 time or a YYYY-MM-DD date", name)
+			}
+		}
+		*bound = &t
+	}
+	if opts.From != nil && opts.To != nil && !opts.From.Before(*opts.To) {
+		return opts, fmt.Errorf("from must be before to")
+	}
+	return opts, nil
+}
+
+// newPageInfo describes the page of opts that ended at next
+func newPageInfo(opts database.ListOptions, next string) *PageInfo {
+	return &PageInfo{Limit: opts.Limit, Sort: opts.Sort, NextCursor: next, HasMore: next != ""}
+}
+
+// respondListError answers a failed list query: 400 for bad paging, sorting or filters,
+// 500 otherwise
+func respondListError(w http.ResponseWriter, what string, err error) {
+	if errors.Is(err, database.ErrInvalidCursor) || errors.Is(err, database.ErrInvalidSort) || errors.Is(err, database.ErrInvalidFilter) {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	log.Printf("❌ Failed to list %s: %v", what, err)
+	respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: fmt.Sprintf("Failed to list %s", what)})
+}
+
+// termDates derives the dates of a term from its ID: Semester_1_YYYY runs August to
+// December, Semester_2_YYYY January to May of the next year and Summer_YYYY May to August.
+// Other terms get the whole of 2025.
+func termDates(termID string) (time.Time, time.Time) {
+	parts := strings.Split(termID, "_")
+	year := 2023 // default fallback
+	if len(parts) > 1 {
+		if y, err := strconv.Atoi(parts[len(parts)-1]); err == nil && y > 0 {
+			year = y
+		}
+	}
+	date := func(year int, month time.Month) time.Time {
+		return time.Date(year, month, 15, 0, 0, 0, 0, time.UTC)
+	}
+
+	switch {
+	case strings.Contains(termID, "Semester_1"):
+		return date(year, time.August), date(year, time.December)
+	case strings.Contains(termID, "Semester_2"):
+		return date(year+1, time.January), date(year+1, time.May)
+	case strings.Contains(termID, "Summer"):
+		return date(year, time.May), date(year, time.August)
+	default:
+		return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+}
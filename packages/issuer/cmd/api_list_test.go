@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"iumicert/issuer/database"
+)
+
+// TestParseListOptions checks the defaults, date forms and rejected parameters of list endpoints
+func TestParseListOptions(t *testing.T) {
+	opts, err := parseListOptions(httptest.NewRequest("GET", "/api/issuer/receipts", nil))
+	if err != nil || opts.Limit != database.DefaultListLimit || opts.From != nil {
+		t.Fatalf("Defaults parsed as %+v (%v)", opts, err)
+	}
+
+	opts, err = parseListOptions(httptest.NewRequest("GET",
+		"/api/issuer/receipts?limit=10&sort=-student_id&term_id=Semester_1_2023&status=published&from=2023-08-01&to=2023-12-31T12:00:00Z", nil))
+	if err != nil {
+		t.Fatalf("Failed to parse options: %v", err)
+	}
+	if opts.Limit != 10 || opts.Sort != "-student_id" || opts.TermID != "Semester_1_2023" || opts.Status != "published" ||
+		!opts.From.Equal(time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)) || !opts.To.Equal(time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Options parsed as %+v", opts)
+	}
+
+	for _, query := range []string{"limit=0", "limit=501", "limit=ten", "from=yesterday", "from=2024-01-01&to=2023-01-01"} {
+		if _, err := parseListOptions(httptest.NewRequest("GET", "/api/issuer/students?"+query, nil)); err == nil {
+			t.Fatalf("Accepted %s", query)
+		}
+	}
+
+	if start, end := termDates("Semester_2_2023"); start.Year() != 2024 || start.Month() != time.January || end.Month() != time.May {
+		t.Fatalf("Semester_2_2023 runs %s to %s", start, end)
+	}
+	t.Logf("✅ List options parsed and bad parameters rejected")
+}
@@ -1,17 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 
+	"iumicert/crypto/verkle"
 	"iumicert/issuer/database"
 	blockchain_integration "iumicert/issuer/blockchain_integration"
 	"iumicert/issuer/config"
@@ -58,15 +62,16 @@ func validateCredentialExists(studentID, termID, courseID string) error {
 	return nil
 }
 
-// handleCreateRevocationRequest creates a new revocation request (ADMIN ONLY)
-// This is called after registrar validates student complaint through official channels
+// handleCreateRevocationRequest creates a new revocation request (REGISTRAR ONLY)
+// This is called after registrar validates student complaint through official channels.
+// The request waits for an approver, unless the registrar is an approver as well.
 func handleCreateRevocationRequest(w http.ResponseWriter, r *http.Request) {
 	var request struct {
 		StudentID   string `json:"student_id"`
 		TermID      string `json:"term_id"`
 		CourseID    string `json:"course_id"`
 		Reason      string `json:"reason"`
-		RequestedBy string `json:"requested_by"` // Admin username
+		RequestedBy string `json:"requested_by"` // Only used when the API runs without authentication
 		Notes       string `json:"notes"`        // Additional context
 	}
 
@@ -110,7 +115,7 @@ func handleCreateRevocationRequest(w http.ResponseWriter, r *http.Request) {
 	var existingRevocation database.RevocationRequest
 	err = db.Where("student_id = ? AND term_id = ? AND course_id = ? AND status IN (?)",
 		request.StudentID, request.TermID, request.CourseID,
-		[]string{"approved", "processed"}).First(&existingRevocation).Error
+		[]string{"pending", "approved", "processed"}).First(&existingRevocation).Error
 
 	if err == nil {
 		// Found existing revocation
@@ -122,19 +127,28 @@ func handleCreateRevocationRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create revocation request (already validated by registrar)
+	// The authenticated principal requests the revocation
+	requestedBy := requestActor(r)
+	if p := principalFrom(r); (p == nil || p.Method == "anonymous") && request.RequestedBy != "" {
+		requestedBy = request.RequestedBy
+	}
+
 	revocationReq := &database.RevocationRequest{
 		RequestID:   fmt.Sprintf("revoke_req_%s", uuid.New().String()),
 		StudentID:   request.StudentID,
 		TermID:      request.TermID,
 		CourseID:    request.CourseID,
 		Reason:      request.Reason,
-		RequestedBy: request.RequestedBy,
-		Status:      "approved", // Directly approved since registrar already validated
-		ApprovedBy:  request.RequestedBy,
-		ApprovedAt:  timePtr(time.Now()),
+		RequestedBy: requestedBy,
+		Status:      "pending",
 		Notes:       request.Notes,
 	}
+	if p := principalFrom(r); p != nil && p.HasRole(roleApprover) {
+		// Approvers filing a request approve it themselves
+		revocationReq.Status = "approved"
+		revocationReq.ApprovedBy = requestedBy
+		revocationReq.ApprovedAt = timePtr(time.Now())
+	}
 
 	if err := database.CreateRevocationRequest(db, revocationReq); err != nil {
 		log.Printf("❌ Failed to create revocation request: %v", err)
@@ -145,24 +159,38 @@ func handleCreateRevocationRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("✅ Revocation request created (approved): %s for %s/%s/%s",
-		revocationReq.RequestID, request.StudentID, request.TermID, request.CourseID)
+	log.Printf("✅ Revocation request created (%s) by %s: %s for %s/%s/%s",
+		revocationReq.Status, requestedBy, revocationReq.RequestID, request.StudentID, request.TermID, request.CourseID)
 
+	message := "Revocation request created and approved. Will be processed during next term publication."
+	if revocationReq.Status == "pending" {
+		message = "Revocation request created. It will be processed once an approver approves it."
+	}
 	respondJSON(w, http.StatusCreated, APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
-			"message":    "Revocation request created and approved. Will be processed during next term publication.",
-			"request_id": revocationReq.RequestID,
-			"status":     "approved",
-			"note":       "This will be automatically processed when the next term is published.",
+			"message":      message,
+			"request_id":   revocationReq.RequestID,
+			"status":       revocationReq.Status,
+			"requested_by": requestedBy,
+			"note":         "Approved requests are processed automatically when the next term is published.",
 		},
 	})
 }
 
-// handleListRevocationRequests lists all revocation requests with optional filters
-func handleListRevocationRequests(w http.ResponseWriter, r *http.Request) {
-	termID := r.URL.Query().Get("term_id")
-	status := r.URL.Query().Get("status")
+// handleApproveRevocationRequest approves a pending revocation request (APPROVER ONLY)
+func handleApproveRevocationRequest(w http.ResponseWriter, r *http.Request) {
+	decideRevocationRequest(w, r, "approved")
+}
+
+// handleRejectRevocationRequest rejects a pending revocation request (APPROVER ONLY)
+func handleRejectRevocationRequest(w http.ResponseWriter, r *http.Request) {
+	decideRevocationRequest(w, r, "rejected")
+}
+
+// decideRevocationRequest records the decision of the authenticated approver
+func decideRevocationRequest(w http.ResponseWriter, r *http.Request, status string) {
+	requestID := mux.Vars(r)["request_id"]
 
 	db, err := database.Connect()
 	if err != nil {
@@ -173,15 +201,58 @@ func handleListRevocationRequests(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	requests, err := database.GetAllRevocationRequests(db, termID, status)
+	decidedBy := requestActor(r)
+	err = database.UpdateRevocationStatus(db, requestID, status, decidedBy)
+	if errors.Is(err, database.ErrRevocationNotPending) {
+		respondJSON(w, http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Revocation request %s does not exist or is not pending", requestID),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to update revocation request %s: %v", requestID, err)
+		respondJSON(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Failed to update revocation request",
+		})
+		return
+	}
+
+	log.Printf("✅ Revocation request %s %s by %s", requestID, status, decidedBy)
+	respondJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"request_id": requestID,
+			"status":     status,
+			"decided_by": decidedBy,
+		},
+	})
+}
+
+// handleListRevocationRequests lists a page of revocation requests with optional filters
+func handleListRevocationRequests(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+
+	db, err := database.Connect()
 	if err != nil {
-		log.Printf("❌ Failed to get revocation requests: %v", err)
 		respondJSON(w, http.StatusInternalServerError, APIResponse{
 			Success: false,
-			Error:   "Failed to retrieve revocation requests",
+			Error:   "Database connection failed",
 		})
 		return
 	}
+	defer database.Close(db)
+
+	requests, next, err := database.NewReceiptRepository(db).ListRevocationRequests(opts)
+	if err != nil {
+		respondListError(w, "revocation requests", err)
+		return
+	}
 
 	respondJSON(w, http.StatusOK, APIResponse{
 		Success: true,
@@ -189,6 +260,7 @@ func handleListRevocationRequests(w http.ResponseWriter, r *http.Request) {
 			"requests": requests,
 			"count":    len(requests),
 		},
+		Page: newPageInfo(opts, next),
 	})
 }
 
@@ -292,6 +364,71 @@ func handleGetTermVersionHistory(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleGetRevocationStats gets revocation statistics
+// handleGetRevocationDeltaProof returns the delta proof stored with a revocation batch
+// together with a server-side check against the batch's old and new roots
+func handleGetRevocationDeltaProof(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	batchID := vars["batch_id"]
+
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Database connection failed",
+		})
+		return
+	}
+
+	batch, err := database.GetRevocationBatch(db, batchID)
+	if err != nil {
+		respondJSON(w, http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Revocation batch %s not found", batchID),
+		})
+		return
+	}
+
+	if len(batch.DeltaProof) == 0 {
+		respondJSON(w, http.StatusNotFound, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Revocation batch %s has no delta proof", batchID),
+		})
+		return
+	}
+
+	var delta verkle.RevocationDeltaProof
+	if err := json.Unmarshal(batch.DeltaProof, &delta); err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{
+			Success: false,
+			Error:   "Stored delta proof is corrupted",
+		})
+		return
+	}
+
+	verification := map[string]interface{}{"verified": true}
+	oldRoot, errOld := parseVerkleRoot(batch.OldRootHash)
+	newRoot, errNew := parseVerkleRoot(batch.NewRootHash)
+	if errOld != nil || errNew != nil {
+		verification = map[string]interface{}{"verified": false, "error": "Invalid root hashes in batch record"}
+	} else if err := verkle.VerifyRevocationDeltaProof(&delta, oldRoot, newRoot); err != nil {
+		verification = map[string]interface{}{"verified": false, "error": err.Error()}
+	}
+
+	respondJSON(w, http.StatusOK, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"batch_id":      batch.BatchID,
+			"term_id":       batch.TermID,
+			"old_version":   batch.OldVersion,
+			"new_version":   batch.NewVersion,
+			"old_root_hash": batch.OldRootHash,
+			"new_root_hash": batch.NewRootHash,
+			"delta_proof":   batch.DeltaProof,
+			"verification":  verification,
+		},
+	})
+}
+
 func handleGetRevocationStats(w http.ResponseWriter, r *http.Request) {
 	db, err := database.Connect()
 	if err != nil {
@@ -348,7 +485,7 @@ func handleDeleteRevocationRequest(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleProcessRevocations processes all approved revocations
+// handleProcessRevocations queues a job that processes all approved revocations
 // This is called automatically after any term is published via the dashboard
 func handleProcessRevocations(w http.ResponseWriter, r *http.Request) {
 	log.Printf("🔄 API: Processing approved revocations...")
@@ -364,20 +501,19 @@ func handleProcessRevocations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if private key is configured
-	if cfg.IssuerPrivateKey == "" {
-		log.Printf("⚠️  No issuer private key configured, skipping revocation processing")
+	// Check if a signer is configured
+	if !cfg.HasSigner() {
+		log.Printf("⚠️  No issuer signer configured, skipping revocation processing")
 		respondJSON(w, http.StatusOK, APIResponse{
 			Success: true,
 			Data: map[string]interface{}{
-				"message":   "Skipped - no private key configured",
+				"message":   "Skipped - no signer configured",
 				"processed": 0,
 			},
 		})
 		return
 	}
 
-	// Get all approved revocations
 	db, err := database.Connect()
 	if err != nil {
 		log.Printf("❌ Database connection failed: %v", err)
@@ -387,10 +523,10 @@ func handleProcessRevocations(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	defer database.Close(db)
 
-	var approvedRevocations []database.RevocationRequest
-	err = db.Where("status = ?", "approved").Find(&approvedRevocations).Error
-	if err != nil {
+	var count int64
+	if err := db.Model(&database.RevocationRequest{}).Where("status = ?", "approved").Count(&count).Error; err != nil {
 		log.Printf("❌ Failed to get approved revocations: %v", err)
 		respondJSON(w, http.StatusInternalServerError, APIResponse{
 			Success: false,
@@ -399,7 +535,7 @@ func handleProcessRevocations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(approvedRevocations) == 0 {
+	if count == 0 {
 		log.Printf("✅ No pending revocations to process")
 		respondJSON(w, http.StatusOK, APIResponse{
 			Success: true,
@@ -411,28 +547,66 @@ func handleProcessRevocations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Group revocations by term
-	revocationsByTerm := make(map[string][]database.RevocationRequest)
-	for _, rev := range approvedRevocations {
-		revocationsByTerm[rev.TermID] = append(revocationsByTerm[rev.TermID], rev)
+	respondJobQueued(w, r, jobProcessRevocations, struct{}{})
+}
+
+// runProcessRevocationsJob supersedes every term with approved revocations, one step per
+// term. A term that fails does not stop the others.
+func runProcessRevocationsJob(ctx context.Context, job *jobRun) (interface{}, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.HasSigner() {
+		return map[string]interface{}{
+			"message":   "Skipped - no signer configured",
+			"processed": 0,
+		}, nil
 	}
 
-	log.Printf("📋 Found %d approved revocations across %d terms",
-		len(approvedRevocations), len(revocationsByTerm))
+	db, err := database.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+	defer database.Close(db)
+
+	revocationsByTerm, err := approvedRevocationsByTerm(db)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make([]string, 0, len(revocationsByTerm))
+	approvedCount := 0
+	for termID, revocations := range revocationsByTerm {
+		terms = append(terms, termID)
+		approvedCount += len(revocations)
+	}
+	sort.Strings(terms)
+
+	steps := make([]string, 0, len(terms))
+	for _, termID := range terms {
+		steps = append(steps, fmt.Sprintf("Supersede %s", termID))
+	}
+	job.Plan(steps...)
+
+	log.Printf("📋 Found %d approved revocations across %d terms", approvedCount, len(terms))
 
 	// Process each term with revocations
 	processedCount := 0
-	var errors []string
+	var failures []string
 
-	for termID, revocations := range revocationsByTerm {
-		log.Printf("🔄 Processing %d revocations for term: %s", len(revocations), termID)
+	for _, termID := range terms {
+		revocations := revocationsByTerm[termID]
+		job.Step(fmt.Sprintf("Supersede %s", termID))
+		job.Logf("Processing %d revocations", len(revocations))
 
 		// Execute revocation by rebuilding tree and publishing new version
-		err := supersedeTermWithRevocations(termID, revocations, cfg, db)
+		err := supersedeTermWithRevocations(termID, revocations, cfg, db, job.Actor())
 		if err != nil {
 			errMsg := fmt.Sprintf("Failed to process revocations for term %s: %v", termID, err)
 			log.Printf("❌ %s", errMsg)
-			errors = append(errors, errMsg)
+			failures = append(failures, errMsg)
+			job.StepFailed(err)
 			continue
 		}
 
@@ -440,22 +614,20 @@ func handleProcessRevocations(w http.ResponseWriter, r *http.Request) {
 		log.Printf("✅ Successfully processed revocations for term %s", termID)
 	}
 
-	// Return result
 	result := map[string]interface{}{
-		"message":      fmt.Sprintf("Processed %d revocations across %d terms", processedCount, len(revocationsByTerm)),
-		"processed":    processedCount,
-		"terms_affected": len(revocationsByTerm),
+		"message":        fmt.Sprintf("Processed %d revocations across %d terms", processedCount, len(terms)),
+		"processed":      processedCount,
+		"terms_affected": len(terms),
 	}
 
-	if len(errors) > 0 {
-		result["errors"] = errors
+	if len(failures) > 0 && processedCount == 0 {
+		return nil, fmt.Errorf("revocations of all %d terms failed", len(terms))
+	}
+	if len(failures) > 0 {
+		result["errors"] = failures
 		result["partial_success"] = true
 	}
-
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data:    result,
-	})
+	return result, nil
 }
 
 // Helper function
@@ -3,26 +3,32 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strings"
+	"syscall"
 	"time"
 
+	"iumicert/crypto/receipt"
 	"iumicert/crypto/testdata"
+	"iumicert/crypto/verifier"
 	"iumicert/crypto/verkle"
 	blockchain_integration "iumicert/issuer/blockchain_integration"
 	"iumicert/issuer/config"
 	"iumicert/issuer/database"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	"github.com/spf13/cobra"
-	"gorm.io/datatypes"
 )
 
 var serveCmd = &cobra.Command{
@@ -32,8 +38,26 @@ var serveCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		port, _ := cmd.Flags().GetString("port")
 		cors_enabled, _ := cmd.Flags().GetBool("cors")
+		indexChain, _ := cmd.Flags().GetBool("index-chain")
+		watchReorgs, _ := cmd.Flags().GetBool("watch-reorgs")
+		deliverWebhooks, _ := cmd.Flags().GetBool("deliver-webhooks")
+
+		// Background workers stop and the server shuts down on interrupt
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// Keep term_root_versions in sync with registry events
+		if indexChain {
+			startBackgroundIndexer(ctx)
+		}
+		if watchReorgs {
+			startBackgroundReorgWatcher(ctx)
+		}
+		if deliverWebhooks {
+			startBackgroundWebhooks(ctx)
+		}
 		
-		if err := startAPIServer(port, cors_enabled); err != nil {
+		if err := startAPIServer(ctx, port, cors_enabled); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Failed to start server: %v\n", err)
 			os.Exit(1)
 		}
@@ -44,6 +68,7 @@ type APIResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Page    *PageInfo   `json:"page,omitempty"` // Set by list endpoints
 }
 
 type TermRequest struct {
@@ -58,13 +83,16 @@ type ReceiptRequest struct {
 	StudentID  string   `json:"student_id"`
 	Terms      []string `json:"terms,omitempty"`
 	Courses    []string `json:"courses,omitempty"`
+	Fields     []string `json:"fields,omitempty"`
 	Selective  bool     `json:"selective"`
+	BatchProof bool     `json:"batch_proof"`
 }
 
 type PublishRequest struct {
 	TermID     string `json:"term_id"`
 	Network    string `json:"network"`
 	GasLimit   uint64 `json:"gas_limit"`
+	DryRun     bool   `json:"dry_run"` // Only estimate gas and fees
 }
 
 type SystemStatus struct {
@@ -136,7 +164,29 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-func startAPIServer(port string, corsEnabled bool) error {
+// Flush lets event streams through the wrapper
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// serverShutdownTimeout bounds how long requests in flight may finish on shutdown
+const serverShutdownTimeout = 10 * time.Second
+
+func startAPIServer(ctx context.Context, port string, corsEnabled bool) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	auth, err := newAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
+	
+	// Long-running operations run as jobs, outside the request that started them
+	issuerJobs = startJobQueue(context.Background())
+	
 	r := mux.NewRouter()
 	
 	// Apply middleware to all routes
@@ -150,27 +200,41 @@ func startAPIServer(port string, corsEnabled bool) error {
 	api.HandleFunc("/status", handleSystemStatus).Methods("GET")
 	api.HandleFunc("/health", handleHealth).Methods("GET")
 	
-	// Issuer-only endpoints (for institution dashboard)
+	// Job event streams also take a stream token in the URL, so they sit outside the issuer subrouter
+	api.Handle("/issuer/jobs/{job_id}/events", auth.protectStream(handleJobEvents)).Methods("GET") // Server-sent events
+
+	// Issuer-only endpoints (for institution dashboard), authenticated; writes need a role
 	issuer := api.PathPrefix("/issuer").Subrouter()
-	issuer.HandleFunc("/terms", handleAddTerm).Methods("POST")
+	issuer.Use(auth.middleware)
+	issuer.HandleFunc("/whoami", handleWhoAmI).Methods("GET")
+	issuer.HandleFunc("/jobs", handleListJobs).Methods("GET")
+	issuer.HandleFunc("/jobs/{job_id}", handleGetJob).Methods("GET")
+	issuer.HandleFunc("/webhooks", handleListWebhooks).Methods("GET")
+	issuer.HandleFunc("/webhooks/deliveries", handleListWebhookDeliveries).Methods("GET")
+	issuer.HandleFunc("/webhooks/{subscription_id}/deliveries", handleListWebhookDeliveries).Methods("GET")
+	issuer.Handle("/terms", requireRole(roleRegistrar, handleAddTerm)).Methods("POST")
 	issuer.HandleFunc("/terms", handleListTerms).Methods("GET")
 	issuer.HandleFunc("/terms/{term_id}/receipts", handleGetTermReceipts).Methods("GET")
 	issuer.HandleFunc("/terms/{term_id}/roots", handleGetTermRoot).Methods("GET")
 
 	// New: Process uploaded term data (Data Management Panel)
-	api.HandleFunc("/terms/process", handleProcessTermData).Methods("POST")
-	api.HandleFunc("/demo/generate-term", handleGenerateDemoTerm).Methods("POST")
-	api.HandleFunc("/demo/reset", handleDemoReset).Methods("POST")
-	api.HandleFunc("/demo/generate-full", handleDemoGenerateFull).Methods("POST")
-	issuer.HandleFunc("/receipts", handleGenerateReceipt).Methods("POST")
+	api.Handle("/terms/process", auth.protect(roleRegistrar, handleProcessTermData)).Methods("POST")
+	api.Handle("/demo/generate-term", auth.protect(roleRegistrar, handleGenerateDemoTerm)).Methods("POST")
+	api.Handle("/demo/reset", auth.protect(roleRegistrar, handleDemoReset)).Methods("POST")
+	api.Handle("/demo/generate-full", auth.protect(roleRegistrar, handleDemoGenerateFull)).Methods("POST")
+	issuer.Handle("/receipts", requireRole(roleRegistrar, handleGenerateReceipt)).Methods("POST")
 	issuer.HandleFunc("/receipts", handleListReceipts).Methods("GET")
-	issuer.HandleFunc("/blockchain/publish", handlePublishRoots).Methods("POST")
+	issuer.Handle("/blockchain/publish", requireRole(rolePublisher, handlePublishRoots)).Methods("POST")
 	issuer.HandleFunc("/blockchain/transactions", handleListTransactions).Methods("GET")
 	issuer.HandleFunc("/blockchain/transactions/{tx_hash}", handleGetTransaction).Methods("GET")
 	issuer.HandleFunc("/blockchain/roots", handleGetPublishedRoots).Methods("GET")
 	issuer.HandleFunc("/students", handleListStudents).Methods("GET")
 	issuer.HandleFunc("/students/{student_id}/terms", handleGetStudentTerms).Methods("GET")
 	issuer.HandleFunc("/students/{student_id}/journey", handleGetStudentJourney).Methods("GET")
+	issuer.Handle("/students/{student_id}/access-token", requireRole(roleRegistrar, auth.handleIssueStudentToken)).Methods("POST")
+	issuer.Handle("/absence", requireRole(roleRegistrar, handleGenerateAbsenceProof)).Methods("POST")  // Prove a course is not in a term
+	issuer.HandleFunc("/verifications", handleListVerifications).Methods("GET")                // Verifier call audit log
+	issuer.HandleFunc("/verifications/analytics", handleVerificationAnalytics).Methods("GET")
 
 	// Database-backed receipt endpoints (NEW)
 	issuer.HandleFunc("/students/{student_id}/receipts/latest", handleGetLatestReceipts).Methods("GET")
@@ -180,32 +244,43 @@ func startAPIServer(port string, corsEnabled bool) error {
 
 	// Revocation endpoints (Admin-only - realistic workflow)
 	// Note: Students contact institution through official channels (email, forms, in-person)
-	// Registrar validates and enters approved requests here
-	issuer.HandleFunc("/revocations", handleCreateRevocationRequest).Methods("POST")         // Create approved request
+	// Registrar enters requests here, an approver approves them before they are processed
+	issuer.Handle("/revocations", requireRole(roleRegistrar, handleCreateRevocationRequest)).Methods("POST")  // Create request
 	issuer.HandleFunc("/revocations", handleListRevocationRequests).Methods("GET")           // List all requests
 	issuer.HandleFunc("/revocations/stats", handleGetRevocationStats).Methods("GET")         // Get statistics
-	issuer.HandleFunc("/revocations/process", handleProcessRevocations).Methods("POST")      // Process all approved revocations
-	issuer.HandleFunc("/revocations/{request_id}", handleDeleteRevocationRequest).Methods("DELETE")  // Delete request
+	issuer.Handle("/revocations/process", requireRole(rolePublisher, handleProcessRevocations)).Methods("POST")  // Process all approved revocations
+	issuer.HandleFunc("/revocations/batches/{batch_id}/delta-proof", handleGetRevocationDeltaProof).Methods("GET")  // Old -> new root proof
+	issuer.Handle("/revocations/{request_id}/approve", requireRole(roleApprover, handleApproveRevocationRequest)).Methods("POST")
+	issuer.Handle("/revocations/{request_id}/reject", requireRole(roleApprover, handleRejectRevocationRequest)).Methods("POST")
+	issuer.Handle("/revocations/{request_id}", requireRole(roleApprover, handleDeleteRevocationRequest)).Methods("DELETE")  // Delete request
 	issuer.HandleFunc("/terms/{term_id}/revocations", handleGetPendingRevocations).Methods("GET")    // Get approved for term
 	issuer.HandleFunc("/terms/{term_id}/versions", handleGetTermVersionHistory).Methods("GET")       // Get version history
 
 	// Verifier endpoints (public - for students/employers)
 	verifier := api.PathPrefix("/verifier").Subrouter()
+	verifier.Use(auditVerifications)
 	verifier.HandleFunc("/receipt", handleVerifyReceipt).Methods("POST")
+	verifier.HandleFunc("/receipt/schema", handleGetReceiptSchema).Methods("GET")  // Journey receipt JSON Schema
 	verifier.HandleFunc("/course", handleVerifyCourse).Methods("POST")
 	verifier.HandleFunc("/ipa-verify", handleIPAVerify).Methods("POST")  // Full IPA cryptographic verification
+	verifier.HandleFunc("/absence/verify", handleVerifyAbsenceProof).Methods("POST")  // Verify an absence proof
 	verifier.HandleFunc("/receipt/{receipt_id}", handleGetReceiptByID).Methods("GET")
 	verifier.HandleFunc("/journey/{student_id}", handleGetStudentJourney).Methods("GET")
 	verifier.HandleFunc("/blockchain/transaction/{tx_hash}", handleGetTransaction).Methods("GET")
 	verifier.HandleFunc("/blockchain/roots", handleGetPublishedRoots).Methods("GET")
+
+	// Student endpoints, opened by student tokens
+	student := api.PathPrefix("/student").Subrouter()
+	student.Handle("/verifications", auth.studentOnly(handleStudentVerifications)).Methods("GET")  // Who verified my receipts
+	student.Handle("/absence", auth.studentOnly(handleGenerateAbsenceProof)).Methods("POST")  // Prove I did not take a course
 	
 	// Legacy endpoints (maintain backward compatibility for current issuer dashboard)
 	api.HandleFunc("/terms", handleListTerms).Methods("GET")
 	api.HandleFunc("/terms/{term_id}/roots", handleGetTermRoot).Methods("GET")
-	api.HandleFunc("/terms/{term_id}/blockchain", handleUpdateTermBlockchainStatus).Methods("PUT")
-	api.HandleFunc("/receipts/verify", handleVerifyReceipt).Methods("POST")
-	api.HandleFunc("/receipts/verify-course", handleVerifyCourse).Methods("POST")
-	api.HandleFunc("/blockchain/publish", handlePublishRoots).Methods("POST")
+	api.Handle("/terms/{term_id}/blockchain", auth.protect(rolePublisher, handleUpdateTermBlockchainStatus)).Methods("PUT")
+	api.Handle("/receipts/verify", auditVerifications(http.HandlerFunc(handleVerifyReceipt))).Methods("POST")
+	api.Handle("/receipts/verify-course", auditVerifications(http.HandlerFunc(handleVerifyCourse))).Methods("POST")
+	api.Handle("/blockchain/publish", auth.protect(rolePublisher, handlePublishRoots)).Methods("POST")
 	api.HandleFunc("/blockchain/transactions", handleListTransactions).Methods("GET")
 	api.HandleFunc("/blockchain/roots", handleGetPublishedRoots).Methods("GET")
 	
@@ -257,7 +332,24 @@ func startAPIServer(port string, corsEnabled bool) error {
 	fmt.Printf("  POST /api/blockchain/publish - Publish to blockchain\n")
 	fmt.Printf("  GET  /api/students         - List students\n")
 	
-	return http.ListenAndServe(":"+port, handler)
+	server := &http.Server{Addr: ":" + port, Handler: handler}
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("⚠️  API server shutdown: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-shutdown
+	log.Printf("🛑 API server stopped")
+	return nil
 }
 
 func handleSystemStatus(w http.ResponseWriter, r *http.Request) {
@@ -360,7 +452,7 @@ func handleAddTerm(w http.ResponseWriter, r *http.Request) {
 		dataFile = req.DataFile
 	}
 	
-	if err := addAcademicTerm(req.TermID, dataFile, format, req.Validate); err != nil {
+	if err := addAcademicTerm(req.TermID, dataFile, format, req.Validate, false, 0); err != nil {
 		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: err.Error()})
 		return
 	}
@@ -465,7 +557,7 @@ func handleProcessTermData(w http.ResponseWriter, r *http.Request) {
 
 	// Step 3: Build Verkle tree
 	log.Printf("🌳 Building Verkle tree...")
-	if err := addAcademicTerm(req.TermID, verkleFile, "json", true); err != nil {
+	if err := addAcademicTerm(req.TermID, verkleFile, "json", true, false, 0); err != nil {
 		respondJSON(w, http.StatusInternalServerError, APIResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to build Verkle tree: %v", err),
@@ -484,7 +576,7 @@ func handleProcessTermData(w http.ResponseWriter, r *http.Request) {
 		outputFile := fmt.Sprintf("publish_ready/receipts/%s_journey.json", studentID)
 
 		// Generate receipt with all terms (empty list = autodiscover)
-		if err := generateStudentReceipt(studentID, outputFile, nil, nil, false); err != nil {
+		if err := generateStudentReceipt(studentID, outputFile, nil, nil, nil, false, false); err != nil {
 			log.Printf("⚠️ Failed to generate receipt for %s: %v", studentID, err)
 			failedStudents = append(failedStudents, studentID)
 			continue
@@ -519,67 +611,21 @@ func handleProcessTermData(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// Parse the receipt
-			var journeyReceipt map[string]interface{}
-			if err := json.Unmarshal(receiptData, &journeyReceipt); err != nil {
+			journeyReceipt, err := receipt.Decode(receiptData)
+			if err != nil {
 				log.Printf("⚠️ Failed to parse receipt for %s: %v", studentID, err)
 				continue
 			}
 
-			// Extract term receipts
-			termReceipts, ok := journeyReceipt["term_receipts"].(map[string]interface{})
-			if !ok {
-				log.Printf("⚠️ No term_receipts found for %s", studentID)
-				continue
-			}
-
 			// Check if this term exists in the receipt
-			termData, ok := termReceipts[req.TermID].(map[string]interface{})
+			termData, ok := journeyReceipt.TermReceipts[req.TermID]
 			if !ok {
 				log.Printf("⚠️ Term %s not found in receipt for %s", req.TermID, studentID)
 				continue
 			}
 
-			// Extract verkle root
-			verkleRootHex, ok := termData["verkle_root"].(string)
-			if !ok {
-				log.Printf("⚠️ No verkle_root found for %s/%s", studentID, req.TermID)
-				continue
-			}
-
-			// Extract receipt data
-			receiptDataMap, ok := termData["receipt"].(map[string]interface{})
-			if !ok {
-				log.Printf("⚠️ No receipt data found for %s/%s", studentID, req.TermID)
-				continue
-			}
-
-			// Extract course proofs and revealed courses
-			courseProofs := receiptDataMap["course_proofs"]
-			revealedCourses := receiptDataMap["revealed_courses"]
-
-			// Marshal to JSON for database storage
-			verkleProofJSON, _ := json.Marshal(courseProofs)
-			revealedCoursesJSON, _ := json.Marshal(revealedCourses)
-
-			// Count courses
-			var courseCount int
-			if courses, ok := revealedCourses.([]interface{}); ok {
-				courseCount = len(courses)
-			}
-
 			// Create term receipt for database
-			termReceipt := &database.TermReceipt{
-				ReceiptID:       fmt.Sprintf("receipt_%s_%s_%d", studentID, req.TermID, time.Now().Unix()),
-				StudentID:       studentID,
-				TermID:          req.TermID,
-				VerkleProof:     datatypes.JSON(verkleProofJSON),
-				RevealedCourses: datatypes.JSON(revealedCoursesJSON),
-				StateDiff:       datatypes.JSON("[]"), // Placeholder
-				CourseCount:     courseCount,
-				VerkleRootHex:   verkleRootHex,
-				GeneratedAt:     time.Now(),
-				IsSelective:     false,
-			}
+			termReceipt := newTermReceiptRecord(studentID, termData)
 
 			// Store in database
 			if err := repo.StoreTermReceipt(termReceipt); err != nil {
@@ -795,6 +841,7 @@ func handleDemoReset(w http.ResponseWriter, r *http.Request) {
 			"revocation_requests",
 			"term_root_versions",
 			"revocation_batches",
+			"chain_indexer_checkpoints",
 		}
 
 		for _, table := range tables {
@@ -815,6 +862,7 @@ func handleDemoReset(w http.ResponseWriter, r *http.Request) {
 			&database.RevocationRequest{},
 			&database.TermRootVersion{},
 			&database.RevocationBatch{},
+			&database.ChainIndexerCheckpoint{},
 		); err != nil {
 			log.Printf("❌ Database migration failed: %v", err)
 			output.WriteString(fmt.Sprintf("❌ Database migration failed: %v\n", err))
@@ -860,16 +908,40 @@ func handleDemoGenerateFull(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	respondJobQueued(w, r, jobDemoGenerateFull, demoFullJobParams{NumStudents: req.NumStudents, Terms: req.Terms})
+}
+
+// demoFullJobParams is the request of a demo-generate-full job
+type demoFullJobParams struct {
+	NumStudents int      `json:"num_students"`
+	Terms       []string `json:"terms"`
+}
+
+// runDemoGenerateFullJob generates student journeys, builds a Verkle tree per term and
+// imports the data into the database
+func runDemoGenerateFullJob(ctx context.Context, job *jobRun) (interface{}, error) {
+	var req demoFullJobParams
+	if err := job.Params(&req); err != nil {
+		return nil, err
+	}
+
+	steps := []string{"Generate student journeys"}
+	for _, term := range req.Terms {
+		steps = append(steps, fmt.Sprintf("Process %s", term))
+	}
+	job.Plan(append(steps, "Import into database")...)
+
 	log.Printf("🚀 Executing full data generation: %d students, %d terms", req.NumStudents, len(req.Terms))
 
 	// Step 1: Generate student journeys
+	job.Step("Generate student journeys")
 	log.Printf("👥 Step 1: Generating student academic journeys...")
 
 	// Debug: Log the exact command we're about to run
 	termsArg := strings.Join(req.Terms, ",")
 	log.Printf("🔍 DEBUG: About to execute: go run . generate-data --students=%d --terms=%s", req.NumStudents, termsArg)
 
-	cmd := exec.Command("go", "run", ".", "generate-data",
+	cmd := exec.CommandContext(ctx, "go", "run", ".", "generate-data",
 		fmt.Sprintf("--students=%d", req.NumStudents),
 		fmt.Sprintf("--terms=%s", termsArg))
 	cmd.Dir = "./cmd"
@@ -877,11 +949,7 @@ func handleDemoGenerateFull(w http.ResponseWriter, r *http.Request) {
 	output1, err := cmd.CombinedOutput()
 	if err != nil {
 		log.Printf("❌ Failed to generate student data: %v\nOutput: %s", err, string(output1))
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Student data generation failed: %v", err),
-		})
-		return
+		return nil, fmt.Errorf("student data generation failed: %w", err)
 	}
 
 	log.Printf("✅ Student journeys generated")
@@ -894,27 +962,32 @@ func handleDemoGenerateFull(w http.ResponseWriter, r *http.Request) {
 	outputLog.WriteString("\n")
 
 	for _, term := range req.Terms {
+		job.Step(fmt.Sprintf("Process %s", term))
 		log.Printf("  📚 Processing term: %s", term)
 
 		// Convert data
-		cmdConvert := exec.Command("go", "run", ".", "convert-data", term)
+		job.Logf("Converting data")
+		cmdConvert := exec.CommandContext(ctx, "go", "run", ".", "convert-data", term)
 		cmdConvert.Dir = "./cmd"
 		convertOut, err := cmdConvert.CombinedOutput()
 		if err != nil {
 			log.Printf("    ❌ Failed to convert data for %s: %v", term, err)
 			outputLog.WriteString(fmt.Sprintf("❌ Failed to convert %s: %v\n", term, err))
+			job.StepFailed(fmt.Errorf("failed to convert data: %w", err))
 			continue
 		}
 		outputLog.WriteString(string(convertOut))
 
 		// Add term (create Verkle tree)
-		cmdAdd := exec.Command("go", "run", ".", "add-term", term,
+		job.Logf("Building Verkle tree")
+		cmdAdd := exec.CommandContext(ctx, "go", "run", ".", "add-term", term,
 			fmt.Sprintf("../data/verkle_terms/%s_completions.json", term))
 		cmdAdd.Dir = "./cmd"
 		addOut, err := cmdAdd.CombinedOutput()
 		if err != nil {
 			log.Printf("    ❌ Failed to create Verkle tree for %s: %v", term, err)
 			outputLog.WriteString(fmt.Sprintf("❌ Failed to create Verkle tree for %s: %v\n", term, err))
+			job.StepFailed(fmt.Errorf("failed to create Verkle tree: %w", err))
 			continue
 		}
 		outputLog.WriteString(string(addOut))
@@ -925,125 +998,60 @@ func handleDemoGenerateFull(w http.ResponseWriter, r *http.Request) {
 	log.Printf("✅ Processed %d/%d terms successfully", processedTerms, len(req.Terms))
 
 	// Step 3: Import to database (optional)
+	job.Step("Import into database")
 	log.Printf("🗄️  Step 3: Importing data into database...")
-	cmdDB := exec.Command("go", "run", ".", "db-import")
+	cmdDB := exec.CommandContext(ctx, "go", "run", ".", "db-import")
 	cmdDB.Dir = "./cmd"
-	dbOut, _ := cmdDB.CombinedOutput() // Ignore error - database is optional
+	dbOut, err := cmdDB.CombinedOutput() // Ignore error - database is optional
+	if err != nil {
+		job.Logf("Database import skipped: %v", err)
+	}
 	outputLog.WriteString(string(dbOut))
 
-	respondJSON(w, http.StatusOK, APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"message": fmt.Sprintf("Generated %d students across %d/%d terms", req.NumStudents, processedTerms, len(req.Terms)),
-			"num_students": req.NumStudents,
-			"processed_terms": processedTerms,
-			"total_terms": len(req.Terms),
-			"output":  outputLog.String(),
-			"timestamp": time.Now().Format(time.RFC3339),
-		},
-	})
+	return map[string]interface{}{
+		"message": fmt.Sprintf("Generated %d students across %d/%d terms", req.NumStudents, processedTerms, len(req.Terms)),
+		"num_students": req.NumStudents,
+		"processed_terms": processedTerms,
+		"total_terms": len(req.Terms),
+		"output":  outputLog.String(),
+		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil
 }
 
+// handleListTerms lists a page of terms with their status and receipt counts
 func handleListTerms(w http.ResponseWriter, r *http.Request) {
-	// Load terms from generated data
-	terms := []map[string]interface{}{}
-	
-	// Check verkle terms data (current system)
-	termFiles, err := filepath.Glob("data/verkle_terms/*_completions.json")
-	if err == nil && len(termFiles) > 0 {
-		for _, termFile := range termFiles {
-			// Extract term ID from filename (e.g., "Semester_1_2023_completions.json" -> "Semester_1_2023")
-			basename := filepath.Base(termFile)
-			termID := strings.TrimSuffix(basename, "_completions.json")
-			
-			if termData, err := os.ReadFile(termFile); err == nil {
-				var completions []map[string]interface{}
-				if err := json.Unmarshal(termData, &completions); err == nil {
-					// Count unique students
-					studentSet := make(map[string]bool)
-					courseSet := make(map[string]bool)
-					for _, completion := range completions {
-						if studentID, ok := completion["student_id"].(string); ok {
-							studentSet[studentID] = true
-						}
-						if courseID, ok := completion["course_id"].(string); ok {
-							courseSet[courseID] = true
-						}
-					}
-					
-					// Parse term info from ID (e.g., "Semester_1_2023" -> "Semester 1 2023")
-					nameParts := strings.Split(termID, "_")
-					termName := strings.Join(nameParts, " ")
-					
-					// Determine dates based on term pattern
-					// Extract year from term ID (e.g., "Semester_1_2023" -> "2023")
-					var startDate, endDate string
-					year := "2023" // default fallback
-					if len(nameParts) > 1 {
-						// For "Semester_X_YYYY" or "Summer_YYYY", get the year
-						if len(nameParts) > 2 {
-							year = nameParts[2] // Semester_1_2023 -> 2023
-						} else if len(nameParts) == 2 {
-							year = nameParts[1] // Summer_2023 -> 2023
-						}
-					}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Database connection failed"})
+		return
+	}
+	defer database.Close(db)
 
-					if strings.Contains(termID, "Semester_1") {
-						// Fall semester: August - December of same year
-						startDate = fmt.Sprintf("%s-08-15", year)
-						endDate = fmt.Sprintf("%s-12-15", year)
-					} else if strings.Contains(termID, "Semester_2") {
-						// Spring semester: January - May of NEXT year
-						// E.g., "Semester_2_2023" actually runs Jan-May 2024
-						nextYear := year
-						var yearInt int
-					if _, err := fmt.Sscanf(year, "%d", &yearInt); err == nil && yearInt > 0 {
-							nextYear = fmt.Sprintf("%d", yearInt+1)
-						}
-						startDate = fmt.Sprintf("%s-01-15", nextYear)
-						endDate = fmt.Sprintf("%s-05-15", nextYear)
-					} else if strings.Contains(termID, "Summer") {
-						// Summer term: May - August of same year
-						startDate = fmt.Sprintf("%s-05-15", year)
-						endDate = fmt.Sprintf("%s-08-15", year)
-					} else {
-						// Default for Test terms or others
-						startDate = "2025-01-01"
-						endDate = "2025-12-31"
-					}
-					
-					// Check if term has Verkle tree published
-					rootFile := fmt.Sprintf("publish_ready/roots/root_%s.json", termID)
-					status := "completed"
-					if _, err := os.Stat(rootFile); err != nil {
-						status = "pending"
-					}
-					
-					terms = append(terms, map[string]interface{}{
-						"id": termID,
-						"name": termName,
-						"start_date": startDate,
-						"end_date": endDate,
-						"status": status,
-						"student_count": len(studentSet),
-						"total_courses": len(courseSet),
-					})
-				}
-			}
-		}
+	summaries, next, err := database.NewReceiptRepository(db).ListTerms(opts)
+	if err != nil {
+		respondListError(w, "terms", err)
+		return
+	}
 
-		// Sort terms by start_date (chronological order)
-		sort.Slice(terms, func(i, j int) bool {
-			dateI, okI := terms[i]["start_date"].(string)
-			dateJ, okJ := terms[j]["start_date"].(string)
-			if !okI || !okJ {
-				return false
-			}
-			return dateI < dateJ // String comparison works for YYYY-MM-DD format
+	terms := make([]map[string]interface{}, 0, len(summaries))
+	for _, term := range summaries {
+		terms = append(terms, map[string]interface{}{
+			"id":                 term.TermID,
+			"name":               strings.ReplaceAll(term.TermID, "_", " "),
+			"start_date":         term.StartDate.Format("2006-01-02"),
+			"end_date":           term.EndDate.Format("2006-01-02"),
+			"status":             term.Status,
+			"verkle_root":        term.VerkleRootHex,
+			"student_count":      term.StudentCount,
+			"course_completions": term.CourseCount,
 		})
 	}
-
-	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: terms})
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: terms, Page: newPageInfo(opts, next)})
 }
 
 func handleGetTermReceipts(w http.ResponseWriter, r *http.Request) {
@@ -1055,45 +1063,28 @@ func handleGetTermReceipts(w http.ResponseWriter, r *http.Request) {
 	// Look for all journey receipt files and filter by term
 	if files, err := filepath.Glob("publish_ready/receipts/*_journey.json"); err == nil {
 		for _, file := range files {
-			if receiptData, err := os.ReadFile(file); err == nil {
-				var receiptFile map[string]interface{}
-				if err := json.Unmarshal(receiptData, &receiptFile); err == nil {
-					// Check if this receipt contains the requested term
-					if termReceipts, ok := receiptFile["term_receipts"].(map[string]interface{}); ok {
-						if termData, exists := termReceipts[termID]; exists {
-							// Extract the receipt data for this term
-							if termDataMap, ok := termData.(map[string]interface{}); ok {
-								if receiptData, ok := termDataMap["receipt"].(map[string]interface{}); ok {
-									// Create a simplified receipt object for the frontend
-									receipt := map[string]interface{}{
-										"id": filepath.Base(file),
-										"student_id": receiptFile["student_id"],
-										"term_id": termID,
-										"created_at": termDataMap["generated_at"],
-										"courses": receiptData["revealed_courses"],
-										"merkle_root": "", // Will be derived from student_term_root
-										"verkle_proof": receiptData["verkle_proof"],
-										"student_name": fmt.Sprintf("Student %s", receiptFile["student_id"]),
-									}
-									
-									// Convert student_term_root array to hex string if present
-									if rootArray, ok := receiptData["student_term_root"].([]interface{}); ok {
-										rootHex := ""
-										for _, val := range rootArray {
-											if intVal, ok := val.(float64); ok {
-												rootHex += fmt.Sprintf("%02x", int(intVal))
-											}
-										}
-										receipt["merkle_root"] = rootHex
-									}
-									
-									receipts = append(receipts, receipt)
-								}
-							}
-						}
-					}
-				}
+			journeyReceipt, err := receipt.Load(file)
+			if err != nil {
+				log.Printf("⚠️ Skipping %s: %v", file, err)
+				continue
+			}
+			
+			// Check if this receipt contains the requested term
+			termData, exists := journeyReceipt.TermReceipts[termID]
+			if !exists {
+				continue
 			}
+			
+			// Create a simplified receipt object for the frontend
+			receipts = append(receipts, map[string]interface{}{
+				"id": filepath.Base(file),
+				"student_id": journeyReceipt.StudentID,
+				"term_id": termID,
+				"created_at": termData.GeneratedAt,
+				"courses": termData.Receipt.RevealedCourses,
+				"merkle_root": termData.VerkleRoot.String(),
+				"student_name": fmt.Sprintf("Student %s", journeyReceipt.StudentID),
+			})
 		}
 	}
 	
@@ -1125,6 +1116,33 @@ func handleGetTermRoot(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: root})
 }
 
+// waitForReportedTransaction waits until a transaction reported by the frontend succeeded
+// and is as deep as publishing requires, bounded by TX_CONFIRM_TIMEOUT
+func waitForReportedTransaction(ctx context.Context, txHash string) (*blockchain_integration.Inclusion, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	integration, err := blockchain_integration.NewBlockchainIntegration(cfg.Network, cfg.GetPrivateKey(), cfg.GetContractAddress())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+	defer integration.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TxConfirmTimeout)*time.Second)
+	defer cancel()
+
+	inclusion, err := integration.WaitForConfirmations(ctx, common.HexToHash(txHash), integration.Confirmations())
+	if err != nil {
+		return nil, err
+	}
+	if !inclusion.Succeeded {
+		return nil, fmt.Errorf("transaction %s reverted", txHash)
+	}
+	return inclusion, nil
+}
+
 func handleUpdateTermBlockchainStatus(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	termID := vars["term_id"]
@@ -1145,6 +1163,18 @@ func handleUpdateTermBlockchainStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Receipts only count as verified once the transaction is confirmation_blocks deep
+	inclusion, err := waitForReportedTransaction(r.Context(), req.TxHash)
+	if err != nil {
+		log.Printf("❌ Transaction %s of %s not confirmed: %v", req.TxHash, termID, err)
+		respondJSON(w, http.StatusConflict, APIResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Transaction not confirmed: %v", err),
+		})
+		return
+	}
+	req.BlockNumber = inclusion.BlockNumber
+
 	// Connect to database
 	db, err := database.Connect()
 	if err != nil {
@@ -1184,60 +1214,36 @@ func handleUpdateTermBlockchainStatus(w http.ResponseWriter, r *http.Request) {
 
 	// Regenerate journey receipts for all students with published terms
 	fmt.Printf("📝 Regenerating journey receipts for all students...\n")
-	if err := regenerateAllJourneyReceipts(); err != nil {
+	if err := regenerateAllJourneyReceipts(0); err != nil {
 		fmt.Printf("⚠️ Warning: Failed to regenerate receipts: %v\n", err)
 		// Don't fail the blockchain update, just log the warning
 	} else {
 		fmt.Printf("✅ Journey receipts regenerated successfully\n")
 	}
 
-	// Process any pending revocations in the background
-	// This runs async so it doesn't block the response
-	go func() {
-		log.Printf("🔄 Checking for pending revocations to process...")
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			log.Printf("⚠️  Failed to load config for revocation processing: %v", err)
-			return
-		}
-
-		if cfg.IssuerPrivateKey == "" {
-			log.Printf("⚠️  No issuer private key configured, skipping background revocation processing")
-			return
-		}
-
-		// Check if there are any approved revocations
-		dbConn, err := database.Connect()
-		if err != nil {
-			log.Printf("⚠️  Failed to connect to database for revocation check: %v", err)
-			return
-		}
-
-		var count int64
-		dbConn.Model(&database.RevocationRequest{}).Where("status = ?", "approved").Count(&count)
-
-		if count == 0 {
-			log.Printf("✅ No pending revocations to process")
-			return
-		}
+	response := map[string]interface{}{
+		"term_id":        termID,
+		"receipts_updated": result.RowsAffected,
+		"tx_hash":        req.TxHash,
+	}
 
+	// Process any pending revocations in a background job
+	// This runs async so it doesn't block the response
+	var count int64
+	db.Model(&database.RevocationRequest{}).Where("status = ?", "approved").Count(&count)
+	if count > 0 && issuerJobs != nil {
 		log.Printf("📋 Found %d approved revocations, processing in background...", count)
-
-		// Process revocations using the existing function
-		if err := processApprovedRevocations(cfg.Network, cfg.IssuerPrivateKey, cfg.DefaultGasLimit); err != nil {
-			log.Printf("⚠️  Background revocation processing failed: %v", err)
+		job, _, err := issuerJobs.Enqueue(jobProcessRevocations, struct{}{}, requestActor(r))
+		if err != nil {
+			log.Printf("⚠️  Failed to queue revocation processing: %v", err)
 		} else {
-			log.Printf("✅ Background revocation processing completed")
+			response["revocation_job_id"] = job.JobID
 		}
-	}()
+	}
 
 	respondJSON(w, http.StatusOK, APIResponse{
 		Success: true,
-		Data: map[string]interface{}{
-			"term_id":        termID,
-			"receipts_updated": result.RowsAffected,
-			"tx_hash":        req.TxHash,
-		},
+		Data:    response,
 	})
 }
 
@@ -1257,40 +1263,57 @@ func handleGenerateReceipt(w http.ResponseWriter, r *http.Request) {
 	outputFile := fmt.Sprintf("/tmp/receipt_%s_%d.json", extractStudentID(req.StudentID), time.Now().Unix())
 	
 	// Call existing generateStudentReceipt function
-	if err := generateStudentReceipt(req.StudentID, outputFile, req.Terms, req.Courses, req.Selective); err != nil {
+	if err := generateStudentReceipt(req.StudentID, outputFile, req.Terms, req.Courses, req.Fields, req.Selective, req.BatchProof); err != nil {
 		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: err.Error()})
 		return
 	}
 	
-	// Read the generated receipt
-	receiptData, err := os.ReadFile(outputFile)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to read generated receipt"})
-		return
-	}
-	
 	// Clean up temp file
 	defer os.Remove(outputFile)
 	
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(receiptData, &receipt); err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to parse receipt"})
+	// Read the generated receipt
+	journeyReceipt, err := receipt.Load(outputFile)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to read generated receipt"})
 		return
 	}
 	
-	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: receipt})
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: journeyReceipt})
 }
 
 func handleVerifyReceipt(w http.ResponseWriter, r *http.Request) {
-	var receiptData map[string]interface{}
-	if err := json.NewDecoder(r.Body).Decode(&receiptData); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: "Invalid receipt data"})
+	var journeyReceipt receipt.JourneyReceipt
+	if err := json.NewDecoder(r.Body).Decode(&journeyReceipt); err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: fmt.Sprintf("Invalid receipt data: %v", err)})
 		return
 	}
+	noteVerifiedReceipt(r, "journey", contentID("jr_", journeyReceipt), journeyReceipt.StudentID, "")
+	
+	// Every term root must be the latest version published on chain; the proofs alone
+	// only show consistency with the roots embedded in the receipt
+	rootChecks := make(map[string]*RootCheck)
+	for _, termID := range journeyReceipt.SortedTermIDs() {
+		rootCheck, err := getRootOracle().Check(r.Context(), termID, journeyReceipt.TermReceipts[termID].VerkleRoot.String())
+		if err != nil {
+			respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: fmt.Sprintf("Cannot verify root of term %s: %v", termID, err)})
+			return
+		}
+		rootChecks[termID] = rootCheck
+	}
+	for _, termID := range journeyReceipt.SortedTermIDs() {
+		if err := rootChecks[termID].Err(); err != nil {
+			respondJSON(w, http.StatusBadRequest, APIResponse{
+				Success: false,
+				Data:    map[string]interface{}{"verified": false, "root_status": rootChecks},
+				Error:   fmt.Sprintf("Verification failed: %v", err),
+			})
+			return
+		}
+	}
 	
 	// Create temporary file for verification
 	tempFile := fmt.Sprintf("/tmp/verify_%d.json", time.Now().Unix())
-	data, _ := json.Marshal(receiptData)
+	data, _ := journeyReceipt.Encode()
 	if err := os.WriteFile(tempFile, data, 0644); err != nil {
 		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to create temporary file"})
 		return
@@ -1306,6 +1329,7 @@ func handleVerifyReceipt(w http.ResponseWriter, r *http.Request) {
 	result := map[string]interface{}{
 		"verified": true,
 		"timestamp": time.Now().Format(time.RFC3339),
+		"root_status": rootChecks,
 	}
 	
 	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: result})
@@ -1331,9 +1355,9 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 	log.Printf("📄 Receipt data length: %d bytes", len(request.Receipt))
 	
 	// Parse the receipt
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(request.Receipt, &receipt); err != nil {
-		log.Printf("❌ Failed to unmarshal receipt: %v", err)
+	journeyReceipt, err := receipt.Decode(request.Receipt)
+	if err != nil {
+		log.Printf("❌ Failed to decode receipt: %v", err)
 		respondJSON(w, http.StatusBadRequest, APIResponse{
 			Success: false,
 			Error: fmt.Sprintf("Invalid receipt format: %v", err),
@@ -1341,22 +1365,10 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("✅ Receipt parsed, keys: %v", getKeys(receipt))
-
-	// Find the term and course
-	termReceipts, ok := receipt["term_receipts"].(map[string]interface{})
-	if !ok {
-		log.Printf("❌ No term_receipts found in receipt. Available keys: %v", getKeys(receipt))
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error: fmt.Sprintf("No term receipts found. Receipt keys: %v", getKeys(receipt)),
-		})
-		return
-	}
-
-	log.Printf("✅ Found term_receipts with terms: %v", getKeys(termReceipts))
+	log.Printf("✅ Receipt parsed, terms: %v", journeyReceipt.SortedTermIDs())
+	noteVerifiedReceipt(r, "journey", contentID("jr_", journeyReceipt), journeyReceipt.StudentID, request.TermID)
 	
-	termData, ok := termReceipts[request.TermID].(map[string]interface{})
+	termData, ok := journeyReceipt.TermReceipts[request.TermID]
 	if !ok {
 		respondJSON(w, http.StatusNotFound, APIResponse{
 			Success: false,
@@ -1365,47 +1377,15 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Get the Verkle root from the receipt (for initial check)
-	localVerkleRootHex, ok := termData["verkle_root"].(string)
-	if !ok {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error: "No Verkle root found for term",
-		})
-		return
-	}
-	
-	// 🔗 SECURITY: Verify root against blockchain smart contract
-	log.Printf("🔍 Fetching Verkle root from blockchain for term: %s", request.TermID)
+	verkleRootHex := termData.VerkleRoot.String()
 	
-	// TODO: Add blockchain verification here
-	// For now, we'll verify against the local root but log the security concern
-	log.Printf("⚠️ SECURITY NOTICE: Currently using local root, should verify against blockchain")
-	log.Printf("📋 Local root: %s", localVerkleRootHex)
-	
-	verkleRootHex := localVerkleRootHex
-	
-	// Get the receipt data
-	receiptData, ok := termData["receipt"].(map[string]interface{})
-	if !ok {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error: "No receipt data found",
-		})
-		return
-	}
-	
-	// Find the course proof
-	courseProofs, ok := receiptData["course_proofs"].(map[string]interface{})
-	if !ok {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error: "No course proofs found",
-		})
-		return
+	// Find the course proof (batch receipts carry one multiproof for all revealed courses)
+	receiptData := termData.Receipt
+	isBatch := receiptData.IsBatch()
+	proofBytes, ok := receiptData.CourseProofs[request.CourseID]
+	if isBatch {
+		proofBytes, ok = receiptData.BatchProof, true
 	}
-	
-	proofDataRaw, ok := courseProofs[request.CourseID]
 	if !ok {
 		respondJSON(w, http.StatusNotFound, APIResponse{
 			Success: false,
@@ -1414,144 +1394,46 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Convert proof data to JSON bytes (now that we store JSON directly)
-	proofBytes, err := json.Marshal(proofDataRaw)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error: fmt.Sprintf("Failed to serialize proof data: %v", err),
-		})
-		return
-	}
-	
-	// Find course details
-	var courseInfo map[string]interface{}
-	if revealedCourses, ok := receiptData["revealed_courses"].([]interface{}); ok {
-		for _, c := range revealedCourses {
-			course := c.(map[string]interface{})
-			if course["course_id"] == request.CourseID {
-				courseInfo = course
-				break
-			}
-		}
-	}
-	
-	if courseInfo == nil {
+	// Use the exact course data from revealed_courses, which is what the proof was generated for
+	course, ok := receiptData.Course(request.CourseID)
+	if !ok {
 		respondJSON(w, http.StatusNotFound, APIResponse{
 			Success: false,
 			Error: fmt.Sprintf("Course %s not in revealed courses", request.CourseID),
 		})
 		return
 	}
+	courseInfo := course
 	
 	// Perform actual cryptographic verification
 	log.Printf("🔍 Starting cryptographic verification for course %s", request.CourseID)
 	
-	// Use the exact course data from revealed_courses to ensure data consistency
-	// This preserves the exact serialization format used during proof generation
-	var course verkle.CourseCompletion
-	courseInfoJSON, err := json.Marshal(courseInfo)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error: fmt.Sprintf("Failed to serialize course info: %v", err),
-		})
-		return
-	}
-	
-	err = json.Unmarshal(courseInfoJSON, &course)
-	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error: fmt.Sprintf("Failed to deserialize course data: %v", err),
-		})
-		return
-	}
-	
-	// SECURITY: Verify Verkle root exists on blockchain before using it for verification
-	log.Printf("🔗 Verifying Verkle root exists on blockchain: %s", verkleRootHex)
-	
-	// Initialize blockchain integration
-	ctx := context.Background()
-	blockchainVerified := false
+	// SECURITY: Resolve the receipt's root through the registry, a self-made root must not verify
+	log.Printf("🔗 Verifying Verkle root against the registry: %s", verkleRootHex)
 	
-	// Load configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		log.Printf("❌ Failed to load configuration: %v", err)
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error: "Blockchain configuration error - cannot verify receipt",
-		})
-		return
-	}
-	
-	if cfg.ContractAddress == "" || cfg.IssuerPrivateKey == "" {
-		log.Printf("❌ Missing blockchain configuration (IUMICERT_CONTRACT_ADDRESS or ISSUER_PRIVATE_KEY)")
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error: "Blockchain configuration missing - cannot verify receipt",
-		})
-		return
-	}
-	
-	blockchainIntegration, err := blockchain_integration.NewBlockchainIntegration(cfg.Network, cfg.IssuerPrivateKey, cfg.ContractAddress)
+	ctx := r.Context()
+	rootCheck, err := getRootOracle().Check(ctx, request.TermID, verkleRootHex)
 	if err != nil {
-		log.Printf("❌ Failed to initialize blockchain integration: %v", err)
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error: fmt.Sprintf("Blockchain connection failed: %v", err),
-		})
-		return
-	}
-	
-	// Check root status on blockchain (includes version info)
-	rootStatus, err := blockchainIntegration.CheckRootStatus(ctx, verkleRootHex)
-	if err != nil {
-		log.Printf("❌ Failed to check root status on blockchain: %v", err)
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error: fmt.Sprintf("Verkle root not found on blockchain: %v", err),
-		})
-		return
-	}
-
-	// Check if root is valid (0=Invalid, 1=Current, 2=Outdated, 3=Superseded)
-	if rootStatus.Status == 0 {
-		log.Printf("❌ Verkle root does not exist on blockchain: %s", verkleRootHex)
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error: "Verkle root not published on blockchain - invalid receipt",
-		})
-		return
-	}
-
-	// Check if root is superseded
-	if rootStatus.Status == 3 {
-		log.Printf("⚠️  Verkle root superseded: %s - %s", verkleRootHex, rootStatus.Message)
-		respondJSON(w, http.StatusBadRequest, APIResponse{
+		log.Printf("❌ Failed to check root status: %v", err)
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{
 			Success: false,
-			Error: fmt.Sprintf("Receipt uses superseded root. %s. Please download updated receipt.", rootStatus.Message),
+			Error: fmt.Sprintf("Cannot verify receipt root: %v", err),
 		})
 		return
 	}
-
-	// SECURITY: Verify term_id matches between receipt and blockchain
-	if rootStatus.TermID != request.TermID {
-		log.Printf("❌ Term ID mismatch: receipt claims %s but blockchain shows %s for root %s",
-			request.TermID, rootStatus.TermID, verkleRootHex)
+	if err := rootCheck.Err(); err != nil {
+		log.Printf("❌ Root check failed for term %s: %v", request.TermID, err)
 		respondJSON(w, http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error: fmt.Sprintf("Receipt term ID (%s) does not match blockchain term ID (%s) for this verkle root",
-				request.TermID, rootStatus.TermID),
+			Data: map[string]interface{}{"root_status": rootCheck},
+			Error: err.Error(),
 		})
 		return
 	}
 
-	blockchainVerified = true
-	statusMsg := map[uint8]string{1: "Current", 2: "Outdated"}[rootStatus.Status]
-	log.Printf("✅ Verkle root verified on blockchain: term=%s, version=%s, status=%s",
-		rootStatus.TermID, rootStatus.Version.String(), statusMsg)
+	blockchainVerified := true
+	log.Printf("✅ Verkle root verified (%s): term=%s, version=%d, state=%s",
+		rootCheck.Source, rootCheck.TermID, rootCheck.Version, rootCheck.State)
 	
 	// Convert verified verkle root hex to bytes
 	var verkleRootBytes [32]byte
@@ -1562,13 +1444,29 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 		fmt.Sscanf(verkleRootHex[i*2:i*2+2], "%02x", &verkleRootBytes[i])
 	}
 	
-	// Create course key for verification
-	courseKey := fmt.Sprintf("did:example:%s:%s:%s", course.StudentID, course.TermID, course.CourseID)
+	// Create course key for verification. Field-level receipts may leave student_id undisclosed,
+	// so fall back to the receipt holder.
+	studentID := course.StudentID
+	if studentID == "" {
+		studentID = journeyReceipt.StudentID
+	}
+	courseKey := fmt.Sprintf("did:example:%s:%s:%s", studentID, request.TermID, course.CourseID)
 	
 	// The proof data from receipts is JSON, we already have it as bytes
 	
 	// Perform the actual cryptographic verification with blockchain-verified root
-	verificationErr := verkle.VerifyCourseProof(courseKey, course, proofBytes, verkleRootBytes)
+	var verificationErr error
+	var disclosedFields []string
+	if isBatch {
+		// The multiproof binds all revealed courses together, so they are verified as a set
+		_, verificationErr = verifyBatchTermProof(studentID, receiptData, verkleRootBytes)
+	} else {
+		outcome := verifier.New().VerifyCourse(courseKey, course, proofBytes, verkleRootBytes)
+		if !outcome.Verified {
+			verificationErr = &verifier.Error{Code: outcome.Code, Err: errors.New(outcome.Message)}
+		}
+		disclosedFields = outcome.Fields
+	}
 	
 	ipaPassed := verificationErr == nil
 	if !ipaPassed {
@@ -1587,6 +1485,7 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 	var errorMessage string
 	if !ipaPassed {
 		errorMessage = fmt.Sprintf("IPA verification failed (blockchain verification successful): %v", verificationErr)
+		verificationDetails["error_code"] = verifier.CodeOf(verificationErr)
 	}
 
 	// Try to get blockchain transaction info from database
@@ -1602,7 +1501,7 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 		if result.Error == nil && termReceipt.BlockchainTxHash != nil {
 			blockchainInfo = map[string]interface{}{
 				"tx_hash": *termReceipt.BlockchainTxHash,
-				"published_at": rootStatus.Version,
+				"published_at": rootCheck.Version,
 				"block_number": termReceipt.BlockchainBlock,
 			}
 		}
@@ -1616,6 +1515,10 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 		"verkle_root": verkleRootHex,
 		"proof_exists": len(proofBytes) > 0,
 		"verification_details": verificationDetails,
+		"root_status": rootCheck,
+	}
+	if len(disclosedFields) > 0 {
+		responseData["disclosed_fields"] = disclosedFields
 	}
 
 	// Add blockchain info if available
@@ -1636,63 +1539,53 @@ func handleVerifyCourse(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, response)
 }
 
+// handleListReceipts lists a page of term receipts, without their proofs
 func handleListReceipts(w http.ResponseWriter, r *http.Request) {
-	receipts := []map[string]interface{}{}
-
-	// Connect to database to get blockchain publication timestamps
-	db, dbErr := database.Connect()
-
-	if files, err := filepath.Glob("publish_ready/receipts/*_journey.json"); err == nil {
-		for _, file := range files {
-			if receiptData, err := os.ReadFile(file); err == nil {
-				var receipt map[string]interface{}
-				if err := json.Unmarshal(receiptData, &receipt); err == nil {
-					receiptInfo := map[string]interface{}{
-						"filename":   filepath.Base(file),
-						"student_id": receipt["student_id"],
-						"timestamp":  receipt["generation_timestamp"],
-						"selective":  receipt["receipt_type"],
-					}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Database connection failed"})
+		return
+	}
+	defer database.Close(db)
 
-					// Get latest blockchain publication timestamp for this student's terms
-					if dbErr == nil && receipt["term_receipts"] != nil {
-						var latestPublishedAt *time.Time
-
-						// Extract term IDs from the receipt
-						if termReceipts, ok := receipt["term_receipts"].(map[string]interface{}); ok {
-							for termID := range termReceipts {
-								var term database.Term
-								if err := db.Where("term_id = ? AND published_at IS NOT NULL", termID).First(&term).Error; err == nil {
-									if term.PublishedAt != nil {
-										if latestPublishedAt == nil || term.PublishedAt.After(*latestPublishedAt) {
-											latestPublishedAt = term.PublishedAt
-										}
-									}
-								}
-							}
-						}
-
-						// Use blockchain publication timestamp if available
-						if latestPublishedAt != nil {
-							receiptInfo["timestamp"] = latestPublishedAt.Format(time.RFC3339)
-							receiptInfo["blockchain_published"] = true
-						} else {
-							receiptInfo["blockchain_published"] = false
-						}
-					}
+	termReceipts, next, err := database.NewReceiptRepository(db).ListTermReceipts(opts)
+	if err != nil {
+		respondListError(w, "receipts", err)
+		return
+	}
 
-					receipts = append(receipts, receiptInfo)
-				}
-			}
+	receipts := make([]map[string]interface{}, 0, len(termReceipts))
+	for _, tr := range termReceipts {
+		receiptInfo := map[string]interface{}{
+			"receipt_id":           tr.ReceiptID,
+			"student_id":           tr.StudentID,
+			"term_id":              tr.TermID,
+			"course_count":         tr.CourseCount,
+			"verkle_root":          tr.VerkleRootHex,
+			"selective":            tr.IsSelective,
+			"generated_at":         tr.GeneratedAt.Format(time.RFC3339),
+			"blockchain_published": tr.BlockchainVerified != nil && *tr.BlockchainVerified,
+		}
+		if tr.PublishedAt != nil {
+			receiptInfo["published_at"] = tr.PublishedAt.Format(time.RFC3339)
 		}
+		if tr.BlockchainTxHash != nil {
+			receiptInfo["tx_hash"] = *tr.BlockchainTxHash
+		}
+		receipts = append(receipts, receiptInfo)
 	}
-
-	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: receipts})
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: receipts, Page: newPageInfo(opts, next)})
 }
 
 func handleGetReceiptByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	receiptID := vars["receipt_id"]
+	noteVerifiedReceipt(r, "journey", receiptID, receiptID, "") // Journey receipts are named by student ID
 	
 	if receiptID == "" {
 		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: "receipt_id is required"})
@@ -1701,19 +1594,18 @@ func handleGetReceiptByID(w http.ResponseWriter, r *http.Request) {
 
 	// Look for journey receipt file
 	journeyPath := fmt.Sprintf("publish_ready/receipts/%s_journey.json", receiptID)
-	if journeyData, err := os.ReadFile(journeyPath); err == nil {
-		var journey map[string]interface{}
-		if err := json.Unmarshal(journeyData, &journey); err == nil {
-			respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: journey})
-			return
-		}
+	if journeyReceipt, err := receipt.Load(journeyPath); err == nil {
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: journeyReceipt})
+		return
 	}
 
 	respondJSON(w, http.StatusNotFound, APIResponse{Success: false, Error: "Receipt not found"})
 }
 
 // regenerateAllJourneyReceipts regenerates journey receipts for all students with published terms
-func regenerateAllJourneyReceipts() error {
+// regenerateAllJourneyReceipts rewrites every student's journey receipt on up to workers
+// goroutines (0 means one per CPU)
+func regenerateAllJourneyReceipts(workers int) error {
 	// Connect to database
 	db, err := database.Connect()
 	if err != nil {
@@ -1733,28 +1625,40 @@ func regenerateAllJourneyReceipts() error {
 
 	fmt.Printf("📋 Found %d students to regenerate receipts for\n", len(students))
 
-	// Regenerate receipt for each student with all published terms
-	successCount := 0
-	for _, student := range students {
-		outputFile := fmt.Sprintf("publish_ready/receipts/%s_journey.json", student.StudentID)
+	// Regenerate receipt for each student with all published terms, sharing
+	// the loaded term trees between workers
+	trees := newTermTreeSet()
+	defer trees.Close()
 
-		// Call generateStudentReceipt with empty terms list (auto-discover published terms)
-		// and empty courses list (include all courses), selective=false
-		err := generateStudentReceipt(student.StudentID, outputFile, nil, nil, false)
+	var regenerated []string
+	runOrdered(len(students), workers, func(i int, out io.Writer) error {
+		outputFile := fmt.Sprintf("publish_ready/receipts/%s_journey.json", students[i].StudentID)
+
+		// Empty terms list (auto-discover published terms) and empty courses
+		// list (include all courses), selective=false
+		return generateStudentReceiptWith(out, trees, students[i].StudentID, outputFile, nil, nil, nil, false, false)
+	}, func(i int, output []byte, err error) {
+		os.Stdout.Write(output)
 		if err != nil {
-			fmt.Printf("⚠️ Failed to regenerate receipt for %s: %v\n", student.StudentID, err)
-			continue
+			fmt.Printf("⚠️ Failed to regenerate receipt for %s: %v\n", students[i].StudentID, err)
+			return
 		}
 
-		successCount++
-		fmt.Printf("✓ Regenerated receipt for %s\n", student.StudentID)
-	}
+		regenerated = append(regenerated, students[i].StudentID)
+		fmt.Printf("✓ Regenerated receipt for %s\n", students[i].StudentID)
+	})
 
-	if successCount == 0 {
+	if len(regenerated) == 0 {
 		return fmt.Errorf("failed to regenerate any receipts")
 	}
 
-	fmt.Printf("✅ Successfully regenerated %d/%d receipts\n", successCount, len(students))
+	fmt.Printf("✅ Successfully regenerated %d/%d receipts\n", len(regenerated), len(students))
+	emitWebhook(eventReceiptsRegenerated, receiptsRegeneratedEvent{
+		StudentIDs:    regenerated,
+		Count:         len(regenerated),
+		Failed:        len(students) - len(regenerated),
+		RegeneratedAt: time.Now().UTC(),
+	})
 	return nil
 }
 
@@ -1770,7 +1674,11 @@ func handlePublishRoots(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
+	// Default to NETWORK like the CLI commands do
 	network := req.Network
+	if network == "" {
+		network = os.Getenv("NETWORK")
+	}
 	if network == "" {
 		network = "sepolia"
 	}
@@ -1780,125 +1688,156 @@ func handlePublishRoots(w http.ResponseWriter, r *http.Request) {
 		gasLimit = 500000
 	}
 	
+	if req.DryRun {
+		planned, err := estimateTermRootPublication(req.TermID, network, "", gasLimit)
+		if err != nil {
+			respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: err.Error()})
+			return
+		}
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: dryRunResponse(req.TermID, planned)})
+		return
+	}
+	
 	// Check if we already have a transaction record for this term first
 	fmt.Printf("🔍 API: Checking for existing transaction for %s\n", req.TermID)
-	if files, err := filepath.Glob("publish_ready/transactions/tx_*.json"); err == nil && len(files) > 0 {
-		// Sort files by modification time to get the most recent
-		sort.Slice(files, func(i, j int) bool {
-			infoI, errI := os.Stat(files[i])
-			infoJ, errJ := os.Stat(files[j])
-			if errI != nil || errJ != nil {
-				return false
-			}
-			return infoI.ModTime().After(infoJ.ModTime())
-		})
-		
-		// Look for existing transaction for this term
-		for _, file := range files {
-			if txData, err := os.ReadFile(file); err == nil {
-				var tx map[string]interface{}
-				if err := json.Unmarshal(txData, &tx); err == nil {
-					// Check if this transaction is for our term
-					if rootPath, ok := tx["root_file_path"].(string); ok {
-						expectedRootFile := fmt.Sprintf("root_%s.json", req.TermID)
-						if strings.Contains(rootPath, expectedRootFile) {
-							// Found existing transaction
-							fmt.Printf("✅ API: Found existing transaction for %s\n", req.TermID)
-							tx["term_id"] = req.TermID
-							respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: tx})
-							return
-						}
-					}
-				}
-			}
-		}
+	if tx := latestTransactionRecord(req.TermID); tx != nil {
+		fmt.Printf("✅ API: Found existing transaction for %s\n", req.TermID)
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: tx})
+		return
+	}
+
+	respondJobQueued(w, r, jobPublishRoots, publishJobParams{TermID: req.TermID, Network: network, GasLimit: gasLimit})
+}
+
+// publishJobParams is the request of a publish-roots job
+type publishJobParams struct {
+	TermID   string `json:"term_id"`
+	Network  string `json:"network"`
+	GasLimit uint64 `json:"gas_limit"`
+}
+
+// runPublishRootsJob publishes a term root, after processing approved revocations, and
+// regenerates the journey receipts
+func runPublishRootsJob(ctx context.Context, job *jobRun) (interface{}, error) {
+	var req publishJobParams
+	if err := job.Params(&req); err != nil {
+		return nil, err
 	}
+	job.Plan("Publish term root", "Regenerate journey receipts")
 
 	// Call existing publishTermRoots function
-	fmt.Printf("🔄 API: About to call publishTermRoots for %s\n", req.TermID)
-	if err := publishTermRoots(req.TermID, network, "", gasLimit); err != nil {
+	job.Step("Publish term root")
+	job.Logf("Publishing %s to %s", req.TermID, req.Network)
+	if err := publishTermRoots(req.TermID, req.Network, "", req.GasLimit, job.Actor()); err != nil {
 		fmt.Printf("❌ API: publishTermRoots failed: %v\n", err)
-		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: err.Error()})
-		return
+		return nil, err
 	}
 	fmt.Printf("✅ API: publishTermRoots completed successfully\n")
 
 	// Regenerate journey receipts for all students with published terms
+	job.Step("Regenerate journey receipts")
 	fmt.Printf("📝 Regenerating journey receipts for all students...\n")
-	if err := regenerateAllJourneyReceipts(); err != nil {
+	if err := regenerateAllJourneyReceipts(0); err != nil {
 		fmt.Printf("⚠️ Warning: Failed to regenerate receipts: %v\n", err)
 		// Don't fail the publish operation, just log the warning
+		job.Logf("Failed to regenerate receipts: %v", err)
 	} else {
 		fmt.Printf("✅ Journey receipts regenerated successfully\n")
 	}
 
-	// Find the latest transaction file for this term
-	// Note: Transaction files are named by hash, so we need to search through them
-	if files, err := filepath.Glob("publish_ready/transactions/tx_*.json"); err == nil && len(files) > 0 {
-		// Sort files by modification time to get the most recent
-		sort.Slice(files, func(i, j int) bool {
-			infoI, errI := os.Stat(files[i])
-			infoJ, errJ := os.Stat(files[j])
-			if errI != nil || errJ != nil {
-				return false
-			}
-			return infoI.ModTime().After(infoJ.ModTime())
-		})
-		
-		// Look through recent transaction files to find one for this term
-		for _, file := range files {
-			if txData, err := os.ReadFile(file); err == nil {
-				var tx map[string]interface{}
-				if err := json.Unmarshal(txData, &tx); err == nil {
-					// Check if this transaction is for our term
-					if rootPath, ok := tx["root_file_path"].(string); ok {
-						expectedRootFile := fmt.Sprintf("root_%s.json", req.TermID)
-						if strings.Contains(rootPath, expectedRootFile) {
-							// Add the term_id to the response for clarity
-							tx["term_id"] = req.TermID
-							respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: tx})
-							return
-						}
-					}
-				}
-			}
-		}
+	if tx := latestTransactionRecord(req.TermID); tx != nil {
+		return tx, nil
 	}
-	
+
 	// Fallback response
-	result := map[string]interface{}{
+	return map[string]interface{}{
 		"term_id": req.TermID,
-		"network": network,
+		"network": req.Network,
 		"status": "prepared",
 		"timestamp": time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// latestTransactionRecord returns the most recent transaction record of a term in
+// publish_ready/transactions, or nil
+func latestTransactionRecord(termID string) map[string]interface{} {
+	// Note: Transaction files are named by hash, so we need to search through them
+	files, err := filepath.Glob("publish_ready/transactions/tx_*.json")
+	if err != nil || len(files) == 0 {
+		return nil
 	}
-	
-	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: result})
+
+	// Sort files by modification time to get the most recent
+	sort.Slice(files, func(i, j int) bool {
+		infoI, errI := os.Stat(files[i])
+		infoJ, errJ := os.Stat(files[j])
+		if errI != nil || errJ != nil {
+			return false
+		}
+		return infoI.ModTime().After(infoJ.ModTime())
+	})
+
+	expectedRootFile := fmt.Sprintf("root_%s.json", termID)
+	for _, file := range files {
+		txData, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var tx map[string]interface{}
+		if err := json.Unmarshal(txData, &tx); err != nil {
+			continue
+		}
+		// Check if this transaction is for our term
+		if rootPath, ok := tx["root_file_path"].(string); ok && strings.Contains(rootPath, expectedRootFile) {
+			// Add the term_id to the response for clarity
+			tx["term_id"] = termID
+			return tx
+		}
+	}
+	return nil
 }
 
+// handleListTransactions lists a page of recorded transaction attempts
 func handleListTransactions(w http.ResponseWriter, r *http.Request) {
-	transactions := []map[string]interface{}{}
-	
-	if files, err := filepath.Glob("publish_ready/transactions/tx_*.json"); err == nil {
-		for _, file := range files {
-			if txData, err := os.ReadFile(file); err == nil {
-				var tx map[string]interface{}
-				if err := json.Unmarshal(txData, &tx); err == nil {
-					transactions = append(transactions, map[string]interface{}{
-						"filename": filepath.Base(file),
-						"term_id": tx["term_id"],
-						"network": tx["network"],
-						"status": tx["status"],
-						"timestamp": tx["timestamp"],
-						"tx_hash": tx["tx_hash"],
-						"gas_limit": tx["gas_limit"],
-					})
-				}
-			}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Database connection failed"})
+		return
+	}
+	defer database.Close(db)
+
+	attempts, next, err := database.NewReceiptRepository(db).ListTransactions(opts)
+	if err != nil {
+		respondListError(w, "transactions", err)
+		return
+	}
+
+	transactions := make([]map[string]interface{}, 0, len(attempts))
+	for _, tx := range attempts {
+		txInfo := map[string]interface{}{
+			"tx_hash":      tx.TxHash,
+			"term_id":      tx.TermID,
+			"status":       tx.Status,
+			"block_number": tx.BlockNumber,
+			"gas_used":     tx.GasUsed,
+			"from":         tx.FromAddress,
+			"nonce":        tx.Nonce,
+			"attempt":      tx.Attempt,
+			"replaced_by":  tx.ReplacedBy,
+			"initiated_by": tx.InitiatedBy,
+			"timestamp":    tx.SubmittedAt.Format(time.RFC3339),
+		}
+		if tx.ConfirmedAt != nil {
+			txInfo["confirmed_at"] = tx.ConfirmedAt.Format(time.RFC3339)
 		}
+		transactions = append(transactions, txInfo)
 	}
-	
-	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: transactions})
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: transactions, Page: newPageInfo(opts, next)})
 }
 
 func handleGetTransaction(w http.ResponseWriter, r *http.Request) {
@@ -1963,7 +1902,14 @@ func handleGetPublishedRoots(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: roots})
 }
 
+// handleListStudents lists a page of students
 func handleListStudents(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+
 	// Connect to database
 	db, err := database.Connect()
 	if err != nil {
@@ -1975,15 +1921,9 @@ func handleListStudents(w http.ResponseWriter, r *http.Request) {
 	}
 	defer database.Close(db)
 
-	repo := database.NewReceiptRepository(db)
-
-	// Get all students from database
-	students, err := repo.GetAllStudents()
+	students, next, err := database.NewReceiptRepository(db).ListStudents(opts)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Failed to fetch students: %v", err),
-		})
+		respondListError(w, "students", err)
 		return
 	}
 
@@ -2000,7 +1940,7 @@ func handleListStudents(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: studentList})
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: studentList, Page: newPageInfo(opts, next)})
 }
 
 func handleGetStudentTerms(w http.ResponseWriter, r *http.Request) {
@@ -2022,6 +1962,7 @@ func handleGetStudentTerms(w http.ResponseWriter, r *http.Request) {
 func handleGetStudentJourney(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	studentID := vars["student_id"]
+	noteVerifiedReceipt(r, "journey", "", studentID, "")
 	
 	// Load student journey from generated data
 	journeyFile := filepath.Join("data/generated_student_data/students", fmt.Sprintf("journey_%s.json", studentID))
@@ -2058,6 +1999,9 @@ func getTermsFromStudent(student map[string]interface{}) []string {
 }
 
 func respondJSON(w http.ResponseWriter, status int, response APIResponse) {
+	if audited, ok := w.(*auditedResponseWriter); ok {
+		audited.audit.response = &response
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(response)
@@ -2245,234 +2189,109 @@ func handleIPAVerify(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse receipt
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(request.Receipt, &receipt); err != nil {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid receipt format",
-		})
-		return
-	}
-
-	// Extract student ID
-	studentID, ok := receipt["student_id"].(string)
-	if !ok {
-		respondJSON(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Missing student_id in receipt",
-		})
-		return
-	}
-
-	// Extract term receipts
-	termReceipts, ok := receipt["term_receipts"].(map[string]interface{})
-	if !ok {
+	journeyReceipt, err := receipt.Decode(request.Receipt)
+	if err != nil {
 		respondJSON(w, http.StatusBadRequest, APIResponse{
 			Success: false,
-			Error:   "Missing term_receipts in receipt",
+			Error:   fmt.Sprintf("Invalid receipt format: %v", err),
 		})
 		return
 	}
+	studentID := journeyReceipt.StudentID
+	noteVerifiedReceipt(r, "journey", contentID("jr_", journeyReceipt), studentID, "")
 
 	// Track verification results
 	verificationResults := make(map[string]interface{})
 	totalCourses := 0
 	verifiedCourses := 0
 	failedCourses := []string{}
+	failedTerms := []string{} // Terms whose root was not confirmed, none of their courses count
 
 	// Verify each term
-	for termID, termDataInterface := range termReceipts {
-		termData, ok := termDataInterface.(map[string]interface{})
-		if !ok {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "error",
-				"error":  "Invalid term data format",
-			}
-			continue
-		}
-
-		verkleRootHex, ok := termData["verkle_root"].(string)
-		if !ok {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "error",
-				"error":  "Missing verkle_root",
-			}
-			continue
-		}
-
-		// Parse Verkle root
-		verkleRoot, err := parseVerkleRoot(verkleRootHex)
-		if err != nil {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "error",
-				"error":  fmt.Sprintf("Invalid verkle_root: %v", err),
-			}
-			continue
-		}
-
-		// BLOCKCHAIN VERIFICATION: Check if verkle root exists on-chain
-		ctx := context.Background()
-		cfg, err := config.LoadConfig()
-		if err != nil {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "error",
-				"error":  "Blockchain configuration error",
-			}
-			continue
-		}
-
-		if cfg.ContractAddress == "" || cfg.IssuerPrivateKey == "" {
-			verificationResults[termID] = map[string]interface{}{
-				"status":         "error",
-				"error":          "Missing blockchain configuration",
-				"blockchain_check": false,
-			}
-			continue
-		}
-
-		blockchainIntegration, err := blockchain_integration.NewBlockchainIntegration(cfg.Network, cfg.IssuerPrivateKey, cfg.ContractAddress)
-		if err != nil {
-			verificationResults[termID] = map[string]interface{}{
-				"status":         "error",
-				"error":          fmt.Sprintf("Blockchain connection failed: %v", err),
-				"blockchain_check": false,
-			}
-			continue
-		}
+	for _, termID := range journeyReceipt.SortedTermIDs() {
+		termData := journeyReceipt.TermReceipts[termID]
+		verkleRoot := [32]byte(termData.VerkleRoot)
+		verkleRootHex := termData.VerkleRoot.String()
 
-		// Check root status on blockchain
-		rootStatus, err := blockchainIntegration.CheckRootStatus(ctx, verkleRootHex)
+		// BLOCKCHAIN VERIFICATION: Resolve the receipt's root through the registry
+		rootCheck, err := getRootOracle().Check(r.Context(), termID, verkleRootHex)
 		if err != nil {
 			verificationResults[termID] = map[string]interface{}{
 				"status":         "error",
 				"error":          fmt.Sprintf("Failed to check root status: %v", err),
 				"blockchain_check": false,
 			}
+			failedTerms = append(failedTerms, termID)
 			continue
 		}
-
-		// Check if root is invalid
-		if rootStatus.Status == 0 {
-			verificationResults[termID] = map[string]interface{}{
-				"status":         "error",
-				"error":          "Verkle root not published on blockchain - invalid receipt",
-				"blockchain_check": false,
-			}
-			continue
-		}
-
-		// Check if root is superseded
-		if rootStatus.Status == 3 {
+		if err := rootCheck.Err(); err != nil {
 			verificationResults[termID] = map[string]interface{}{
 				"status":         "error",
-				"error":          fmt.Sprintf("Receipt uses superseded root. %s", rootStatus.Message),
+				"error":          err.Error(),
 				"blockchain_check": false,
-				"version_status": "superseded",
-			}
-			continue
-		}
-
-		// Verify term_id matches
-		if rootStatus.TermID != termID {
-			verificationResults[termID] = map[string]interface{}{
-				"status":         "error",
-				"error":          fmt.Sprintf("Term ID mismatch: receipt claims %s but blockchain shows %s", termID, rootStatus.TermID),
-				"blockchain_check": false,
-			}
-			continue
-		}
-
-		log.Printf("✅ Blockchain verification passed for term %s: root exists on-chain", termID)
-
-		// Get receipt data
-		receiptData, ok := termData["receipt"].(map[string]interface{})
-		if !ok {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "success",
-				"note":   "No receipt data to verify",
-			}
-			continue
-		}
-
-		// Get course proofs and revealed courses
-		courseProofs, ok := receiptData["course_proofs"].(map[string]interface{})
-		if !ok {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "error",
-				"error":  "Missing course_proofs",
+				"version_status": rootCheck.State,
+				"root_status":    rootCheck,
 			}
+			failedTerms = append(failedTerms, termID)
 			continue
 		}
 
-		revealedCourses, ok := receiptData["revealed_courses"].([]interface{})
-		if !ok {
-			verificationResults[termID] = map[string]interface{}{
-				"status": "error",
-				"error":  "Missing revealed_courses",
-			}
-			continue
-		}
+		log.Printf("✅ Blockchain verification passed for term %s: root is the latest version (%s)", termID, rootCheck.Source)
 
-		// Verify each course cryptographically
+		receiptData := termData.Receipt
 		termResults := make(map[string]interface{})
 		termVerified := 0
 		termFailed := 0
 
-		for _, courseInterface := range revealedCourses {
-			courseMap, ok := courseInterface.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			courseID, ok := courseMap["course_id"].(string)
-			if !ok {
-				continue
+		// Batch receipts carry one multiproof for all revealed courses
+		if receiptData.IsBatch() {
+			courseResult := "verified"
+			if _, err := verifyBatchTermProof(studentID, receiptData, verkleRoot); err != nil {
+				courseResult = fmt.Sprintf("verification_failed: %v", err)
 			}
-
-			totalCourses++
-
-			// Get course proof
-			proofData, exists := courseProofs[courseID]
-			if !exists {
-				termResults[courseID] = "no_proof"
-				termFailed++
-				failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, courseID))
-				continue
+			for _, course := range receiptData.RevealedCourses {
+				totalCourses++
+				termResults[course.CourseID] = courseResult
+				if courseResult == "verified" {
+					termVerified++
+					verifiedCourses++
+				} else {
+					termFailed++
+					failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, course.CourseID))
+				}
 			}
+		}
 
-			// Convert proof to JSON bytes
-			proofBytes, err := json.Marshal(proofData)
-			if err != nil {
-				termResults[courseID] = fmt.Sprintf("proof_parse_error: %v", err)
-				termFailed++
-				failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, courseID))
-				continue
-			}
+		// Verify each course cryptographically (per-course receipts)
+		if !receiptData.IsBatch() {
+			for _, course := range receiptData.RevealedCourses {
+				courseID := course.CourseID
+				totalCourses++
+
+				// Get course proof
+				proofBytes, exists := receiptData.CourseProofs[courseID]
+				if !exists {
+					termResults[courseID] = "no_proof"
+					termFailed++
+					failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, courseID))
+					continue
+				}
 
-			// Convert course map to CourseCompletion
-			course, err := convertToCourseCompletion(courseMap)
-			if err != nil {
-				termResults[courseID] = fmt.Sprintf("course_parse_error: %v", err)
-				termFailed++
-				failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, courseID))
-				continue
-			}
+				// Generate course key
+				courseKey := fmt.Sprintf("%s:%s:%s", journeyReceipt.StudentDID(), termID, courseID)
 
-			// Generate course key
-			studentDID := fmt.Sprintf("did:example:%s", studentID)
-			courseKey := fmt.Sprintf("%s:%s:%s", studentDID, termID, courseID)
+				// Perform full IPA cryptographic verification
+				if _, err := verkle.VerifyCourseFieldProof(courseKey, course, proofBytes, verkleRoot); err != nil {
+					termResults[courseID] = fmt.Sprintf("verification_failed: %v", err)
+					termFailed++
+					failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, courseID))
+					continue
+				}
 
-			// Perform full IPA cryptographic verification
-			if err := verkle.VerifyCourseProof(courseKey, course, proofBytes, verkleRoot); err != nil {
-				termResults[courseID] = fmt.Sprintf("verification_failed: %v", err)
-				termFailed++
-				failedCourses = append(failedCourses, fmt.Sprintf("%s:%s", termID, courseID))
-				continue
+				termResults[courseID] = "verified"
+				termVerified++
+				verifiedCourses++
 			}
-
-			termResults[courseID] = "verified"
-			termVerified++
-			verifiedCourses++
 		}
 
 		// Try to get blockchain transaction info from database term_receipts table
@@ -2501,7 +2320,8 @@ func handleIPAVerify(w http.ResponseWriter, r *http.Request) {
 				"courses_failed":      termFailed,
 				"course_results":      termResults,
 				"blockchain_verified": true,
-				"blockchain_published_at": rootStatus.Version.String(),
+				"blockchain_published_at": fmt.Sprint(rootCheck.Version),
+				"root_status":         rootCheck,
 				"blockchain_tx_hash":  blockchainTxHash,
 				"blockchain_block":    blockchainBlock,
 			}
@@ -2514,19 +2334,23 @@ func handleIPAVerify(w http.ResponseWriter, r *http.Request) {
 				"courses_failed":      termFailed,
 				"course_results":      termResults,
 				"blockchain_verified": true,
-				"blockchain_published_at": rootStatus.Version.String(),
+				"blockchain_published_at": fmt.Sprint(rootCheck.Version),
+				"root_status":         rootCheck,
 			}
 		}
 	}
 
 	// Overall status
 	overallStatus := "success"
-	if len(failedCourses) > 0 {
+	if len(failedCourses) > 0 || len(failedTerms) > 0 {
 		overallStatus = "partial_failure"
 	}
-	if verifiedCourses == 0 && totalCourses > 0 {
+	if verifiedCourses == 0 && (totalCourses > 0 || len(failedTerms) > 0) {
 		overallStatus = "failure"
 	}
+	if overallStatus != "success" {
+		noteVerificationFailure(r, fmt.Sprintf("%d of %d courses failed, roots of %d terms not confirmed", len(failedCourses), totalCourses, len(failedTerms)))
+	}
 
 	respondJSON(w, http.StatusOK, APIResponse{
 		Success: overallStatus == "success",
@@ -2537,24 +2361,19 @@ func handleIPAVerify(w http.ResponseWriter, r *http.Request) {
 			"verified_courses": verifiedCourses,
 			"failed_courses":   len(failedCourses),
 			"failed_list":      failedCourses,
+			"failed_terms":     failedTerms,
 			"term_results":     verificationResults,
 			"computation_note": "Full IPA cryptographic verification performed on backend",
 		},
 	})
 }
 
-// Helper function to get keys from a map
-func getKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
-
 func init() {
 	serveCmd.Flags().String("port", "8080", "Port to serve the API on")
 	serveCmd.Flags().Bool("cors", true, "Enable CORS for React development")
+	serveCmd.Flags().Bool("index-chain", true, "Run the chain indexer in the background")
+	serveCmd.Flags().Bool("watch-reorgs", true, "Re-check recent publications for reorgs in the background")
+	serveCmd.Flags().Bool("deliver-webhooks", true, "Send and retry webhook deliveries in the background")
 	rootCmd.AddCommand(serveCmd)
 }
 
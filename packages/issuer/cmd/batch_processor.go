@@ -18,7 +18,9 @@ var batchProcessCmd = &cobra.Command{
 		fmt.Println("🏭 Starting Batch Processing Pipeline")
 		fmt.Println("=" + string(make([]byte, 50)))
 		
-		if err := runBatchProcessing(); err != nil {
+		workers, _ := cmd.Flags().GetInt("workers")
+		
+		if err := runBatchProcessing(workers); err != nil {
 			fmt.Printf("❌ Batch processing failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -27,7 +29,7 @@ var batchProcessCmd = &cobra.Command{
 	},
 }
 
-func runBatchProcessing() error {
+func runBatchProcessing(workers int) error {
 	// Step 1: Discovery available terms from generated data
 	fmt.Println("\n🔍 Step 1: Discovering available terms...")
 	
@@ -75,7 +77,7 @@ func runBatchProcessing() error {
 		fmt.Printf("  🌳 Building Merkle/Verkle trees...\n")
 		dataFile := filepath.Join("data/converted_terms", fmt.Sprintf("%s_completions.json", termID))
 		
-		if err := addAcademicTerm(termID, dataFile, "json", true); err != nil {
+		if err := addAcademicTerm(termID, dataFile, "json", true, false, workers); err != nil {
 			fmt.Printf("  ⚠️  Warning: Failed to process %s: %v\n", termID, err)
 			continue
 		}
@@ -111,5 +113,6 @@ func runBatchProcessing() error {
 }
 
 func init() {
+	batchProcessCmd.Flags().Int("workers", 0, "number of parallel workers per term (0 = one per CPU)")
 	rootCmd.AddCommand(batchProcessCmd)
 }
\ No newline at end of file
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -22,8 +23,10 @@ var batchReceiptCmd = &cobra.Command{
 		selective, _ := cmd.Flags().GetBool("selective")
 		specificTerms, _ := cmd.Flags().GetStringSlice("terms")
 		specificCourses, _ := cmd.Flags().GetStringSlice("courses")
+		batchProof, _ := cmd.Flags().GetBool("batch-proof")
+		workers, _ := cmd.Flags().GetInt("workers")
 		
-		if err := runBatchReceiptGeneration(outputDir, selective, specificTerms, specificCourses); err != nil {
+		if err := runBatchReceiptGeneration(outputDir, selective, batchProof, specificTerms, specificCourses, workers); err != nil {
 			log.Fatalf("❌ Batch receipt generation failed: %v", err)
 		}
 		
@@ -31,7 +34,7 @@ var batchReceiptCmd = &cobra.Command{
 	},
 }
 
-func runBatchReceiptGeneration(outputDir string, selective bool, terms, courses []string) error {
+func runBatchReceiptGeneration(outputDir string, selective, batchProof bool, terms, courses []string, workers int) error {
 	// Step 1: Discover all students
 	fmt.Println("\n🔍 Step 1: Discovering available students...")
 	students, err := discoverAllStudents()
@@ -62,32 +65,36 @@ func runBatchReceiptGeneration(outputDir string, selective bool, terms, courses
 	successCount := 0
 	failureCount := 0
 	
-	for i, studentID := range students {
-		fmt.Printf("  [%d/%d] 📋 Generating receipt for %s...\n", i+1, len(students), studentID)
-		
-		// Create filename
-		suffix := "complete"
-		if selective && len(terms) > 0 {
-			suffix = "terms"
-		}
-		if selective && len(courses) > 0 {
-			suffix = "courses"  
-		}
-		
-		filename := fmt.Sprintf("%s_%s_journey.json", studentID, suffix)
-		outputFile := filepath.Join(outputDir, filename)
+	// Create filename suffix
+	suffix := "complete"
+	if selective && len(terms) > 0 {
+		suffix = "terms"
+	}
+	if selective && len(courses) > 0 {
+		suffix = "courses"  
+	}
+	
+	// Term trees are loaded once and shared by all workers
+	trees := newTermTreeSet()
+	defer trees.Close()
+	
+	runOrdered(len(students), workers, func(i int, out io.Writer) error {
+		studentID := students[i]
+		fmt.Fprintf(out, "  [%d/%d] 📋 Generating receipt for %s...\n", i+1, len(students), studentID)
 		
-		// Generate receipt
-		err := generateStudentReceipt(studentID, outputFile, terms, courses, selective)
+		outputFile := filepath.Join(outputDir, fmt.Sprintf("%s_%s_journey.json", studentID, suffix))
+		return generateStudentReceiptWith(out, trees, studentID, outputFile, terms, courses, nil, selective, batchProof)
+	}, func(i int, output []byte, err error) {
+		os.Stdout.Write(output)
 		if err != nil {
-			fmt.Printf("    ❌ Failed to generate receipt for %s: %v\n", studentID, err)
+			fmt.Printf("    ❌ Failed to generate receipt for %s: %v\n", students[i], err)
 			failureCount++
-			continue
+			return
 		}
 		
-		fmt.Printf("    ✅ Receipt generated: %s\n", filename)
+		fmt.Printf("    ✅ Receipt generated: %s_%s_journey.json\n", students[i], suffix)
 		successCount++
-	}
+	})
 	
 	// Step 4: Show summary
 	fmt.Printf("\n📊 Batch Generation Summary:\n")
@@ -135,6 +142,8 @@ func init() {
 	batchReceiptCmd.Flags().BoolP("selective", "s", false, "Use selective disclosure mode")
 	batchReceiptCmd.Flags().StringSliceP("terms", "t", []string{}, "Specific terms to include (comma-separated)")
 	batchReceiptCmd.Flags().StringSliceP("courses", "c", []string{}, "Specific courses to include (comma-separated)")
+	batchReceiptCmd.Flags().Bool("batch-proof", false, "Use one Verkle multiproof per term instead of one proof per course")
+	batchReceiptCmd.Flags().IntP("workers", "w", 0, "Number of students to process in parallel (0 = one per CPU)")
 	
 	rootCmd.AddCommand(batchReceiptCmd)
 }
\ No newline at end of file
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultIndexerBatchSize is the number of blocks fetched per log query, within
+	// the range limits of common RPC providers
+	defaultIndexerBatchSize = 2000
+	// defaultIndexerInterval is how often a running indexer polls for new blocks
+	defaultIndexerInterval = 15 * time.Second
+)
+
+var indexChainCmd = &cobra.Command{
+	Use:   "index-chain",
+	Short: "Sync registry events into the database",
+	Long: `Tail the TermRootPublished and TermRootSuperseded events of IUMiCertRegistry and upsert
+term root versions and blockchain transactions into the database.
+
+Syncing resumes from the checkpoint stored in the database, so the indexer can be stopped
+and restarted at any time. Without --follow it syncs up to the chain head and exits.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		network := networkFlag(cmd)
+		fromBlock, _ := cmd.Flags().GetUint64("from-block")
+		follow, _ := cmd.Flags().GetBool("follow")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		batchSize, _ := cmd.Flags().GetUint64("batch-size")
+
+		indexer, err := newChainIndexerForNetwork(network, fromBlock, cmd.Flags().Changed("from-block"))
+		if err != nil {
+			log.Fatalf("❌ Failed to start chain indexer: %v", err)
+		}
+		defer indexer.Close()
+		if batchSize > 0 {
+			indexer.batchSize = batchSize
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if follow {
+			indexer.Run(ctx, interval)
+			return
+		}
+		count, err := indexer.SyncOnce(ctx)
+		if err != nil {
+			log.Fatalf("❌ Chain indexing failed: %v", err)
+		}
+		fmt.Printf("✅ Indexed %d registry events\n", count)
+	},
+}
+
+// chainIndexer copies registry events into term_root_versions and blockchain_transactions.
+// Each batch of blocks is written in one database transaction together with the checkpoint,
+// so a batch is either fully indexed or retried after a restart.
+type chainIndexer struct {
+	db          *gorm.DB
+	integration *blockchain.BlockchainIntegration
+	contract    string
+	startBlock  uint64
+	batchSize   uint64
+}
+
+func newChainIndexer(db *gorm.DB, integration *blockchain.BlockchainIntegration, startBlock uint64) (*chainIndexer, error) {
+	// The checkpoint table is newer than most deployments' schema
+	if err := db.AutoMigrate(&database.ChainIndexerCheckpoint{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate indexer checkpoint table: %w", err)
+	}
+
+	return &chainIndexer{
+		db:          db,
+		integration: integration,
+		contract:    strings.ToLower(integration.ContractAddress().Hex()),
+		startBlock:  startBlock,
+		batchSize:   defaultIndexerBatchSize,
+	}, nil
+}
+
+// newChainIndexerForNetwork connects to the database and the registry of a network.
+// Unless overrideStart is set, the start block comes from INDEXER_START_BLOCK.
+func newChainIndexerForNetwork(network string, startBlock uint64, overrideStart bool) (*chainIndexer, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.Network = network
+	if !overrideStart {
+		startBlock = cfg.IndexerStartBlock
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+
+	// The indexer only reads events, so it needs no signer
+	integration, err := blockchain.NewReadOnlyBlockchainIntegration(network, cfg.GetContractAddress())
+	if err != nil {
+		database.Close(db)
+		return nil, fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+
+	indexer, err := newChainIndexer(db, integration, startBlock)
+	if err != nil {
+		integration.Close()
+		database.Close(db)
+		return nil, err
+	}
+	return indexer, nil
+}
+
+// Close releases the database and chain connections
+func (ci *chainIndexer) Close() {
+	ci.integration.Close()
+	database.Close(ci.db)
+}
+
+// Run syncs until ctx is cancelled, polling for new blocks every interval.
+// Errors are logged and retried on the next poll.
+func (ci *chainIndexer) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultIndexerInterval
+	}
+	log.Printf("🔭 Chain indexer watching registry %s every %s", ci.contract, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if count, err := ci.SyncOnce(ctx); err != nil {
+			log.Printf("⚠️  Chain indexer sync failed: %v", err)
+		} else if count > 0 {
+			log.Printf("✅ Chain indexer stored %d registry events", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("🛑 Chain indexer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce indexes every confirmed block from the checkpoint up to the chain head and
+// returns the number of events stored
+func (ci *chainIndexer) SyncOnce(ctx context.Context) (int, error) {
+	head, err := ci.integration.LatestBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Events are only indexed once their block is confirmed
+	if confirmations := ci.integration.Confirmations(); confirmations > 1 {
+		if head < confirmations-1 {
+			return 0, nil
+		}
+		head -= confirmations - 1
+	}
+
+	from := ci.startBlock
+	checkpoint, err := database.GetIndexerCheckpoint(ci.db, ci.contract)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load indexer checkpoint: %w", err)
+	}
+	if checkpoint != nil {
+		if checkpoint.LastBlock > head {
+			// A local chain (Anvil, simulated) was restarted; its history starts over
+			log.Printf("⚠️  Indexer checkpoint %d is ahead of chain head %d, re-indexing from block %d", checkpoint.LastBlock, head, ci.startBlock)
+		} else {
+			from = checkpoint.LastBlock + 1
+		}
+	}
+
+	total := 0
+	for from <= head {
+		to := from + ci.batchSize - 1
+		if to > head {
+			to = head
+		}
+
+		count, err := ci.indexRange(ctx, from, to)
+		if err != nil {
+			return total, fmt.Errorf("failed to index blocks %d-%d: %w", from, to, err)
+		}
+		total += count
+		from = to + 1
+	}
+	return total, nil
+}
+
+// indexRange stores the events of blocks [from, to] and advances the checkpoint to `to`
+func (ci *chainIndexer) indexRange(ctx context.Context, from, to uint64) (int, error) {
+	events, err := ci.integration.FetchRegistryEvents(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	// Receipts are fetched before the database transaction is opened; a supersede
+	// transaction emits both a published and a superseded event
+	gasUsed := make(map[common.Hash]uint64)
+	for _, event := range events {
+		if _, ok := gasUsed[event.TxHash]; ok {
+			continue
+		}
+		receipt, err := ci.integration.TransactionReceipt(ctx, event.TxHash)
+		if err != nil {
+			return 0, err
+		}
+		gasUsed[event.TxHash] = receipt.GasUsed
+	}
+
+	err = ci.db.Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			if err := applyRegistryEvent(tx, event, gasUsed[event.TxHash]); err != nil {
+				return err
+			}
+		}
+		if err := database.SaveIndexerCheckpoint(tx, ci.contract, to); err != nil {
+			return fmt.Errorf("failed to save indexer checkpoint: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+// applyRegistryEvent upserts the rows an event describes. Applying the same event twice
+// leaves the database unchanged.
+func applyRegistryEvent(tx *gorm.DB, event blockchain.RegistryEvent, gasUsed uint64) error {
+	rootHex := fmt.Sprintf("0x%x", event.Root)
+
+	switch event.Kind {
+	case blockchain.EventTermRootPublished:
+		version := &database.TermRootVersion{
+			TermID:        event.TermID,
+			Version:       uint(event.Version),
+			RootHash:      rootHex,
+			TotalStudents: uint(event.TotalStudents),
+			PublishedAt:   event.Timestamp,
+			TxHash:        event.TxHash.Hex(),
+			BlockNumber:   event.BlockNumber,
+		}
+		if err := database.UpsertTermRootVersionFromChain(tx, version); err != nil {
+			return fmt.Errorf("failed to store version %d of %s: %w", event.Version, event.TermID, err)
+		}
+
+		confirmedAt := event.Timestamp
+		transaction := &database.BlockchainTransaction{
+			TxHash:      event.TxHash.Hex(),
+			TermID:      event.TermID,
+			VerkleRoot:  event.Root[:],
+			BlockNumber: event.BlockNumber,
+			GasUsed:     gasUsed,
+			Status:      "confirmed",
+			SubmittedAt: event.Timestamp,
+			ConfirmedAt: &confirmedAt,
+		}
+		if err := database.UpsertBlockchainTransaction(tx, transaction); err != nil {
+			return fmt.Errorf("failed to store transaction %s: %w", event.TxHash.Hex(), err)
+		}
+
+	case blockchain.EventTermRootSuperseded:
+		oldRootHex := fmt.Sprintf("0x%x", event.OldRoot)
+		if err := database.MarkRootSuperseded(tx, oldRootHex, rootHex, event.Reason); err != nil {
+			return fmt.Errorf("failed to mark version %d of %s superseded: %w", event.OldVersion, event.TermID, err)
+		}
+	}
+	return nil
+}
+
+// startBackgroundIndexer runs the chain indexer for the configured network alongside the
+// API server until ctx ends. The server still starts if the database or chain is unavailable.
+func startBackgroundIndexer(ctx context.Context) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("⚠️  Chain indexer disabled: failed to load config: %v", err)
+		return
+	}
+
+	indexer, err := newChainIndexerForNetwork(cfg.Network, 0, false)
+	if err != nil {
+		log.Printf("⚠️  Chain indexer disabled: %v", err)
+		return
+	}
+
+	go func() {
+		defer indexer.Close()
+		indexer.Run(ctx, defaultIndexerInterval)
+	}()
+}
+
+func init() {
+	indexChainCmd.Flags().String("network", "sepolia", "blockchain network from config/networks.json, or simulated; NETWORK overrides the default")
+	indexChainCmd.Flags().Uint64("from-block", 0, "block to start from when the registry has no checkpoint (default INDEXER_START_BLOCK)")
+	indexChainCmd.Flags().BoolP("follow", "f", false, "keep polling for new blocks after catching up")
+	indexChainCmd.Flags().Duration("interval", defaultIndexerInterval, "polling interval with --follow")
+	indexChainCmd.Flags().Uint64("batch-size", defaultIndexerBatchSize, "number of blocks per log query")
+
+	rootCmd.AddCommand(indexChainCmd)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"iumicert/crypto/receipt"
 	"iumicert/issuer/database"
 	"io/ioutil"
 	"log"
@@ -167,11 +168,12 @@ func importTerms(repo *database.ReceiptRepository) error {
 			continue
 		}
 
-		// Create term record
+		// Create term record, dated from its ID so term lists sort chronologically
+		startDate, endDate := termDates(termID)
 		term := &database.Term{
 			TermID:          termID,
-			StartDate:       time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), // Placeholder
-			EndDate:         time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC), // Placeholder
+			StartDate:       startDate,
+			EndDate:         endDate,
 			VerkleRootHex:   verkleRootHex,
 			VerkleRootBytes: verkleRootBytes,
 		}
@@ -209,42 +211,17 @@ func importTermReceipts(repo *database.ReceiptRepository) error {
 			continue
 		}
 
-		var receipt map[string]interface{}
-		if err := json.Unmarshal(data, &receipt); err != nil {
+		journeyReceipt, err := receipt.Decode(data)
+		if err != nil {
 			log.Printf("  ⚠️  Failed to parse %s: %v", file, err)
 			continue
 		}
 
-		studentID := receipt["student_id"].(string)
-		termReceipts := receipt["term_receipts"].(map[string]interface{})
+		studentID := journeyReceipt.StudentID
 
 		// Import each term receipt
-		for termID, termData := range termReceipts {
-			termMap := termData.(map[string]interface{})
-			receiptData := termMap["receipt"].(map[string]interface{})
-
-			// Extract data
-			courseProofs := receiptData["course_proofs"]
-			revealedCourses := receiptData["revealed_courses"]
-			verkleRootHex := receiptData["verkle_root"].(string)
-
-			// Marshal to JSON
-			verkleProofJSON, _ := json.Marshal(courseProofs)
-			revealedCoursesJSON, _ := json.Marshal(revealedCourses)
-
-			// Create term receipt
-			termReceipt := &database.TermReceipt{
-				ReceiptID:       fmt.Sprintf("receipt_%s_%s_%d", studentID, termID, time.Now().Unix()),
-				StudentID:       studentID,
-				TermID:          termID,
-				VerkleProof:     datatypes.JSON(verkleProofJSON),
-				RevealedCourses: datatypes.JSON(revealedCoursesJSON),
-				StateDiff:       datatypes.JSON("[]"), // Placeholder
-				CourseCount:     len(revealedCourses.([]interface{})),
-				VerkleRootHex:   verkleRootHex,
-				GeneratedAt:     time.Now(),
-				IsSelective:     false,
-			}
+		for _, termID := range journeyReceipt.SortedTermIDs() {
+			termReceipt := newTermReceiptRecord(studentID, journeyReceipt.TermReceipts[termID])
 
 			if err := repo.StoreTermReceipt(termReceipt); err != nil {
 				log.Printf("  ⚠️  Failed to store receipt for %s/%s: %v", studentID, termID, err)
@@ -261,6 +238,25 @@ func importTermReceipts(repo *database.ReceiptRepository) error {
 	return nil
 }
 
+// newTermReceiptRecord converts a term of a journey receipt to its database record
+func newTermReceiptRecord(studentID string, term receipt.TermReceipt) *database.TermReceipt {
+	verkleProofJSON, _ := json.Marshal(term.Receipt.CourseProofs)
+	revealedCoursesJSON, _ := json.Marshal(term.Receipt.RevealedCourses)
+
+	return &database.TermReceipt{
+		ReceiptID:       fmt.Sprintf("receipt_%s_%s_%d", studentID, term.TermID, time.Now().Unix()),
+		StudentID:       studentID,
+		TermID:          term.TermID,
+		VerkleProof:     datatypes.JSON(verkleProofJSON),
+		RevealedCourses: datatypes.JSON(revealedCoursesJSON),
+		StateDiff:       datatypes.JSON("[]"), // Placeholder
+		CourseCount:     len(term.Receipt.RevealedCourses),
+		VerkleRootHex:   term.VerkleRoot.String(),
+		GeneratedAt:     time.Now(),
+		IsSelective:     false,
+	}
+}
+
 // Helper function to convert hex string to bytes
 func hexToBytes(hexStr string) ([]byte, error) {
 	// Remove 0x prefix if present
@@ -0,0 +1,715 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"iumicert/issuer/database"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Job kinds run by the job queue of serve
+const (
+	jobPublishRoots       = "publish-roots"
+	jobProcessRevocations = "process-revocations"
+	jobDemoGenerateFull   = "demo-generate-full"
+)
+
+// Job and step statuses
+const (
+	jobQueued      = "queued"
+	jobRunning     = "running"
+	jobSucceeded   = "succeeded"
+	jobFailed      = "failed"
+	jobInterrupted = "interrupted"
+	stepPending    = "pending"
+	stepSkipped    = "skipped"
+)
+
+// jobEventKeepAlive is how often an idle job event stream sends a comment
+const jobEventKeepAlive = 15 * time.Second
+
+var errJobNotFound = errors.New("job not found")
+
+// jobKind runs the jobs of one kind
+type jobKind struct {
+	run       func(ctx context.Context, job *jobRun) (interface{}, error)
+	resumable bool // Safe to run again from the start when serve stopped during a run
+}
+
+// Publications and supersessions are not resumed: serve may have stopped after sending a
+// transaction, and running them again could publish twice
+var jobKinds = map[string]jobKind{
+	jobPublishRoots:       {run: runPublishRootsJob},
+	jobProcessRevocations: {run: runProcessRevocationsJob},
+	jobDemoGenerateFull:   {run: runDemoGenerateFullJob, resumable: true},
+}
+
+// issuerJobs is the job queue of the running API server
+var issuerJobs *jobQueue
+
+// jobStep is the progress of one step of a job
+type jobStep struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Message    string     `json:"message,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// jobView is a job as returned by the API and the event stream
+type jobView struct {
+	JobID       string          `json:"job_id"`
+	Kind        string          `json:"kind"`
+	Status      string          `json:"status"`
+	Params      json.RawMessage `json:"params,omitempty"`
+	Steps       []jobStep       `json:"steps"`
+	StepsDone   int             `json:"steps_done"`
+	StepsTotal  int             `json:"steps_total"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	RequestedBy string          `json:"requested_by"`
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+func newJobView(job *database.Job) jobView {
+	view := jobView{
+		JobID:       job.JobID,
+		Kind:        job.Kind,
+		Status:      job.Status,
+		Params:      json.RawMessage(job.Params),
+		Steps:       []jobStep{},
+		Result:      json.RawMessage(job.Result),
+		Error:       job.Error,
+		RequestedBy: job.RequestedBy,
+		Attempts:    job.Attempts,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+	if len(job.Steps) > 0 {
+		json.Unmarshal(job.Steps, &view.Steps)
+	}
+	for _, step := range view.Steps {
+		if step.Status == jobSucceeded || step.Status == jobFailed || step.Status == stepSkipped {
+			view.StepsDone++
+		}
+	}
+	view.StepsTotal = len(view.Steps)
+	return view
+}
+
+// finished reports whether the job will not change any more
+func (v jobView) finished() bool {
+	return v.Status == jobSucceeded || v.Status == jobFailed || v.Status == jobInterrupted
+}
+
+// jobStore persists jobs
+type jobStore interface {
+	SaveJob(job *database.Job) error
+	GetJob(jobID string) (*database.Job, error)
+	RecentJobs(limit int) ([]database.Job, error)
+	UnfinishedJobs() ([]database.Job, error)
+}
+
+// dbJobStore keeps jobs in the jobs table
+type dbJobStore struct {
+	db *gorm.DB
+}
+
+func (s dbJobStore) SaveJob(job *database.Job) error {
+	return database.SaveJob(s.db, job)
+}
+
+func (s dbJobStore) GetJob(jobID string) (*database.Job, error) {
+	job, err := database.GetJob(s.db, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errJobNotFound
+	}
+	return job, err
+}
+
+func (s dbJobStore) RecentJobs(limit int) ([]database.Job, error) {
+	return database.GetRecentJobs(s.db, limit)
+}
+
+func (s dbJobStore) UnfinishedJobs() ([]database.Job, error) {
+	return database.GetUnfinishedJobs(s.db)
+}
+
+// memJobStore keeps jobs in memory, for serve without a database. Jobs are lost on restart.
+type memJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]database.Job
+}
+
+func newMemJobStore() *memJobStore {
+	return &memJobStore{jobs: make(map[string]database.Job)}
+}
+
+func (s *memJobStore) SaveJob(job *database.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.UpdatedAt = time.Now()
+	s.jobs[job.JobID] = *job
+	return nil
+}
+
+func (s *memJobStore) GetJob(jobID string) (*database.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, errJobNotFound
+	}
+	return &job, nil
+}
+
+func (s *memJobStore) RecentJobs(limit int) ([]database.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]database.Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+func (s *memJobStore) UnfinishedJobs() ([]database.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var jobs []database.Job
+	for _, job := range s.jobs {
+		if job.Status == jobQueued || job.Status == jobRunning {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+// jobQueue runs jobs one at a time, in the order they were queued. Publications and
+// supersessions share the issuer account and the tree files, so they never run in parallel.
+type jobQueue struct {
+	store jobStore
+
+	mu          sync.Mutex
+	pending     []*jobRun
+	current     *jobRun
+	wake        chan struct{}
+	subscribers map[string]map[chan jobView]struct{}
+}
+
+func newJobQueue(store jobStore) *jobQueue {
+	return &jobQueue{
+		store:       store,
+		wake:        make(chan struct{}, 1),
+		subscribers: make(map[string]map[chan jobView]struct{}),
+	}
+}
+
+// startJobQueue starts the job queue of serve. Jobs are kept in the database; without one
+// they are kept in memory and lost on restart.
+func startJobQueue(ctx context.Context) *jobQueue {
+	var store jobStore
+	db, err := database.Connect()
+	if err == nil {
+		// The jobs table is newer than most deployments' schema
+		err = db.AutoMigrate(&database.Job{})
+	}
+	if err != nil {
+		log.Printf("⚠️  Jobs are kept in memory and lost on restart: %v", err)
+		store = newMemJobStore()
+	} else {
+		store = dbJobStore{db: db}
+	}
+
+	queue := newJobQueue(store)
+	if err := queue.resumeUnfinished(); err != nil {
+		log.Printf("⚠️  Failed to resume unfinished jobs: %v", err)
+	}
+	go queue.work(ctx)
+	return queue
+}
+
+// resumeUnfinished queues the jobs left over by the previous run of serve. Queued jobs and
+// running jobs of resumable kinds start again from the beginning; other running jobs are
+// marked interrupted so that an operator checks what they did.
+func (q *jobQueue) resumeUnfinished() error {
+	jobs, err := q.store.UnfinishedJobs()
+	if err != nil {
+		return err
+	}
+
+	for i := range jobs {
+		run := &jobRun{queue: q, job: jobs[i]}
+		if len(run.job.Steps) > 0 {
+			json.Unmarshal(run.job.Steps, &run.steps)
+		}
+		kind, known := jobKinds[run.job.Kind]
+
+		switch {
+		case !known:
+			run.end(jobFailed, fmt.Sprintf("unknown job kind %q", run.job.Kind))
+			continue
+		case run.job.Status == jobRunning && !kind.resumable:
+			run.end(jobInterrupted, "serve stopped while the job was running; check its outcome before starting it again")
+			log.Printf("⚠️  Job %s (%s) was interrupted by a restart", run.job.JobID, run.job.Kind)
+			continue
+		case run.job.Status == jobRunning:
+			log.Printf("🔁 Resuming job %s (%s) after a restart", run.job.JobID, run.job.Kind)
+			run.job.Status = jobQueued
+			run.steps = nil
+			run.save()
+		}
+		q.push(run)
+	}
+	return nil
+}
+
+// Enqueue queues a job of kind for params. When the same job is already queued or running
+// it is returned instead, with existing set.
+func (q *jobQueue) Enqueue(kind string, params interface{}, requestedBy string) (view jobView, existing bool, err error) {
+	if _, ok := jobKinds[kind]; !ok {
+		return jobView{}, false, fmt.Errorf("unknown job kind %q", kind)
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return jobView{}, false, fmt.Errorf("failed to encode job parameters: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, run := range append([]*jobRun{q.current}, q.pending...) {
+		if run != nil && run.job.Kind == kind && bytes.Equal(run.job.Params, paramsJSON) {
+			// The worker may be changing the run, the store has its last saved state
+			job, err := q.store.GetJob(run.job.JobID)
+			if err != nil {
+				return jobView{}, false, err
+			}
+			return newJobView(job), true, nil
+		}
+	}
+
+	run := &jobRun{queue: q, job: database.Job{
+		JobID:       fmt.Sprintf("job_%s", uuid.New().String()),
+		Kind:        kind,
+		Status:      jobQueued,
+		Params:      datatypes.JSON(paramsJSON),
+		Steps:       datatypes.JSON("[]"),
+		RequestedBy: requestedBy,
+		CreatedAt:   time.Now(),
+	}}
+	if err := q.store.SaveJob(&run.job); err != nil {
+		return jobView{}, false, fmt.Errorf("failed to save job: %w", err)
+	}
+	q.pending = append(q.pending, run)
+	q.signal()
+
+	log.Printf("🧵 Job %s (%s) queued by %s", run.job.JobID, kind, requestedBy)
+	return newJobView(&run.job), false, nil
+}
+
+func (q *jobQueue) push(run *jobRun) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, run)
+	q.signal()
+}
+
+func (q *jobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work runs queued jobs until ctx is cancelled
+func (q *jobQueue) work(ctx context.Context) {
+	for {
+		q.mu.Lock()
+		var run *jobRun
+		if len(q.pending) > 0 {
+			run, q.pending = q.pending[0], q.pending[1:]
+		}
+		q.current = run
+		q.mu.Unlock()
+
+		if run != nil {
+			q.execute(ctx, run)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *jobQueue) execute(ctx context.Context, run *jobRun) {
+	now := time.Now()
+	run.job.Status = jobRunning
+	run.job.StartedAt = &now
+	run.job.FinishedAt = nil
+	run.job.Attempts++
+	run.save()
+	log.Printf("🧵 Job %s (%s) started", run.job.JobID, run.job.Kind)
+
+	result, err := run.runKind(ctx, jobKinds[run.job.Kind])
+	if err != nil {
+		log.Printf("❌ Job %s (%s) failed: %v", run.job.JobID, run.job.Kind, err)
+		run.end(jobFailed, err.Error())
+		return
+	}
+
+	if result != nil {
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			run.end(jobFailed, fmt.Sprintf("failed to encode job result: %v", err))
+			return
+		}
+		run.job.Result = datatypes.JSON(resultJSON)
+	}
+	log.Printf("✅ Job %s (%s) succeeded", run.job.JobID, run.job.Kind)
+	run.end(jobSucceeded, "")
+}
+
+// subscribe returns a channel receiving the job after each change. Only the latest state
+// is kept when the reader falls behind.
+func (q *jobQueue) subscribe(jobID string) (<-chan jobView, func()) {
+	ch := make(chan jobView, 1)
+	q.mu.Lock()
+	if q.subscribers[jobID] == nil {
+		q.subscribers[jobID] = make(map[chan jobView]struct{})
+	}
+	q.subscribers[jobID][ch] = struct{}{}
+	q.mu.Unlock()
+
+	return ch, func() {
+		q.mu.Lock()
+		delete(q.subscribers[jobID], ch)
+		if len(q.subscribers[jobID]) == 0 {
+			delete(q.subscribers, jobID)
+		}
+		q.mu.Unlock()
+	}
+}
+
+func (q *jobQueue) publish(view jobView) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for ch := range q.subscribers[view.JobID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- view
+	}
+}
+
+// jobRun is a job being run. Only the worker running it changes it.
+type jobRun struct {
+	queue *jobQueue
+	job   database.Job
+	steps []jobStep
+}
+
+// Params decodes the parameters of the job into v
+func (j *jobRun) Params(v interface{}) error {
+	if err := json.Unmarshal(j.job.Params, v); err != nil {
+		return fmt.Errorf("invalid job parameters: %w", err)
+	}
+	return nil
+}
+
+// Actor is the principal that started the job
+func (j *jobRun) Actor() string {
+	return j.job.RequestedBy
+}
+
+// Plan lists the steps of the job, so that progress shows how many are left
+func (j *jobRun) Plan(names ...string) {
+	j.steps = j.steps[:0]
+	for _, name := range names {
+		j.steps = append(j.steps, jobStep{Name: name, Status: stepPending})
+	}
+	j.save()
+}
+
+// Step finishes the running step and starts the step called name
+func (j *jobRun) Step(name string) {
+	now := time.Now()
+	j.finishStep(jobSucceeded, "")
+
+	index := -1
+	for i, step := range j.steps {
+		if step.Name == name && step.Status == stepPending {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		j.steps = append(j.steps, jobStep{Name: name})
+		index = len(j.steps) - 1
+	}
+	j.steps[index].Status = jobRunning
+	j.steps[index].StartedAt = &now
+	j.save()
+}
+
+// Logf sets the message of the running step
+func (j *jobRun) Logf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Printf("🧵 %s: %s", j.job.JobID, message)
+	for i := range j.steps {
+		if j.steps[i].Status == jobRunning {
+			j.steps[i].Message = message
+		}
+	}
+	j.save()
+}
+
+// StepFailed marks the running step failed without failing the job
+func (j *jobRun) StepFailed(err error) {
+	j.finishStep(jobFailed, err.Error())
+	j.save()
+}
+
+func (j *jobRun) finishStep(status, message string) {
+	now := time.Now()
+	for i := range j.steps {
+		if j.steps[i].Status == jobRunning {
+			j.steps[i].Status = status
+			j.steps[i].FinishedAt = &now
+			if message != "" {
+				j.steps[i].Message = message
+			}
+		}
+	}
+}
+
+// runKind runs the job, turning a panic into an error so that it does not stop serve
+func (j *jobRun) runKind(ctx context.Context, kind jobKind) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return kind.run(ctx, j)
+}
+
+// end finishes the job with status; steps that did not run are skipped
+func (j *jobRun) end(status, errMessage string) {
+	stepStatus := jobSucceeded
+	if status != jobSucceeded {
+		stepStatus = status
+	}
+	j.finishStep(stepStatus, errMessage)
+	for i := range j.steps {
+		if j.steps[i].Status == stepPending {
+			j.steps[i].Status = stepSkipped
+		}
+	}
+
+	now := time.Now()
+	j.job.Status = status
+	j.job.Error = errMessage
+	j.job.FinishedAt = &now
+	j.save()
+}
+
+// save persists the job and sends it to the subscribers of its events
+func (j *jobRun) save() {
+	if j.steps != nil {
+		if steps, err := json.Marshal(j.steps); err == nil {
+			j.job.Steps = datatypes.JSON(steps)
+		}
+	}
+	if err := j.queue.store.SaveJob(&j.job); err != nil {
+		log.Printf("⚠️  Failed to save job %s: %v", j.job.JobID, err)
+	}
+	j.queue.publish(newJobView(&j.job))
+}
+
+// respondJobQueued queues a job for the request and responds with 202 and where to follow it
+func respondJobQueued(w http.ResponseWriter, r *http.Request, kind string, params interface{}) {
+	if issuerJobs == nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: "Job queue is not running"})
+		return
+	}
+	view, existing, err := issuerJobs.Enqueue(kind, params, requestActor(r))
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+
+	message := "Job queued"
+	if existing {
+		message = "The same job is already queued or running"
+	}
+	statusURL := fmt.Sprintf("/api/issuer/jobs/%s", view.JobID)
+	w.Header().Set("Location", statusURL)
+	respondJSON(w, http.StatusAccepted, APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"message":    message,
+			"job_id":     view.JobID,
+			"kind":       view.Kind,
+			"status":     view.Status,
+			"status_url": statusURL,
+			"events_url": jobEventsURL(r, view.JobID),
+		},
+	})
+}
+
+// jobEventsURL returns the event stream of a job for the principal of r, with a stream
+// token for browsers, which cannot send credentials in headers to an event stream
+func jobEventsURL(r *http.Request, jobID string) string {
+	eventsURL := fmt.Sprintf("/api/issuer/jobs/%s/events", jobID)
+	token, err := issueStreamToken(requestActor(r), jobID)
+	if err != nil {
+		log.Printf("⚠️  Failed to issue stream token for job %s: %v", jobID, err)
+		return eventsURL
+	}
+	return eventsURL + "?access_token=" + url.QueryEscape(token)
+}
+
+// handleGetJob returns a job with its step-by-step progress
+func handleGetJob(w http.ResponseWriter, r *http.Request) {
+	if issuerJobs == nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: "Job queue is not running"})
+		return
+	}
+	job, err := issuerJobs.store.GetJob(mux.Vars(r)["job_id"])
+	if errors.Is(err, errJobNotFound) {
+		respondJSON(w, http.StatusNotFound, APIResponse{Success: false, Error: "Job not found"})
+		return
+	}
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to get job"})
+		return
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: struct {
+		jobView
+		EventsURL string `json:"events_url"`
+	}{newJobView(job), jobEventsURL(r, job.JobID)}})
+}
+
+// handleListJobs returns the latest jobs, newest first (limit, default 50)
+func handleListJobs(w http.ResponseWriter, r *http.Request) {
+	if issuerJobs == nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: "Job queue is not running"})
+		return
+	}
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 500 {
+			respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: "limit must be between 1 and 500"})
+			return
+		}
+		limit = parsed
+	}
+
+	jobs, err := issuerJobs.store.RecentJobs(limit)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to list jobs"})
+		return
+	}
+	views := make([]jobView, 0, len(jobs))
+	for i := range jobs {
+		views = append(views, newJobView(&jobs[i]))
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: views})
+}
+
+// handleJobEvents streams a job as server-sent events: the job as it is, then again after
+// every change until it finishes
+func handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	if issuerJobs == nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: "Job queue is not running"})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Streaming is not supported"})
+		return
+	}
+
+	jobID := mux.Vars(r)["job_id"]
+	events, unsubscribe := issuerJobs.subscribe(jobID)
+	defer unsubscribe()
+
+	job, err := issuerJobs.store.GetJob(jobID)
+	if errors.Is(err, errJobNotFound) {
+		respondJSON(w, http.StatusNotFound, APIResponse{Success: false, Error: "Job not found"})
+		return
+	}
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to get job"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	view := newJobView(job)
+	if writeJobEvent(w, flusher, view) != nil || view.finished() {
+		return
+	}
+
+	keepAlive := time.NewTicker(jobEventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case view := <-events:
+			if writeJobEvent(w, flusher, view) != nil || view.finished() {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeJobEvent(w http.ResponseWriter, flusher http.Flusher, view jobView) error {
+	data, err := json.Marshal(view)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
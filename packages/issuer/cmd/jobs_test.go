@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"iumicert/issuer/database"
+
+	"github.com/gorilla/mux"
+	"gorm.io/datatypes"
+)
+
+// registerTestJobKind adds a job kind for the duration of a test
+func registerTestJobKind(t *testing.T, name string, kind jobKind) {
+	t.Helper()
+	jobKinds[name] = kind
+	t.Cleanup(func() { delete(jobKinds, name) })
+}
+
+// waitForJob polls the store until the job finished
+func waitForJob(t *testing.T, store jobStore, jobID string) jobView {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := store.GetJob(jobID)
+		if err != nil {
+			t.Fatalf("Failed to get job: %v", err)
+		}
+		if view := newJobView(job); view.finished() {
+			return view
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", jobID)
+	return jobView{}
+}
+
+// TestJobQueue runs jobs through the queue and follows one over its event stream
+func TestJobQueue(t *testing.T) {
+	release := make(chan struct{})
+	registerTestJobKind(t, "test-steps", jobKind{run: func(ctx context.Context, job *jobRun) (interface{}, error) {
+		var params struct{ TermID string }
+		if err := job.Params(&params); err != nil {
+			return nil, err
+		}
+		job.Plan("Build tree", "Publish root", "Notify")
+		job.Step("Build tree")
+		<-release
+		job.Step("Publish root")
+		job.Logf("Published %s", params.TermID)
+		return map[string]string{"term_id": params.TermID, "by": job.Actor()}, nil
+	}})
+	registerTestJobKind(t, "test-fail", jobKind{run: func(ctx context.Context, job *jobRun) (interface{}, error) {
+		job.Step("Connect")
+		return nil, errors.New("network unreachable")
+	}})
+	registerTestJobKind(t, "test-panic", jobKind{run: func(ctx context.Context, job *jobRun) (interface{}, error) {
+		var tree map[string]int
+		tree["root"]++
+		return nil, nil
+	}})
+
+	store := newMemJobStore()
+	queue := newJobQueue(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.work(ctx)
+
+	previous := issuerJobs
+	issuerJobs = queue
+	defer func() { issuerJobs = previous }()
+
+	r := mux.NewRouter()
+	r.HandleFunc("/api/issuer/jobs", handleListJobs).Methods("GET")
+	r.HandleFunc("/api/issuer/jobs/{job_id}", handleGetJob).Methods("GET")
+	r.HandleFunc("/api/issuer/jobs/{job_id}/events", handleJobEvents).Methods("GET")
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	params := map[string]string{"TermID": "Semester_1_2024"}
+	queued, existing, err := queue.Enqueue("test-steps", params, "publisher@iu.edu.vn")
+	if err != nil || existing {
+		t.Fatalf("Failed to queue job: %v (existing %v)", err, existing)
+	}
+	again, existing, err := queue.Enqueue("test-steps", params, "publisher@iu.edu.vn")
+	if err != nil || !existing || again.JobID != queued.JobID {
+		t.Fatalf("Same job queued twice: %s and %s (%v)", queued.JobID, again.JobID, err)
+	}
+
+	resp, err := http.Get(server.URL + "/api/issuer/jobs/" + queued.JobID + "/events")
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Event stream served as %s", resp.Header.Get("Content-Type"))
+	}
+
+	close(release)
+	var events []jobView
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var view jobView
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &view); err != nil {
+			t.Fatalf("Invalid event %q: %v", line, err)
+		}
+		events = append(events, view)
+	}
+
+	last := events[len(events)-1]
+	if len(events) < 2 || last.Status != jobSucceeded {
+		t.Fatalf("Stream ended after %d events with status %s", len(events), last.Status)
+	}
+	statuses := []string{}
+	for _, step := range last.Steps {
+		statuses = append(statuses, step.Status)
+	}
+	if strings.Join(statuses, ",") != "succeeded,succeeded,skipped" || last.StepsDone != 3 || last.StepsTotal != 3 {
+		t.Fatalf("Steps ended as %v (%d/%d)", statuses, last.StepsDone, last.StepsTotal)
+	}
+	if last.Steps[1].Message != "Published Semester_1_2024" {
+		t.Fatalf("Step message is %q", last.Steps[1].Message)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/api/issuer/jobs/"+queued.JobID, nil))
+	var got struct {
+		Data jobView `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if rec.Code != http.StatusOK || !strings.Contains(string(got.Data.Result), "publisher@iu.edu.vn") || got.Data.Attempts != 1 {
+		t.Fatalf("GET job returned %d: %s", rec.Code, rec.Body.String())
+	}
+
+	failed, _, _ := queue.Enqueue("test-fail", nil, "publisher@iu.edu.vn")
+	if view := waitForJob(t, store, failed.JobID); view.Status != jobFailed || view.Error != "network unreachable" || view.Steps[0].Status != jobFailed {
+		t.Fatalf("Failing job ended as %s (%q), step %s", view.Status, view.Error, view.Steps[0].Status)
+	}
+	panicked, _, _ := queue.Enqueue("test-panic", nil, "publisher@iu.edu.vn")
+	if view := waitForJob(t, store, panicked.JobID); view.Status != jobFailed || !strings.Contains(view.Error, "panicked") {
+		t.Fatalf("Panicking job ended as %s (%q)", view.Status, view.Error)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/api/issuer/jobs/job_missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Missing job returned %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/api/issuer/jobs?limit=2", nil))
+	var list struct {
+		Data []jobView `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Data) != 2 || list.Data[0].JobID != panicked.JobID {
+		t.Fatalf("Job list returned %d jobs", len(list.Data))
+	}
+	t.Logf("✅ Job %s streamed %d events, failures and panics recorded", queued.JobID, len(events))
+}
+
+// TestJobsResumeAfterRestart checks what happens to jobs left unfinished by a stopped serve
+func TestJobsResumeAfterRestart(t *testing.T) {
+	quick := func(ctx context.Context, job *jobRun) (interface{}, error) {
+		job.Step("Work")
+		return "done", nil
+	}
+	registerTestJobKind(t, "test-resumable", jobKind{run: quick, resumable: true})
+	registerTestJobKind(t, "test-once", jobKind{run: quick})
+
+	store := newMemJobStore()
+	started := time.Now().Add(-time.Minute)
+	runningSteps := datatypes.JSON(`[{"name":"Work","status":"running"},{"name":"Record","status":"pending"}]`)
+	leftOver := []database.Job{
+		{JobID: "job_queued", Kind: "test-once", Status: jobQueued},
+		{JobID: "job_resumable", Kind: "test-resumable", Status: jobRunning, Steps: runningSteps, Attempts: 1, StartedAt: &started},
+		{JobID: "job_once", Kind: "test-once", Status: jobRunning, Steps: runningSteps, Attempts: 1, StartedAt: &started},
+		{JobID: "job_unknown", Kind: "test-removed", Status: jobQueued},
+	}
+	for i := range leftOver {
+		leftOver[i].CreatedAt = started.Add(time.Duration(i) * time.Second)
+		store.SaveJob(&leftOver[i])
+	}
+
+	queue := newJobQueue(store)
+	if err := queue.resumeUnfinished(); err != nil {
+		t.Fatalf("Failed to resume jobs: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.work(ctx)
+
+	if view := waitForJob(t, store, "job_queued"); view.Status != jobSucceeded || view.Attempts != 1 {
+		t.Fatalf("Queued job ended as %s after %d attempts", view.Status, view.Attempts)
+	}
+	if view := waitForJob(t, store, "job_resumable"); view.Status != jobSucceeded || view.Attempts != 2 || len(view.Steps) != 1 {
+		t.Fatalf("Resumable job ended as %s after %d attempts with %d steps", view.Status, view.Attempts, len(view.Steps))
+	}
+	once := waitForJob(t, store, "job_once")
+	if once.Status != jobInterrupted || once.Steps[0].Status != jobInterrupted || once.Steps[1].Status != stepSkipped {
+		t.Fatalf("Running job ended as %s, steps %+v", once.Status, once.Steps)
+	}
+	if view := waitForJob(t, store, "job_unknown"); view.Status != jobFailed {
+		t.Fatalf("Job of unknown kind ended as %s", view.Status)
+	}
+	t.Logf("✅ Queued and resumable jobs ran again, %s was marked %s", once.JobID, once.Status)
+}
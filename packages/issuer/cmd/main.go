@@ -6,18 +6,21 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"iumicert/crypto/receipt"
 	"iumicert/crypto/verkle"
 	blockchain "iumicert/issuer/blockchain_integration"
 	"iumicert/issuer/config"
 	"iumicert/issuer/database"
 
 	"github.com/spf13/cobra"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -64,8 +67,10 @@ Generates cryptographic root commitment ready for blockchain publishing.`,
 		
 		format, _ := cmd.Flags().GetString("format")
 		validate, _ := cmd.Flags().GetBool("validate")
+		fieldLeaves, _ := cmd.Flags().GetBool("field-leaves")
+		workers, _ := cmd.Flags().GetInt("workers")
 		
-		if err := addAcademicTerm(termID, dataFile, format, validate); err != nil {
+		if err := addAcademicTerm(termID, dataFile, format, validate, fieldLeaves, workers); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Failed to add term: %v\n", err)
 			os.Exit(1)
 		}
@@ -86,8 +91,10 @@ Enables privacy-preserving verification of specific achievements.`,
 		terms, _ := cmd.Flags().GetStringSlice("terms")
 		courses, _ := cmd.Flags().GetStringSlice("courses")
 		selective, _ := cmd.Flags().GetBool("selective")
+		batchProof, _ := cmd.Flags().GetBool("batch-proof")
+		fields, _ := cmd.Flags().GetStringSlice("fields")
 		
-		if err := generateStudentReceipt(studentID, outputFile, terms, courses, selective); err != nil {
+		if err := generateStudentReceipt(studentID, outputFile, terms, courses, fields, selective, batchProof); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Failed to generate receipt: %v\n", err)
 			os.Exit(1)
 		}
@@ -115,16 +122,47 @@ var publishRootsCmd = &cobra.Command{
 	Use:   "publish-roots [term-id]",
 	Short: "Publish term root commitments to blockchain",
 	Long: `Publish Verkle tree root commitments for academic terms to the blockchain.
-Enables on-chain verification of academic journey receipts.`,
+Enables on-chain verification of academic journey receipts.
+
+With --unsigned-out the publication is written as an unsigned transaction for an offline
+signer; sign it with sign-tx and send it with broadcast-tx.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		termID := args[0]
 		
-		network, _ := cmd.Flags().GetString("network")
+		network := networkFlag(cmd)
 		privateKey, _ := cmd.Flags().GetString("private-key")
 		gasLimit, _ := cmd.Flags().GetUint64("gas-limit")
 		
-		if err := publishTermRoots(termID, network, privateKey, gasLimit); err != nil {
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			fmt.Printf("🧪 Dry run: estimating publication of %s, nothing is sent\n", termID)
+			planned, err := estimateTermRootPublication(termID, network, privateKey, gasLimit)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Dry run failed: %v\n", err)
+				os.Exit(1)
+			}
+			if len(planned) == 0 {
+				fmt.Println("✅ Nothing would be sent")
+			}
+			for _, tx := range planned {
+				fmt.Printf("\n📝 %s for %s (root %s)\n", tx.Method, tx.TermID, tx.VerkleRoot)
+				printFeeEstimate(tx.Estimate)
+			}
+			if !withinBudget(planned) {
+				os.Exit(1)
+			}
+			return
+		}
+		
+		if unsignedOut, _ := cmd.Flags().GetString("unsigned-out"); unsignedOut != "" {
+			if err := preparePublicationOffline(termID, network, privateKey, gasLimit, unsignedOut); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to prepare publication: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		
+		if err := publishTermRoots(termID, network, privateKey, gasLimit, cliActor()); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Failed to publish roots: %v\n", err)
 			os.Exit(1)
 		}
@@ -140,12 +178,15 @@ This command:
 2. Removes revoked credentials
 3. Rebuilds and re-commits the tree
 4. Publishes new version via SupersedeTerm()
-5. Updates database records`,
+5. Updates database records
+
+With --unsigned-out steps 4 and 5 are left to broadcast-tx, after the transaction was
+signed offline with sign-tx.`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		termID := args[0]
 
-		network, _ := cmd.Flags().GetString("network")
+		network := networkFlag(cmd)
 		privateKey, _ := cmd.Flags().GetString("private-key")
 		gasLimit, _ := cmd.Flags().GetUint64("gas-limit")
 
@@ -192,8 +233,22 @@ This command:
 			fmt.Printf("  - %s / %s: %s\n", rev.StudentID, rev.CourseID, rev.Reason)
 		}
 
+		if unsignedOut, _ := cmd.Flags().GetString("unsigned-out"); unsignedOut != "" {
+			writer, err := newOfflineTxWriter(cfg, unsignedOut)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to prepare supersession: %v\n", err)
+				os.Exit(1)
+			}
+			defer writer.Close()
+			if _, err := prepareSupersessionOffline(writer, db, termID, approvedRevocations); err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Failed to prepare supersession: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
 		// Execute supersession
-		if err := supersedeTermWithRevocations(termID, approvedRevocations, cfg, db); err != nil {
+		if err := supersedeTermWithRevocations(termID, approvedRevocations, cfg, db, cliActor()); err != nil {
 			fmt.Fprintf(os.Stderr, "❌ Failed to supersede term: %v\n", err)
 			os.Exit(1)
 		}
@@ -235,18 +290,25 @@ func init() {
 	// Add command flags
 	addTermCmd.Flags().String("format", "json", "input data format (json, csv)")
 	addTermCmd.Flags().Bool("validate", true, "validate input data")
+	addTermCmd.Flags().Bool("field-leaves", false, "store each course field in its own leaf to allow field-level disclosure")
+	addTermCmd.Flags().Int("workers", 0, "number of parallel workers for leaf hashing (0 = one per CPU)")
 	
 	generateReceiptCmd.Flags().StringSlice("terms", []string{}, "specific terms to include")
 	generateReceiptCmd.Flags().StringSlice("courses", []string{}, "specific courses to include")
 	generateReceiptCmd.Flags().Bool("selective", false, "enable selective disclosure")
+	generateReceiptCmd.Flags().Bool("batch-proof", false, "use one Verkle multiproof per term instead of one proof per course")
+	generateReceiptCmd.Flags().StringSlice("fields", []string{}, "course fields to disclose, e.g. credits,completed_at (field-leaf terms only)")
 	
-	publishRootsCmd.Flags().String("network", "sepolia", "blockchain network")
+	publishRootsCmd.Flags().String("network", "sepolia", "blockchain network from config/networks.json, or simulated; NETWORK overrides the default")
 	publishRootsCmd.Flags().String("private-key", "", "private key for signing")
 	publishRootsCmd.Flags().Uint64("gas-limit", 0, "gas limit for transaction")
+	publishRootsCmd.Flags().Bool("dry-run", false, "estimate gas and fees of the publication without sending it")
+	publishRootsCmd.Flags().String("unsigned-out", "", "write the publication as an unsigned transaction to this directory, for sign-tx")
 
-	supersedeTermCmd.Flags().String("network", "sepolia", "blockchain network")
+	supersedeTermCmd.Flags().String("network", "sepolia", "blockchain network from config/networks.json, or simulated; NETWORK overrides the default")
 	supersedeTermCmd.Flags().String("private-key", "", "private key for signing")
 	supersedeTermCmd.Flags().Uint64("gas-limit", 0, "gas limit for transaction")
+	supersedeTermCmd.Flags().String("unsigned-out", "", "write the new version as an unsigned transaction to this directory, for sign-tx")
 
 	// Add subcommands
 	rootCmd.AddCommand(initCmd)
@@ -260,6 +322,16 @@ func init() {
 	rootCmd.AddCommand(testVerifyCmd)
 }
 
+// networkFlag returns the --network flag, letting the NETWORK environment variable
+// (e.g. NETWORK=simulated) take precedence over the flag default
+func networkFlag(cmd *cobra.Command) string {
+	network, _ := cmd.Flags().GetString("network")
+	if env := os.Getenv("NETWORK"); env != "" && !cmd.Flags().Changed("network") {
+		return env
+	}
+	return network
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
@@ -340,7 +412,7 @@ func initializeRepository(institutionID string) error {
 	return nil
 }
 
-func addAcademicTerm(termID, dataFile, format string, validate bool) error {
+func addAcademicTerm(termID, dataFile, format string, validate, fieldLeaves bool, workers int) error {
 	fmt.Printf("📚 Adding academic term: %s\n", termID)
 	fmt.Printf("📖 Processing data from: %s (format: %s)\n", dataFile, format)
 
@@ -393,12 +465,14 @@ func addAcademicTerm(termID, dataFile, format string, validate bool) error {
 	// Build term-level Verkle tree
 	fmt.Println("🔗 Preparing Verkle tree aggregation...")
 	termTree := verkle.NewTermVerkleTree(termID)
+	if fieldLeaves {
+		fmt.Println("🧩 Using field-leaf layout (one leaf per course field)")
+		termTree.TreeFormat = verkle.TreeFormatFieldLeaves
+	}
 	
-	for studentDID, courses := range studentCompletions {
-		err := termTree.AddCourses(studentDID, courses)
-		if err != nil {
-			return fmt.Errorf("failed to add courses for student %s to verkle tree: %w", studentDID, err)
-		}
+	// Salts and leaf hashes are computed in parallel; inserts stay sequential
+	if err := termTree.AddStudentCourses(studentCompletions, workers); err != nil {
+		return fmt.Errorf("failed to add courses to verkle tree: %w", err)
 	}
 	
 	// Publish the term
@@ -422,7 +496,17 @@ func addAcademicTerm(termID, dataFile, format string, validate bool) error {
 	termTreeFile := filepath.Join(verkleDir, fmt.Sprintf("%s_verkle_tree.json", termID))
 	if err := os.WriteFile(termTreeFile, termTreeData, 0644); err != nil {
 		return fmt.Errorf("failed to save term tree: %w", err)
-	}	// Save root for blockchain publishing
+	}
+	
+	// Persist the tree nodes so later proofs skip re-inserting every entry
+	if err := saveTermTreeStore(termTree); err != nil {
+		return fmt.Errorf("failed to save term node store: %w", err)
+	}
+	if err := termTree.Close(); err != nil {
+		return fmt.Errorf("failed to close term node store: %w", err)
+	}
+	
+	// Save root for blockchain publishing
 	rootsDir := resolveProjectPath("publish_ready/roots")
 	if err := os.MkdirAll(rootsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create roots directory: %w", err)
@@ -453,19 +537,28 @@ func addAcademicTerm(termID, dataFile, format string, validate bool) error {
 	return nil
 }
 
-func generateStudentReceipt(studentID, outputFile string, terms, courses []string, selective bool) error {
-	fmt.Printf("👤 Generating receipt for student: %s\n", studentID)
-	fmt.Printf("📋 Output file: %s\n", outputFile)
+func generateStudentReceipt(studentID, outputFile string, terms, courses, fields []string, selective, batchProof bool) error {
+	return generateStudentReceiptWith(os.Stdout, nil, studentID, outputFile, terms, courses, fields, selective, batchProof)
+}
+
+// generateStudentReceiptWith writes progress to out and takes term trees from trees when
+// given (shared by batch workers); otherwise each term tree is loaded and closed here
+func generateStudentReceiptWith(out io.Writer, trees *termTreeSet, studentID, outputFile string, terms, courses, fields []string, selective, batchProof bool) error {
+	fmt.Fprintf(out, "👤 Generating receipt for student: %s\n", studentID)
+	fmt.Fprintf(out, "📋 Output file: %s\n", outputFile)
 	
 	if selective {
-		fmt.Println("🔒 Using selective disclosure mode")
+		fmt.Fprintln(out, "🔒 Using selective disclosure mode")
+	}
+	if batchProof {
+		fmt.Fprintln(out, "📦 Using batch multiproof mode (one proof per term)")
 	}
 	
 	// Determine terms to include
 	var targetTerms []string
 	if len(terms) > 0 {
 		targetTerms = terms
-		fmt.Printf("📚 Including specific terms: %v\n", targetTerms)
+		fmt.Fprintf(out, "📚 Including specific terms: %v\n", targetTerms)
 	} else {
 		// Auto-discover terms from data
 		var err error
@@ -473,37 +566,38 @@ func generateStudentReceipt(studentID, outputFile string, terms, courses []strin
 		if err != nil {
 			return fmt.Errorf("failed to discover student terms: %w", err)
 		}
-		fmt.Printf("📚 Auto-discovered terms: %v\n", targetTerms)
+		fmt.Fprintf(out, "📚 Auto-discovered terms: %v\n", targetTerms)
 	}
 	
-	fmt.Println("🔐 Generating academic journey receipt...")
+	fmt.Fprintln(out, "🔐 Generating academic journey receipt...")
 	
-	receipts := make(map[string]interface{})
+	generatedAt := time.Now()
+	journeyReceipt := &receipt.JourneyReceipt{
+		SchemaVersion: receipt.SchemaVersion,
+		StudentID:     studentID,
+		ReceiptType: receipt.ReceiptType{
+			SelectiveDisclosure: selective,
+			SpecificCourses:     len(courses) > 0,
+			SpecificTerms:       len(terms) > 0,
+		},
+		GenerationTimestamp: generatedAt.Truncate(time.Second),
+		TermsIncluded:       targetTerms,
+		CoursesFilter:       courses,
+		TermReceipts:        make(map[string]receipt.TermReceipt),
+		BlockchainReady:     true,
+	}
 	
 	for _, termID := range targetTerms {
-		// Load the complete TermVerkleTree saved during term addition
-    // Load the complete TermVerkleTree saved during term addition (project-level data dir)
-    verkleTreeFile := filepath.Join("data", "verkle_trees", fmt.Sprintf("%s_verkle_tree.json", termID))
-		if _, err := os.Stat(verkleTreeFile); os.IsNotExist(err) {
-			verkleTreeFile = filepath.Join("..", "data", "verkle_trees", fmt.Sprintf("%s_verkle_tree.json", termID))
+		// Load the complete TermVerkleTree saved during term addition (node store or JSON)
+		var termTree *verkle.TermVerkleTree
+		var err error
+		if trees != nil {
+			termTree, err = trees.get(termID)
+		} else {
+			termTree, err = loadTermTree(termID)
 		}
-		
-		verkleTreeData, err := os.ReadFile(verkleTreeFile)
 		if err != nil {
-			fmt.Printf("  ⚠️ Skipping term %s: Verkle tree data not found\n", termID)
-			continue
-		}
-		
-		// Deserialize the TermVerkleTree
-		var termTree verkle.TermVerkleTree
-		if err := json.Unmarshal(verkleTreeData, &termTree); err != nil {
-			fmt.Printf("  ⚠️ Skipping term %s: Failed to parse Verkle tree data\n", termID)
-			continue
-		}
-		
-		// Rebuild the internal Verkle tree (since it's not serialized)
-		if err := termTree.RebuildVerkleTree(); err != nil {
-			fmt.Printf("  ⚠️ Skipping term %s: Failed to rebuild Verkle tree: %v\n", termID, err)
+			fmt.Fprintf(out, "  ⚠️ Skipping term %s: %v\n", termID, err)
 			continue
 		}
 		
@@ -519,54 +613,27 @@ func generateStudentReceipt(studentID, outputFile string, terms, courses []strin
 		// Generate verification receipt using the real Verkle tree
 		// Convert student ID to DID format for Verkle tree lookup
 		studentDID := fmt.Sprintf("did:example:%s", studentID)
-		receipt, err := termTree.GenerateStudentReceipt(studentDID, targetCourses)
+		var termReceipt *verkle.VerificationReceipt
+		if batchProof {
+			termReceipt, err = termTree.GenerateStudentBatchReceipt(studentDID, targetCourses, fields...)
+		} else {
+			termReceipt, err = termTree.GenerateStudentReceipt(studentDID, targetCourses, fields...)
+		}
+		if trees == nil {
+			termTree.Close()
+		}
 		if err != nil {
-			fmt.Printf("  ⚠️ Skipping term %s: Failed to generate receipt: %v\n", termID, err)
+			fmt.Fprintf(out, "  ⚠️ Skipping term %s: Failed to generate receipt: %v\n", termID, err)
 			continue
 		}
 		
-		// Convert the receipt to the expected format
-		receipts[termID] = map[string]interface{}{
-			"term_id": termID,
-			"student_id": studentID,
-			"receipt": map[string]interface{}{
-				"student_id": receipt.StudentDID,
-				"term_id": receipt.TermID,
-				"revealed_courses": receipt.RevealedCourses,
-				"verkle_root": fmt.Sprintf("%x", receipt.VerkleRoot),
-				"course_proofs": receipt.CourseProofs,
-				"proof_type": "verkle_32_byte",
-				"selective_disclosure": receipt.SelectiveDisclosure,
-				"verification_path": "single_verkle_proof",
-				"timestamp": receipt.PublishedAt.Format(time.RFC3339),
-				"metadata": receipt.Metadata,
-			},
-			"verkle_root": fmt.Sprintf("%x", receipt.VerkleRoot),
-			"revealed_courses": len(receipt.RevealedCourses),
-			"total_courses": receipt.Metadata.TotalCourses,
-			"generated_at": time.Now().Format(time.RFC3339),
-		}
+		journeyReceipt.TermReceipts[termID] = receipt.NewTermReceipt(studentID, termReceipt, generatedAt)
 		
-		fmt.Printf("  ✓ Generated receipt for term %s (%d/%d courses)\n", termID, 
-			len(receipt.RevealedCourses), receipt.Metadata.TotalCourses)
+		fmt.Fprintf(out, "  ✓ Generated receipt for term %s (%d/%d courses)\n", termID, 
+			len(termReceipt.RevealedCourses), termReceipt.Metadata.TotalCourses)
 	}
 	
-	// Create comprehensive receipt
-	journeyReceipt := map[string]interface{}{
-		"student_id": studentID,
-		"receipt_type": map[string]interface{}{
-			"selective_disclosure": selective,
-			"specific_courses": len(courses) > 0,
-			"specific_terms": len(terms) > 0,
-		},
-		"generation_timestamp": time.Now().Format(time.RFC3339),
-		"terms_included": targetTerms,
-		"courses_filter": courses,
-		"term_receipts": receipts,
-		"blockchain_ready": true,
-	}
-	
-	fmt.Println("📄 Creating journey receipt...")
+	fmt.Fprintln(out, "📄 Creating journey receipt...")
 	
 	// Ensure output directory exists
 	if dir := filepath.Dir(outputFile); dir != "." {
@@ -576,7 +643,7 @@ func generateStudentReceipt(studentID, outputFile string, terms, courses []strin
 	}
 	
 	// Save to specified output file
-	receiptData, err := json.MarshalIndent(journeyReceipt, "", "  ")
+	receiptData, err := journeyReceipt.Encode()
 	if err != nil {
 		return fmt.Errorf("failed to marshal receipt: %w", err)
 	}
@@ -585,8 +652,8 @@ func generateStudentReceipt(studentID, outputFile string, terms, courses []strin
 		return fmt.Errorf("failed to write receipt file: %w", err)
 	}
 
-	fmt.Printf("💾 Receipt saved to: %s\n", outputFile)
-	fmt.Println("✅ Receipt generated successfully!")
+	fmt.Fprintf(out, "💾 Receipt saved to: %s\n", outputFile)
+	fmt.Fprintln(out, "✅ Receipt generated successfully!")
 
 	return nil
 }
@@ -595,125 +662,76 @@ func verifyReceiptLocally(receiptFile string) error {
 	fmt.Printf("🔍 Verifying receipt: %s\n", receiptFile)
 	
 	fmt.Println("📖 Parsing receipt data...")
-	data, err := os.ReadFile(receiptFile)
+	journeyReceipt, err := receipt.Load(receiptFile)
 	if err != nil {
-		return fmt.Errorf("failed to read receipt file: %w", err)
-	}
-	
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(data, &receipt); err != nil {
-		return fmt.Errorf("failed to parse receipt: %w", err)
-	}
-	
-	studentID, ok := receipt["student_id"].(string)
-	if !ok {
-		return fmt.Errorf("invalid receipt: missing student_id")
-	}
-	
-	termReceipts, ok := receipt["term_receipts"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("invalid receipt: missing term_receipts")
+		return err
 	}
+	studentID := journeyReceipt.StudentID
 	
 	fmt.Printf("📋 Verifying receipt for student: %s\n", studentID)
 	
 	fmt.Println("🔐 Validating Verkle proofs...")
-	for termID, termReceiptInterface := range termReceipts {
-		termReceiptMap, ok := termReceiptInterface.(map[string]interface{})
-		if !ok {
-			return fmt.Errorf("invalid term receipt for %s", termID)
-		}
-		
-		verkleRootHex, ok := termReceiptMap["verkle_root"].(string)
-		if !ok {
-			return fmt.Errorf("missing verkle_root for term %s", termID)
-		}
-		
-		// Parse Verkle root from hex string
-		verkleRoot, err := parseVerkleRoot(verkleRootHex)
-		if err != nil {
-			return fmt.Errorf("invalid verkle_root format for term %s: %w", termID, err)
-		}
+	for _, termID := range journeyReceipt.SortedTermIDs() {
+		termReceipt := journeyReceipt.TermReceipts[termID]
+		verkleRoot := [32]byte(termReceipt.VerkleRoot)
+		verkleRootHex := termReceipt.VerkleRoot.String()
+		receiptData := termReceipt.Receipt
 		
-		// Check if there's a receipt with course proofs
-		if receiptData, ok := termReceiptMap["receipt"].(map[string]interface{}); ok {
-			if courseProofs, ok := receiptData["course_proofs"].(map[string]interface{}); ok {
-				fmt.Printf("  🔍 Term %s: Verifying %d course proofs against Verkle root %s...\n", 
-					termID, len(courseProofs), verkleRootHex[:16]+"...")
+		if receiptData.IsBatch() {
+			fmt.Printf("  🔍 Term %s: Verifying batch multiproof against Verkle root %s...\n",
+				termID, verkleRootHex[:16]+"...")
+			
+			verificationCount, err := verifyBatchTermProof(studentID, receiptData, verkleRoot)
+			if err != nil {
+				return fmt.Errorf("cryptographic verification failed for term %s: %w", termID, err)
+			}
+			
+			fmt.Printf("  ✅ Term %s: All %d courses verified with a single multiproof\n", termID, verificationCount)
+		} else if len(receiptData.CourseProofs) > 0 {
+			fmt.Printf("  🔍 Term %s: Verifying %d course proofs against Verkle root %s...\n", 
+				termID, len(receiptData.CourseProofs), verkleRootHex[:16]+"...")
+			
+			// Perform full cryptographic verification for each course
+			verificationCount := 0
+			for _, course := range receiptData.RevealedCourses {
+				courseID := course.CourseID
 				
-				// Get revealed courses for verification
-				revealedCourses, ok := receiptData["revealed_courses"].([]interface{})
-				if !ok {
-					return fmt.Errorf("missing revealed_courses for term %s", termID)
+				// Get the course proof
+				proofBytes, exists := receiptData.CourseProofs[courseID]
+				if !exists {
+					fmt.Printf("    ⚠️  No proof found for course %s\n", courseID)
+					continue
 				}
 				
-				// Perform full cryptographic verification for each course
-				verificationCount := 0
-				for _, courseInterface := range revealedCourses {
-					courseMap, ok := courseInterface.(map[string]interface{})
-					if !ok {
-						continue
-					}
-					
-					courseID, ok := courseMap["course_id"].(string)
-					if !ok {
-						continue
-					}
-					
-					// Get the course proof
-					proofData, exists := courseProofs[courseID]
-					if !exists {
-						fmt.Printf("    ⚠️  No proof found for course %s\n", courseID)
-						continue
-					}
-					
-						// Convert proof data (map) to JSON bytes
-					proofBytes, err := json.Marshal(proofData)
-					if err != nil {
-						fmt.Printf("    ❌ Failed to parse proof for course %s: %v\n", courseID, err)
-						continue
-					}
-					
-					// Convert course map to CourseCompletion struct
-					course, err := convertToCourseCompletion(courseMap)
-					if err != nil {
-						fmt.Printf("    ❌ Failed to parse course data for %s: %v\n", courseID, err)
-						continue
-					}
-					
-					// Generate course key for verification
-					studentDID := fmt.Sprintf("did:example:%s", studentID)
-					courseKey := fmt.Sprintf("%s:%s:%s", studentDID, termID, courseID)
-					
-					// Perform full cryptographic verification
-					if err := verkle.VerifyCourseProof(courseKey, course, proofBytes, verkleRoot); err != nil {
-						return fmt.Errorf("cryptographic verification failed for course %s in term %s: %w", courseID, termID, err)
-					}
-					
-					verificationCount++
-					fmt.Printf("    ✅ Course %s: Cryptographic proof verified\n", courseID)
+				// Generate course key for verification
+				courseKey := fmt.Sprintf("%s:%s:%s", journeyReceipt.StudentDID(), termID, courseID)
+				
+				// Perform full cryptographic verification
+				disclosedFields, err := verkle.VerifyCourseFieldProof(courseKey, course, proofBytes, verkleRoot)
+				if err != nil {
+					return fmt.Errorf("cryptographic verification failed for course %s in term %s: %w", courseID, termID, err)
 				}
 				
-				fmt.Printf("  ✅ Term %s: All %d course proofs cryptographically verified\n", termID, verificationCount)
-			} else {
-				fmt.Printf("  ✓ Term %s: Verkle root %s (no course proofs to verify)\n", termID, verkleRootHex[:16]+"...")
+				verificationCount++
+				if len(disclosedFields) < len(verkle.CourseFields) {
+					fmt.Printf("    ✅ Course %s: Cryptographic proof verified (fields: %s)\n", courseID, strings.Join(disclosedFields, ", "))
+				} else {
+					fmt.Printf("    ✅ Course %s: Cryptographic proof verified\n", courseID)
+				}
 			}
+			
+			fmt.Printf("  ✅ Term %s: All %d course proofs cryptographically verified\n", termID, verificationCount)
 		} else {
-			fmt.Printf("  ✓ Term %s: Verkle root %s (no receipt data)\n", termID, verkleRootHex[:16]+"...")
+			fmt.Printf("  ✓ Term %s: Verkle root %s (no course proofs to verify)\n", termID, verkleRootHex[:16]+"...")
 		}
 	}
 	
 	fmt.Println("⏰ Checking temporal consistency...")
-	timestamp, ok := receipt["generation_timestamp"].(string)
-	if !ok {
+	generatedTime := journeyReceipt.GenerationTimestamp
+	if generatedTime.IsZero() {
 		return fmt.Errorf("invalid receipt: missing generation_timestamp")
 	}
 	
-	generatedTime, err := time.Parse(time.RFC3339, timestamp)
-	if err != nil {
-		return fmt.Errorf("invalid timestamp format: %w", err)
-	}
-	
 	if generatedTime.After(time.Now()) {
 		return fmt.Errorf("receipt timestamp is in the future")
 	}
@@ -723,18 +741,37 @@ func verifyReceiptLocally(receiptFile string) error {
 	fmt.Println("✅ Local verification successful!")
 	fmt.Println("📝 Verification summary:")
 	fmt.Printf("  ✓ Student ID: %s\n", studentID)
-	fmt.Printf("  ✓ Terms verified: %d\n", len(termReceipts))
-	fmt.Printf("  ✓ Timestamp valid: %s\n", timestamp)
+	fmt.Printf("  ✓ Terms verified: %d\n", len(journeyReceipt.TermReceipts))
+	fmt.Printf("  ✓ Timestamp valid: %s\n", generatedTime.Format(time.RFC3339))
 	
 	return nil
 }
 
-func publishTermRoots(termID, network, privateKey string, gasLimit uint64) error {
+// verifyBatchTermProof verifies the batch multiproof of a term receipt against all revealed courses
+// and returns the number of courses covered by the proof
+func verifyBatchTermProof(studentID string, receiptData receipt.TermReceiptBody, verkleRoot [32]byte) (int, error) {
+	studentDID := fmt.Sprintf("did:example:%s", studentID)
+	courseKeys := make([]string, 0, len(receiptData.RevealedCourses))
+	for _, course := range receiptData.RevealedCourses {
+		courseKeys = append(courseKeys, fmt.Sprintf("%s:%s:%s", studentDID, receiptData.TermID, course.CourseID))
+	}
+	
+	// Field-level receipts disclose the same fields for every course in the batch
+	if _, err := verkle.VerifyBatchCourseFieldProof(courseKeys, receiptData.RevealedCourses, receiptData.BatchProof, verkleRoot); err != nil {
+		return 0, err
+	}
+	
+	return len(receiptData.RevealedCourses), nil
+}
+
+// publishTermRoots publishes the root of a term, processing approved revocations first.
+// actor is recorded as the initiator of the transactions.
+func publishTermRoots(termID, network, privateKey string, gasLimit uint64, actor string) error {
 	fmt.Printf("⛓️  Publishing roots for term: %s\n", termID)
 
 	// STEP 1: Check for approved revocations across ALL existing terms
 	fmt.Println("🔍 Checking for approved revocations to process...")
-	if err := processApprovedRevocations(network, privateKey, gasLimit); err != nil {
+	if err := processApprovedRevocations(network, privateKey, gasLimit, actor); err != nil {
 		fmt.Printf("⚠️  Warning: Failed to process revocations: %v\n", err)
 		fmt.Println("⚠️  Continuing with term publication...")
 	}
@@ -791,14 +828,9 @@ func publishTermRoots(termID, network, privateKey string, gasLimit uint64) error
 	}
 	
 	// Load root data - try both relative paths for CLI and API server contexts
-	rootFile := filepath.Join("publish_ready/roots", fmt.Sprintf("root_%s.json", termID))
-	if _, err := os.Stat(rootFile); os.IsNotExist(err) {
-		// Try alternative path for different working directory contexts
-		altRootFile := filepath.Join("../publish_ready/roots", fmt.Sprintf("root_%s.json", termID))
-		if _, err := os.Stat(altRootFile); os.IsNotExist(err) {
-			return fmt.Errorf("root file not found: %s (also tried %s). Run 'add-term' first", rootFile, altRootFile)
-		}
-		rootFile = altRootFile
+	rootFile, err := findRootFile(termID)
+	if err != nil {
+		return err
 	}
 	
 	fmt.Printf("🌐 Target network: %s\n", cfg.Network)
@@ -814,6 +846,8 @@ func publishTermRoots(termID, network, privateKey string, gasLimit uint64) error
 		return fmt.Errorf("failed to create blockchain integration: %w", err)
 	}
 	defer integration.Close()
+	defer recordTransactions(integration, actor)()
+	notifyPublications(integration, cfg.Network)
 	
 	fmt.Println("📡 Publishing term root to blockchain...")
 	
@@ -854,6 +888,52 @@ func resolveProjectPath(relativePath string) string {
 	return parentPath
 }
 
+// loadTermTree opens a term tree for proof generation. The node store is used when present;
+// otherwise the saved JSON entries are re-inserted into a fresh Verkle tree.
+// Callers must Close the returned tree.
+func loadTermTree(termID string) (*verkle.TermVerkleTree, error) {
+	return openTermTree(termID, false)
+}
+
+// loadTermTreeForUpdate opens a term tree whose changes are written back with saveTermTreeStore
+func loadTermTreeForUpdate(termID string) (*verkle.TermVerkleTree, error) {
+	return openTermTree(termID, true)
+}
+
+func openTermTree(termID string, writable bool) (*verkle.TermVerkleTree, error) {
+	storePath := termTreeStorePath(termID)
+	if _, err := os.Stat(storePath); err == nil {
+		var termTree *verkle.TermVerkleTree
+		if writable {
+			termTree, err = verkle.OpenTermVerkleTree(storePath)
+		} else {
+			termTree, err = verkle.OpenTermVerkleTreeReadOnly(storePath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to open node store for %s: %w", termID, err)
+		}
+		return termTree, nil
+	}
+	
+	verkleTreeFile := resolveProjectPath(filepath.Join("data", "verkle_trees", fmt.Sprintf("%s_verkle_tree.json", termID)))
+	
+	treeData, err := os.ReadFile(verkleTreeFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load term tree for %s: %w", termID, err)
+	}
+	
+	var termTree verkle.TermVerkleTree
+	if err := json.Unmarshal(treeData, &termTree); err != nil {
+		return nil, fmt.Errorf("failed to parse term tree for %s: %w", termID, err)
+	}
+	
+	if err := termTree.RebuildVerkleTree(); err != nil {
+		return nil, fmt.Errorf("failed to rebuild verkle tree for %s: %w", termID, err)
+	}
+	
+	return &termTree, nil
+}
+
 func loadCompletionsFromJSON(dataFile string) ([]verkle.CourseCompletion, error) {
 	// This is a simplified loader - in production you'd have more robust parsing
 	data, err := os.ReadFile(dataFile)
@@ -926,51 +1006,33 @@ func testCourseProofVerification(receiptFile string) error {
 	fmt.Println("🔬 Testing Full Verkle Proof Verification")
 	fmt.Println("==========================================")
 	
-	data, err := os.ReadFile(receiptFile)
+	// Strict decoding already checks the structure of every term receipt
+	journeyReceipt, err := receipt.Load(receiptFile)
 	if err != nil {
-		return fmt.Errorf("failed to read receipt: %w", err)
-	}
-	
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(data, &receipt); err != nil {
-		return fmt.Errorf("failed to parse receipt: %w", err)
-	}
-	
-	termReceipts, ok := receipt["term_receipts"].(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("no term_receipts found")
+		return err
 	}
 	
 	totalProofs := 0
 	successCount := 0
 	
-	for termID, termInterface := range termReceipts {
-		termData, ok := termInterface.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	for _, termID := range journeyReceipt.SortedTermIDs() {
+		termData := journeyReceipt.TermReceipts[termID]
 		
 		fmt.Printf("\n📚 Testing Term: %s\n", termID)
+		fmt.Printf("   🌳 Verkle Root: %s...\n", termData.VerkleRoot.String()[:16])
 		
-		verkleRootHex, ok := termData["verkle_root"].(string)
-		if !ok {
-			fmt.Printf("   ⚠️  No verkle_root found\n")
-			continue
+		proofCount := len(termData.Receipt.CourseProofs)
+		if termData.Receipt.IsBatch() {
+			proofCount = 1
 		}
-		
-		fmt.Printf("   🌳 Verkle Root: %s...\n", verkleRootHex[:16])
-		
-		receiptData, ok := termData["receipt"].(map[string]interface{})
-		if ok {
-			if courseProofs, ok := receiptData["course_proofs"].(map[string]interface{}); ok {
-				fmt.Printf("   📝 Found %d course proofs\n", len(courseProofs))
-				totalProofs += len(courseProofs)
-				
-				// For now, count them as verified since we check structure
-				// Full cryptographic verification requires the actual tree
-				successCount += len(courseProofs)
-				fmt.Printf("   ✅ All proofs have valid structure\n")
-			}
+		if proofCount > 0 {
+			fmt.Printf("   📝 Found %d course proofs\n", proofCount)
+			totalProofs += proofCount
+			
+			// For now, count them as verified since we check structure
+			// Full cryptographic verification requires the actual tree
+			successCount += proofCount
+			fmt.Printf("   ✅ All proofs have valid structure\n")
 		}
 	}
 	
@@ -1024,136 +1086,179 @@ func convertProofToBytes(proofData interface{}) ([]byte, error) {
 	}
 }
 
-func convertToCourseCompletion(courseMap map[string]interface{}) (verkle.CourseCompletion, error) {
-	var course verkle.CourseCompletion
-	
-	// Convert map to JSON and then unmarshal to struct
-	courseJSON, err := json.Marshal(courseMap)
+// supersedeTermWithRevocations rebuilds a term tree with credentials removed and publishes new version.
+// actor is recorded as the principal that processed the revocations.
+func supersedeTermWithRevocations(termID string, revocations []database.RevocationRequest, cfg *config.Config, db *gorm.DB, actor string) error {
+	supersession, err := rebuildTermWithRevocations(termID, revocations, db)
 	if err != nil {
-		return course, fmt.Errorf("failed to marshal course map: %w", err)
+		return err
 	}
-	
-	if err := json.Unmarshal(courseJSON, &course); err != nil {
-		return course, fmt.Errorf("failed to unmarshal course: %w", err)
+	defer supersession.Close()
+
+	// STEP 6: Connect to blockchain and check if term already exists
+	integration, err := blockchain.NewBlockchainIntegration(
+		cfg.Network,
+		cfg.GetPrivateKey(),
+		cfg.GetContractAddress(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create blockchain integration: %w", err)
 	}
-	
-	return course, nil
+	defer integration.Close()
+	integration.SetTxStore(dbTxStore{db: db, actor: actor})
+	notifyPublications(integration, cfg.Network)
+
+	ctx := context.Background()
+	var result *blockchain.PublishResult
+	if !supersession.onChain(ctx, integration) {
+		// Term NOT yet on blockchain - publish as v1 with credential already removed
+		fmt.Printf("⛓️  Term %s not yet on blockchain. Publishing as v1 (with %d credentials removed)...\n", termID, supersession.revokedCount)
+		result, err = integration.PublishTermRoot(ctx, supersession.newRootHex, termID, supersession.totalStudents)
+		if err != nil {
+			return fmt.Errorf("blockchain publish failed: %w", err)
+		}
+	} else {
+		// Term ALREADY on blockchain - supersede with new version
+		fmt.Printf("⛓️  Term %s exists on blockchain (v%d). Publishing v%d via SupersedeTerm...\n",
+			termID, supersession.currentVersion, supersession.newVersion)
+		result, err = integration.SupersedeTerm(ctx, termID, supersession.newRootHex, supersession.totalStudents, supersession.reason)
+		if err != nil {
+			return fmt.Errorf("blockchain supersession failed: %w", err)
+		}
+	}
+
+	fmt.Printf("✅ Blockchain transaction: %s\n", result.TransactionHash)
+	fmt.Printf("  - Block: %d\n", result.BlockNumber)
+	fmt.Printf("  - Gas used: %d\n", result.GasUsed)
+
+	return supersession.record(db, result, actor)
 }
 
+// termSupersession is a term tree rebuilt with approved revocations, waiting for its new
+// root to be published. Close discards the rebuilt tree unless record saved it.
+type termSupersession struct {
+	termID         string
+	revocations    []database.RevocationRequest
+	oldTree        *verkle.TermVerkleTree
+	termTree       *verkle.TermVerkleTree
+	originalRoot   [32]byte
+	newRoot        [32]byte
+	newRootHex     string
+	revokedCount   int
+	deltaProof     *verkle.RevocationDeltaProof
+	totalStudents  *big.Int
+	reason         string
+	latestVersion  *database.TermRootVersion
+	currentVersion uint
+	newVersion     uint
+}
 
-// processApprovedRevocations checks for and processes approved revocations before publishing new term
-// supersedeTermWithRevocations rebuilds a term tree with credentials removed and publishes new version
-func supersedeTermWithRevocations(termID string, revocations []database.RevocationRequest, cfg *config.Config, db *gorm.DB) error {
+// rebuildTermWithRevocations replaces the revoked credentials of a term with tombstones
+// and proves the new root against the old one. Revoking is deterministic, so rebuilding
+// the same term with the same revocations gives the same root.
+func rebuildTermWithRevocations(termID string, revocations []database.RevocationRequest, db *gorm.DB) (*termSupersession, error) {
 	fmt.Printf("🔄 Rebuilding Verkle tree for term %s with %d revocations\n", termID, len(revocations))
 
 	// STEP 1: Load existing term tree
-	verkleTreeFile := filepath.Join("data/verkle_trees", fmt.Sprintf("%s_verkle_tree.json", termID))
-	treeData, err := os.ReadFile(verkleTreeFile)
+	oldTree, err := loadTermTreeForUpdate(termID)
 	if err != nil {
-		return fmt.Errorf("failed to load term tree: %w", err)
+		return nil, err
 	}
-
-	var termTree verkle.TermVerkleTree
-	if err := json.Unmarshal(treeData, &termTree); err != nil {
-		return fmt.Errorf("failed to parse term tree: %w", err)
+	s := &termSupersession{termID: termID, revocations: revocations, oldTree: oldTree}
+	if err := s.rebuild(db); err != nil {
+		// The updated tree shares the node store; closing discards unsaved changes
+		s.Close()
+		return nil, err
 	}
+	return s, nil
+}
 
-	originalCount := len(termTree.CourseEntries)
-	originalRoot := termTree.VerkleRoot
-	fmt.Printf("📊 Original tree: %d course entries, root: %x\n", originalCount, originalRoot[:8])
+func (s *termSupersession) rebuild(db *gorm.DB) error {
+	originalCount := len(s.oldTree.CourseEntries)
+	s.originalRoot = s.oldTree.VerkleRoot
+	fmt.Printf("📊 Original tree: %d course entries, root: %x\n", originalCount, s.originalRoot[:8])
 
-	// STEP 2: Remove revoked credentials from CourseEntries
-	revokedCount := 0
-	for _, rev := range revocations {
+	// STEP 2: Replace revoked credentials with revocation tombstones
+	s.termTree = s.oldTree.Clone()
+	var revokedKeys []string
+	for _, rev := range s.revocations {
 		studentDID := fmt.Sprintf("did:example:%s", rev.StudentID)
-		courseKey := fmt.Sprintf("%s:%s:%s", studentDID, termID, rev.CourseID)
+		courseKey := fmt.Sprintf("%s:%s:%s", studentDID, s.termID, rev.CourseID)
 
-		if _, exists := termTree.CourseEntries[courseKey]; exists {
-			delete(termTree.CourseEntries, courseKey)
-			delete(termTree.CourseProofs, courseKey) // Also remove proof
-			revokedCount++
+		if err := s.termTree.RevokeCourse(courseKey); err == nil {
+			revokedKeys = append(revokedKeys, courseKey)
 			fmt.Printf("  ✓ Removed: %s\n", courseKey)
 		} else {
 			fmt.Printf("  ⚠️  Not found: %s (may have been already removed)\n", courseKey)
 		}
 	}
+	s.revokedCount = len(revokedKeys)
 
-	if revokedCount == 0 {
+	if s.revokedCount == 0 {
 		return fmt.Errorf("no credentials were actually removed from the tree")
 	}
 
-	fmt.Printf("🗑️  Removed %d credentials from tree\n", revokedCount)
-
-	// STEP 3: Rebuild Verkle tree with remaining credentials
-	if err := termTree.RebuildVerkleTree(); err != nil {
-		return fmt.Errorf("failed to rebuild verkle tree: %w", err)
-	}
+	fmt.Printf("🗑️  Removed %d credentials from tree\n", s.revokedCount)
 
-	// STEP 4: Publish new tree version
-	if err := termTree.PublishTerm(); err != nil {
+	// STEP 3: Publish new tree version
+	if err := s.termTree.PublishTerm(); err != nil {
 		return fmt.Errorf("failed to publish updated term: %w", err)
 	}
 
-	newRoot := termTree.VerkleRoot
-	newCount := len(termTree.CourseEntries)
-	fmt.Printf("✅ New tree: %d course entries, root: %x\n", newCount, newRoot[:8])
+	// STEP 4: Prove the new root differs from the old one only by the revocations
+	deltaProof, err := verkle.GenerateRevocationDeltaProof(s.oldTree, s.termTree, revokedKeys)
+	if err != nil {
+		return fmt.Errorf("failed to generate revocation delta proof: %w", err)
+	}
+	if deltaProof.OldRoot != s.originalRoot {
+		return fmt.Errorf("stored root %x does not match rebuilt tree root %x", s.originalRoot[:8], deltaProof.OldRoot[:8])
+	}
+	if err := verkle.VerifyRevocationDeltaProof(deltaProof, s.originalRoot, s.termTree.VerkleRoot); err != nil {
+		return fmt.Errorf("revocation delta proof self-check failed: %w", err)
+	}
+	fmt.Printf("🔗 Revocation delta proof: %x -> %x (%d revoked, all other keys unchanged)\n",
+		s.originalRoot[:8], s.termTree.VerkleRoot[:8], len(revokedKeys))
+	s.deltaProof = deltaProof
+	s.newRoot = s.termTree.VerkleRoot
+	s.newRootHex = fmt.Sprintf("0x%x", s.newRoot)
+	newCount := len(s.termTree.CourseEntries)
+	fmt.Printf("✅ New tree: %d course entries, root: %x\n", newCount, s.newRoot[:8])
 
 	// STEP 5: Get latest version from database to determine new version number
-	latestVersion, err := database.GetLatestTermVersion(db, termID)
+	latestVersion, err := database.GetLatestTermVersion(db, s.termID)
 	if err != nil {
 		return fmt.Errorf("failed to get latest term version: %w", err)
 	}
-
-	var currentVersion uint = 0
+	s.latestVersion = latestVersion
 	if latestVersion != nil {
-		currentVersion = latestVersion.Version
+		s.currentVersion = latestVersion.Version
 	}
-	newVersion := currentVersion + 1
+	s.newVersion = s.currentVersion + 1
 
-	// STEP 6: Connect to blockchain and check if term already exists
-	integration, err := blockchain.NewBlockchainIntegration(
-		cfg.Network,
-		cfg.GetPrivateKey(),
-		cfg.GetContractAddress(),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to create blockchain integration: %w", err)
-	}
-	defer integration.Close()
-
-	ctx := context.Background()
-	newRootHex := fmt.Sprintf("0x%x", newRoot)
-	totalStudents := big.NewInt(int64(countUniqueStudents(termTree.CourseEntries, termID)))
-	reason := fmt.Sprintf("Revoked %d credentials due to institutional correction", revokedCount)
-
-	// Check if term already exists on blockchain
-	existingRoot, err := integration.GetLatestRootForTerm(ctx, termID)
+	s.totalStudents = big.NewInt(int64(countUniqueStudents(s.termTree.CourseEntries, s.termID)))
+	s.reason = fmt.Sprintf("Revoked %d credentials due to institutional correction", s.revokedCount)
+	return nil
+}
 
-	var result *blockchain.PublishResult
+// onChain reports whether the term already has a root on chain. A term that has none is
+// published as v1 with the credentials already removed rather than superseded.
+func (s *termSupersession) onChain(ctx context.Context, integration *blockchain.BlockchainIntegration) bool {
+	existingRoot, err := integration.GetLatestRootForTerm(ctx, s.termID)
 	if err != nil || existingRoot == nil || existingRoot.Version.Cmp(big.NewInt(0)) == 0 {
-		// Term NOT yet on blockchain - publish as v1 with credential already removed
-		fmt.Printf("⛓️  Term %s not yet on blockchain. Publishing as v1 (with %d credentials removed)...\n", termID, revokedCount)
-		result, err = integration.PublishTermRoot(ctx, newRootHex, termID, totalStudents)
-		if err != nil {
-			return fmt.Errorf("blockchain publish failed: %w", err)
-		}
-		newVersion = 1 // Override to 1 since this is fresh publish
-	} else {
-		// Term ALREADY on blockchain - supersede with new version
-		fmt.Printf("⛓️  Term %s exists on blockchain (v%d). Publishing v%d via SupersedeTerm...\n",
-			termID, existingRoot.Version.Int64(), newVersion)
-		result, err = integration.SupersedeTerm(ctx, termID, newRootHex, totalStudents, reason)
-		if err != nil {
-			return fmt.Errorf("blockchain supersession failed: %w", err)
-		}
+		s.newVersion = 1 // Override to 1 since this is fresh publish
+		return false
 	}
+	return true
+}
 
-	fmt.Printf("✅ Blockchain transaction: %s\n", result.TransactionHash)
-	fmt.Printf("  - Block: %d\n", result.BlockNumber)
-	fmt.Printf("  - Gas used: %d\n", result.GasUsed)
+// record saves the rebuilt tree, root and delta proof files and the database records
+// once the new root was published in result by actor
+func (s *termSupersession) record(db *gorm.DB, result *blockchain.PublishResult, actor string) error {
+	termID, newVersion, currentVersion := s.termID, s.newVersion, s.currentVersion
 
 	// STEP 7: Save updated tree files
-	termTreeData, err := termTree.SerializeToJSON()
+	verkleTreeFile := filepath.Join("data/verkle_trees", fmt.Sprintf("%s_verkle_tree.json", termID))
+	termTreeData, err := s.termTree.SerializeToJSON()
 	if err != nil {
 		return fmt.Errorf("failed to serialize updated tree: %w", err)
 	}
@@ -1161,19 +1266,23 @@ func supersedeTermWithRevocations(termID string, revocations []database.Revocati
 	if err := os.WriteFile(verkleTreeFile, termTreeData, 0644); err != nil {
 		return fmt.Errorf("failed to save updated tree: %w", err)
 	}
+	if err := saveTermTreeStore(s.termTree); err != nil {
+		return fmt.Errorf("failed to save updated node store: %w", err)
+	}
 
 	// Save new root file with version suffix
 	rootsDir := "publish_ready/roots"
 	rootData := map[string]interface{}{
 		"term_id":              termID,
 		"version":              newVersion,
-		"verkle_root":          newRootHex,
+		"verkle_root":          s.newRootHex,
 		"timestamp":            time.Now().Format(time.RFC3339),
-		"total_students":       totalStudents.Int64(),
+		"total_students":       s.totalStudents.Int64(),
 		"ready_for_blockchain": true,
-		"supersedes_root":      fmt.Sprintf("0x%x", originalRoot),
-		"supersession_reason":  reason,
-		"credentials_revoked":  revokedCount,
+		"supersedes_root":      fmt.Sprintf("0x%x", s.originalRoot),
+		"supersession_reason":  s.reason,
+		"credentials_revoked":  s.revokedCount,
+		"delta_proof_file":     fmt.Sprintf("revocation_proofs/delta_%s_v%d.json", termID, newVersion),
 	}
 
 	rootFile, err := json.MarshalIndent(rootData, "", "  ")
@@ -1186,22 +1295,39 @@ func supersedeTermWithRevocations(termID string, revocations []database.Revocati
 		return fmt.Errorf("failed to save root file: %w", err)
 	}
 
-	fmt.Printf("💾 Saved updated tree and root files\n")
+	// Save delta proof so auditors can check the supersession offline
+	s.deltaProof.OldVersion = uint32(currentVersion)
+	s.deltaProof.NewVersion = uint32(newVersion)
+	deltaProofJSON, err := json.MarshalIndent(s.deltaProof, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal revocation delta proof: %w", err)
+	}
+	deltaDir := "publish_ready/revocation_proofs"
+	if err := os.MkdirAll(deltaDir, 0755); err != nil {
+		return fmt.Errorf("failed to create revocation proofs directory: %w", err)
+	}
+	deltaFileName := fmt.Sprintf("delta_%s_v%d.json", termID, newVersion)
+	if err := os.WriteFile(filepath.Join(deltaDir, deltaFileName), deltaProofJSON, 0644); err != nil {
+		return fmt.Errorf("failed to save revocation delta proof: %w", err)
+	}
+
+	fmt.Printf("💾 Saved updated tree, root and delta proof files\n")
 
 	// STEP 8: Store version in database
 	termVersion := &database.TermRootVersion{
 		TermID:              termID,
 		Version:             uint(newVersion),
-		RootHash:            newRootHex,
-		TotalStudents:       uint(totalStudents.Int64()),
+		RootHash:            s.newRootHex,
+		TotalStudents:       uint(s.totalStudents.Int64()),
 		PublishedAt:         time.Now(),
 		IsSuperseded:        false,
 		SupersededBy:        "",
 		SupersessionReason:  "",
 		TxHash:              result.TransactionHash,
 		BlockNumber:         result.BlockNumber,
-		CredentialsRevoked:  uint(revokedCount),
-		ChangeDescription:   reason,
+		PublishedBy:         actor,
+		CredentialsRevoked:  uint(s.revokedCount),
+		ChangeDescription:   s.reason,
 	}
 
 	if err := database.CreateTermRootVersion(db, termVersion); err != nil {
@@ -1209,18 +1335,14 @@ func supersedeTermWithRevocations(termID string, revocations []database.Revocati
 	}
 
 	// Mark old version as superseded if it exists
-	if latestVersion != nil {
-		if err := database.MarkTermVersionSuperseded(db, termID, currentVersion, newRootHex, reason); err != nil {
+	if s.latestVersion != nil {
+		if err := database.MarkTermVersionSuperseded(db, termID, currentVersion, s.newRootHex, s.reason); err != nil {
 			fmt.Printf("⚠️  Warning: Failed to mark old version as superseded: %v\n", err)
 		}
 	}
 
 	// STEP 9: Mark revocations as processed
-	var requestIDs []string
-	for _, rev := range revocations {
-		requestIDs = append(requestIDs, rev.RequestID)
-	}
-	err = database.MarkRevocationProcessed(db, requestIDs, result.TransactionHash, uint(newVersion))
+	err = database.MarkRevocationProcessed(db, s.requestIDs(), result.TransactionHash, uint(newVersion))
 	if err != nil {
 		fmt.Printf("⚠️  Warning: Failed to mark revocations as processed: %v\n", err)
 	}
@@ -1231,23 +1353,56 @@ func supersedeTermWithRevocations(termID string, revocations []database.Revocati
 		TermID:       termID,
 		OldVersion:   uint(currentVersion),
 		NewVersion:   uint(newVersion),
-		OldRootHash:  fmt.Sprintf("0x%x", originalRoot),
-		NewRootHash:  newRootHex,
-		RequestCount: len(revocations),
+		OldRootHash:  fmt.Sprintf("0x%x", s.originalRoot),
+		NewRootHash:  s.newRootHex,
+		RequestCount: len(s.revocations),
 		ProcessedAt:  time.Now(),
-		ProcessedBy:  "system",
+		ProcessedBy:  actor,
 		TxHash:       result.TransactionHash,
 		Status:       "completed",
+		DeltaProof:   datatypes.JSON(deltaProofJSON),
 	}
 
 	if err := database.CreateRevocationBatch(db, batch); err != nil {
 		fmt.Printf("⚠️  Warning: Failed to create revocation batch record: %v\n", err)
 	}
 
+	revoked := make([]revokedCredential, 0, len(s.revocations))
+	for _, rev := range s.revocations {
+		revoked = append(revoked, revokedCredential{RequestID: rev.RequestID, StudentID: rev.StudentID, CourseID: rev.CourseID})
+	}
+	emitWebhook(eventRevocationBatchProcessed, revocationBatchEvent{
+		BatchID:            batch.BatchID,
+		TermID:             termID,
+		OldVersion:         batch.OldVersion,
+		NewVersion:         batch.NewVersion,
+		OldRoot:            batch.OldRootHash,
+		NewRoot:            batch.NewRootHash,
+		CredentialsRevoked: s.revokedCount,
+		Revocations:        revoked,
+		TransactionHash:    result.TransactionHash,
+		ProcessedBy:        actor,
+		ProcessedAt:        batch.ProcessedAt.UTC(),
+	})
+
 	fmt.Printf("✅ Revocation processing complete for term %s\n", termID)
 	return nil
 }
 
+// requestIDs returns the IDs of the revocation requests the supersession processes
+func (s *termSupersession) requestIDs() []string {
+	var requestIDs []string
+	for _, rev := range s.revocations {
+		requestIDs = append(requestIDs, rev.RequestID)
+	}
+	return requestIDs
+}
+
+// Close closes the term tree, discarding the rebuilt tree if it was not saved
+func (s *termSupersession) Close() {
+	s.oldTree.Close()
+}
+
 // countUniqueStudents counts unique students in course entries
 func countUniqueStudents(entries map[string]verkle.CourseCompletion, termID string) int {
 	students := make(map[string]bool)
@@ -1261,34 +1416,23 @@ func countUniqueStudents(entries map[string]verkle.CourseCompletion, termID stri
 	return len(students)
 }
 
-func processApprovedRevocations(network, privateKey string, gasLimit uint64) error {
+// processApprovedRevocations checks for and processes approved revocations before publishing new term
+func processApprovedRevocations(network, privateKey string, gasLimit uint64, actor string) error {
 	// Connect to database
 	db, err := database.Connect()
 	if err != nil {
 		return fmt.Errorf("database connection failed: %w", err)
 	}
 
-	// Get all approved (not yet processed) revocations
-	var approvedRevocations []database.RevocationRequest
-	err = db.Where("status = ?", "approved").Find(&approvedRevocations).Error
+	revocationsByTerm, err := approvedRevocationsByTerm(db)
 	if err != nil {
-		return fmt.Errorf("failed to get approved revocations: %w", err)
+		return err
 	}
-
-	if len(approvedRevocations) == 0 {
+	if len(revocationsByTerm) == 0 {
 		fmt.Println("✅ No pending revocations to process")
 		return nil
 	}
 
-	// Group revocations by term
-	revocationsByTerm := make(map[string][]database.RevocationRequest)
-	for _, rev := range approvedRevocations {
-		revocationsByTerm[rev.TermID] = append(revocationsByTerm[rev.TermID], rev)
-	}
-
-	fmt.Printf("📋 Found %d approved revocations across %d terms\n", 
-		len(approvedRevocations), len(revocationsByTerm))
-
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -1317,7 +1461,7 @@ func processApprovedRevocations(network, privateKey string, gasLimit uint64) err
 		}
 
 		// Execute revocation by rebuilding tree and publishing new version
-		err := supersedeTermWithRevocations(termID, revocations, cfg, db)
+		err := supersedeTermWithRevocations(termID, revocations, cfg, db, actor)
 		if err != nil {
 			fmt.Printf("❌ Failed to process revocations for term %s: %v\n", termID, err)
 			fmt.Printf("⚠️  These revocations will remain in 'approved' status\n")
@@ -1329,3 +1473,22 @@ func processApprovedRevocations(network, privateKey string, gasLimit uint64) err
 
 	return nil
 }
+
+// approvedRevocationsByTerm returns the approved, not yet processed revocations grouped by term
+func approvedRevocationsByTerm(db *gorm.DB) (map[string][]database.RevocationRequest, error) {
+	var approvedRevocations []database.RevocationRequest
+	err := db.Where("status = ?", "approved").Find(&approvedRevocations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approved revocations: %w", err)
+	}
+
+	revocationsByTerm := make(map[string][]database.RevocationRequest)
+	for _, rev := range approvedRevocations {
+		revocationsByTerm[rev.TermID] = append(revocationsByTerm[rev.TermID], rev)
+	}
+	if len(approvedRevocations) > 0 {
+		fmt.Printf("📋 Found %d approved revocations across %d terms\n",
+			len(approvedRevocations), len(revocationsByTerm))
+	}
+	return revocationsByTerm, nil
+}
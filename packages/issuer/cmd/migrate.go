@@ -50,4 +50,5 @@ func runMigrations() {
 	fmt.Println("  - accumulated_receipts")
 	fmt.Println("  - verification_logs")
 	fmt.Println("  - blockchain_transactions")
+	fmt.Println("  - chain_indexer_checkpoints")
 }
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Kinds of offline transaction bookkeeping
+const (
+	offlineKindPublish      = "publish"      // publish-roots: a term root file
+	offlineKindSupersession = "supersession" // supersede-term, process-revocations: approved revocations of a term
+)
+
+// offlineBookkeeping travels with an offline transaction and tells broadcast-tx which
+// records to update once the transaction is confirmed
+type offlineBookkeeping struct {
+	Kind               string   `json:"kind"`
+	RootFile           string   `json:"root_file,omitempty"`
+	RevocationRequests []string `json:"revocation_requests,omitempty"`
+}
+
+var processRevocationsCmd = &cobra.Command{
+	Use:   "process-revocations",
+	Short: "Process approved revocations of every term",
+	Long: `Rebuild every term with approved revocations and publish its new version, as
+publish-roots does before publishing a term.
+
+With --unsigned-out the publications are not sent: each one is written to the directory
+as an unsigned transaction, with consecutive nonces, to be signed offline with sign-tx
+and sent with broadcast-tx in nonce order.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		network := networkFlag(cmd)
+		privateKey, _ := cmd.Flags().GetString("private-key")
+		gasLimit, _ := cmd.Flags().GetUint64("gas-limit")
+		unsignedOut, _ := cmd.Flags().GetString("unsigned-out")
+
+		var err error
+		if unsignedOut != "" {
+			err = prepareRevocationsOffline(network, privateKey, gasLimit, unsignedOut)
+		} else {
+			err = processApprovedRevocations(network, privateKey, gasLimit, cliActor())
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to process revocations: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var signTxCmd = &cobra.Command{
+	Use:   "sign-tx [unsigned-file]",
+	Short: "Sign a prepared transaction on the offline machine",
+	Long: `Sign a transaction file written with --unsigned-out. It is meant for the offline
+machine that holds the issuer key and needs no network access.
+
+The calldata is decoded and checked against the term, root and student count in the file
+before anything is signed. The key comes from SIGNER (keystore, remote or, outside
+production, key); --keystore selects a keystore file directly.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		out, _ := cmd.Flags().GetString("out")
+		keystorePath, _ := cmd.Flags().GetString("keystore")
+
+		if err := signOfflineTransaction(args[0], out, keystorePath); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to sign transaction: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+var broadcastTxCmd = &cobra.Command{
+	Use:   "broadcast-tx [signed-file]",
+	Short: "Send a transaction signed offline and update the database",
+	Long: `Send a transaction signed with sign-tx, wait until it is confirmed and update the
+records as publish-roots or supersede-term would have.
+
+For a supersession the term tree is rebuilt with the same revocations first; when the
+rebuilt root is not the signed one, e.g. because the term changed since the transaction
+was prepared, nothing is sent. An interrupted broadcast can be repeated.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := broadcastOfflineTransaction(args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to broadcast transaction: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	processRevocationsCmd.Flags().String("network", "sepolia", "blockchain network from config/networks.json, or simulated; NETWORK overrides the default")
+	processRevocationsCmd.Flags().String("private-key", "", "private key for signing")
+	processRevocationsCmd.Flags().Uint64("gas-limit", 0, "gas limit for transaction")
+	processRevocationsCmd.Flags().String("unsigned-out", "", "write unsigned transactions to this directory instead of sending them")
+
+	signTxCmd.Flags().String("out", "", "signed transaction file (default: next to the unsigned file, .signed.json)")
+	signTxCmd.Flags().String("keystore", "", "encrypted keystore file to sign with, instead of SIGNER")
+
+	rootCmd.AddCommand(processRevocationsCmd)
+	rootCmd.AddCommand(signTxCmd)
+	rootCmd.AddCommand(broadcastTxCmd)
+}
+
+// offlineTxWriter prepares unsigned transactions for the issuer account with consecutive
+// nonces, starting at the pending nonce of the account
+type offlineTxWriter struct {
+	cfg         *config.Config
+	integration *blockchain.BlockchainIntegration
+	nonce       uint64
+	outDir      string
+}
+
+// newOfflineTxWriter connects to the network of cfg. Only the address of the issuer
+// account is needed; with SIGNER=offline it is SIGNER_ADDRESS.
+func newOfflineTxWriter(cfg *config.Config, outDir string) (*offlineTxWriter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	integration, err := blockchain.NewBlockchainIntegration(cfg.Network, cfg.GetPrivateKey(), cfg.GetContractAddress())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+	nonce, err := integration.PendingNonce(context.Background())
+	if err != nil {
+		integration.Close()
+		return nil, err
+	}
+	return &offlineTxWriter{cfg: cfg, integration: integration, nonce: nonce, outDir: outDir}, nil
+}
+
+// write saves otx with its bookkeeping and moves on to the next nonce
+func (w *offlineTxWriter) write(otx *blockchain.OfflineTx, bookkeeping offlineBookkeeping) (string, error) {
+	data, err := json.Marshal(bookkeeping)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal bookkeeping: %w", err)
+	}
+	otx.Bookkeeping = data
+
+	path := filepath.Join(w.outDir, fmt.Sprintf("%s_%s_nonce%d.unsigned.json", otx.Method, otx.TermID, otx.Nonce))
+	if err := blockchain.WriteOfflineTx(path, otx); err != nil {
+		return "", err
+	}
+	w.nonce++
+
+	printOfflineTx(otx)
+	fmt.Printf("📝 Unsigned transaction written to %s\n", path)
+	return path, nil
+}
+
+func (w *offlineTxWriter) Close() {
+	w.integration.Close()
+}
+
+// preparePublicationOffline writes the publication of a term root as an unsigned
+// transaction. Approved revocations are not processed; prepare them first with
+// process-revocations --unsigned-out.
+func preparePublicationOffline(termID, network, privateKey string, gasLimit uint64, outDir string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if network != "" {
+		cfg.Network = network
+	}
+	if privateKey != "" {
+		cfg.IssuerPrivateKey = privateKey
+	}
+	if gasLimit > 0 {
+		cfg.DefaultGasLimit = gasLimit
+	}
+
+	rootFile, err := findRootFile(termID)
+	if err != nil {
+		return err
+	}
+	verkleRootHex, rootTermID, totalStudents, err := blockchain.ReadRootFile(rootFile)
+	if err != nil {
+		return err
+	}
+
+	writer, err := newOfflineTxWriter(cfg, outDir)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	fmt.Println("ℹ️  Approved revocations are not processed with --unsigned-out; run process-revocations --unsigned-out for them")
+	otx, err := writer.integration.PreparePublishTermRoot(context.Background(), cfg.Network, writer.nonce, verkleRootHex, rootTermID, totalStudents)
+	if err != nil {
+		return fmt.Errorf("failed to prepare publication of %s: %w", termID, err)
+	}
+	_, err = writer.write(otx, offlineBookkeeping{Kind: offlineKindPublish, RootFile: rootFile})
+	return err
+}
+
+// prepareSupersessionOffline rebuilds a term with its approved revocations like
+// supersedeTermWithRevocations, but writes the publication of the new root as an unsigned
+// transaction. Nothing is saved; broadcast-tx rebuilds the term once more and records it.
+func prepareSupersessionOffline(writer *offlineTxWriter, db *gorm.DB, termID string, revocations []database.RevocationRequest) (string, error) {
+	supersession, err := rebuildTermWithRevocations(termID, revocations, db)
+	if err != nil {
+		return "", err
+	}
+	defer supersession.Close()
+
+	ctx := context.Background()
+	var otx *blockchain.OfflineTx
+	if !supersession.onChain(ctx, writer.integration) {
+		fmt.Printf("⛓️  Term %s not yet on blockchain. Preparing v1 (with %d credentials removed)...\n", termID, supersession.revokedCount)
+		otx, err = writer.integration.PreparePublishTermRoot(ctx, writer.cfg.Network, writer.nonce, supersession.newRootHex, termID, supersession.totalStudents)
+	} else {
+		fmt.Printf("⛓️  Term %s exists on blockchain (v%d). Preparing v%d via SupersedeTerm...\n",
+			termID, supersession.currentVersion, supersession.newVersion)
+		otx, err = writer.integration.PrepareSupersedeTerm(ctx, writer.cfg.Network, writer.nonce, termID, supersession.newRootHex, supersession.totalStudents, supersession.reason)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare transaction: %w", err)
+	}
+	return writer.write(otx, offlineBookkeeping{Kind: offlineKindSupersession, RevocationRequests: supersession.requestIDs()})
+}
+
+// prepareRevocationsOffline prepares the supersession of every term with approved
+// revocations, in term order
+func prepareRevocationsOffline(network, privateKey string, gasLimit uint64, outDir string) error {
+	db, err := database.Connect()
+	if err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+	revocationsByTerm, err := approvedRevocationsByTerm(db)
+	if err != nil {
+		return err
+	}
+	if len(revocationsByTerm) == 0 {
+		fmt.Println("✅ No pending revocations to process")
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if network != "" {
+		cfg.Network = network
+	}
+	if privateKey != "" {
+		cfg.IssuerPrivateKey = privateKey
+	}
+	if gasLimit > 0 {
+		cfg.DefaultGasLimit = gasLimit
+	}
+
+	writer, err := newOfflineTxWriter(cfg, outDir)
+	if err != nil {
+		return err
+	}
+	defer writer.Close()
+
+	termIDs := make([]string, 0, len(revocationsByTerm))
+	for termID := range revocationsByTerm {
+		termIDs = append(termIDs, termID)
+	}
+	sort.Strings(termIDs)
+
+	for _, termID := range termIDs {
+		fmt.Printf("\n🔄 Preparing %d revocations for term: %s\n", len(revocationsByTerm[termID]), termID)
+		if _, err := prepareSupersessionOffline(writer, db, termID, revocationsByTerm[termID]); err != nil {
+			fmt.Printf("❌ Failed to prepare revocations for term %s: %v\n", termID, err)
+			continue
+		}
+	}
+	return nil
+}
+
+// signOfflineTransaction signs the transaction in path and writes it to out
+func signOfflineTransaction(path, out, keystorePath string) error {
+	otx, err := blockchain.ReadOfflineTx(path)
+	if err != nil {
+		return err
+	}
+	if err := otx.Verify(); err != nil {
+		return fmt.Errorf("refusing to sign %s: %w", path, err)
+	}
+	printOfflineTx(otx)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.Network = otx.Network
+	if keystorePath != "" {
+		cfg.SignerType = config.SignerKeystore
+		cfg.KeystorePath = keystorePath
+	}
+	if cfg.SignerType == config.SignerOffline {
+		return errors.New("this machine has no issuer key (SIGNER=offline); run sign-tx on the offline machine")
+	}
+
+	ctx := context.Background()
+	signer, err := blockchain.ConfiguredSigner(ctx, cfg, cfg.GetPrivateKey())
+	if err != nil {
+		return err
+	}
+	if err := otx.Sign(ctx, signer); err != nil {
+		return err
+	}
+
+	if out == "" {
+		out = strings.TrimSuffix(strings.TrimSuffix(path, ".json"), ".unsigned") + ".signed.json"
+	}
+	if err := blockchain.WriteOfflineTx(out, otx); err != nil {
+		return err
+	}
+	fmt.Printf("✍️  Signed transaction %s written to %s\n", otx.TxHash.Hex(), out)
+	fmt.Println("💡 Copy it to the online machine and run broadcast-tx")
+	return nil
+}
+
+// broadcastOfflineTransaction sends the signed transaction in path and updates the records
+// named by its bookkeeping
+func broadcastOfflineTransaction(path string) error {
+	otx, err := blockchain.ReadOfflineTx(path)
+	if err != nil {
+		return err
+	}
+	if _, err := otx.SignedTransaction(); err != nil {
+		if errors.Is(err, blockchain.ErrNotSigned) {
+			return fmt.Errorf("%s is not signed; sign it with sign-tx on the offline machine", path)
+		}
+		return err
+	}
+	var bookkeeping offlineBookkeeping
+	if err := json.Unmarshal(otx.Bookkeeping, &bookkeeping); err != nil {
+		return fmt.Errorf("transaction file has no bookkeeping: %w", err)
+	}
+	printOfflineTx(otx)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg.Network = otx.Network
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	switch bookkeeping.Kind {
+	case offlineKindPublish:
+		return broadcastPublication(cfg, otx, bookkeeping)
+	case offlineKindSupersession:
+		return broadcastSupersession(cfg, otx, bookkeeping)
+	default:
+		return fmt.Errorf("unknown bookkeeping kind %q", bookkeeping.Kind)
+	}
+}
+
+// broadcastPublication sends a publish-roots transaction and saves its transaction record
+func broadcastPublication(cfg *config.Config, otx *blockchain.OfflineTx, bookkeeping offlineBookkeeping) error {
+	integration, err := blockchain.NewBlockchainIntegration(cfg.Network, cfg.GetPrivateKey(), cfg.GetContractAddress())
+	if err != nil {
+		return fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+	defer integration.Close()
+	defer recordTransactions(integration, cliActor())()
+	notifyPublications(integration, cfg.Network)
+
+	fmt.Println("📡 Broadcasting signed transaction...")
+	result, err := integration.BroadcastOfflineTx(context.Background(), otx)
+	if err != nil {
+		return err
+	}
+	if err := integration.SaveTransactionRecord(result, bookkeeping.RootFile); err != nil {
+		fmt.Printf("⚠️  Warning: failed to save transaction record: %v\n", err)
+	}
+
+	fmt.Printf("✅ Term root published successfully!\n")
+	fmt.Printf("🔗 Transaction hash: %s\n", result.TransactionHash)
+	fmt.Printf("📦 Block number: %d\n", result.BlockNumber)
+	fmt.Printf("⛽ Gas used: %d\n", result.GasUsed)
+	return nil
+}
+
+// broadcastSupersession rebuilds the term with the revocations of the transaction, checks
+// that the rebuilt root is the signed one, sends the transaction and records the new
+// version like supersedeTermWithRevocations
+func broadcastSupersession(cfg *config.Config, otx *blockchain.OfflineTx, bookkeeping offlineBookkeeping) error {
+	db, err := database.Connect()
+	if err != nil {
+		return fmt.Errorf("database connection failed: %w", err)
+	}
+
+	var revocations []database.RevocationRequest
+	err = db.Where("request_id IN ? AND term_id = ? AND status = ?", bookkeeping.RevocationRequests, otx.TermID, "approved").
+		Find(&revocations).Error
+	if err != nil {
+		return fmt.Errorf("failed to get approved revocations: %w", err)
+	}
+	if len(revocations) != len(bookkeeping.RevocationRequests) {
+		return fmt.Errorf("%d of the %d revocations of the transaction are no longer approved for term %s; prepare it again",
+			len(bookkeeping.RevocationRequests)-len(revocations), len(bookkeeping.RevocationRequests), otx.TermID)
+	}
+
+	supersession, err := rebuildTermWithRevocations(otx.TermID, revocations, db)
+	if err != nil {
+		return err
+	}
+	defer supersession.Close()
+	if common.Hash(supersession.newRoot) != otx.VerkleRoot {
+		return fmt.Errorf("rebuilt root %s is not the signed root %s; the term changed since the transaction was prepared, prepare it again",
+			supersession.newRootHex, otx.VerkleRoot.Hex())
+	}
+	if otx.Method == blockchain.MethodPublishTermRoot {
+		supersession.newVersion = 1 // The term was not on chain when the transaction was prepared
+	}
+
+	integration, err := blockchain.NewBlockchainIntegration(cfg.Network, cfg.GetPrivateKey(), cfg.GetContractAddress())
+	if err != nil {
+		return fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+	defer integration.Close()
+	integration.SetTxStore(dbTxStore{db: db, actor: cliActor()})
+	notifyPublications(integration, cfg.Network)
+
+	fmt.Println("📡 Broadcasting signed transaction...")
+	result, err := integration.BroadcastOfflineTx(context.Background(), otx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Blockchain transaction: %s\n", result.TransactionHash)
+	fmt.Printf("  - Block: %d\n", result.BlockNumber)
+	fmt.Printf("  - Gas used: %d\n", result.GasUsed)
+
+	return supersession.record(db, result, cliActor())
+}
+
+// printOfflineTx prints what an offline transaction does, for review before signing
+func printOfflineTx(otx *blockchain.OfflineTx) {
+	fmt.Printf("📄 %s for term %s\n", otx.Method, otx.TermID)
+	fmt.Printf("  - Root: %s\n", otx.VerkleRoot.Hex())
+	fmt.Printf("  - Students: %d\n", otx.TotalStudents)
+	if otx.Reason != "" {
+		fmt.Printf("  - Reason: %s\n", otx.Reason)
+	}
+	fmt.Printf("  - Network: %s (chain %d), registry %s\n", otx.Network, otx.ChainID, otx.To.Hex())
+	fmt.Printf("  - From: %s, nonce %d\n", otx.From.Hex(), otx.Nonce)
+	if otx.MaxPriorityFeePerGas != nil {
+		fmt.Printf("  - Gas limit %d, max fee %s gwei, priority fee %s gwei\n", otx.GasLimit, formatGwei(otx.MaxFeePerGas), formatGwei(otx.MaxPriorityFeePerGas))
+	} else {
+		fmt.Printf("  - Gas limit %d, gas price %s gwei\n", otx.GasLimit, formatGwei(otx.MaxFeePerGas))
+	}
+	if otx.MaxCost != nil {
+		fmt.Printf("  - Costs at most %s ETH\n", formatEther(otx.MaxCost))
+	}
+}
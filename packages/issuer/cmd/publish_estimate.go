@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"gorm.io/gorm"
+)
+
+// plannedTransaction is one transaction a publication would send, priced without sending it
+type plannedTransaction struct {
+	TermID     string
+	Method     string
+	VerkleRoot string
+	Estimate   *blockchain.FeeEstimate
+}
+
+// estimateTermRootPublication prices the transactions publishTermRoots would send for
+// termID without sending anything. Like the real publication, approved revocations are
+// applied first: each affected term is rebuilt and priced as supersedeTerm when it is
+// already on chain, or as publishTermRoot of the rebuilt root otherwise. termID itself is
+// priced from its root file unless it is already on chain or covered by a revocation.
+// Rebuilt trees are discarded.
+func estimateTermRootPublication(termID, network, privateKey string, gasLimit uint64) ([]plannedTransaction, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if network != "" {
+		cfg.Network = network
+	}
+	if privateKey != "" {
+		cfg.IssuerPrivateKey = privateKey
+	}
+	if gasLimit > 0 {
+		cfg.DefaultGasLimit = gasLimit
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Estimates run as the issuer: owner-only calls revert from any other address
+	integration, err := blockchain.NewBlockchainIntegration(cfg.Network, cfg.GetPrivateKey(), cfg.GetContractAddress())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+	defer integration.Close()
+
+	ctx := context.Background()
+	planned, err := estimateRevocationSupersessions(ctx, integration)
+	if err != nil {
+		fmt.Printf("⚠️  Warning: Failed to estimate revocations: %v\n", err)
+		fmt.Println("⚠️  Continuing with term publication...")
+	}
+	for _, tx := range planned {
+		if tx.TermID == termID {
+			return planned, nil
+		}
+	}
+
+	if latest, err := integration.GetLatestRootForTerm(ctx, termID); err == nil && latest != nil && latest.Version.Sign() > 0 {
+		fmt.Printf("✅ Term %s already published to blockchain (v%s)\n", termID, latest.Version)
+		return planned, nil
+	}
+
+	rootFile, err := findRootFile(termID)
+	if err != nil {
+		return nil, err
+	}
+	verkleRootHex, rootTermID, totalStudents, err := blockchain.ReadRootFile(rootFile)
+	if err != nil {
+		return nil, err
+	}
+	estimate, err := integration.EstimatePublishTermRoot(ctx, verkleRootHex, rootTermID, totalStudents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate publication of %s: %w", termID, err)
+	}
+	return append(planned, plannedTransaction{
+		TermID:     rootTermID,
+		Method:     blockchain.MethodPublishTermRoot,
+		VerkleRoot: verkleRootHex,
+		Estimate:   estimate,
+	}), nil
+}
+
+// estimateRevocationSupersessions prices the transactions processApprovedRevocations would
+// send. A term that cannot be rebuilt is skipped, as the real run leaves its revocations
+// approved.
+func estimateRevocationSupersessions(ctx context.Context, integration *blockchain.BlockchainIntegration) ([]plannedTransaction, error) {
+	db, err := database.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+	revocationsByTerm, err := approvedRevocationsByTerm(db)
+	if err != nil {
+		return nil, err
+	}
+
+	termIDs := make([]string, 0, len(revocationsByTerm))
+	for termID := range revocationsByTerm {
+		termIDs = append(termIDs, termID)
+	}
+	sort.Strings(termIDs)
+
+	var planned []plannedTransaction
+	for _, termID := range termIDs {
+		tx, err := estimateSupersession(ctx, integration, termID, revocationsByTerm[termID], db)
+		if err != nil {
+			fmt.Printf("❌ Failed to estimate revocations for term %s: %v\n", termID, err)
+			continue
+		}
+		planned = append(planned, *tx)
+	}
+	return planned, nil
+}
+
+// estimateSupersession rebuilds termID with its revocations and prices publishing the new
+// root the way supersedeTermWithRevocations would. The rebuilt tree is discarded.
+func estimateSupersession(ctx context.Context, integration *blockchain.BlockchainIntegration, termID string, revocations []database.RevocationRequest, db *gorm.DB) (*plannedTransaction, error) {
+	supersession, err := rebuildTermWithRevocations(termID, revocations, db)
+	if err != nil {
+		return nil, err
+	}
+	defer supersession.Close()
+
+	tx := &plannedTransaction{TermID: termID, VerkleRoot: supersession.newRootHex}
+	if supersession.onChain(ctx, integration) {
+		tx.Method = blockchain.MethodSupersedeTerm
+		tx.Estimate, err = integration.EstimateSupersedeTerm(ctx, termID, supersession.newRootHex, supersession.totalStudents, supersession.reason)
+	} else {
+		tx.Method = blockchain.MethodPublishTermRoot
+		tx.Estimate, err = integration.EstimatePublishTermRoot(ctx, supersession.newRootHex, termID, supersession.totalStudents)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate %s of %s: %w", tx.Method, termID, err)
+	}
+	return tx, nil
+}
+
+// withinBudget reports whether every planned transaction fits the budget
+func withinBudget(planned []plannedTransaction) bool {
+	for _, tx := range planned {
+		if !tx.Estimate.WithinBudget() {
+			return false
+		}
+	}
+	return true
+}
+
+// findRootFile returns the root file of termID written by add-term, relative to the
+// project root or to cmd/
+func findRootFile(termID string) (string, error) {
+	rootFile := filepath.Join("publish_ready/roots", fmt.Sprintf("root_%s.json", termID))
+	if _, err := os.Stat(rootFile); os.IsNotExist(err) {
+		altRootFile := filepath.Join("../publish_ready/roots", fmt.Sprintf("root_%s.json", termID))
+		if _, err := os.Stat(altRootFile); os.IsNotExist(err) {
+			return "", fmt.Errorf("root file not found: %s (also tried %s). Run 'add-term' first", rootFile, altRootFile)
+		}
+		rootFile = altRootFile
+	}
+	return rootFile, nil
+}
+
+// printFeeEstimate prints the cost of a transaction as estimated before sending it
+func printFeeEstimate(estimate *blockchain.FeeEstimate) {
+	fmt.Printf("⛽ Gas estimate: %d (gas limit %d)\n", estimate.GasEstimate, estimate.GasLimit)
+	if estimate.BaseFee != nil {
+		fmt.Printf("📊 Base fee: %s gwei, priority fee: %s gwei, max fee: %s gwei\n",
+			formatGwei(estimate.BaseFee), formatGwei(estimate.MaxPriorityFeePerGas), formatGwei(estimate.MaxFeePerGas))
+	} else {
+		fmt.Printf("📊 Gas price: %s gwei (legacy transaction)\n", formatGwei(estimate.MaxFeePerGas))
+	}
+	fmt.Printf("💰 Expected cost: %s ETH, at most %s ETH\n", formatEther(estimate.ExpectedCost), formatEther(estimate.MaxCost))
+	if estimate.Budget != nil {
+		if estimate.WithinBudget() {
+			fmt.Printf("✅ Within budget of %s ETH\n", formatEther(estimate.Budget))
+		} else {
+			fmt.Printf("🚫 Exceeds budget of %s ETH (MAX_TX_COST), the transaction would not be sent\n", formatEther(estimate.Budget))
+		}
+	}
+}
+
+// dryRunResponse is the API form of a dry run of publishing termID
+func dryRunResponse(termID string, planned []plannedTransaction) map[string]interface{} {
+	transactions := make([]map[string]interface{}, 0, len(planned))
+	for _, tx := range planned {
+		transactions = append(transactions, feeEstimateResponse(tx))
+	}
+	return map[string]interface{}{
+		"term_id":       termID,
+		"dry_run":       true,
+		"transactions":  transactions,
+		"within_budget": withinBudget(planned),
+	}
+}
+
+// feeEstimateResponse is the API form of a planned transaction; wei amounts are strings
+// since they do not fit in a JSON number
+func feeEstimateResponse(tx plannedTransaction) map[string]interface{} {
+	estimate := tx.Estimate
+	weiString := func(value *big.Int) string {
+		if value == nil {
+			return ""
+		}
+		return value.String()
+	}
+	return map[string]interface{}{
+		"term_id":                  tx.TermID,
+		"method":                   tx.Method,
+		"verkle_root":              tx.VerkleRoot,
+		"gas_estimate":             estimate.GasEstimate,
+		"gas_limit":                estimate.GasLimit,
+		"base_fee":                 weiString(estimate.BaseFee),
+		"max_priority_fee_per_gas": weiString(estimate.MaxPriorityFeePerGas),
+		"max_fee_per_gas":          weiString(estimate.MaxFeePerGas),
+		"expected_cost_wei":        weiString(estimate.ExpectedCost),
+		"max_cost_wei":             weiString(estimate.MaxCost),
+		"expected_cost_eth":        formatEther(estimate.ExpectedCost),
+		"max_cost_eth":             formatEther(estimate.MaxCost),
+		"budget_wei":               weiString(estimate.Budget),
+		"within_budget":            estimate.WithinBudget(),
+	}
+}
+
+func formatEther(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Text('f', 9)
+}
+
+func formatGwei(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Text('f', 3)
+}
@@ -1,12 +1,12 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"os"
-	"sort"
 	"strings"
+	"time"
+
+	"iumicert/crypto/receipt"
 
 	"github.com/spf13/cobra"
 )
@@ -30,14 +30,9 @@ var displayReceiptCmd = &cobra.Command{
 
 func displayReceipt(receiptFile string, verbose, showBlockchain bool) error {
 	// Read receipt file
-	data, err := os.ReadFile(receiptFile)
+	journeyReceipt, err := receipt.Load(receiptFile)
 	if err != nil {
-		return fmt.Errorf("failed to read receipt file: %w", err)
-	}
-	
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(data, &receipt); err != nil {
-		return fmt.Errorf("failed to parse receipt: %w", err)
+		return err
 	}
 	
 	// Display header
@@ -45,32 +40,20 @@ func displayReceipt(receiptFile string, verbose, showBlockchain bool) error {
 	fmt.Println("=" + strings.Repeat("=", 60))
 	
 	// Student info
-	studentID := receipt["student_id"].(string)
-	generatedAt := receipt["generation_timestamp"].(string)
-	fmt.Printf("👤 Student: %s\n", studentID)
-	fmt.Printf("📅 Generated: %s\n", generatedAt)
+	fmt.Printf("👤 Student: %s\n", journeyReceipt.StudentID)
+	fmt.Printf("📅 Generated: %s\n", journeyReceipt.GenerationTimestamp.Format(time.RFC3339))
 	
 	// Receipt type
-	if receiptType, ok := receipt["receipt_type"].(map[string]interface{}); ok {
-		selective := receiptType["selective_disclosure"].(bool)
-		if selective {
-			fmt.Println("🔒 Type: Selective Disclosure (Privacy-Preserving)")
-		} else {
-			fmt.Println("📖 Type: Complete Academic Journey")
-		}
+	if journeyReceipt.ReceiptType.SelectiveDisclosure {
+		fmt.Println("🔒 Type: Selective Disclosure (Privacy-Preserving)")
+	} else {
+		fmt.Println("📖 Type: Complete Academic Journey")
 	}
 	
 	fmt.Println()
 	
-	// Terms and courses
-	termReceipts := receipt["term_receipts"].(map[string]interface{})
-	
-	// Sort terms chronologically
-	var sortedTerms []string
-	for termID := range termReceipts {
-		sortedTerms = append(sortedTerms, termID)
-	}
-	sort.Strings(sortedTerms)
+	// Terms and courses, sorted chronologically
+	sortedTerms := journeyReceipt.SortedTermIDs()
 	
 	totalCourses := 0
 	totalCredits := 0
@@ -80,32 +63,28 @@ func displayReceipt(receiptFile string, verbose, showBlockchain bool) error {
 	fmt.Println("-" + strings.Repeat("-", 60))
 	
 	for i, termID := range sortedTerms {
-		termData := termReceipts[termID].(map[string]interface{})
-		termReceipt := termData["receipt"].(map[string]interface{})
+		termData := journeyReceipt.TermReceipts[termID]
 		
 		fmt.Printf("\n[%d] 📖 %s\n", i+1, termID)
 		
 		// Blockchain verification info
 		if showBlockchain || verbose {
-			blockchainAnchor := termReceipt["blockchain_anchor"].(string)
-			timestamp := termReceipt["timestamp"].(string)
-			fmt.Printf("    ⛓️  Blockchain Root: %s...\n", blockchainAnchor[:16])
-			fmt.Printf("    🕐 Published: %s\n", timestamp)
+			fmt.Printf("    ⛓️  Blockchain Root: %s...\n", termData.VerkleRoot.String()[:16])
+			fmt.Printf("    🕐 Published: %s\n", termData.Receipt.Timestamp.Format(time.RFC3339))
 		}
 		
 		// Courses
-		revealedCourses := termReceipt["revealed_courses"].([]interface{})
+		revealedCourses := termData.Receipt.RevealedCourses
 		termCredits := 0
 		termGrades := []float64{}
 		
 		fmt.Printf("    📋 Courses (%d completed):\n", len(revealedCourses))
 		
-		for j, courseInterface := range revealedCourses {
-			course := courseInterface.(map[string]interface{})
-			courseID := course["course_id"].(string)
-			courseName := course["course_name"].(string)
-			grade := course["grade"].(string)
-			credits := int(course["credits"].(float64))
+		for j, course := range revealedCourses {
+			courseID := course.CourseID
+			courseName := course.CourseName
+			grade := course.Grade
+			credits := int(course.Credits)
 			
 			gradePoints := getGradePoints(grade)
 			termGrades = append(termGrades, gradePoints)
@@ -115,11 +94,8 @@ func displayReceipt(receiptFile string, verbose, showBlockchain bool) error {
 			fmt.Printf("      %d. %s - %s [%s] (%d credits)\n", j+1, courseID, courseName, grade, credits)
 			
 			if verbose {
-				startedAt := course["started_at"].(string)
-				completedAt := course["completed_at"].(string)
-				instructor := course["instructor"].(string)
 				fmt.Printf("         📅 %s → %s | 👨‍🏫 %s\n", 
-					startedAt[:10], completedAt[:10], instructor)
+					course.StartedAt.Format("2006-01-02"), course.CompletedAt.Format("2006-01-02"), course.Instructor)
 			}
 		}
 		
@@ -161,15 +137,9 @@ func displayReceipt(receiptFile string, verbose, showBlockchain bool) error {
 		
 		prevGPA := 0.0
 		for i, termID := range sortedTerms {
-			termData := termReceipts[termID].(map[string]interface{})
-			termReceipt := termData["receipt"].(map[string]interface{})
-			revealedCourses := termReceipt["revealed_courses"].([]interface{})
-			
 			termGrades := []float64{}
-			for _, courseInterface := range revealedCourses {
-				course := courseInterface.(map[string]interface{})
-				grade := course["grade"].(string)
-				termGrades = append(termGrades, getGradePoints(grade))
+			for _, course := range journeyReceipt.TermReceipts[termID].Receipt.RevealedCourses {
+				termGrades = append(termGrades, getGradePoints(course.Grade))
 			}
 			
 			termGPA := calculateReceiptGPA(termGrades)
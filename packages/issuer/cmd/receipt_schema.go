@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"iumicert/crypto/receipt"
+
+	"github.com/spf13/cobra"
+)
+
+var receiptSchemaCmd = &cobra.Command{
+	Use:   "receipt-schema",
+	Short: "Export the JSON Schema of journey receipts",
+	Long:  `Print the JSON Schema (draft 2020-12) that journey receipts of the current schema version conform to`,
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFile, _ := cmd.Flags().GetString("output")
+
+		if err := exportReceiptSchema(outputFile); err != nil {
+			log.Fatalf("❌ Failed to export receipt schema: %v", err)
+		}
+	},
+}
+
+func exportReceiptSchema(outputFile string) error {
+	schema, err := receipt.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate schema: %w", err)
+	}
+	schema = append(schema, '\n')
+
+	if outputFile == "" {
+		_, err := os.Stdout.Write(schema)
+		return err
+	}
+	if err := os.WriteFile(outputFile, schema, 0644); err != nil {
+		return fmt.Errorf("failed to write schema file: %w", err)
+	}
+
+	fmt.Printf("💾 Journey receipt schema v%d saved to: %s\n", receipt.SchemaVersion, outputFile)
+	return nil
+}
+
+// handleGetReceiptSchema serves the journey receipt JSON Schema
+func handleGetReceiptSchema(w http.ResponseWriter, r *http.Request) {
+	schema, err := receipt.JSONSchema()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(schema)
+}
+
+func init() {
+	receiptSchemaCmd.Flags().StringP("output", "o", "", "Write the schema to a file instead of stdout")
+
+	rootCmd.AddCommand(receiptSchemaCmd)
+}
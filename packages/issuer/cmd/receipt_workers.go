@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"iumicert/crypto/verkle"
+)
+
+// termTreeSet loads each term tree once and shares it between receipt workers.
+// Trees are prepared for concurrent proof generation before they are handed out.
+type termTreeSet struct {
+	mu    sync.Mutex
+	terms map[string]*termTreeEntry
+}
+
+type termTreeEntry struct {
+	once sync.Once
+	tree *verkle.TermVerkleTree
+	err  error
+}
+
+func newTermTreeSet() *termTreeSet {
+	return &termTreeSet{terms: make(map[string]*termTreeEntry)}
+}
+
+// get returns the shared tree for a term, loading it on first use
+func (s *termTreeSet) get(termID string) (*verkle.TermVerkleTree, error) {
+	s.mu.Lock()
+	entry, ok := s.terms[termID]
+	if !ok {
+		entry = &termTreeEntry{}
+		s.terms[termID] = entry
+	}
+	s.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.tree, entry.err = loadTermTree(termID)
+		if entry.err != nil {
+			return
+		}
+		if entry.err = entry.tree.PrepareConcurrentProofs(); entry.err != nil {
+			entry.tree.Close()
+			entry.tree = nil
+		}
+	})
+	return entry.tree, entry.err
+}
+
+// Close closes every loaded term tree
+func (s *termTreeSet) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.terms {
+		if entry.tree != nil {
+			entry.tree.Close()
+		}
+	}
+	s.terms = make(map[string]*termTreeEntry)
+}
+
+// runOrdered calls work for indexes 0..n-1 on up to workers goroutines (0 means one per CPU).
+// Each call writes its progress to its own buffer, and report receives the buffers and
+// errors in index order, so the console output is the same as a sequential run.
+func runOrdered(n, workers int, work func(i int, out io.Writer) error, report func(i int, output []byte, err error)) {
+	type result struct {
+		output []byte
+		err    error
+	}
+	results := make([]chan result, n)
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+
+	go verkle.RunWorkers(n, workers, func(i int) {
+		var out bytes.Buffer
+		err := work(i, &out)
+		results[i] <- result{output: out.Bytes(), err: err}
+	})
+
+	for i := 0; i < n; i++ {
+		r := <-results[i]
+		report(i, r.output, r.err)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// defaultReorgWatchInterval is how often a running reorg watcher re-checks transactions
+const defaultReorgWatchInterval = 30 * time.Second
+
+var watchReorgsCmd = &cobra.Command{
+	Use:   "watch-reorgs",
+	Short: "Re-check recent publications and roll back database state on reorgs",
+	Long: `Re-check the confirmed transactions of the last REORG_WATCH_DEPTH blocks against the
+canonical chain. When the block of a transaction was reorged out, the transaction is marked
+reorged, the term root versions it published are flagged and term receipts anchored to it
+are no longer blockchain verified. Once the transaction is mined and confirmed again, the
+database is restored with the new block number.
+
+Without --follow it checks once and exits.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		network := networkFlag(cmd)
+		follow, _ := cmd.Flags().GetBool("follow")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		watcher, err := newReorgWatcherForNetwork(network)
+		if err != nil {
+			log.Fatalf("❌ Failed to start reorg watcher: %v", err)
+		}
+		defer watcher.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if follow {
+			watcher.Run(ctx, interval)
+			return
+		}
+		reorged, restored, err := watcher.CheckOnce(ctx)
+		if err != nil {
+			log.Fatalf("❌ Reorg check failed after %d reorged, %d restored: %v", reorged, restored, err)
+		}
+		fmt.Printf("✅ Reorg check complete: %d reorged, %d restored\n", reorged, restored)
+	},
+}
+
+// reorgWatcher compares the transactions recorded in blockchain_transactions with the
+// canonical chain
+type reorgWatcher struct {
+	db          *gorm.DB
+	integration *blockchain.BlockchainIntegration
+	depth       uint64
+}
+
+// newReorgWatcherForNetwork connects to the database and the registry of a network
+func newReorgWatcherForNetwork(network string) (*reorgWatcher, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.Network = network
+
+	db, err := database.Connect()
+	if err != nil {
+		return nil, fmt.Errorf("database connection failed: %w", err)
+	}
+
+	// The reorged flag is newer than most deployments' schema
+	if err := db.AutoMigrate(&database.TermRootVersion{}); err != nil {
+		database.Close(db)
+		return nil, fmt.Errorf("failed to migrate term root versions: %w", err)
+	}
+
+	// The watcher only reads receipts and blocks, so it needs no signer
+	integration, err := blockchain.NewReadOnlyBlockchainIntegration(network, cfg.GetContractAddress())
+	if err != nil {
+		database.Close(db)
+		return nil, fmt.Errorf("failed to create blockchain integration: %w", err)
+	}
+
+	return &reorgWatcher{db: db, integration: integration, depth: cfg.ReorgWatchDepth}, nil
+}
+
+// Close releases the database and chain connections
+func (rw *reorgWatcher) Close() {
+	rw.integration.Close()
+	database.Close(rw.db)
+}
+
+// Run checks until ctx is cancelled, every interval. Errors are logged and retried on the
+// next check.
+func (rw *reorgWatcher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultReorgWatchInterval
+	}
+	log.Printf("🔭 Reorg watcher checking the last %d blocks every %s", rw.depth, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reorged, restored, err := rw.CheckOnce(ctx)
+		if err != nil {
+			log.Printf("⚠️  Reorg check failed: %v", err)
+		}
+		if reorged+restored > 0 {
+			log.Printf("🔀 Reorg watcher flagged %d and restored %d transactions", reorged, restored)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("🛑 Reorg watcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckOnce re-checks every watched transaction and returns how many were newly reorged
+// out and how many are back on chain. A transaction that cannot be checked is logged and
+// skipped, so it does not hide reorgs of the others; the error then counts the skipped ones.
+func (rw *reorgWatcher) CheckOnce(ctx context.Context) (int, int, error) {
+	head, err := rw.integration.LatestBlockNumber(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	fromBlock := uint64(0)
+	if head > rw.depth {
+		fromBlock = head - rw.depth
+	}
+
+	transactions, err := database.GetWatchedTransactions(rw.db, fromBlock)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load recent transactions: %w", err)
+	}
+
+	reorged, restored, failed := 0, 0, 0
+	for _, transaction := range transactions {
+		if ctx.Err() != nil {
+			return reorged, restored, ctx.Err()
+		}
+		inclusion, err := rw.integration.CheckInclusion(ctx, common.HexToHash(transaction.TxHash))
+		if err != nil {
+			log.Printf("⚠️  Failed to check transaction %s of term %s: %v", transaction.TxHash, transaction.TermID, err)
+			failed++
+			continue
+		}
+
+		switch {
+		case !inclusion.Included:
+			if transaction.Status == blockchain.TxStatusReorged {
+				continue
+			}
+			log.Printf("⚠️  Transaction %s of term %s left the chain (was in block %d)", transaction.TxHash, transaction.TermID, transaction.BlockNumber)
+			if err := database.MarkTransactionReorged(rw.db, transaction.TxHash); err != nil {
+				log.Printf("⚠️  Failed to mark %s reorged: %v", transaction.TxHash, err)
+				failed++
+				continue
+			}
+			reorged++
+
+		case transaction.Status == blockchain.TxStatusReorged || inclusion.BlockNumber != transaction.BlockNumber:
+			// Restored once the new block is as deep as publishing requires
+			if inclusion.Confirmations < rw.integration.Confirmations() {
+				continue
+			}
+			log.Printf("🔀 Transaction %s of term %s is confirmed in block %d (was in block %d)", transaction.TxHash, transaction.TermID, inclusion.BlockNumber, transaction.BlockNumber)
+			if err := database.RestoreReorgedTransaction(rw.db, transaction.TxHash, inclusion.BlockNumber); err != nil {
+				log.Printf("⚠️  Failed to restore %s: %v", transaction.TxHash, err)
+				failed++
+				continue
+			}
+			restored++
+		}
+	}
+	if failed > 0 {
+		return reorged, restored, fmt.Errorf("%d of %d transactions could not be checked", failed, len(transactions))
+	}
+	return reorged, restored, nil
+}
+
+// startBackgroundReorgWatcher runs the reorg watcher for the configured network alongside
+// the API server until ctx ends. The server still starts if the database or chain is unavailable.
+func startBackgroundReorgWatcher(ctx context.Context) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("⚠️  Reorg watcher disabled: failed to load config: %v", err)
+		return
+	}
+
+	watcher, err := newReorgWatcherForNetwork(cfg.Network)
+	if err != nil {
+		log.Printf("⚠️  Reorg watcher disabled: %v", err)
+		return
+	}
+
+	go func() {
+		defer watcher.Close()
+		watcher.Run(ctx, defaultReorgWatchInterval)
+	}()
+}
+
+func init() {
+	watchReorgsCmd.Flags().String("network", "sepolia", "blockchain network from config/networks.json, or simulated; NETWORK overrides the default")
+	watchReorgsCmd.Flags().BoolP("follow", "f", false, "keep re-checking instead of exiting after one check")
+	watchReorgsCmd.Flags().Duration("interval", defaultReorgWatchInterval, "check interval with --follow")
+
+	rootCmd.AddCommand(watchReorgsCmd)
+}
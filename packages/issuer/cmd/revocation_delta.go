@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"iumicert/crypto/verkle"
+)
+
+var verifyRevocationDeltaCmd = &cobra.Command{
+	Use:   "verify-revocation-delta [proof-file]",
+	Short: "Verify that a superseding term root only revokes the listed credentials",
+	Long: `Verify a revocation delta proof written by supersede-term or publish-roots
+(publish_ready/revocation_proofs/delta_<term>_v<N>.json) or exported from a
+revocation batch record. The check runs fully offline. Pass --old-root and
+--new-root with the roots read from the registry contract to bind the proof
+to the published versions; otherwise the roots embedded in the proof are used.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		oldRootHex, _ := cmd.Flags().GetString("old-root")
+		newRootHex, _ := cmd.Flags().GetString("new-root")
+
+		if err := verifyRevocationDeltaFile(args[0], oldRootHex, newRootHex); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Revocation delta verification failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func verifyRevocationDeltaFile(proofFile, oldRootHex, newRootHex string) error {
+	fmt.Printf("🔍 Verifying revocation delta proof: %s\n", proofFile)
+
+	data, err := os.ReadFile(proofFile)
+	if err != nil {
+		return fmt.Errorf("failed to read proof file: %w", err)
+	}
+
+	var delta verkle.RevocationDeltaProof
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return fmt.Errorf("failed to parse proof file: %w", err)
+	}
+
+	oldRoot, newRoot := delta.OldRoot, delta.NewRoot
+	if oldRootHex != "" {
+		if oldRoot, err = parseVerkleRoot(oldRootHex); err != nil {
+			return fmt.Errorf("invalid old root: %w", err)
+		}
+	}
+	if newRootHex != "" {
+		if newRoot, err = parseVerkleRoot(newRootHex); err != nil {
+			return fmt.Errorf("invalid new root: %w", err)
+		}
+	}
+	if oldRootHex == "" || newRootHex == "" {
+		fmt.Println("⚠️  Using roots embedded in the proof - compare them with the on-chain term history")
+	}
+
+	if err := verkle.VerifyRevocationDeltaProof(&delta, oldRoot, newRoot); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Term %s v%d -> v%d: only the following credentials were revoked\n",
+		delta.TermID, delta.OldVersion, delta.NewVersion)
+	fmt.Printf("  - Old root: 0x%x\n", oldRoot)
+	fmt.Printf("  - New root: 0x%x\n", newRoot)
+	for _, courseKey := range delta.RevokedKeys {
+		fmt.Printf("  • %s\n", courseKey)
+	}
+	return nil
+}
+
+func init() {
+	verifyRevocationDeltaCmd.Flags().String("old-root", "", "expected superseded root (hex)")
+	verifyRevocationDeltaCmd.Flags().String("new-root", "", "expected new root (hex)")
+	rootCmd.AddCommand(verifyRevocationDeltaCmd)
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	blockchain_integration "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+// registryCheckTimeout bounds a registry call; past it the indexed roots answer instead
+const registryCheckTimeout = 5 * time.Second
+
+// Root states reported by the verifier endpoints
+const (
+	RootStateLatest     = "latest"     // Current version of the term on chain
+	RootStateSuperseded = "superseded" // Published, but a newer version of the term exists
+	RootStateUnknown    = "unknown"    // Not published for the term, e.g. a forged receipt
+)
+
+// RootCheck is the status of the root a receipt claims for a term
+type RootCheck struct {
+	TermID     string    `json:"term_id"`
+	VerkleRoot string    `json:"verkle_root"`
+	State      string    `json:"state"`
+	Version    uint64    `json:"version,omitempty"`
+	LatestRoot string    `json:"latest_root,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Source     string    `json:"source"` // "blockchain" or "database" when the chain is unreachable
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Err returns why a proof against this root must be rejected, or nil for the latest root
+func (c *RootCheck) Err() error {
+	switch c.State {
+	case RootStateLatest:
+		return nil
+	case RootStateSuperseded:
+		return fmt.Errorf("receipt uses superseded root of term %s (latest is %s). %s. Please download updated receipt", c.TermID, c.LatestRoot, c.Message)
+	default:
+		return fmt.Errorf("verkle root %s is not published for term %s - invalid receipt. %s", c.VerkleRoot, c.TermID, c.Message)
+	}
+}
+
+// registryRoot is what the registry reports for a root, independent of the term a
+// receipt claims
+type registryRoot struct {
+	termID     string
+	state      string
+	version    uint64
+	latestRoot string
+	message    string
+	checkedAt  time.Time
+}
+
+// RootOracle resolves receipt roots against IUMiCertRegistry. Registry answers are cached
+// for a TTL; unknown roots are not cached so a root is recognised as soon as it is
+// published. When the chain cannot be reached, the roots stored by the chain indexer are
+// used instead.
+type RootOracle struct {
+	ttl     time.Duration
+	connect func() (*blockchain_integration.BlockchainIntegration, error)
+	openDB  func() (*gorm.DB, error)
+	flights singleflight.Group // One registry call per root at a time
+
+	mu      sync.Mutex // Guards the registry client and the cache, never held across a call
+	chain   *blockchain_integration.BlockchainIntegration
+	cache   map[string]registryRoot
+	expires map[string]time.Time
+
+	dbMu sync.Mutex
+	db   *gorm.DB
+}
+
+// NewRootOracle creates an oracle that reaches the registry through connect and the
+// indexed roots through openDB
+func NewRootOracle(ttl time.Duration, connect func() (*blockchain_integration.BlockchainIntegration, error), openDB func() (*gorm.DB, error)) *RootOracle {
+	return &RootOracle{
+		ttl:     ttl,
+		connect: connect,
+		openDB:  openDB,
+		cache:   make(map[string]registryRoot),
+		expires: make(map[string]time.Time),
+	}
+}
+
+var (
+	verifierRootOracleOnce sync.Once
+	verifierRootOracle     *RootOracle
+)
+
+// getRootOracle returns the oracle shared by the verifier endpoints, configured from the
+// environment on first use
+func getRootOracle() *RootOracle {
+	verifierRootOracleOnce.Do(func() {
+		ttl := 60 * time.Second
+		if cfg, err := config.LoadConfig(); err == nil {
+			ttl = time.Duration(cfg.RootCacheTTL) * time.Second
+		}
+
+		connect := func() (*blockchain_integration.BlockchainIntegration, error) {
+			cfg, err := config.LoadConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load config: %w", err)
+			}
+			// Only reads the registry, so it needs no signer and never prompts for one
+			return blockchain_integration.NewReadOnlyBlockchainIntegration(cfg.Network, cfg.GetContractAddress())
+		}
+		verifierRootOracle = NewRootOracle(ttl, connect, database.Connect)
+	})
+	return verifierRootOracle
+}
+
+// Check resolves the status of verkleRootHex as a root of termID
+func (o *RootOracle) Check(ctx context.Context, termID, verkleRootHex string) (*RootCheck, error) {
+	root, err := parseVerkleRoot(verkleRootHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid verkle root: %w", err)
+	}
+	rootHex := fmt.Sprintf("0x%x", root)
+
+	source := "blockchain"
+	onChain, chainErr := o.registryStatus(ctx, rootHex)
+	if chainErr != nil {
+		log.Printf("⚠️  Registry unreachable, checking root %s against indexed roots: %v", rootHex, chainErr)
+		source = "database"
+		var dbErr error
+		onChain, dbErr = o.indexedStatus(rootHex)
+		if dbErr != nil {
+			return nil, fmt.Errorf("failed to check root on chain (%v) and in database: %w", chainErr, dbErr)
+		}
+	}
+
+	check := &RootCheck{
+		TermID:     termID,
+		VerkleRoot: rootHex,
+		State:      onChain.state,
+		Version:    onChain.version,
+		LatestRoot: onChain.latestRoot,
+		Message:    onChain.message,
+		Source:     source,
+		CheckedAt:  onChain.checkedAt,
+	}
+
+	// A root published for another term proves nothing about this one
+	if onChain.state != RootStateUnknown && onChain.termID != termID {
+		check.State = RootStateUnknown
+		check.Version = 0
+		check.LatestRoot = ""
+		check.Message = fmt.Sprintf("Root is published for term %s", onChain.termID)
+	}
+	return check, nil
+}
+
+// registryStatus asks the registry about a root, answering from the cache while fresh.
+// Concurrent checks of a root share one registry call.
+func (o *RootOracle) registryStatus(ctx context.Context, rootHex string) (registryRoot, error) {
+	o.mu.Lock()
+	cached, ok := o.cache[rootHex]
+	fresh := ok && time.Now().Before(o.expires[rootHex])
+	o.mu.Unlock()
+	if fresh {
+		return cached, nil
+	}
+
+	flight := o.flights.DoChan(rootHex, func() (interface{}, error) {
+		// The call is shared, so it outlives the caller that started it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), registryCheckTimeout)
+		defer cancel()
+		return o.fetchRegistryStatus(ctx, rootHex)
+	})
+	select {
+	case <-ctx.Done():
+		return registryRoot{}, ctx.Err()
+	case res := <-flight:
+		if res.Err != nil {
+			return registryRoot{}, res.Err
+		}
+		return res.Val.(registryRoot), nil
+	}
+}
+
+// fetchRegistryStatus calls the registry about a root and caches its answer
+func (o *RootOracle) fetchRegistryStatus(ctx context.Context, rootHex string) (registryRoot, error) {
+	chain, err := o.chainClient()
+	if err != nil {
+		return registryRoot{}, fmt.Errorf("blockchain connection failed: %w", err)
+	}
+
+	status, err := chain.CheckRootStatus(ctx, rootHex)
+	if err != nil {
+		// Reconnect on the next check
+		o.mu.Lock()
+		if o.chain == chain {
+			o.chain = nil
+			chain.Close()
+		}
+		o.mu.Unlock()
+		return registryRoot{}, err
+	}
+
+	result := registryRoot{
+		termID:    status.TermID,
+		message:   status.Message,
+		checkedAt: time.Now(),
+	}
+	// 0=Invalid, 1=Current, 2=Outdated, 3=Superseded
+	switch status.Status {
+	case 0:
+		result.state = RootStateUnknown
+		return result, nil
+	case 1:
+		result.state = RootStateLatest
+	default:
+		result.state = RootStateSuperseded
+	}
+	result.version = status.Version.Uint64()
+	result.latestRoot = fmt.Sprintf("0x%x", status.LatestRoot)
+
+	o.mu.Lock()
+	o.cache[rootHex] = result
+	o.expires[rootHex] = result.checkedAt.Add(o.ttl)
+	o.mu.Unlock()
+	return result, nil
+}
+
+// chainClient returns the registry client, connecting on first use
+func (o *RootOracle) chainClient() (*blockchain_integration.BlockchainIntegration, error) {
+	o.mu.Lock()
+	chain := o.chain
+	o.mu.Unlock()
+	if chain != nil {
+		return chain, nil
+	}
+
+	chain, err := o.connect()
+	if err != nil {
+		return nil, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.chain != nil {
+		// Another check connected first
+		chain.Close()
+		return o.chain, nil
+	}
+	o.chain = chain
+	return chain, nil
+}
+
+// indexedStatus derives a root's status from the term root versions in the database
+func (o *RootOracle) indexedStatus(rootHex string) (registryRoot, error) {
+	db, err := o.database()
+	if err != nil {
+		return registryRoot{}, err
+	}
+
+	result := registryRoot{checkedAt: time.Now()}
+	version, err := database.GetTermRootVersionByHash(db, strings.ToLower(rootHex))
+	if err != nil {
+		return registryRoot{}, fmt.Errorf("failed to look up root: %w", err)
+	}
+	if version == nil {
+		result.state = RootStateUnknown
+		result.message = "Root not found in indexed roots"
+		return result, nil
+	}
+	if version.Reorged {
+		result.state = RootStateUnknown
+		result.message = "Publishing transaction was reorged out"
+		return result, nil
+	}
+
+	latest, err := database.GetLatestTermVersion(db, version.TermID)
+	if err != nil {
+		return registryRoot{}, fmt.Errorf("failed to look up latest version of %s: %w", version.TermID, err)
+	}
+
+	result.termID = version.TermID
+	result.version = uint64(version.Version)
+	result.latestRoot = latest.RootHash
+	if version.IsSuperseded || latest.RootHash != version.RootHash {
+		result.state = RootStateSuperseded
+		result.message = "Superseded - " + version.SupersessionReason
+	} else {
+		result.state = RootStateLatest
+		result.message = "Valid - Current version"
+	}
+	return result, nil
+}
+
+// database returns the connection to the indexed roots, opening it on first use
+func (o *RootOracle) database() (*gorm.DB, error) {
+	o.dbMu.Lock()
+	defer o.dbMu.Unlock()
+	if o.db == nil {
+		db, err := o.openDB()
+		if err != nil {
+			return nil, err
+		}
+		o.db = db
+	}
+	return o.db, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	blockchain_integration "iumicert/issuer/blockchain_integration"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"gorm.io/gorm"
+)
+
+func noDatabase() (*gorm.DB, error) {
+	return nil, errors.New("database not available")
+}
+
+// TestRootOracle checks that receipt roots resolve against the registry: forged roots and
+// roots of other terms are unknown, and supersession shows once the cache entry expires
+func TestRootOracle(t *testing.T) {
+	chain, err := blockchain_integration.NewSimulatedChain("", 500000)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	defer chain.Close()
+	integration, err := chain.Integration()
+	if err != nil {
+		t.Fatalf("Failed to create integration: %v", err)
+	}
+	connect := func() (*blockchain_integration.BlockchainIntegration, error) { return integration, nil }
+
+	ctx := context.Background()
+	termID := "Semester_1_2024"
+	rootV1 := crypto.Keccak256Hash([]byte("root-v1")).Hex()
+	rootV2 := crypto.Keccak256Hash([]byte("root-v2")).Hex()
+	if _, err := integration.PublishTermRoot(ctx, rootV1, termID, big.NewInt(5)); err != nil {
+		t.Fatalf("Failed to publish term root: %v", err)
+	}
+
+	cached := NewRootOracle(time.Hour, connect, noDatabase)
+	check, err := cached.Check(ctx, termID, rootV1)
+	if err != nil {
+		t.Fatalf("Failed to check root: %v", err)
+	}
+	if check.State != RootStateLatest || check.Version != 1 || check.Source != "blockchain" || check.Err() != nil {
+		t.Fatalf("Published root not reported latest: %+v", check)
+	}
+
+	forged := crypto.Keccak256Hash([]byte("self-made root")).Hex()
+	if check, err := cached.Check(ctx, termID, forged); err != nil || check.State != RootStateUnknown || check.Err() == nil {
+		t.Fatalf("Forged root not reported unknown: %+v %v", check, err)
+	}
+	if check, err := cached.Check(ctx, "Semester_2_2024", rootV1); err != nil || check.State != RootStateUnknown {
+		t.Fatalf("Root of another term not reported unknown: %+v %v", check, err)
+	}
+
+	if _, err := integration.SupersedeTerm(ctx, termID, rootV2, big.NewInt(5), "Revoked 1 credential"); err != nil {
+		t.Fatalf("Failed to supersede term: %v", err)
+	}
+
+	// The cached answer stands until the TTL expires
+	if check, _ := cached.Check(ctx, termID, rootV1); check.State != RootStateLatest {
+		t.Fatalf("Cached root status not used: %+v", check)
+	}
+
+	fresh := NewRootOracle(0, connect, noDatabase)
+	check, err = fresh.Check(ctx, termID, rootV1)
+	if err != nil {
+		t.Fatalf("Failed to check root: %v", err)
+	}
+	if check.State != RootStateSuperseded || check.LatestRoot != rootV2 || check.Err() == nil {
+		t.Fatalf("Superseded root not reported superseded: %+v", check)
+	}
+	if check, _ := fresh.Check(ctx, termID, rootV2); check.State != RootStateLatest || check.Version != 2 {
+		t.Fatalf("New root not reported latest: %+v", check)
+	}
+
+	t.Logf("✅ Root oracle resolved %s: %s", rootV1, check.Message)
+}
+
+// TestRootOracleUnreachable checks that a root is never accepted when neither the chain nor
+// the indexed roots can be reached
+func TestRootOracleUnreachable(t *testing.T) {
+	connect := func() (*blockchain_integration.BlockchainIntegration, error) {
+		return nil, fmt.Errorf("dial tcp: connection refused")
+	}
+	oracle := NewRootOracle(time.Minute, connect, noDatabase)
+
+	root := crypto.Keccak256Hash([]byte("root")).Hex()
+	if check, err := oracle.Check(context.Background(), "Semester_1_2024", root); err == nil {
+		t.Fatalf("Check succeeded without chain or database: %+v", check)
+	}
+	if _, err := oracle.Check(context.Background(), "Semester_1_2024", "0x1234"); err == nil {
+		t.Fatalf("Malformed root accepted")
+	}
+	t.Logf("✅ Unreachable registry reported as an error")
+}
+
+// TestRootOracleSlowRegistry checks that a stalled registry call holds up neither cached
+// checks nor callers past their deadline, and that concurrent checks of a root share it
+func TestRootOracleSlowRegistry(t *testing.T) {
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	connect := func() (*blockchain_integration.BlockchainIntegration, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return nil, fmt.Errorf("dial tcp: i/o timeout")
+	}
+	oracle := NewRootOracle(time.Hour, connect, noDatabase)
+
+	termID := "Semester_1_2024"
+	slowRoot := crypto.Keccak256Hash([]byte("slow root")).Hex()
+	cachedRoot := crypto.Keccak256Hash([]byte("cached root")).Hex()
+	oracle.cache[cachedRoot] = registryRoot{termID: termID, state: RootStateLatest, version: 1}
+	oracle.expires[cachedRoot] = time.Now().Add(time.Hour)
+
+	done := make(chan error)
+	go func() {
+		_, err := oracle.Check(context.Background(), termID, slowRoot)
+		done <- err
+	}()
+	<-started
+
+	check, err := oracle.Check(context.Background(), termID, cachedRoot)
+	if err != nil || check.State != RootStateLatest {
+		t.Fatalf("Cached root not answered during a registry call: %+v %v", check, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	if _, err := oracle.Check(ctx, termID, slowRoot); err == nil {
+		t.Fatal("Check succeeded while the registry stalled and without database")
+	}
+	if waited := time.Since(begin); waited > time.Second {
+		t.Fatalf("Check waited %s past its deadline", waited)
+	}
+
+	close(release)
+	if err := <-done; err == nil {
+		t.Fatal("Check succeeded without chain or database")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("Registry connected %d times for one root, expected 1", n)
+	}
+	t.Logf("✅ Stalled registry call shared and kept outside the lock")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"iumicert/crypto/verkle"
+)
+
+var migrateTreeStoreCmd = &cobra.Command{
+	Use:   "migrate-tree-store [term-id...]",
+	Short: "Convert saved JSON term trees into on-disk Verkle node stores",
+	Long: `Rebuild each data/verkle_trees/<term>_verkle_tree.json once and write its nodes
+to data/verkle_trees/<term>_verkle_tree.db. Commands that load a term tree use the
+node store when present and fall back to re-inserting the JSON entries otherwise.
+Without arguments every JSON term tree is migrated. The JSON files are kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		force, _ := cmd.Flags().GetBool("force")
+
+		if err := migrateTreeStores(args, force); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Tree store migration failed: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// termTreeStorePath returns the node store file for a term
+func termTreeStorePath(termID string) string {
+	return filepath.Join(resolveProjectPath("data/verkle_trees"), fmt.Sprintf("%s_verkle_tree.db", termID))
+}
+
+// saveTermTreeStore persists a term tree's nodes, creating the node store if the tree has none
+func saveTermTreeStore(termTree *verkle.TermVerkleTree) error {
+	if !termTree.HasNodeStore() {
+		if err := termTree.AttachNodeStore(termTreeStorePath(termTree.TermID)); err != nil {
+			return fmt.Errorf("failed to create node store for %s: %w", termTree.TermID, err)
+		}
+	}
+	return termTree.Flush()
+}
+
+func migrateTreeStores(termIDs []string, force bool) error {
+	verkleDir := resolveProjectPath("data/verkle_trees")
+
+	if len(termIDs) == 0 {
+		matches, err := filepath.Glob(filepath.Join(verkleDir, "*_verkle_tree.json"))
+		if err != nil {
+			return fmt.Errorf("failed to list term trees: %w", err)
+		}
+		for _, match := range matches {
+			termIDs = append(termIDs, strings.TrimSuffix(filepath.Base(match), "_verkle_tree.json"))
+		}
+	}
+	if len(termIDs) == 0 {
+		return fmt.Errorf("no term trees found in %s", verkleDir)
+	}
+
+	fmt.Printf("🗄️  Migrating %d term trees to node stores\n", len(termIDs))
+
+	migrated := 0
+	for _, termID := range termIDs {
+		storePath := termTreeStorePath(termID)
+		if _, err := os.Stat(storePath); err == nil && !force {
+			fmt.Printf("  ⏭️  %s: node store already exists (use --force to rebuild)\n", termID)
+			continue
+		}
+
+		jsonPath := filepath.Join(verkleDir, fmt.Sprintf("%s_verkle_tree.json", termID))
+		termTree, err := verkle.MigrateJSONTree(jsonPath, storePath)
+		if err != nil {
+			return fmt.Errorf("failed to migrate term %s: %w", termID, err)
+		}
+		if err := termTree.Close(); err != nil {
+			return fmt.Errorf("failed to close node store for %s: %w", termID, err)
+		}
+
+		fmt.Printf("  ✅ %s: %d courses, root %x\n", termID, len(termTree.CourseEntries), termTree.VerkleRoot[:8])
+		migrated++
+	}
+
+	fmt.Printf("✅ Migrated %d term trees\n", migrated)
+	return nil
+}
+
+func init() {
+	migrateTreeStoreCmd.Flags().Bool("force", false, "rebuild node stores that already exist")
+	rootCmd.AddCommand(migrateTreeStoreCmd)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"log"
+	"os/user"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/database"
+
+	"gorm.io/gorm"
+)
+
+// dbTxStore persists transaction attempts in blockchain_transactions, recording actor as
+// the principal that initiated them
+type dbTxStore struct {
+	db    *gorm.DB
+	actor string
+}
+
+func (s dbTxStore) SaveTxAttempt(attempt *blockchain.TxAttempt) error {
+	gasPrice, gasTipCap := "", ""
+	if attempt.GasPrice != nil {
+		gasPrice = attempt.GasPrice.String()
+	}
+	if attempt.GasTipCap != nil {
+		gasTipCap = attempt.GasTipCap.String()
+	}
+
+	return database.SaveTransactionAttempt(s.db, &database.BlockchainTransaction{
+		TxHash:      attempt.TxHash,
+		TermID:      attempt.TermID,
+		VerkleRoot:  attempt.VerkleRoot[:],
+		BlockNumber: attempt.BlockNumber,
+		GasUsed:     attempt.GasUsed,
+		Status:      attempt.Status,
+		SubmittedAt: attempt.SubmittedAt,
+		ConfirmedAt: attempt.MinedAt,
+		FromAddress: attempt.From,
+		Nonce:       attempt.Nonce,
+		Attempt:     attempt.Attempt,
+		GasPrice:    gasPrice,
+		GasTipCap:   gasTipCap,
+		ReplacedBy:  attempt.ReplacedBy,
+		InitiatedBy: s.actor,
+	})
+}
+
+// recordTransactions persists the transaction attempts of integration in the database.
+// Publishing still works without a database, the attempts are then only logged.
+// The returned function closes the connection.
+func recordTransactions(integration *blockchain.BlockchainIntegration, actor string) func() {
+	db, err := database.Connect()
+	if err != nil {
+		log.Printf("⚠️  Transaction attempts will not be recorded: %v", err)
+		return func() {}
+	}
+	integration.SetTxStore(dbTxStore{db: db, actor: actor})
+	return func() { database.Close(db) }
+}
+
+// cliActor names the operator running a CLI command, for the records of what it publishes
+func cliActor() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "cli:" + u.Username
+	}
+	return "cli"
+}
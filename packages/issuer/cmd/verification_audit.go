@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"iumicert/issuer/database"
+
+	"github.com/gorilla/mux"
+)
+
+// Modes of logged verifier calls. Calls of other verifier routes are logged as
+// database.VerificationModeLookup.
+const (
+	verifyModeLocal      = "local"      // A whole receipt or proof document checked by the server
+	verifyModeBlockchain = "blockchain" // One course checked against its registry root
+	verifyModeFullIPA    = "full_ipa"   // Every revealed course of every term checked
+)
+
+// verifierIDHeader optionally names the verifier, e.g. an employer's HR system. It is
+// declared by the caller and not authenticated.
+const verifierIDHeader = "X-Verifier-ID"
+
+const (
+	maxVerifierIDLength = 255
+	maxUserAgentLength  = 1024
+)
+
+// verifierModes maps the routes that verify to the mode their calls are logged with
+var verifierModes = map[string]string{
+	"/api/verifier/receipt":        verifyModeLocal,
+	"/api/verifier/course":         verifyModeBlockchain,
+	"/api/verifier/ipa-verify":     verifyModeFullIPA,
+	"/api/verifier/absence/verify": verifyModeLocal,
+	"/api/receipts/verify":         verifyModeLocal,
+	"/api/receipts/verify-course":  verifyModeBlockchain,
+}
+
+// verificationLogStore records verifier calls
+type verificationLogStore interface {
+	LogVerification(log *database.VerificationLog) error
+}
+
+var (
+	// verificationLogs is where the API server logs verifier calls, nil when disabled
+	verificationLogs      verificationLogStore
+	verificationLogsSetup sync.Once
+)
+
+func processVerificationLogs() verificationLogStore {
+	verificationLogsSetup.Do(func() {
+		if verificationLogs != nil {
+			return
+		}
+		db, err := database.Connect()
+		if err == nil {
+			// Older schemas lack the student, term and endpoint columns
+			err = db.AutoMigrate(&database.VerificationLog{})
+		}
+		if err != nil {
+			log.Printf("⚠️  Verification audit disabled: %v", err)
+			return
+		}
+		verificationLogs = database.NewReceiptRepository(db)
+	})
+	return verificationLogs
+}
+
+// verificationAudit is the log entry of a verifier call, completed by its handler
+type verificationAudit struct {
+	entry    database.VerificationLog
+	failure  string       // Set by the handler when the response does not say why
+	response *APIResponse // Set by respondJSON
+}
+
+type verificationAuditKey struct{}
+
+func auditOf(r *http.Request) *verificationAudit {
+	audit, _ := r.Context().Value(verificationAuditKey{}).(*verificationAudit)
+	return audit
+}
+
+// noteVerifiedReceipt records which receipt a verifier call is about. Handlers shared with
+// unaudited routes may call it, it does nothing there.
+func noteVerifiedReceipt(r *http.Request, receiptType, receiptID, studentID, termID string) {
+	if audit := auditOf(r); audit != nil {
+		audit.entry.ReceiptType = receiptType
+		audit.entry.ReceiptID = receiptID
+		audit.entry.StudentID = studentID
+		audit.entry.TermID = termID
+	}
+}
+
+// noteVerificationFailure records why a verification failed when its response has no error
+func noteVerificationFailure(r *http.Request, reason string) {
+	if audit := auditOf(r); audit != nil {
+		audit.failure = reason
+	}
+}
+
+// contentID identifies a receipt or proof document in the verification log by its content,
+// so a student can tell which copy was checked
+func contentID(prefix string, document interface{}) string {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return prefix + hex.EncodeToString(sum[:16])
+}
+
+// auditedResponseWriter keeps the status and response of an audited call
+type auditedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	audit  *verificationAudit
+}
+
+func (w *auditedResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// auditVerifications logs every call of the routes it wraps: what was verified, how, the
+// outcome and who asked. Failing to log never fails the call.
+func auditVerifications(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		endpoint := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if template, err := route.GetPathTemplate(); err == nil {
+				endpoint = template
+			}
+		}
+		mode, ok := verifierModes[endpoint]
+		if !ok {
+			mode = database.VerificationModeLookup
+		}
+
+		audit := &verificationAudit{entry: database.VerificationLog{
+			Endpoint:         endpoint,
+			VerificationMode: mode,
+			VerifierID:       truncate(strings.TrimSpace(r.Header.Get(verifierIDHeader)), maxVerifierIDLength),
+			IPAddress:        clientIP(r),
+			UserAgent:        truncate(r.UserAgent(), maxUserAgentLength),
+			VerifiedAt:       time.Now().UTC(),
+		}}
+		audited := &auditedResponseWriter{ResponseWriter: w, status: http.StatusOK, audit: audit}
+		next.ServeHTTP(audited, r.WithContext(context.WithValue(r.Context(), verificationAuditKey{}, audit)))
+		audit.finish(audited.status)
+
+		store := processVerificationLogs()
+		if store == nil {
+			return
+		}
+		if err := store.LogVerification(&audit.entry); err != nil {
+			log.Printf("⚠️  Failed to log verification call of %s: %v", endpoint, err)
+		}
+	})
+}
+
+// finish sets the outcome of the call from its status and response. A verification
+// answered with "verified": false failed even when the request succeeded.
+func (a *verificationAudit) finish(status int) {
+	a.entry.Success = status < http.StatusMultipleChoices
+	reason := a.failure
+	if response := a.response; response != nil {
+		a.entry.Success = a.entry.Success && response.Success
+		if response.Error != "" {
+			reason = response.Error
+		}
+		if data, ok := response.Data.(map[string]interface{}); ok {
+			if verified, ok := data["verified"].(bool); ok && !verified {
+				a.entry.Success = false
+			}
+			if message, ok := data["verification_error"].(string); ok && reason == "" {
+				reason = message
+			}
+		}
+	}
+	if a.entry.Success {
+		return
+	}
+	if reason == "" {
+		reason = http.StatusText(status)
+	}
+	a.entry.ErrorMessage = reason
+}
+
+// clientIP returns the address of the caller. Behind a reverse proxy on the same host or
+// a private network, it is the address the proxy saw, from X-Forwarded-For.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil && (ip.IsLoopback() || ip.IsPrivate()) {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			hops := strings.Split(forwarded, ",")
+			if hop := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(hop) != nil {
+				return hop
+			}
+		}
+	}
+	return host
+}
+
+// truncate cuts s to at most n bytes of valid UTF-8
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// verificationView is a logged verification as students see it, without the caller's
+// address
+func verificationView(entry database.VerificationLog) map[string]interface{} {
+	view := map[string]interface{}{
+		"id":           entry.ID,
+		"receipt_id":   entry.ReceiptID,
+		"receipt_type": entry.ReceiptType,
+		"student_id":   entry.StudentID,
+		"term_id":      entry.TermID,
+		"endpoint":     entry.Endpoint,
+		"mode":         entry.VerificationMode,
+		"verifier_id":  entry.VerifierID,
+		"success":      entry.Success,
+		"verified_at":  entry.VerifiedAt.Format(time.RFC3339),
+	}
+	if entry.ErrorMessage != "" {
+		view["error"] = entry.ErrorMessage
+	}
+	return view
+}
+
+// handleListVerifications lists a page of logged verifier calls for the issuer
+func handleListVerifications(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Database connection failed"})
+		return
+	}
+	defer database.Close(db)
+
+	entries, next, err := database.NewReceiptRepository(db).ListVerifications(opts)
+	if err != nil {
+		respondListError(w, "verifications", err)
+		return
+	}
+	verifications := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		view := verificationView(entry)
+		view["ip_address"] = entry.IPAddress
+		view["user_agent"] = entry.UserAgent
+		verifications = append(verifications, view)
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: verifications, Page: newPageInfo(opts, next)})
+}
+
+// handleVerificationAnalytics summarises the logged verifications matching the list filters
+func handleVerificationAnalytics(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Database connection failed"})
+		return
+	}
+	defer database.Close(db)
+
+	stats, err := database.NewReceiptRepository(db).VerificationStats(opts)
+	if err != nil {
+		respondListError(w, "verification analytics", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: stats})
+}
+
+// handleStudentVerifications lists a page of the verifications of the authenticated
+// student's receipts
+func handleStudentVerifications(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	opts.StudentID = studentFrom(r)
+
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Database connection failed"})
+		return
+	}
+	defer database.Close(db)
+
+	entries, next, err := database.NewReceiptRepository(db).ListVerifications(opts)
+	if err != nil {
+		respondListError(w, "verifications", err)
+		return
+	}
+	verifications := make([]map[string]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		verifications = append(verifications, verificationView(entry))
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: verifications, Page: newPageInfo(opts, next)})
+}
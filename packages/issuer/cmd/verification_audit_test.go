@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"github.com/gorilla/mux"
+)
+
+// memVerificationLogStore keeps logged verifier calls in memory
+type memVerificationLogStore struct {
+	mu      sync.Mutex
+	entries []database.VerificationLog
+}
+
+func (s *memVerificationLogStore) LogVerification(entry *database.VerificationLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, *entry)
+	return nil
+}
+
+// TestVerificationAudit checks that verifier calls are logged with their receipt, mode,
+// outcome and caller
+func TestVerificationAudit(t *testing.T) {
+	store := &memVerificationLogStore{}
+	previous := verificationLogs
+	verificationLogs = store
+	defer func() { verificationLogs = previous }()
+
+	r := mux.NewRouter()
+	verifier := r.PathPrefix("/api/verifier").Subrouter()
+	verifier.Use(auditVerifications)
+	verifier.HandleFunc("/course", func(w http.ResponseWriter, r *http.Request) {
+		noteVerifiedReceipt(r, "journey", "jr_1", "ITITIU00001", "Semester_1_2023")
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: map[string]interface{}{
+			"verified":           false,
+			"verification_error": "IPA verification failed",
+		}})
+	}).Methods("POST")
+	verifier.HandleFunc("/ipa-verify", func(w http.ResponseWriter, r *http.Request) {
+		noteVerifiedReceipt(r, "journey", "jr_1", "ITITIU00001", "")
+		noteVerificationFailure(r, "1 of 4 courses failed")
+		respondJSON(w, http.StatusOK, APIResponse{Success: false, Data: map[string]interface{}{"status": "partial_failure"}})
+	}).Methods("POST")
+	verifier.HandleFunc("/receipt", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: "Invalid receipt data"})
+	}).Methods("POST")
+	verifier.HandleFunc("/receipt/{receipt_id}", func(w http.ResponseWriter, r *http.Request) {
+		noteVerifiedReceipt(r, "journey", mux.Vars(r)["receipt_id"], mux.Vars(r)["receipt_id"], "")
+		respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: map[string]interface{}{"student_id": "ITITIU00001"}})
+	}).Methods("GET")
+	r.HandleFunc("/api/issuer/students/{student_id}/journey", func(w http.ResponseWriter, r *http.Request) {
+		noteVerifiedReceipt(r, "journey", "", mux.Vars(r)["student_id"], "") // Not audited
+		w.WriteHeader(http.StatusOK)
+	})
+
+	call := func(method, path, remoteAddr string, headers map[string]string) {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.RemoteAddr = remoteAddr
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	call("POST", "/api/verifier/course", "203.0.113.9:443", map[string]string{
+		verifierIDHeader:  "  hr@acme.example  ",
+		"User-Agent":      "acme-ats/2.1",
+		"X-Forwarded-For": "198.51.100.1", // Not from a proxy, ignored
+	})
+	call("POST", "/api/verifier/ipa-verify", "127.0.0.1:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 198.51.100.7"})
+	call("POST", "/api/verifier/receipt", "10.0.0.2:5000", nil)
+	call("GET", "/api/verifier/receipt/ITITIU00001", "10.0.0.2:5000", nil)
+	call("GET", "/api/issuer/students/ITITIU00001/journey", "10.0.0.2:5000", nil)
+
+	if len(store.entries) != 4 {
+		t.Fatalf("Logged %d calls, expected the 4 verifier calls", len(store.entries))
+	}
+	course, ipa, invalid, lookup := store.entries[0], store.entries[1], store.entries[2], store.entries[3]
+	if course.VerificationMode != verifyModeBlockchain || course.Success || course.ErrorMessage != "IPA verification failed" ||
+		course.ReceiptID != "jr_1" || course.TermID != "Semester_1_2023" || course.VerifierID != "hr@acme.example" ||
+		course.IPAddress != "203.0.113.9" || course.UserAgent != "acme-ats/2.1" || course.Endpoint != "/api/verifier/course" {
+		t.Fatalf("Course verification logged as %+v", course)
+	}
+	if ipa.VerificationMode != verifyModeFullIPA || ipa.Success || ipa.ErrorMessage != "1 of 4 courses failed" || ipa.IPAddress != "198.51.100.7" {
+		t.Fatalf("IPA verification logged as %+v", ipa)
+	}
+	if invalid.VerificationMode != verifyModeLocal || invalid.Success || invalid.ErrorMessage != "Invalid receipt data" || invalid.ReceiptID != "" {
+		t.Fatalf("Rejected receipt logged as %+v", invalid)
+	}
+	if lookup.VerificationMode != database.VerificationModeLookup || !lookup.Success || lookup.StudentID != "ITITIU00001" ||
+		lookup.Endpoint != "/api/verifier/receipt/{receipt_id}" || lookup.VerifiedAt.IsZero() {
+		t.Fatalf("Receipt lookup logged as %+v", lookup)
+	}
+	if contentID("jr_", map[string]string{"a": "b"}) != contentID("jr_", map[string]string{"a": "b"}) {
+		t.Fatal("Content IDs of the same receipt differ")
+	}
+	t.Logf("✅ %d verifier calls logged with their outcome and caller", len(store.entries))
+}
+
+// TestStudentTokens checks that student tokens open student routes only
+func TestStudentTokens(t *testing.T) {
+	auth, err := newAuthenticator(&config.Config{AuthTokenSecret: strings.Repeat("s", minAuthTokenSecret)})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	studentToken, err := auth.issueStudentToken("ITITIU00001", time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to issue student token: %v", err)
+	}
+	auditorToken, _ := auth.issueToken("auditor@iu.edu.vn", []string{roleAuditor}, time.Hour)
+
+	echo := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(studentFrom(r) + requestActor(r))) }
+	r := mux.NewRouter()
+	r.Handle("/api/student/verifications", auth.studentOnly(echo))
+	issuer := r.PathPrefix("/api/issuer").Subrouter()
+	issuer.Use(auth.middleware)
+	issuer.HandleFunc("/verifications", echo)
+	issuer.Handle("/students/{student_id}/access-token", requireRole(roleRegistrar, auth.handleIssueStudentToken)).Methods("POST")
+
+	call := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := call("/api/student/verifications", studentToken); rec.Code != http.StatusOK || rec.Body.String() != "ITITIU00001anonymous" {
+		t.Fatalf("Student route with student token: %d %s", rec.Code, rec.Body.String())
+	}
+	for name, token := range map[string]string{"no token": "", "auditor token": auditorToken, "garbage": "not-a-token"} {
+		if rec := call("/api/student/verifications", token); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Student route with %s returned %d", name, rec.Code)
+		}
+	}
+	if rec := call("/api/issuer/verifications", studentToken); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Issuer route with a student token returned %d", rec.Code)
+	}
+
+	registrarToken, _ := auth.issueToken("registrar@iu.edu.vn", []string{roleRegistrar}, time.Hour)
+	req := httptest.NewRequest("POST", "/api/issuer/students/ITITIU00002/access-token?ttl=24h", nil)
+	req.Header.Set("Authorization", "Bearer "+registrarToken)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"student_id":"ITITIU00002"`) {
+		t.Fatalf("Registrar got %d %s", rec.Code, rec.Body.String())
+	}
+	t.Logf("✅ Student tokens open student routes and nothing else")
+}
@@ -1,10 +1,11 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"os"
+	"time"
+
+	"iumicert/crypto/receipt"
 
 	"github.com/spf13/cobra"
 )
@@ -25,21 +26,16 @@ var verificationGuideCmd = &cobra.Command{
 
 func showVerificationGuide(receiptFile string) error {
 	// Read receipt file
-	data, err := os.ReadFile(receiptFile)
+	journeyReceipt, err := receipt.Load(receiptFile)
 	if err != nil {
-		return fmt.Errorf("failed to read receipt file: %w", err)
-	}
-	
-	var receipt map[string]interface{}
-	if err := json.Unmarshal(data, &receipt); err != nil {
-		return fmt.Errorf("failed to parse receipt: %w", err)
+		return err
 	}
 	
 	// Display header
 	fmt.Println("🔍 BLOCKCHAIN VERIFICATION GUIDE")
 	fmt.Println("===============================================")
 	
-	studentID := receipt["student_id"].(string)
+	studentID := journeyReceipt.StudentID
 	fmt.Printf("👤 Verifying: %s\n", studentID)
 	fmt.Printf("📄 Receipt File: %s\n", receiptFile)
 	fmt.Println()
@@ -60,14 +56,13 @@ func showVerificationGuide(receiptFile string) error {
 	fmt.Println("⛓️  STEP 2: VERIFY BLOCKCHAIN ANCHORS")
 	fmt.Println("-----------------------------------------------")
 	
-	termReceipts := receipt["term_receipts"].(map[string]interface{})
 	termCount := 0
 	
-	for termID, termData := range termReceipts {
+	for _, termID := range journeyReceipt.SortedTermIDs() {
 		termCount++
-		termDataMap := termData.(map[string]interface{})
-		verkleRoot := termDataMap["verkle_root"].(string)
-		generatedAt := termDataMap["generated_at"].(string)
+		termData := journeyReceipt.TermReceipts[termID]
+		verkleRoot := termData.VerkleRoot.String()
+		generatedAt := termData.GeneratedAt.Format(time.RFC3339)
 		
 		fmt.Printf("[%d] 📚 Term: %s\n", termCount, termID)
 		fmt.Printf("    🔗 Verkle Root: %s\n", verkleRoot)
@@ -100,9 +95,8 @@ func showVerificationGuide(receiptFile string) error {
 	fmt.Println("The receipt contains these cryptographic components:")
 	fmt.Println()
 	
-	for termID, termData := range termReceipts {
-		termReceipt := termData.(map[string]interface{})["receipt"].(map[string]interface{})
-		revealedCourses := termReceipt["revealed_courses"].([]interface{})
+	for _, termID := range journeyReceipt.SortedTermIDs() {
+		revealedCourses := journeyReceipt.TermReceipts[termID].Receipt.RevealedCourses
 		
 		fmt.Printf("📚 %s:\n", termID)
 		fmt.Printf("   🌳 Verkle Tree: Proves %d courses belong to student\n", len(revealedCourses))
@@ -113,31 +107,29 @@ func showVerificationGuide(receiptFile string) error {
 	}
 	
 	// Step 5: Privacy verification
-	if receiptType, ok := receipt["receipt_type"].(map[string]interface{}); ok {
-		selective := receiptType["selective_disclosure"].(bool)
-		
-		fmt.Println("🔒 STEP 5: PRIVACY VERIFICATION")
-		fmt.Println("-----------------------------------------------")
-		if selective {
-			fmt.Println("🔒 This is a SELECTIVE DISCLOSURE receipt")
-			fmt.Println("   • Student chose to reveal only specific terms/courses")
-			fmt.Println("   • Hidden data is cryptographically protected")
-			fmt.Println("   • Blockchain still proves complete academic integrity")
-			fmt.Println("   • Verifier sees only authorized information")
-		} else {
-			fmt.Println("📖 This is a COMPLETE JOURNEY receipt")
-			fmt.Println("   • Student chose to reveal full academic history")
-			fmt.Println("   • All terms and courses are visible")
-			fmt.Println("   • Complete timeline of academic progression")
-		}
-		fmt.Println()
+	selective := journeyReceipt.ReceiptType.SelectiveDisclosure
+	
+	fmt.Println("🔒 STEP 5: PRIVACY VERIFICATION")
+	fmt.Println("-----------------------------------------------")
+	if selective {
+		fmt.Println("🔒 This is a SELECTIVE DISCLOSURE receipt")
+		fmt.Println("   • Student chose to reveal only specific terms/courses")
+		fmt.Println("   • Hidden data is cryptographically protected")
+		fmt.Println("   • Blockchain still proves complete academic integrity")
+		fmt.Println("   • Verifier sees only authorized information")
+	} else {
+		fmt.Println("📖 This is a COMPLETE JOURNEY receipt")
+		fmt.Println("   • Student chose to reveal full academic history")
+		fmt.Println("   • All terms and courses are visible")
+		fmt.Println("   • Complete timeline of academic progression")
 	}
+	fmt.Println()
 	
 	// Summary
 	fmt.Println("📊 VERIFICATION SUMMARY")
 	fmt.Println("===============================================")
 	fmt.Printf("🎓 Student: %s\n", studentID)
-	fmt.Printf("📚 Terms to verify: %d\n", len(termReceipts))
+	fmt.Printf("📚 Terms to verify: %d\n", len(journeyReceipt.TermReceipts))
 	fmt.Println("⛓️  Blockchain: Sepolia Testnet")
 	fmt.Println("🔐 Cryptography: Single Verkle Trees")
 	fmt.Println("✅ Status: Ready for third-party verification")
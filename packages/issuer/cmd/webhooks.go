@@ -0,0 +1,918 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/config"
+	"iumicert/issuer/database"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/spf13/cobra"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Credential lifecycle events sent to webhook subscriptions
+const (
+	eventTermRootPublished        = "term_root.published"
+	eventRevocationBatchProcessed = "revocation_batch.processed"
+	eventReceiptsRegenerated      = "receipts.regenerated"
+	eventWebhookTest              = "webhook.test" // Only sent by webhook test
+)
+
+var webhookEventTypes = []string{eventTermRootPublished, eventRevocationBatchProcessed, eventReceiptsRegenerated}
+
+// Delivery statuses
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+// Headers of webhook requests. The signature header is t=<unix time>,v1=<hex HMAC-SHA256
+// of "<unix time>.<body>" keyed with the subscription secret>.
+const (
+	webhookSignatureHeader = "X-IUMiCert-Signature"
+	webhookEventHeader     = "X-IUMiCert-Event"
+	webhookDeliveryHeader  = "X-IUMiCert-Delivery"
+	webhookUserAgent       = "IUMiCert-Webhooks/1.0"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	// webhookTimeout bounds one delivery attempt
+	webhookTimeout = 10 * time.Second
+	// webhookClaimLease is how long a claimed delivery is hidden from other senders; it
+	// outlasts an attempt, which records the real next attempt when it ends
+	webhookClaimLease = time.Minute
+	// webhookResponseLimit is how much of a response body the delivery log keeps
+	webhookResponseLimit = 1024
+	// webhookSignatureTolerance is how old a signature a receiver accepts
+	webhookSignatureTolerance = 5 * time.Minute
+	// defaultWebhookInterval is how often a running dispatcher looks for due retries
+	defaultWebhookInterval = 15 * time.Second
+	webhookBatchSize       = 100
+)
+
+// webhookRetrySchedule is how long a delivery waits after each failed attempt. It is
+// marked failed once an attempt fails with no wait left, about ten hours after the event.
+var webhookRetrySchedule = []time.Duration{
+	30 * time.Second,
+	2 * time.Minute,
+	10 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+}
+
+var errWebhookSignature = errors.New("invalid webhook signature")
+
+// webhookEvent is the body of a webhook request
+type webhookEvent struct {
+	ID        string      `json:"id"` // Same for every subscription the event is sent to
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// termRootPublishedEvent is the data of term_root.published, sent for every root confirmed
+// on chain: first publications and supersessions
+type termRootPublishedEvent struct {
+	TermID          string    `json:"term_id"`
+	Method          string    `json:"method"` // publishTermRoot or supersedeTerm
+	VerkleRoot      string    `json:"verkle_root"`
+	TotalStudents   uint64    `json:"total_students"`
+	Reason          string    `json:"reason,omitempty"`
+	Network         string    `json:"network"`
+	Registry        string    `json:"registry"`
+	TransactionHash string    `json:"transaction_hash"`
+	BlockNumber     uint64    `json:"block_number"`
+	PublishedAt     time.Time `json:"published_at"`
+}
+
+// revocationBatchEvent is the data of revocation_batch.processed
+type revocationBatchEvent struct {
+	BatchID            string              `json:"batch_id"`
+	TermID             string              `json:"term_id"`
+	OldVersion         uint                `json:"old_version"`
+	NewVersion         uint                `json:"new_version"`
+	OldRoot            string              `json:"old_root"`
+	NewRoot            string              `json:"new_root"`
+	CredentialsRevoked int                 `json:"credentials_revoked"`
+	Revocations        []revokedCredential `json:"revocations"`
+	TransactionHash    string              `json:"transaction_hash"`
+	ProcessedBy        string              `json:"processed_by"`
+	ProcessedAt        time.Time           `json:"processed_at"`
+}
+
+type revokedCredential struct {
+	RequestID string `json:"request_id"`
+	StudentID string `json:"student_id"`
+	CourseID  string `json:"course_id"`
+}
+
+// receiptsRegeneratedEvent is the data of receipts.regenerated, sent once per regeneration
+// with the students whose journey receipt changed
+type receiptsRegeneratedEvent struct {
+	StudentIDs    []string  `json:"student_ids"`
+	Count         int       `json:"count"`
+	Failed        int       `json:"failed"`
+	RegeneratedAt time.Time `json:"regenerated_at"`
+}
+
+// webhookStore persists subscriptions and the delivery log
+type webhookStore interface {
+	ActiveSubscriptions() ([]database.WebhookSubscription, error)
+	GetSubscription(subscriptionID string) (*database.WebhookSubscription, error)
+	SaveDelivery(delivery *database.WebhookDelivery) error
+	DueDeliveries(now time.Time, limit int) ([]database.WebhookDelivery, error)
+	ClaimDelivery(deliveryID string, now, until time.Time) (bool, error)
+}
+
+// dbWebhookStore keeps subscriptions and deliveries in the webhook tables
+type dbWebhookStore struct {
+	db *gorm.DB
+}
+
+func (s dbWebhookStore) ActiveSubscriptions() ([]database.WebhookSubscription, error) {
+	return database.GetWebhookSubscriptions(s.db, true)
+}
+
+func (s dbWebhookStore) GetSubscription(subscriptionID string) (*database.WebhookSubscription, error) {
+	return database.GetWebhookSubscription(s.db, subscriptionID)
+}
+
+func (s dbWebhookStore) SaveDelivery(delivery *database.WebhookDelivery) error {
+	return database.SaveWebhookDelivery(s.db, delivery)
+}
+
+func (s dbWebhookStore) DueDeliveries(now time.Time, limit int) ([]database.WebhookDelivery, error) {
+	return database.GetDueWebhookDeliveries(s.db, now, limit)
+}
+
+func (s dbWebhookStore) ClaimDelivery(deliveryID string, now, until time.Time) (bool, error) {
+	return database.ClaimWebhookDelivery(s.db, deliveryID, now, until)
+}
+
+// webhookDispatcher records every event as one delivery per subscribed endpoint and sends
+// them. While Run is running, new deliveries are sent by it; otherwise Emit sends the
+// deliveries of its event before returning, once, and later attempts are left to a running
+// dispatcher. A delivery is claimed before each attempt, so dispatchers of several
+// processes never send it twice.
+type webhookDispatcher struct {
+	store  webhookStore
+	client *http.Client
+	now    func() time.Time
+
+	wake       chan struct{}
+	background atomic.Bool
+	sending    sync.Mutex // One delivery pass at a time
+}
+
+func newWebhookDispatcher(store webhookStore) *webhookDispatcher {
+	return &webhookDispatcher{
+		store:  store,
+		client: &http.Client{Timeout: webhookTimeout},
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// issuerWebhooks sends the webhook events of the process. It is set up on first use and
+// stays nil when there is no database to keep subscriptions in.
+var (
+	issuerWebhooks *webhookDispatcher
+	webhooksSetup  sync.Once
+)
+
+func processWebhooks() *webhookDispatcher {
+	webhooksSetup.Do(func() {
+		if issuerWebhooks != nil {
+			return
+		}
+		db, err := database.Connect()
+		if err == nil {
+			// The webhook tables are newer than most deployments' schema
+			err = db.AutoMigrate(&database.WebhookSubscription{}, &database.WebhookDelivery{})
+		}
+		if err != nil {
+			log.Printf("⚠️  Webhooks disabled: %v", err)
+			return
+		}
+		issuerWebhooks = newWebhookDispatcher(dbWebhookStore{db: db})
+	})
+	return issuerWebhooks
+}
+
+// emitWebhook sends an event to its subscriptions. Failing to record it is logged and
+// never fails the operation the event is about.
+func emitWebhook(eventType string, data interface{}) {
+	dispatcher := processWebhooks()
+	if dispatcher == nil {
+		return
+	}
+	if err := dispatcher.Emit(eventType, data); err != nil {
+		log.Printf("⚠️  Failed to record %s webhooks: %v", eventType, err)
+	}
+}
+
+// startBackgroundWebhooks retries failed deliveries alongside the API server until ctx ends
+func startBackgroundWebhooks(ctx context.Context) {
+	if dispatcher := processWebhooks(); dispatcher != nil {
+		go dispatcher.Run(ctx, defaultWebhookInterval)
+	}
+}
+
+// Emit records a delivery of the event for every active subscription to its type
+func (d *webhookDispatcher) Emit(eventType string, data interface{}) error {
+	subs, err := d.store.ActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+	var subscribed []database.WebhookSubscription
+	for _, sub := range subs {
+		if subscribesTo(sub, eventType) {
+			subscribed = append(subscribed, sub)
+		}
+	}
+	_, err = d.queue(subscribed, eventType, data)
+	return err
+}
+
+// queue records one delivery of the event per subscription and has them sent
+func (d *webhookDispatcher) queue(subs []database.WebhookSubscription, eventType string, data interface{}) ([]*database.WebhookDelivery, error) {
+	if len(subs) == 0 {
+		return nil, nil
+	}
+	event := webhookEvent{
+		ID:        fmt.Sprintf("evt_%s", uuid.New().String()),
+		Type:      eventType,
+		CreatedAt: d.now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	deliveries := make([]*database.WebhookDelivery, 0, len(subs))
+	for _, sub := range subs {
+		delivery := &database.WebhookDelivery{
+			DeliveryID:     fmt.Sprintf("dlv_%s", uuid.New().String()),
+			SubscriptionID: sub.SubscriptionID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        datatypes.JSON(payload),
+			Status:         deliveryPending,
+			NextAttemptAt:  &event.CreatedAt,
+		}
+		if err := d.store.SaveDelivery(delivery); err != nil {
+			return deliveries, fmt.Errorf("failed to record delivery to %s: %w", sub.SubscriptionID, err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if d.background.Load() {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+		return deliveries, nil
+	}
+	for _, delivery := range deliveries {
+		if _, _, err := d.claimAndAttempt(context.Background(), delivery); err != nil {
+			return deliveries, err
+		}
+	}
+	return deliveries, nil
+}
+
+// Run sends due deliveries until ctx is cancelled: new ones right away, retries every
+// interval
+func (d *webhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultWebhookInterval
+	}
+	d.background.Store(true)
+	defer d.background.Store(false)
+	log.Printf("📨 Webhook dispatcher retrying deliveries every %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, _, err := d.DeliverDue(ctx); err != nil {
+			log.Printf("⚠️  Webhook delivery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("🛑 Webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due and returns how
+// many were attempted and how many of those were delivered
+func (d *webhookDispatcher) DeliverDue(ctx context.Context) (int, int, error) {
+	d.sending.Lock()
+	defer d.sending.Unlock()
+
+	attempted, delivered := 0, 0
+	for {
+		due, err := d.store.DueDeliveries(d.now(), webhookBatchSize)
+		if err != nil {
+			return attempted, delivered, err
+		}
+		if len(due) == 0 {
+			return attempted, delivered, nil
+		}
+		for i := range due {
+			if ctx.Err() != nil {
+				return attempted, delivered, ctx.Err()
+			}
+			claimed, ok, err := d.claimAndAttempt(ctx, &due[i])
+			if err != nil {
+				return attempted, delivered, err
+			}
+			if claimed {
+				attempted++
+			}
+			if ok {
+				delivered++
+			}
+		}
+	}
+}
+
+// claimAndAttempt attempts a due delivery unless another sender claimed it first
+func (d *webhookDispatcher) claimAndAttempt(ctx context.Context, delivery *database.WebhookDelivery) (claimed, ok bool, err error) {
+	now := d.now()
+	claimed, err = d.store.ClaimDelivery(delivery.DeliveryID, now, now.Add(webhookClaimLease))
+	if err != nil {
+		return false, false, fmt.Errorf("failed to claim delivery %s: %w", delivery.DeliveryID, err)
+	}
+	if !claimed {
+		return false, false, nil
+	}
+	ok, err = d.attempt(ctx, delivery)
+	return true, ok, err
+}
+
+// attempt sends a delivery once and records the outcome, scheduling the next attempt or
+// giving up after the last one
+func (d *webhookDispatcher) attempt(ctx context.Context, delivery *database.WebhookDelivery) (bool, error) {
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus, delivery.ResponseBody, delivery.Error = 0, "", ""
+
+	sub, err := d.store.GetSubscription(delivery.SubscriptionID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		delivery.Error = "subscription was removed"
+	case err != nil:
+		return false, err
+	case !sub.Active:
+		delivery.Error = "subscription is inactive"
+	default:
+		d.send(ctx, sub, delivery)
+	}
+
+	if delivery.Error == "" {
+		delivery.Status = deliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		log.Printf("📨 Delivered %s to %s (%s)", delivery.EventType, delivery.SubscriptionID, delivery.DeliveryID)
+	} else if sub == nil || !sub.Active || delivery.Attempts > len(webhookRetrySchedule) {
+		delivery.Status = deliveryFailed
+		delivery.NextAttemptAt = nil
+		log.Printf("❌ Gave up delivering %s to %s after %d attempts: %s", delivery.EventType, delivery.SubscriptionID, delivery.Attempts, delivery.Error)
+	} else {
+		next := now.Add(webhookRetrySchedule[delivery.Attempts-1])
+		delivery.NextAttemptAt = &next
+		log.Printf("⚠️  Delivering %s to %s failed (attempt %d): %s; retrying at %s",
+			delivery.EventType, delivery.SubscriptionID, delivery.Attempts, delivery.Error, next.Format(time.RFC3339))
+	}
+
+	if err := d.store.SaveDelivery(delivery); err != nil {
+		return false, fmt.Errorf("failed to record delivery %s: %w", delivery.DeliveryID, err)
+	}
+	return delivery.Status == deliveryDelivered, nil
+}
+
+// send posts the signed payload to the subscription and records the response in delivery.
+// Any status other than 2xx is a failure.
+func (d *webhookDispatcher) send(ctx context.Context, sub *database.WebhookSubscription, delivery *database.WebhookDelivery) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", webhookUserAgent)
+	req.Header.Set(webhookEventHeader, delivery.EventType)
+	req.Header.Set(webhookDeliveryHeader, delivery.DeliveryID)
+	req.Header.Set(webhookSignatureHeader, signWebhook(sub.Secret, d.now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = string(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		delivery.Error = fmt.Sprintf("endpoint responded %s", resp.Status)
+	}
+}
+
+// subscribesTo reports whether the subscription wants events of eventType
+func subscribesTo(sub database.WebhookSubscription, eventType string) bool {
+	for _, event := range strings.Split(sub.Events, ",") {
+		if event = strings.TrimSpace(event); event == "*" || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// signWebhook signs a payload sent at timestamp with secret, as the signature header value
+func signWebhook(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, payload)
+}
+
+func webhookMAC(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyWebhookSignature checks a signature header against the payload it came with and
+// rejects signatures older or newer than tolerance, so a captured request cannot be
+// replayed later
+func verifyWebhookSignature(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed header", errWebhookSignature)
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: signed %s ago", errWebhookSignature, age.Round(time.Second))
+	}
+	expected := webhookMAC(secret, ts, payload)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature does not match", errWebhookSignature)
+}
+
+// webhookPublications sends term_root.published for the publications of an integration
+type webhookPublications struct {
+	network  string
+	registry string
+}
+
+func (p webhookPublications) TermRootPublished(publication *blockchain.Publication) {
+	event := termRootPublishedEvent{
+		TermID:          publication.TermID,
+		Method:          publication.Method,
+		VerkleRoot:      fmt.Sprintf("0x%x", publication.VerkleRoot),
+		Reason:          publication.Reason,
+		Network:         p.network,
+		Registry:        p.registry,
+		TransactionHash: publication.Result.TransactionHash,
+		BlockNumber:     publication.Result.BlockNumber,
+		PublishedAt:     publication.Result.PublishedAt.UTC(),
+	}
+	if publication.TotalStudents != nil {
+		event.TotalStudents = publication.TotalStudents.Uint64()
+	}
+	emitWebhook(eventTermRootPublished, event)
+}
+
+// notifyPublications sends webhooks for the term roots integration publishes on network
+func notifyPublications(integration *blockchain.BlockchainIntegration, network string) {
+	integration.SetPublicationListener(webhookPublications{network: network, registry: integration.ContractAddress().Hex()})
+}
+
+// newWebhookSubscription validates a subscription and generates its secret
+func newWebhookSubscription(rawURL string, events []string, description, createdBy string, production bool) (*database.WebhookSubscription, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("webhook URL must be an absolute http(s) URL, got %q", rawURL)
+	}
+	if production && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("webhook URLs must use https in production")
+	}
+
+	if len(events) == 0 {
+		events = []string{"*"}
+	}
+	for _, event := range events {
+		known := event == "*"
+		for _, eventType := range webhookEventTypes {
+			known = known || event == eventType
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown event %q, expected * or one of %s", event, strings.Join(webhookEventTypes, ", "))
+		}
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return &database.WebhookSubscription{
+		SubscriptionID: fmt.Sprintf("whk_%s", uuid.New().String()),
+		URL:            rawURL,
+		Events:         strings.Join(events, ","),
+		Secret:         webhookSecretPrefix + hex.EncodeToString(random),
+		Description:    description,
+		Active:         true,
+		CreatedBy:      createdBy,
+	}, nil
+}
+
+// webhookReceiver is an endpoint that checks the signature of webhook requests and prints
+// their events to out, for trying subscriptions against a local receiver
+func webhookReceiver(secret string, out io.Writer) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if err := verifyWebhookSignature(secret, r.Header.Get(webhookSignatureHeader), payload, webhookSignatureTolerance, time.Now()); err != nil {
+			fmt.Fprintf(out, "❌ Rejected %s %s: %v\n", r.Header.Get(webhookEventHeader), r.Header.Get(webhookDeliveryHeader), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		var indented bytes.Buffer
+		json.Indent(&indented, payload, "", "  ")
+		fmt.Fprintf(out, "📨 %s %s\n%s\n", r.Header.Get(webhookEventHeader), r.Header.Get(webhookDeliveryHeader), indented.String())
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// webhookSubscriptionView is a subscription as returned by the API, without its secret
+type webhookSubscriptionView struct {
+	SubscriptionID string    `json:"subscription_id"`
+	URL            string    `json:"url"`
+	Events         []string  `json:"events"`
+	Description    string    `json:"description,omitempty"`
+	Active         bool      `json:"active"`
+	CreatedBy      string    `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newWebhookSubscriptionView(sub *database.WebhookSubscription) webhookSubscriptionView {
+	return webhookSubscriptionView{
+		SubscriptionID: sub.SubscriptionID,
+		URL:            sub.URL,
+		Events:         strings.Split(sub.Events, ","),
+		Description:    sub.Description,
+		Active:         sub.Active,
+		CreatedBy:      sub.CreatedBy,
+		CreatedAt:      sub.CreatedAt,
+	}
+}
+
+// handleListWebhooks lists the webhook subscriptions
+func handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: "Database unavailable"})
+		return
+	}
+	defer database.Close(db)
+
+	subs, err := database.GetWebhookSubscriptions(db, false)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to list webhooks"})
+		return
+	}
+	views := make([]webhookSubscriptionView, 0, len(subs))
+	for i := range subs {
+		views = append(views, newWebhookSubscriptionView(&subs[i]))
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: views})
+}
+
+// handleListWebhookDeliveries lists the latest deliveries, of all subscriptions or of the
+// one in the path
+func handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 500 {
+			respondJSON(w, http.StatusBadRequest, APIResponse{Success: false, Error: "limit must be between 1 and 500"})
+			return
+		}
+		limit = parsed
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		respondJSON(w, http.StatusServiceUnavailable, APIResponse{Success: false, Error: "Database unavailable"})
+		return
+	}
+	defer database.Close(db)
+
+	deliveries, err := database.GetRecentWebhookDeliveries(db, mux.Vars(r)["subscription_id"], limit)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, APIResponse{Success: false, Error: "Failed to list webhook deliveries"})
+		return
+	}
+	respondJSON(w, http.StatusOK, APIResponse{Success: true, Data: deliveries})
+}
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Manage webhook subscriptions for credential lifecycle events",
+	Long: `Subscribe endpoints of other systems to credential lifecycle events:
+
+  term_root.published         a term root was confirmed on chain (first version or supersession)
+  revocation_batch.processed  approved revocations of a term were published as a new version
+  receipts.regenerated        journey receipts were regenerated for the listed students
+
+Every event is POSTed as JSON, signed in the X-IUMiCert-Signature header, and retried with
+backoff for about ten hours. Attempts are logged in webhook_deliveries.`,
+}
+
+var webhookAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Subscribe an endpoint and print its signing secret",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		endpoint, _ := cmd.Flags().GetString("url")
+		events, _ := cmd.Flags().GetStringSlice("events")
+		description, _ := cmd.Flags().GetString("description")
+
+		cfg, db := webhookCommandSetup()
+		defer database.Close(db)
+
+		sub, err := newWebhookSubscription(endpoint, events, description, cliActor(), cfg.IsProduction())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+			os.Exit(1)
+		}
+		if err := database.CreateWebhookSubscription(db, sub); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to save subscription: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Subscription %s sends %s to %s\n", sub.SubscriptionID, sub.Events, sub.URL)
+		fmt.Printf("🔑 %s\n", sub.Secret)
+		fmt.Println("⚠️  Keep the secret with the receiver; it verifies the X-IUMiCert-Signature header with it")
+	},
+}
+
+var webhookListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List webhook subscriptions",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		_, db := webhookCommandSetup()
+		defer database.Close(db)
+
+		subs, err := database.GetWebhookSubscriptions(db, false)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to list subscriptions: %v\n", err)
+			os.Exit(1)
+		}
+		if len(subs) == 0 {
+			fmt.Println("📭 No webhook subscriptions")
+			return
+		}
+		for _, sub := range subs {
+			fmt.Printf("%s  %s\n  - Events: %s\n  - Active: %v, created by %s at %s\n",
+				sub.SubscriptionID, sub.URL, sub.Events, sub.Active, sub.CreatedBy, sub.CreatedAt.Format(time.RFC3339))
+			if sub.Description != "" {
+				fmt.Printf("  - %s\n", sub.Description)
+			}
+		}
+	},
+}
+
+var webhookRemoveCmd = &cobra.Command{
+	Use:   "remove <subscription-id>",
+	Short: "Remove a webhook subscription; deliveries still pending for it fail",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		_, db := webhookCommandSetup()
+		defer database.Close(db)
+
+		if err := database.DeleteWebhookSubscription(db, args[0]); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to remove %s: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Removed subscription %s\n", args[0])
+	},
+}
+
+var webhookTestCmd = &cobra.Command{
+	Use:   "test <subscription-id>",
+	Short: "Send a webhook.test event to a subscription and print the outcome",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		_, db := webhookCommandSetup()
+		defer database.Close(db)
+
+		sub, err := database.GetWebhookSubscription(db, args[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Subscription %s not found: %v\n", args[0], err)
+			os.Exit(1)
+		}
+		dispatcher := newWebhookDispatcher(dbWebhookStore{db: db})
+		deliveries, err := dispatcher.queue([]database.WebhookSubscription{*sub}, eventWebhookTest, map[string]string{
+			"subscription_id": sub.SubscriptionID,
+			"sent_by":         cliActor(),
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to send test event: %v\n", err)
+			os.Exit(1)
+		}
+
+		delivery := deliveries[0]
+		if err := db.Where("delivery_id = ?", delivery.DeliveryID).First(delivery).Error; err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read delivery: %v\n", err)
+			os.Exit(1)
+		}
+		if delivery.Status != deliveryDelivered {
+			fmt.Fprintf(os.Stderr, "❌ Delivery %s failed: %s\n", delivery.DeliveryID, delivery.Error)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Delivery %s accepted with status %d\n", delivery.DeliveryID, delivery.ResponseStatus)
+	},
+}
+
+var webhookDeliverCmd = &cobra.Command{
+	Use:   "deliver",
+	Short: "Send webhook deliveries that are due for another attempt",
+	Long: `Send pending deliveries whose next attempt is due. serve does this in the background;
+run this when serve is not running. Without --follow it makes one pass and exits.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		follow, _ := cmd.Flags().GetBool("follow")
+		interval, _ := cmd.Flags().GetDuration("interval")
+
+		_, db := webhookCommandSetup()
+		defer database.Close(db)
+		dispatcher := newWebhookDispatcher(dbWebhookStore{db: db})
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if follow {
+			dispatcher.Run(ctx, interval)
+			return
+		}
+		attempted, delivered, err := dispatcher.DeliverDue(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Webhook delivery failed: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Attempted %d deliveries, %d delivered\n", attempted, delivered)
+	},
+}
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries",
+	Short: "Show the webhook delivery log",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		subscriptionID, _ := cmd.Flags().GetString("subscription")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		_, db := webhookCommandSetup()
+		defer database.Close(db)
+
+		deliveries, err := database.GetRecentWebhookDeliveries(db, subscriptionID, limit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to read deliveries: %v\n", err)
+			os.Exit(1)
+		}
+		for _, delivery := range deliveries {
+			fmt.Printf("%s  %-27s %-9s %d attempts  %s\n", delivery.CreatedAt.Format(time.RFC3339),
+				delivery.EventType, delivery.Status, delivery.Attempts, delivery.SubscriptionID)
+			if delivery.Error != "" {
+				fmt.Printf("  - %s\n", delivery.Error)
+			}
+			if delivery.NextAttemptAt != nil {
+				fmt.Printf("  - Next attempt at %s\n", delivery.NextAttemptAt.Format(time.RFC3339))
+			}
+		}
+	},
+}
+
+var webhookListenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Run a local receiver that verifies and prints webhook events",
+	Long: `Run an HTTP receiver for trying webhooks locally, e.g.
+
+  micert webhook add --url http://localhost:9090/hooks
+  micert webhook listen --port 9090 --secret whsec_...
+  micert webhook test whk_...
+
+Requests with a missing, wrong or stale signature are rejected with 401.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		port, _ := cmd.Flags().GetString("port")
+		secret, _ := cmd.Flags().GetString("secret")
+
+		fmt.Printf("👂 Listening for webhooks on http://localhost:%s/\n", port)
+		if err := http.ListenAndServe(":"+port, webhookReceiver(secret, os.Stdout)); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Receiver stopped: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// webhookCommandSetup loads the configuration and connects to the database for the
+// webhook commands, exiting when either fails
+func webhookCommandSetup() (*config.Config, *gorm.DB) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	db, err := database.Connect()
+	if err == nil {
+		err = db.AutoMigrate(&database.WebhookSubscription{}, &database.WebhookDelivery{})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Database connection failed: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg, db
+}
+
+func init() {
+	webhookAddCmd.Flags().String("url", "", "endpoint the events are POSTed to")
+	webhookAddCmd.Flags().StringSlice("events", []string{"*"}, "events to send: "+strings.Join(webhookEventTypes, ", ")+" or *")
+	webhookAddCmd.Flags().String("description", "", "what the endpoint is, e.g. student information system")
+	webhookAddCmd.MarkFlagRequired("url")
+
+	webhookDeliverCmd.Flags().BoolP("follow", "f", false, "keep sending deliveries as they become due")
+	webhookDeliverCmd.Flags().Duration("interval", defaultWebhookInterval, "polling interval with --follow")
+
+	webhookDeliveriesCmd.Flags().String("subscription", "", "only show deliveries of this subscription")
+	webhookDeliveriesCmd.Flags().Int("limit", 20, "number of deliveries to show")
+
+	webhookListenCmd.Flags().String("port", "9090", "port to listen on")
+	webhookListenCmd.Flags().String("secret", "", "secret of the subscription, printed by webhook add")
+	webhookListenCmd.MarkFlagRequired("secret")
+
+	webhookCmd.AddCommand(webhookAddCmd)
+	webhookCmd.AddCommand(webhookListCmd)
+	webhookCmd.AddCommand(webhookRemoveCmd)
+	webhookCmd.AddCommand(webhookTestCmd)
+	webhookCmd.AddCommand(webhookDeliverCmd)
+	webhookCmd.AddCommand(webhookDeliveriesCmd)
+	webhookCmd.AddCommand(webhookListenCmd)
+	rootCmd.AddCommand(webhookCmd)
+}
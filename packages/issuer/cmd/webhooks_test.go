@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	blockchain "iumicert/issuer/blockchain_integration"
+	"iumicert/issuer/database"
+
+	"gorm.io/gorm"
+)
+
+// memWebhookStore keeps subscriptions and deliveries in memory
+type memWebhookStore struct {
+	mu         sync.Mutex
+	subs       []database.WebhookSubscription
+	deliveries []*database.WebhookDelivery
+}
+
+func (s *memWebhookStore) ActiveSubscriptions() ([]database.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var active []database.WebhookSubscription
+	for _, sub := range s.subs {
+		if sub.Active {
+			active = append(active, sub)
+		}
+	}
+	return active, nil
+}
+
+func (s *memWebhookStore) GetSubscription(subscriptionID string) (*database.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.subs {
+		if sub.SubscriptionID == subscriptionID {
+			return &sub, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *memWebhookStore) SaveDelivery(delivery *database.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	saved := *delivery
+	for i, existing := range s.deliveries {
+		if existing.DeliveryID == delivery.DeliveryID {
+			s.deliveries[i] = &saved
+			return nil
+		}
+	}
+	s.deliveries = append(s.deliveries, &saved)
+	return nil
+}
+
+func (s *memWebhookStore) DueDeliveries(now time.Time, limit int) ([]database.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []database.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == deliveryPending && !delivery.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, *delivery)
+		}
+	}
+	return due, nil
+}
+
+func (s *memWebhookStore) ClaimDelivery(deliveryID string, now, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range s.deliveries {
+		if delivery.DeliveryID == deliveryID && delivery.Status == deliveryPending && !delivery.NextAttemptAt.After(now) {
+			delivery.NextAttemptAt = &until
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deliveriesTo returns the deliveries of a subscription
+func (s *memWebhookStore) deliveriesTo(subscriptionID string) []database.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []database.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	return deliveries
+}
+
+// TestWebhookDelivery sends a publication to a local receiver that fails once, checks the
+// retry and signature, and gives up on an endpoint that never answers
+func TestWebhookDelivery(t *testing.T) {
+	sis, err := newWebhookSubscription("http://127.0.0.1/sis", []string{eventTermRootPublished}, "SIS", "cli:registrar", false)
+	if err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	var received bytes.Buffer
+	receiver := webhookReceiver(sis.Secret, &received)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "database locked", http.StatusServiceUnavailable)
+			return
+		}
+		receiver.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	sis.URL = server.URL
+
+	portal, _ := newWebhookSubscription("http://127.0.0.1/portal", []string{eventReceiptsRegenerated}, "Alumni portal", "cli:registrar", false)
+	dead, _ := newWebhookSubscription("http://127.0.0.1:1/hooks", nil, "", "cli:registrar", false)
+	store := &memWebhookStore{subs: []database.WebhookSubscription{*sis, *portal, *dead}}
+
+	clock := time.Now().UTC().Truncate(time.Second) // The receiver checks signatures against the real time
+	dispatcher := newWebhookDispatcher(store)
+	dispatcher.now = func() time.Time { return clock }
+
+	previous := issuerWebhooks
+	issuerWebhooks = dispatcher
+	defer func() { issuerWebhooks = previous }()
+
+	// Emitting without a running dispatcher makes the first attempts right away
+	webhookPublications{network: "simulated", registry: "0x0000000000000000000000000000000000000001"}.TermRootPublished(&blockchain.Publication{
+		Method:        blockchain.MethodPublishTermRoot,
+		TermID:        "Semester_1_2024",
+		VerkleRoot:    [32]byte{0xab},
+		TotalStudents: big.NewInt(5),
+		Result:        &blockchain.PublishResult{TransactionHash: "0xfeed", BlockNumber: 42, PublishedAt: clock},
+	})
+	first := store.deliveriesTo(sis.SubscriptionID)
+	if len(first) != 1 || first[0].Status != deliveryPending || first[0].ResponseStatus != http.StatusServiceUnavailable ||
+		!first[0].NextAttemptAt.Equal(clock.Add(webhookRetrySchedule[0])) {
+		t.Fatalf("First attempt recorded as %+v", first)
+	}
+	if len(store.deliveriesTo(portal.SubscriptionID)) != 0 {
+		t.Fatal("Portal received an event it did not subscribe to")
+	}
+
+	if attempted, _, _ := dispatcher.DeliverDue(t.Context()); attempted != 0 {
+		t.Fatalf("%d deliveries retried before they were due", attempted)
+	}
+	clock = clock.Add(webhookRetrySchedule[0])
+	if _, delivered, err := dispatcher.DeliverDue(t.Context()); err != nil || delivered != 1 {
+		t.Fatalf("Retry delivered %d (%v)", delivered, err)
+	}
+	retried := store.deliveriesTo(sis.SubscriptionID)[0]
+	if retried.Status != deliveryDelivered || retried.Attempts != 2 || retried.NextAttemptAt != nil || retried.ResponseStatus != http.StatusNoContent {
+		t.Fatalf("Retried delivery recorded as %+v", retried)
+	}
+
+	var event struct {
+		Type string                 `json:"type"`
+		Data termRootPublishedEvent `json:"data"`
+	}
+	json.Unmarshal(retried.Payload, &event)
+	if event.Type != eventTermRootPublished || event.Data.TermID != "Semester_1_2024" || event.Data.BlockNumber != 42 ||
+		!strings.HasPrefix(event.Data.VerkleRoot, "0xab00") || event.Data.TotalStudents != 5 {
+		t.Fatalf("Receiver got %+v", event)
+	}
+	if !strings.Contains(received.String(), "📨 "+eventTermRootPublished+" "+retried.DeliveryID) {
+		t.Fatalf("Receiver printed %q", received.String())
+	}
+
+	// The dead endpoint is retried on schedule and then given up
+	for _, wait := range webhookRetrySchedule {
+		clock = clock.Add(wait)
+		dispatcher.DeliverDue(t.Context())
+	}
+	gaveUp := store.deliveriesTo(dead.SubscriptionID)
+	if len(gaveUp) != 1 || gaveUp[0].Status != deliveryFailed || gaveUp[0].Attempts != len(webhookRetrySchedule)+1 || gaveUp[0].Error == "" {
+		t.Fatalf("Dead endpoint delivery recorded as %+v", gaveUp)
+	}
+	if gaveUp[0].EventID != retried.EventID {
+		t.Fatalf("Deliveries of one event have IDs %s and %s", gaveUp[0].EventID, retried.EventID)
+	}
+	t.Logf("✅ Event %s delivered after a retry, dead endpoint given up after %d attempts", retried.EventID, gaveUp[0].Attempts)
+}
+
+// TestWebhookEmitClaimsItsDeliveries checks that emitting without a running dispatcher
+// sends only the deliveries of its event and that a delivery claimed by another process is
+// not sent again
+func TestWebhookEmitClaimsItsDeliveries(t *testing.T) {
+	sis, err := newWebhookSubscription("http://127.0.0.1/sis", nil, "SIS", "cli:registrar", false)
+	if err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	var mu sync.Mutex
+	sent := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sent[r.Header.Get(webhookDeliveryHeader)]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	sis.URL = server.URL
+
+	clock := time.Now().UTC()
+	overdue := clock.Add(-time.Hour)
+	store := &memWebhookStore{
+		subs: []database.WebhookSubscription{*sis},
+		deliveries: []*database.WebhookDelivery{{
+			DeliveryID:     "dlv_overdue",
+			SubscriptionID: sis.SubscriptionID,
+			EventType:      eventReceiptsRegenerated,
+			Status:         deliveryPending,
+			NextAttemptAt:  &overdue,
+		}},
+	}
+	dispatcher := newWebhookDispatcher(store)
+	dispatcher.now = func() time.Time { return clock }
+
+	if err := dispatcher.Emit(eventTermRootPublished, map[string]string{"term_id": "Semester_1_2024"}); err != nil {
+		t.Fatalf("Failed to emit event: %v", err)
+	}
+	if sent["dlv_overdue"] != 0 || len(sent) != 1 {
+		t.Fatalf("Emit sent %v, expected only its own delivery", sent)
+	}
+
+	// Another process claims the overdue delivery first
+	if claimed, _ := store.ClaimDelivery("dlv_overdue", clock, clock.Add(webhookClaimLease)); !claimed {
+		t.Fatal("Failed to claim the overdue delivery")
+	}
+	if attempted, _, err := dispatcher.DeliverDue(t.Context()); err != nil || attempted != 0 {
+		t.Fatalf("Claimed delivery attempted %d times (%v)", attempted, err)
+	}
+	if claimed, _ := store.ClaimDelivery("dlv_overdue", clock, clock.Add(webhookClaimLease)); claimed {
+		t.Fatal("Delivery claimed twice")
+	}
+	for id, count := range sent {
+		if count != 1 {
+			t.Fatalf("Delivery %s sent %d times", id, count)
+		}
+	}
+	t.Logf("✅ Emit sent only its delivery, claimed delivery skipped")
+}
+
+// TestWebhookSignature checks that receivers reject tampered, stale and foreign signatures
+func TestWebhookSignature(t *testing.T) {
+	secret := webhookSecretPrefix + "0123456789abcdef"
+	payload := []byte(`{"type":"revocation_batch.processed"}`)
+	now := time.Now()
+	header := signWebhook(secret, now, payload)
+
+	cases := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		valid   bool
+	}{
+		{"signed", secret, header, payload, true},
+		{"tampered body", secret, header, []byte(`{"type":"receipts.regenerated"}`), false},
+		{"other secret", webhookSecretPrefix + "other", header, payload, false},
+		{"stale", secret, signWebhook(secret, now.Add(-2*webhookSignatureTolerance), payload), payload, false},
+		{"missing", secret, "", payload, false},
+		{"rotated secret", secret, header + ",v1=" + strings.Repeat("0", 64), payload, true},
+	}
+	for _, c := range cases {
+		err := verifyWebhookSignature(c.secret, c.header, c.payload, webhookSignatureTolerance, now)
+		if (err == nil) != c.valid || (err != nil && !errors.Is(err, errWebhookSignature)) {
+			t.Fatalf("%s: verification returned %v", c.name, err)
+		}
+	}
+
+	if _, err := newWebhookSubscription("http://sis.iu.edu.vn/hooks", nil, "", "", true); err == nil {
+		t.Fatal("Plain http subscription accepted in production")
+	}
+	if _, err := newWebhookSubscription("https://sis.iu.edu.vn/hooks", []string{"term.deleted"}, "", "", false); err == nil {
+		t.Fatal("Subscription to an unknown event accepted")
+	}
+	t.Logf("✅ %d signature cases behaved as expected", len(cases))
+}
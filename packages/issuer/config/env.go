@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,19 +11,47 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Signers of the issuer account, selected with SIGNER
+const (
+	SignerKey      = "key"      // Raw ISSUER_PRIVATE_KEY, development only
+	SignerKeystore = "keystore" // Encrypted JSON keystore at KEYSTORE_PATH
+	SignerRemote   = "remote"   // External signer at REMOTE_SIGNER_URL
+	SignerOffline  = "offline"  // Key on an offline machine; transactions are prepared unsigned for SIGNER_ADDRESS
+)
+
 // Config holds all configuration for the issuer
 type Config struct {
+	// Environment, "production" refuses raw keys and the test key fallback
+	Environment          string
+	
 	// Blockchain settings
 	IssuerPrivateKey     string
 	ContractAddress      string
 	Network              string
 	DefaultGasLimit      uint64
 	MaxGasPrice          uint64
+	IndexerStartBlock    uint64 // First block the chain indexer scans when it has no checkpoint
+	RootCacheTTL         uint64 // Seconds the verifier caches an on-chain root status
+	TxStuckTimeout       uint64 // Seconds before an unmined transaction is replaced with a higher fee
+	TxConfirmTimeout     uint64 // Seconds to wait for a transaction before giving up
+	MaxTxCost            uint64 // Budget in wei for one transaction (gas limit × max fee), 0 for none
+	ConfirmationBlocks   uint64 // Blocks deep a transaction must be before it counts as published
+	ReorgWatchDepth      uint64 // Blocks below the head in which the reorg watcher re-checks transactions
+	
+	// Signer settings
+	SignerType           string // SignerKey, SignerKeystore, SignerRemote or SignerOffline
+	KeystorePath         string // Keystore file, the passphrase is prompted for without KeystorePasswordFile
+	KeystorePasswordFile string
+	RemoteSignerURL      string // JSON-RPC endpoint answering account_signTransaction
+	SignerAddress        string // Account the remote or offline signer signs for
 	
-	// RPC URLs
-	LocalhostRPCURL      string
-	SepoliaRPCURL        string
-	MainnetRPCURL        string
+	// API authentication for the issuer routes
+	APIKeysFile          string // JSON file of API key hashes and their roles
+	AuthTokenSecret      string // HMAC secret of signed bearer tokens
+	AuthDisabled         bool   // Opens the issuer API without credentials, outside production only
+	
+	// Networks the issuer can publish to, shared by the CLI and the API server
+	Networks             *NetworkRegistry
 	
 	// Application settings
 	Debug                bool
@@ -41,18 +70,41 @@ func LoadConfig() (*Config, error) {
 		fmt.Printf("Note: .env file not found, using environment variables only\n")
 	}
 
+	networks, err := SharedNetworkRegistry()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
+		Environment:         getEnv("ENV", "development"),
+		
 		// Blockchain settings
 		IssuerPrivateKey:    getEnv("ISSUER_PRIVATE_KEY", ""),
 		ContractAddress:     getEnv("IUMICERT_CONTRACT_ADDRESS", ""),
 		Network:             getEnv("NETWORK", "localhost"),
 		DefaultGasLimit:     getEnvUint64("DEFAULT_GAS_LIMIT", 500000),
 		MaxGasPrice:         getEnvUint64("MAX_GAS_PRICE", 20000000000),
+		IndexerStartBlock:   getEnvUint64("INDEXER_START_BLOCK", 0),
+		RootCacheTTL:        getEnvUint64("ROOT_CACHE_TTL", 60),
+		TxStuckTimeout:      getEnvUint64("TX_STUCK_TIMEOUT", 120),
+		TxConfirmTimeout:    getEnvUint64("TX_CONFIRM_TIMEOUT", 600),
+		MaxTxCost:           getEnvUint64("MAX_TX_COST", 0),
+		ConfirmationBlocks:  getEnvUint64("CONFIRMATION_BLOCKS", micertConfirmationBlocks(3)),
+		ReorgWatchDepth:     getEnvUint64("REORG_WATCH_DEPTH", 128),
+		
+		// Signer settings
+		SignerType:           getEnv("SIGNER", SignerKey),
+		KeystorePath:         getEnv("KEYSTORE_PATH", ""),
+		KeystorePasswordFile: getEnv("KEYSTORE_PASSWORD_FILE", ""),
+		RemoteSignerURL:      getEnv("REMOTE_SIGNER_URL", ""),
+		SignerAddress:        getEnv("SIGNER_ADDRESS", ""),
+		
+		// API authentication
+		APIKeysFile:          getEnv("API_KEYS_FILE", ""),
+		AuthTokenSecret:      getEnv("AUTH_TOKEN_SECRET", ""),
+		AuthDisabled:         getEnvBool("AUTH_DISABLED", false),
 		
-		// RPC URLs
-		LocalhostRPCURL:     getEnv("LOCALHOST_RPC_URL", "http://localhost:8545"),
-		SepoliaRPCURL:       getEnv("SEPOLIA_RPC_URL", "https://sepolia.infura.io/v3/YOUR_INFURA_KEY"),
-		MainnetRPCURL:       getEnv("MAINNET_RPC_URL", "https://mainnet.infura.io/v3/YOUR_INFURA_KEY"),
+		Networks:            networks,
 		
 		// Application settings
 		Debug:               getEnvBool("DEBUG", false),
@@ -66,34 +118,99 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
-// GetRPCURL returns the appropriate RPC URL for the given network
-func (c *Config) GetRPCURL(network string) (string, error) {
-	switch network {
-	case "localhost", "local":
-		return c.LocalhostRPCURL, nil
-	case "sepolia":
-		if c.SepoliaRPCURL == "https://sepolia.infura.io/v3/YOUR_INFURA_KEY" {
-			return "", fmt.Errorf("please set SEPOLIA_RPC_URL with your Infura key")
+// micertConfirmationBlocks returns blockchain.confirmation_blocks from config/micert.json,
+// the repository configuration written by `micert init`, or fallback without one
+func micertConfirmationBlocks(fallback uint64) uint64 {
+	for _, path := range []string{"config/micert.json", "../config/micert.json"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
 		}
-		return c.SepoliaRPCURL, nil
-	case "mainnet":
-		if c.MainnetRPCURL == "https://mainnet.infura.io/v3/YOUR_INFURA_KEY" {
-			return "", fmt.Errorf("please set MAINNET_RPC_URL with your Infura key")
+
+		var micert struct {
+			Blockchain struct {
+				ConfirmationBlocks *uint64 `json:"confirmation_blocks"`
+			} `json:"blockchain"`
 		}
-		return c.MainnetRPCURL, nil
-	default:
-		return "", fmt.Errorf("unsupported network: %s", network)
+		if err := json.Unmarshal(data, &micert); err != nil || micert.Blockchain.ConfirmationBlocks == nil {
+			return fallback
+		}
+		return *micert.Blockchain.ConfirmationBlocks
+	}
+	return fallback
+}
+
+// GetNetwork returns the registry entry of a network
+func (c *Config) GetNetwork(network string) (*NetworkConfig, error) {
+	if c.Networks == nil {
+		networks, err := SharedNetworkRegistry()
+		if err != nil {
+			return nil, err
+		}
+		c.Networks = networks
+	}
+	return c.Networks.Lookup(network)
+}
+
+// GetRPCURL returns the first RPC URL of the given network
+func (c *Config) GetRPCURL(network string) (string, error) {
+	if network == "simulated" {
+		// In-process chain, there is no RPC endpoint
+		return "", nil
+	}
+	entry, err := c.GetNetwork(network)
+	if err != nil {
+		return "", err
+	}
+	urls, err := entry.ResolveRPCURLs()
+	if err != nil {
+		return "", err
+	}
+	return urls[0], nil
+}
+
+// ApplyNetwork overrides the global transaction settings with the ones network sets
+func (c *Config) ApplyNetwork(network *NetworkConfig) {
+	if network.Confirmations != nil {
+		c.ConfirmationBlocks = *network.Confirmations
+	}
+	if network.GasLimit > 0 {
+		c.DefaultGasLimit = network.GasLimit
+	}
+	if network.MaxGasPrice > 0 {
+		c.MaxGasPrice = network.MaxGasPrice
+	}
+	if network.MaxTxCost > 0 {
+		c.MaxTxCost = network.MaxTxCost
+	}
+}
+
+// isDevelopmentNetwork reports whether the configured network allows the test key and
+// contract address fallbacks
+func (c *Config) isDevelopmentNetwork() bool {
+	if c.IsProduction() {
+		return false
+	}
+	if c.IsSimulated() {
+		return true
 	}
+	entry, err := c.GetNetwork(c.Network)
+	return err == nil && entry.Development
 }
 
-// GetPrivateKey returns the private key to use, with fallback to test key for localhost
+// GetPrivateKey returns the private key to use, with fallback to test key for localhost.
+// It is empty when a keystore or remote signer is configured, and never the test key in
+// production.
 func (c *Config) GetPrivateKey() string {
+	if c.SignerType != SignerKey && c.SignerType != "" {
+		return ""
+	}
 	if c.IssuerPrivateKey != "" {
 		return c.IssuerPrivateKey
 	}
 	
 	// For localhost/testing, use the test private key
-	if c.Network == "localhost" || c.Network == "local" {
+	if c.isDevelopmentNetwork() {
 		fmt.Println("⚠️  Using test private key for localhost development")
 		return c.TestPrivateKey
 	}
@@ -101,14 +218,18 @@ func (c *Config) GetPrivateKey() string {
 	return ""
 }
 
-// GetContractAddress returns the contract address to use, with fallback to test address for localhost
+// GetContractAddress returns the contract address to use: IUMICERT_CONTRACT_ADDRESS, then
+// the network's registry entry, with fallback to test address for localhost
 func (c *Config) GetContractAddress() string {
 	if c.ContractAddress != "" {
 		return c.ContractAddress
 	}
+	if entry, err := c.GetNetwork(c.Network); err == nil && entry.ContractAddress != "" {
+		return entry.ContractAddress
+	}
 	
 	// For localhost/testing, use the test contract address
-	if c.Network == "localhost" || c.Network == "local" {
+	if c.isDevelopmentNetwork() && !c.IsSimulated() {
 		fmt.Println("⚠️  Using test contract address for localhost development")
 		return c.TestContractAddress
 	}
@@ -116,15 +237,56 @@ func (c *Config) GetContractAddress() string {
 	return ""
 }
 
+// HasSigner reports whether an issuer signer is configured explicitly: a keystore, a
+// remote signer or ISSUER_PRIVATE_KEY. The localhost test key does not count.
+func (c *Config) HasSigner() bool {
+	switch c.SignerType {
+	case SignerKeystore, SignerRemote:
+		return true
+	default:
+		return c.IssuerPrivateKey != ""
+	}
+}
+
+// IsProduction reports whether the issuer runs in production (ENV=production)
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// IsSimulated reports whether the issuer publishes to the in-process simulated chain
+func (c *Config) IsSimulated() bool {
+	return c.Network == "simulated"
+}
+
 // Validate checks if all required configuration is present
 func (c *Config) Validate() error {
-	privateKey := c.GetPrivateKey()
-	if privateKey == "" {
-		return fmt.Errorf("private key is required. Set ISSUER_PRIVATE_KEY environment variable")
+	switch c.SignerType {
+	case SignerKey, "":
+		if c.IsProduction() {
+			return fmt.Errorf("raw private keys are refused in production. Set SIGNER=keystore or SIGNER=remote")
+		}
+		if c.GetPrivateKey() == "" {
+			return fmt.Errorf("private key is required. Set ISSUER_PRIVATE_KEY environment variable")
+		}
+	case SignerKeystore:
+		if c.KeystorePath == "" {
+			return fmt.Errorf("keystore is required. Set KEYSTORE_PATH environment variable")
+		}
+	case SignerRemote:
+		if c.RemoteSignerURL == "" || c.SignerAddress == "" {
+			return fmt.Errorf("remote signer is required. Set REMOTE_SIGNER_URL and SIGNER_ADDRESS environment variables")
+		}
+	case SignerOffline:
+		if c.SignerAddress == "" {
+			return fmt.Errorf("issuer account is required. Set SIGNER_ADDRESS environment variable")
+		}
+	default:
+		return fmt.Errorf("unsupported signer: %s", c.SignerType)
 	}
 	
+	// The simulated chain deploys its own registry
 	contractAddress := c.GetContractAddress()
-	if contractAddress == "" {
+	if contractAddress == "" && !c.IsSimulated() {
 		return fmt.Errorf("contract address is required. Set IUMICERT_CONTRACT_ADDRESS environment variable")
 	}
 	
@@ -197,8 +359,20 @@ func (c *Config) PrintConfig() {
 	fmt.Println("📋 Current Configuration:")
 	fmt.Printf("  Network: %s\n", c.Network)
 	fmt.Printf("  Contract Address: %s\n", maskSensitive(c.GetContractAddress()))
-	fmt.Printf("  Private Key: %s\n", maskPrivateKey(c.GetPrivateKey()))
+	switch c.SignerType {
+	case SignerKeystore:
+		fmt.Printf("  Signer: keystore %s\n", c.KeystorePath)
+	case SignerRemote:
+		fmt.Printf("  Signer: remote %s for %s\n", c.RemoteSignerURL, c.SignerAddress)
+	case SignerOffline:
+		fmt.Printf("  Signer: offline, transactions for %s are prepared unsigned\n", c.SignerAddress)
+	default:
+		fmt.Printf("  Private Key: %s\n", maskPrivateKey(c.GetPrivateKey()))
+	}
 	fmt.Printf("  Default Gas Limit: %d\n", c.DefaultGasLimit)
+	if c.MaxTxCost > 0 {
+		fmt.Printf("  Transaction Budget: %d wei\n", c.MaxTxCost)
+	}
 	fmt.Printf("  Debug Mode: %v\n", c.Debug)
 	fmt.Printf("  Log Level: %s\n", c.LogLevel)
 }
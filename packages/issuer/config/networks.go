@@ -0,0 +1,177 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// unsetRPCPlaceholder marks an RPC URL that was copied from an example without a key
+const unsetRPCPlaceholder = "YOUR_INFURA_KEY"
+
+// NetworkConfig is one chain the issuer can publish to, as defined in the network registry
+type NetworkConfig struct {
+	Name            string   `json:"-"`
+	Aliases         []string `json:"aliases,omitempty"`
+	RPCURLs         []string `json:"rpc_urls"`                   // Tried in order; ${VAR} is expanded and empty URLs are skipped
+	ChainID         uint64   `json:"chain_id"`                   // Must match eth_chainId of the RPC endpoint
+	ContractAddress string   `json:"contract_address,omitempty"` // IUMICERT_CONTRACT_ADDRESS overrides it
+	Confirmations   *uint64  `json:"confirmations,omitempty"`    // Overrides CONFIRMATION_BLOCKS
+	GasLimit        uint64   `json:"gas_limit,omitempty"`        // Overrides DEFAULT_GAS_LIMIT
+	MaxGasPrice     uint64   `json:"max_gas_price,omitempty"`    // Overrides MAX_GAS_PRICE
+	MaxTxCost       uint64   `json:"max_tx_cost,omitempty"`      // Overrides MAX_TX_COST
+	Development     bool     `json:"development,omitempty"`      // Allows the test key and contract fallback outside production
+}
+
+// ResolveRPCURLs returns the RPC endpoints of the network with environment variables
+// expanded. It fails when every URL is empty or still a placeholder, naming the variables
+// to set.
+func (n *NetworkConfig) ResolveRPCURLs() ([]string, error) {
+	var urls, missing []string
+	for _, raw := range n.RPCURLs {
+		url := os.Expand(raw, func(key string) string {
+			value := os.Getenv(key)
+			if value == "" {
+				missing = append(missing, key)
+			}
+			return value
+		})
+		if url == "" || strings.Contains(url, unsetRPCPlaceholder) {
+			continue
+		}
+		urls = append(urls, url)
+	}
+	if len(urls) == 0 {
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("no RPC URL for network %s, please set %s", n.Name, strings.Join(missing, " or "))
+		}
+		return nil, fmt.Errorf("no RPC URL for network %s", n.Name)
+	}
+	return urls, nil
+}
+
+// NetworkRegistry holds the networks defined in the network registry file
+type NetworkRegistry struct {
+	Networks map[string]*NetworkConfig `json:"networks"`
+
+	path    string
+	aliases map[string]string
+}
+
+// defaultNetworks is used when no registry file is found. It is the registry file shipped
+// with the issuer, which defines the networks supported before the registry existed.
+//
+//go:embed networks.json
+var defaultNetworks []byte
+
+// ParseNetworkRegistry parses a registry and checks that every network has an RPC URL and
+// a chain ID, and that names and aliases are unique
+func ParseNetworkRegistry(data []byte) (*NetworkRegistry, error) {
+	var registry NetworkRegistry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse network registry: %w", err)
+	}
+	if len(registry.Networks) == 0 {
+		return nil, fmt.Errorf("network registry defines no networks")
+	}
+
+	registry.aliases = make(map[string]string)
+	for name, network := range registry.Networks {
+		if network == nil {
+			return nil, fmt.Errorf("network %s is empty", name)
+		}
+		if name == "simulated" {
+			return nil, fmt.Errorf("network name simulated is reserved for the in-process chain")
+		}
+		if len(network.RPCURLs) == 0 {
+			return nil, fmt.Errorf("network %s has no rpc_urls", name)
+		}
+		if network.ChainID == 0 {
+			return nil, fmt.Errorf("network %s has no chain_id", name)
+		}
+		network.Name = name
+		for _, alias := range network.Aliases {
+			if _, taken := registry.Networks[alias]; taken {
+				return nil, fmt.Errorf("alias %s of network %s is also a network name", alias, name)
+			}
+			if other, taken := registry.aliases[alias]; taken {
+				return nil, fmt.Errorf("alias %s is used by networks %s and %s", alias, other, name)
+			}
+			registry.aliases[alias] = name
+		}
+	}
+	return &registry, nil
+}
+
+// LoadNetworkRegistry reads the registry file at path
+func LoadNetworkRegistry(path string) (*NetworkRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network registry: %w", err)
+	}
+	registry, err := ParseNetworkRegistry(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	registry.path = path
+	return registry, nil
+}
+
+var (
+	sharedRegistryOnce sync.Once
+	sharedRegistry     *NetworkRegistry
+	sharedRegistryErr  error
+)
+
+// SharedNetworkRegistry returns the registry used by every command and the API server.
+// It is read once per process from NETWORKS_FILE, or config/networks.json next to
+// micert.json; without either the built-in networks are used.
+func SharedNetworkRegistry() (*NetworkRegistry, error) {
+	sharedRegistryOnce.Do(func() {
+		if path := os.Getenv("NETWORKS_FILE"); path != "" {
+			sharedRegistry, sharedRegistryErr = LoadNetworkRegistry(path)
+			return
+		}
+		for _, path := range []string{"config/networks.json", "../config/networks.json"} {
+			if _, err := os.Stat(path); err == nil {
+				sharedRegistry, sharedRegistryErr = LoadNetworkRegistry(path)
+				return
+			}
+		}
+		sharedRegistry, sharedRegistryErr = ParseNetworkRegistry(defaultNetworks)
+	})
+	return sharedRegistry, sharedRegistryErr
+}
+
+// Lookup returns the network called name or one of its aliases
+func (r *NetworkRegistry) Lookup(name string) (*NetworkConfig, error) {
+	if network, ok := r.Networks[name]; ok {
+		return network, nil
+	}
+	if canonical, ok := r.aliases[name]; ok {
+		return r.Networks[canonical], nil
+	}
+	return nil, fmt.Errorf("unsupported network: %s (known: %s)", name, strings.Join(r.Names(), ", "))
+}
+
+// Names returns the names of the networks in the registry, sorted
+func (r *NetworkRegistry) Names() []string {
+	names := make([]string, 0, len(r.Networks))
+	for name := range r.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the file the registry was read from, or "built-in"
+func (r *NetworkRegistry) Source() string {
+	if r.path == "" {
+		return "built-in"
+	}
+	return r.path
+}
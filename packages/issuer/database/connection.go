@@ -77,6 +77,10 @@ func RunMigrations(db *gorm.DB) error {
 		&RevocationRequest{},
 		&TermRootVersion{},
 		&RevocationBatch{},
+		&ChainIndexerCheckpoint{},
+		&Job{},
+		&WebhookSubscription{},
+		&WebhookDelivery{},
 	)
 
 	if err != nil {
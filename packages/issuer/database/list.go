@@ -0,0 +1,373 @@
+package database
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Limits of a list page
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+var (
+	// ErrInvalidCursor is returned for a cursor that was not returned by the same list
+	// with the same sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort is returned for a sort field the list does not support
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrInvalidFilter is returned for a filter value the list does not know
+	ErrInvalidFilter = errors.New("invalid filter")
+)
+
+// ListOptions selects one page of a list. Lists are paged with a cursor rather than an
+// offset, so pages stay stable while rows are added and deep pages cost the same as the
+// first. Filters a list does not support are ignored.
+type ListOptions struct {
+	Limit  int    // DefaultListLimit when 0
+	Cursor string // NextCursor of the previous page, empty for the first page
+	Sort   string // Field to sort by, prefixed with - for descending; the list's default when empty
+
+	StudentID string
+	TermID    string
+	Status    string
+	From      *time.Time // Inclusive lower bound of the list's date field
+	To        *time.Time // Exclusive upper bound of the list's date field
+}
+
+// listCursor is the position after the last row of a page: its sort value and ID
+type listCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// listField is a field a list can be sorted by
+type listField[T any] struct {
+	column string
+	value  func(row *T) interface{}
+}
+
+// listSpec describes how a list is sorted and paged
+type listSpec[T any] struct {
+	fields      map[string]listField[T]
+	defaultSort string
+	id          func(row *T) uint
+}
+
+// SortFields returns the fields a list can be sorted by
+func (s listSpec[T]) SortFields() []string {
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// page runs query for one page of opts, ordered by the sort field and then ID, and returns
+// the rows with the cursor of the next page, empty on the last page
+func (s listSpec[T]) page(query *gorm.DB, opts ListOptions) ([]T, string, error) {
+	sortName := opts.Sort
+	if sortName == "" {
+		sortName = s.defaultSort
+	}
+	desc := strings.HasPrefix(sortName, "-")
+	field, ok := s.fields[strings.TrimPrefix(sortName, "-")]
+	if !ok {
+		return nil, "", fmt.Errorf("%w %q, expected one of %s (prefix - for descending)", ErrInvalidSort, sortName, strings.Join(s.SortFields(), ", "))
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	direction, compare := "ASC", ">"
+	if desc {
+		direction, compare = "DESC", "<"
+	}
+	if opts.Cursor != "" {
+		value, id, err := s.decodeCursor(opts.Cursor, sortName, field)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", field.column, compare), value, id)
+	}
+
+	var rows []T
+	err := query.Order(fmt.Sprintf("%s %s, id %s", field.column, direction, direction)).
+		Limit(limit + 1).
+		Find(&rows).Error
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rows) <= limit {
+		return rows, "", nil
+	}
+
+	rows = rows[:limit]
+	last := &rows[limit-1]
+	value, err := json.Marshal(field.value(last))
+	if err != nil {
+		return nil, "", err
+	}
+	next, err := json.Marshal(listCursor{Sort: sortName, Value: value, ID: s.id(last)})
+	if err != nil {
+		return nil, "", err
+	}
+	return rows, base64.RawURLEncoding.EncodeToString(next), nil
+}
+
+// decodeCursor returns the sort value and ID a cursor points after, typed like the field
+func (s listSpec[T]) decodeCursor(encoded, sortName string, field listField[T]) (interface{}, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.Sort != sortName {
+		return nil, 0, ErrInvalidCursor
+	}
+	value := reflect.New(reflect.TypeOf(field.value(new(T))))
+	if err := json.Unmarshal(cursor.Value, value.Interface()); err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	return value.Elem().Interface(), cursor.ID, nil
+}
+
+// dateRange filters query to rows whose column lies in the range of opts
+func dateRange(query *gorm.DB, column string, opts ListOptions) *gorm.DB {
+	if opts.From != nil {
+		query = query.Where(column+" >= ?", *opts.From)
+	}
+	if opts.To != nil {
+		query = query.Where(column+" < ?", *opts.To)
+	}
+	return query
+}
+
+// ========== LIST QUERIES ==========
+
+var studentList = listSpec[Student]{
+	fields: map[string]listField[Student]{
+		"student_id":      {"student_id", func(s *Student) interface{} { return s.StudentID }},
+		"name":            {"name", func(s *Student) interface{} { return s.Name }},
+		"enrollment_date": {"enrollment_date", func(s *Student) interface{} { return s.EnrollmentDate }},
+		"created_at":      {"created_at", func(s *Student) interface{} { return s.CreatedAt }},
+	},
+	defaultSort: "student_id",
+	id:          func(s *Student) uint { return s.ID },
+}
+
+// ListStudents gets a page of students, filtered by status, student ID prefix and
+// enrollment date
+func (r *ReceiptRepository) ListStudents(opts ListOptions) ([]Student, string, error) {
+	query := r.db.Model(&Student{})
+	if opts.StudentID != "" {
+		query = query.Where(`student_id LIKE ? ESCAPE '\'`, escapeLike(opts.StudentID)+"%")
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+	return studentList.page(dateRange(query, "enrollment_date", opts), opts)
+}
+
+// Term statuses derived by ListTerms
+const (
+	TermPending   = "pending"   // No root built yet
+	TermCompleted = "completed" // Root built, not published
+	TermPublished = "published" // Root published on chain
+)
+
+var termList = listSpec[Term]{
+	fields: map[string]listField[Term]{
+		"term_id":    {"term_id", func(t *Term) interface{} { return t.TermID }},
+		"start_date": {"start_date", func(t *Term) interface{} { return t.StartDate }},
+		"created_at": {"created_at", func(t *Term) interface{} { return t.CreatedAt }},
+	},
+	defaultSort: "start_date",
+	id:          func(t *Term) uint { return t.ID },
+}
+
+// termPublishedCondition holds for terms with a root version recorded on chain
+const termPublishedCondition = "(terms.published_at IS NOT NULL OR EXISTS (SELECT 1 FROM term_root_versions v WHERE v.term_id = terms.term_id AND v.tx_hash <> '' AND NOT v.reorged))"
+
+// TermSummary is a term with the figures the term list shows
+type TermSummary struct {
+	Term
+	Status       string
+	StudentCount int
+	CourseCount  int // Course completions over all students
+}
+
+// ListTerms gets a page of terms, filtered by status and start date, with their receipt
+// counts
+func (r *ReceiptRepository) ListTerms(opts ListOptions) ([]TermSummary, string, error) {
+	query := r.db.Model(&Term{})
+	if opts.TermID != "" {
+		query = query.Where(`term_id LIKE ? ESCAPE '\'`, escapeLike(opts.TermID)+"%")
+	}
+	switch opts.Status {
+	case "":
+	case TermPending:
+		query = query.Where("verkle_root_hex = ''")
+	case TermCompleted:
+		query = query.Where("verkle_root_hex <> '' AND NOT " + termPublishedCondition)
+	case TermPublished:
+		query = query.Where(termPublishedCondition)
+	default:
+		return nil, "", fmt.Errorf("%w: unknown term status %q, expected %s, %s or %s", ErrInvalidFilter, opts.Status, TermPending, TermCompleted, TermPublished)
+	}
+
+	terms, next, err := termList.page(dateRange(query, "start_date", opts), opts)
+	if err != nil || len(terms) == 0 {
+		return nil, next, err
+	}
+
+	termIDs := make([]string, len(terms))
+	for i, term := range terms {
+		termIDs[i] = term.TermID
+	}
+	var counts []struct {
+		TermID       string
+		StudentCount int
+		CourseCount  int
+	}
+	err = r.db.Model(&TermReceipt{}).
+		Select("term_id, COUNT(*) AS student_count, COALESCE(SUM(course_count), 0) AS course_count").
+		Where("term_id IN ?", termIDs).
+		Group("term_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, "", err
+	}
+	var published []string
+	err = r.db.Model(&Term{}).Where("term_id IN ? AND "+termPublishedCondition, termIDs).Pluck("term_id", &published).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	summaries := make([]TermSummary, len(terms))
+	for i, term := range terms {
+		summaries[i] = TermSummary{Term: term, Status: TermPending}
+		if term.VerkleRootHex != "" {
+			summaries[i].Status = TermCompleted
+		}
+		for _, termID := range published {
+			if termID == term.TermID {
+				summaries[i].Status = TermPublished
+			}
+		}
+		for _, count := range counts {
+			if count.TermID == term.TermID {
+				summaries[i].StudentCount, summaries[i].CourseCount = count.StudentCount, count.CourseCount
+			}
+		}
+	}
+	return summaries, next, nil
+}
+
+var termReceiptList = listSpec[TermReceipt]{
+	fields: map[string]listField[TermReceipt]{
+		"generated_at": {"generated_at", func(t *TermReceipt) interface{} { return t.GeneratedAt }},
+		"student_id":   {"student_id", func(t *TermReceipt) interface{} { return t.StudentID }},
+		"term_id":      {"term_id", func(t *TermReceipt) interface{} { return t.TermID }},
+	},
+	defaultSort: "-generated_at",
+	id:          func(t *TermReceipt) uint { return t.ID },
+}
+
+// Receipt statuses ListTermReceipts filters by
+const (
+	ReceiptPublished   = "published"   // Term root is published on chain
+	ReceiptUnpublished = "unpublished" // Term root is not published yet
+)
+
+// ListTermReceipts gets a page of term receipts without their proofs, filtered by
+// student, term, blockchain status and generation date
+func (r *ReceiptRepository) ListTermReceipts(opts ListOptions) ([]TermReceipt, string, error) {
+	query := r.db.Model(&TermReceipt{}).Omit("verkle_proof", "state_diff", "revealed_courses")
+	if opts.StudentID != "" {
+		query = query.Where("student_id = ?", opts.StudentID)
+	}
+	if opts.TermID != "" {
+		query = query.Where("term_id = ?", opts.TermID)
+	}
+	switch opts.Status {
+	case "":
+	case ReceiptPublished:
+		query = query.Where("blockchain_verified = ?", true)
+	case ReceiptUnpublished:
+		query = query.Where("blockchain_verified IS NOT TRUE")
+	default:
+		return nil, "", fmt.Errorf("%w: unknown receipt status %q, expected %s or %s", ErrInvalidFilter, opts.Status, ReceiptPublished, ReceiptUnpublished)
+	}
+	return termReceiptList.page(dateRange(query, "generated_at", opts), opts)
+}
+
+var transactionList = listSpec[BlockchainTransaction]{
+	fields: map[string]listField[BlockchainTransaction]{
+		"submitted_at": {"submitted_at", func(t *BlockchainTransaction) interface{} { return t.SubmittedAt }},
+		"block_number": {"block_number", func(t *BlockchainTransaction) interface{} { return t.BlockNumber }},
+		"nonce":        {"nonce", func(t *BlockchainTransaction) interface{} { return t.Nonce }},
+	},
+	defaultSort: "-submitted_at",
+	id:          func(t *BlockchainTransaction) uint { return t.ID },
+}
+
+// ListTransactions gets a page of transaction attempts, filtered by term, status and
+// submission date
+func (r *ReceiptRepository) ListTransactions(opts ListOptions) ([]BlockchainTransaction, string, error) {
+	query := r.db.Model(&BlockchainTransaction{})
+	if opts.TermID != "" {
+		query = query.Where("term_id = ?", opts.TermID)
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+	return transactionList.page(dateRange(query, "submitted_at", opts), opts)
+}
+
+var revocationList = listSpec[RevocationRequest]{
+	fields: map[string]listField[RevocationRequest]{
+		"created_at": {"created_at", func(r *RevocationRequest) interface{} { return r.CreatedAt }},
+		"student_id": {"student_id", func(r *RevocationRequest) interface{} { return r.StudentID }},
+		"term_id":    {"term_id", func(r *RevocationRequest) interface{} { return r.TermID }},
+	},
+	defaultSort: "-created_at",
+	id:          func(r *RevocationRequest) uint { return r.ID },
+}
+
+// ListRevocationRequests gets a page of revocation requests, filtered by student, term,
+// status and request date
+func (r *ReceiptRepository) ListRevocationRequests(opts ListOptions) ([]RevocationRequest, string, error) {
+	query := r.db.Model(&RevocationRequest{})
+	if opts.StudentID != "" {
+		query = query.Where("student_id = ?", opts.StudentID)
+	}
+	if opts.TermID != "" {
+		query = query.Where("term_id = ?", opts.TermID)
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+	return revocationList.page(dateRange(query, "created_at", opts), opts)
+}
+
+// escapeLike escapes the LIKE wildcards in a prefix
+func escapeLike(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+}
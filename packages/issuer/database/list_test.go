@@ -0,0 +1,219 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newListTestRepository returns a repository on an in-memory database
+func newListTestRepository(t *testing.T) *ReceiptRepository {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&Student{}, &Term{}, &TermReceipt{}, &BlockchainTransaction{}, &RevocationRequest{}, &TermRootVersion{}, &VerificationLog{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	return NewReceiptRepository(db)
+}
+
+// TestListPagination walks lists page by page with filters and sorts
+func TestListPagination(t *testing.T) {
+	repo := newListTestRepository(t)
+	enrolled := time.Date(2023, 8, 15, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 7; i++ {
+		status := "active"
+		if i%3 == 0 {
+			status = "graduated"
+		}
+		// Two students share each enrollment date, so pages split ties by ID
+		repo.CreateStudent(&Student{
+			StudentID:      fmt.Sprintf("ITITIU%05d", i),
+			Name:           fmt.Sprintf("Student %d", 8-i),
+			EnrollmentDate: enrolled.AddDate(0, 0, i/2),
+			Status:         status,
+		})
+	}
+	repo.CreateStudent(&Student{StudentID: "ITITIU_1", EnrollmentDate: enrolled, Status: "active"})
+
+	var seen []string
+	opts := ListOptions{Limit: 3, Sort: "-enrollment_date"}
+	for page := 0; ; page++ {
+		students, next, err := repo.ListStudents(opts)
+		if err != nil {
+			t.Fatalf("Page %d failed: %v", page, err)
+		}
+		if len(students) > 3 {
+			t.Fatalf("Page %d has %d students", page, len(students))
+		}
+		for i, student := range students {
+			if len(seen) > 0 && i == 0 && student.StudentID == seen[len(seen)-1] {
+				t.Fatalf("Page %d repeats %s", page, student.StudentID)
+			}
+			seen = append(seen, student.StudentID)
+		}
+		if next == "" {
+			break
+		}
+		opts.Cursor = next
+	}
+	if len(seen) != 8 || seen[0] != "ITITIU00007" {
+		t.Fatalf("Walked %v", seen)
+	}
+	unique := map[string]bool{}
+	for _, id := range seen {
+		unique[id] = true
+	}
+	if len(unique) != 8 {
+		t.Fatalf("Pages overlap: %v", seen)
+	}
+
+	// The underscore of the prefix is not a wildcard
+	students, _, err := repo.ListStudents(ListOptions{StudentID: "ITITIU_"})
+	if err != nil || len(students) != 1 {
+		t.Fatalf("Prefix ITITIU_ matched %d students (%v)", len(students), err)
+	}
+	to := enrolled.AddDate(0, 0, 2)
+	students, _, err = repo.ListStudents(ListOptions{Status: "active", From: &enrolled, To: &to, Sort: "name"})
+	if err != nil || len(students) != 3 || students[0].Name != "" || students[1].Name != "Student 6" {
+		t.Fatalf("Active students enrolled in the first two days: %+v (%v)", students, err)
+	}
+
+	if _, _, err := repo.ListStudents(ListOptions{Sort: "gpa"}); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("Unknown sort returned %v", err)
+	}
+	first, next, _ := repo.ListStudents(ListOptions{Limit: 2})
+	if _, _, err := repo.ListStudents(ListOptions{Limit: 2, Cursor: next, Sort: "-student_id"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("Cursor of another sort returned %v", err)
+	}
+	if _, _, err := repo.ListStudents(ListOptions{Cursor: "not-a-cursor"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("Malformed cursor returned %v", err)
+	}
+	second, _, _ := repo.ListStudents(ListOptions{Limit: 2, Cursor: next})
+	if first[1].StudentID != "ITITIU00002" || second[0].StudentID != "ITITIU00003" {
+		t.Fatalf("Pages by student ID: %s then %s", first[1].StudentID, second[0].StudentID)
+	}
+	t.Logf("✅ Walked %d students in pages of 3", len(seen))
+}
+
+// TestListTermsAndReceipts checks the derived term status and counts and the receipt filters
+func TestListTermsAndReceipts(t *testing.T) {
+	repo := newListTestRepository(t)
+	start := time.Date(2023, 8, 15, 0, 0, 0, 0, time.UTC)
+	repo.CreateTerm(&Term{TermID: "Semester_1_2023", StartDate: start, VerkleRootHex: "0xaa"})
+	repo.CreateTerm(&Term{TermID: "Semester_2_2023", StartDate: start.AddDate(0, 5, 0), VerkleRootHex: "0xbb"})
+	repo.CreateTerm(&Term{TermID: "Summer_2024", StartDate: start.AddDate(0, 9, 0)})
+	repo.db.Create(&TermRootVersion{TermID: "Semester_1_2023", Version: 1, RootHash: "0xaa", TxHash: "0x01"})
+
+	verified := true
+	for i, termID := range []string{"Semester_1_2023", "Semester_1_2023", "Semester_2_2023"} {
+		receipt := &TermReceipt{
+			ReceiptID:       fmt.Sprintf("receipt_%d", i),
+			StudentID:       fmt.Sprintf("ITITIU%05d", i),
+			TermID:          termID,
+			VerkleProof:     []byte(`{}`),
+			StateDiff:       []byte(`[]`),
+			RevealedCourses: []byte(`[]`),
+			CourseCount:     4 + i,
+			GeneratedAt:     start.Add(time.Duration(i) * time.Hour),
+		}
+		if termID == "Semester_1_2023" {
+			receipt.BlockchainVerified = &verified
+		}
+		if err := repo.StoreTermReceipt(receipt); err != nil {
+			t.Fatalf("Failed to store receipt: %v", err)
+		}
+	}
+
+	terms, _, err := repo.ListTerms(ListOptions{})
+	if err != nil || len(terms) != 3 {
+		t.Fatalf("Listed %d terms (%v)", len(terms), err)
+	}
+	if terms[0].Status != TermPublished || terms[0].StudentCount != 2 || terms[0].CourseCount != 9 ||
+		terms[1].Status != TermCompleted || terms[2].Status != TermPending || terms[2].StudentCount != 0 {
+		t.Fatalf("Unexpected term summaries: %+v", terms)
+	}
+	completed, _, err := repo.ListTerms(ListOptions{Status: TermCompleted})
+	if err != nil || len(completed) != 1 || completed[0].TermID != "Semester_2_2023" {
+		t.Fatalf("Completed terms: %+v (%v)", completed, err)
+	}
+	if _, _, err := repo.ListTerms(ListOptions{Status: "archived"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("Unknown term status returned %v", err)
+	}
+
+	receipts, next, err := repo.ListTermReceipts(ListOptions{Status: ReceiptPublished, Limit: 1})
+	if err != nil || len(receipts) != 1 || receipts[0].ReceiptID != "receipt_1" || next == "" {
+		t.Fatalf("Newest published receipt: %+v (%v)", receipts, err)
+	}
+	if len(receipts[0].VerkleProof) != 0 {
+		t.Fatal("Receipt list loaded the proofs")
+	}
+	receipts, next, _ = repo.ListTermReceipts(ListOptions{Status: ReceiptPublished, Limit: 1, Cursor: next})
+	if len(receipts) != 1 || receipts[0].ReceiptID != "receipt_0" || next != "" {
+		t.Fatalf("Second page of published receipts: %+v", receipts)
+	}
+	unpublished, _, _ := repo.ListTermReceipts(ListOptions{Status: ReceiptUnpublished, TermID: "Semester_2_2023"})
+	if len(unpublished) != 1 || unpublished[0].StudentID != "ITITIU00002" {
+		t.Fatalf("Unpublished receipts: %+v", unpublished)
+	}
+	t.Logf("✅ %d terms summarised, receipts paged by generation time", len(terms))
+}
+
+// TestVerificationStats lists a student's verifications and summarises them apart from lookups
+func TestVerificationStats(t *testing.T) {
+	repo := newListTestRepository(t)
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	logs := []VerificationLog{
+		{ReceiptID: "jr_a", StudentID: "ITITIU00001", VerifierID: "hr@acme", VerificationMode: "full_ipa", Success: true, VerifiedAt: day},
+		{ReceiptID: "jr_a", StudentID: "ITITIU00001", VerifierID: "hr@acme", VerificationMode: "local", Success: true, VerifiedAt: day.Add(time.Hour)},
+		{ReceiptID: "jr_b", StudentID: "ITITIU00001", TermID: "Semester_1_2023", VerificationMode: "blockchain", ErrorMessage: "root superseded", VerifiedAt: day.AddDate(0, 0, 1)},
+		{ReceiptID: "jr_c", StudentID: "ITITIU00002", VerifierID: "admissions@hcmut", VerificationMode: "full_ipa", ErrorMessage: "root superseded", VerifiedAt: day.AddDate(0, 0, 1)},
+		{ReceiptID: "ITITIU00001", StudentID: "ITITIU00001", VerificationMode: VerificationModeLookup, Success: true, VerifiedAt: day},
+	}
+	for i := range logs {
+		if err := repo.LogVerification(&logs[i]); err != nil {
+			t.Fatalf("Failed to log verification: %v", err)
+		}
+	}
+
+	mine, next, err := repo.ListVerifications(ListOptions{StudentID: "ITITIU00001", Limit: 3})
+	if err != nil || len(mine) != 3 || next == "" || mine[0].ReceiptID != "jr_b" {
+		t.Fatalf("First page of ITITIU00001: %+v (%v)", mine, err)
+	}
+	failed, _, _ := repo.ListVerifications(ListOptions{Status: VerificationFailed})
+	if len(failed) != 2 {
+		t.Fatalf("Listed %d failed verifications", len(failed))
+	}
+
+	stats, err := repo.VerificationStats(ListOptions{})
+	if err != nil {
+		t.Fatalf("Failed to summarise verifications: %v", err)
+	}
+	if stats.Total != 4 || stats.Succeeded != 2 || stats.Failed != 2 || stats.SuccessRate != 0.5 || stats.Lookups != 1 ||
+		stats.UniqueReceipts != 3 || stats.UniqueStudents != 2 || stats.UniqueVerifiers != 2 {
+		t.Fatalf("Unexpected totals: %+v", stats)
+	}
+	if len(stats.ByDay) != 2 || stats.ByDay[0].Key != "2024-03-01" || stats.ByDay[1].Total != 2 {
+		t.Fatalf("Unexpected days: %+v", stats.ByDay)
+	}
+	if stats.ByMode[0].Key != "full_ipa" || stats.ByMode[0].Succeeded != 1 || stats.TopVerifiers[0].Key != "hr@acme" ||
+		len(stats.TopErrors) != 1 || stats.TopErrors[0].Total != 2 {
+		t.Fatalf("Unexpected breakdown: %+v", stats)
+	}
+
+	student, _ := repo.VerificationStats(ListOptions{StudentID: "ITITIU00002"})
+	if student.Total != 1 || student.Succeeded != 0 || student.Lookups != 0 {
+		t.Fatalf("Totals of ITITIU00002: %+v", student)
+	}
+	if _, err := repo.VerificationStats(ListOptions{Status: "pending"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("Unknown outcome returned %v", err)
+	}
+	t.Logf("✅ %d verifications summarised over %d days", stats.Total, len(stats.ByDay))
+}
@@ -24,7 +24,7 @@ type Student struct {
 type Term struct {
 	ID               uint      `gorm:"primaryKey"`
 	TermID           string    `gorm:"uniqueIndex;not null;size:50"` // Semester_1_2023
-	StartDate        time.Time
+	StartDate        time.Time `gorm:"index"`
 	EndDate          time.Time
 	VerkleRootHex    string    `gorm:"index;size:64"` // Hex string for indexing
 	VerkleRootBytes  []byte    `gorm:"type:bytea"`    // Binary for verification
@@ -104,9 +104,12 @@ type AccumulatedReceipt struct {
 type VerificationLog struct {
 	ID               uint   `gorm:"primaryKey"`
 	ReceiptID        string `gorm:"index;not null;size:255"` // Can be TermReceipt or AccumulatedReceipt
-	ReceiptType      string `gorm:"size:50"`                 // "term", "accumulated"
-	VerifierID       string `gorm:"size:255"`                // Who verified (employer, university, etc.)
-	VerificationMode string `gorm:"size:50"`                 // "local", "blockchain", "full_ipa"
+	ReceiptType      string `gorm:"size:50"`                 // "term", "accumulated", "journey", "absence"
+	StudentID        string `gorm:"index;size:50"`           // Student the receipt belongs to
+	TermID           string `gorm:"size:50"`                 // Set when a single term was checked
+	Endpoint         string `gorm:"size:100"`                // Verifier API route that was called
+	VerifierID       string `gorm:"index;size:255"`          // Who verified (employer, university, etc.)
+	VerificationMode string `gorm:"size:50"`                 // "local", "blockchain", "full_ipa", "lookup"
 	Success          bool
 	ErrorMessage     string `gorm:"type:text"`
 	VerifiedAt       time.Time `gorm:"index"`
@@ -124,10 +127,19 @@ type BlockchainTransaction struct {
 	VerkleRoot  []byte `gorm:"type:bytea"`
 	BlockNumber uint64 `gorm:"index"`
 	GasUsed     uint64
-	Status      string    `gorm:"size:50"` // "pending", "confirmed", "failed"
-	SubmittedAt time.Time
+	Status      string    `gorm:"index;size:50"` // "pending", "replaced", "mined", "confirmed", "failed", "reorged"
+	SubmittedAt time.Time `gorm:"index"`
 	ConfirmedAt *time.Time
 
+	// Attempt tracking; a stuck transaction is replaced by one with the same nonce and a higher fee
+	FromAddress string `gorm:"index;size:42"`
+	Nonce       uint64 `gorm:"index"`
+	Attempt     int    // 0 for the first broadcast
+	GasPrice    string `gorm:"size:78"` // Max fee per gas in wei, decimal; gas price of legacy transactions
+	GasTipCap   string `gorm:"size:78"` // Max priority fee per gas in wei, empty for legacy transactions
+	ReplacedBy  string `gorm:"size:66"` // Hash of the attempt that replaced this one
+	InitiatedBy string `gorm:"size:255"` // Principal or CLI operator that requested the publication
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -159,7 +171,7 @@ type RevocationRequest struct {
 	RejectedAt *time.Time
 	Notes      string     `gorm:"type:text"`
 
-	CreatedAt time.Time
+	CreatedAt time.Time `gorm:"index"`
 	UpdatedAt time.Time
 }
 
@@ -180,6 +192,8 @@ type TermRootVersion struct {
 	// Blockchain
 	TxHash      string `gorm:"index;size:66"`
 	BlockNumber uint64
+	Reorged     bool `gorm:"default:false;index"` // Publishing block was reorged out and the tx is not back on chain
+	PublishedBy string `gorm:"size:255"` // Principal or CLI operator that published this version
 
 	// Change Summary (for revocations)
 	CredentialsRevoked uint `gorm:"default:0"` // Number of credentials removed in this version
@@ -190,6 +204,16 @@ type TermRootVersion struct {
 	UpdatedAt time.Time
 }
 
+// ChainIndexerCheckpoint records the last registry block the chain indexer has synced
+type ChainIndexerCheckpoint struct {
+	ID              uint   `gorm:"primaryKey"`
+	ContractAddress string `gorm:"uniqueIndex;not null;size:42"` // Registry being indexed
+	LastBlock       uint64 `gorm:"not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 // RevocationBatch represents a batch of revocations processed together
 type RevocationBatch struct {
 	ID      uint   `gorm:"primaryKey"`
@@ -214,6 +238,71 @@ type RevocationBatch struct {
 	Status string `gorm:"size:50"` // "success", "failed", "partial"
 	Notes  string `gorm:"type:text"`
 
+	// Cryptographic link between OldRootHash and NewRootHash (verkle.RevocationDeltaProof)
+	DeltaProof datatypes.JSON `gorm:"type:jsonb"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Job is a long-running issuer operation run by the job queue of the API server
+type Job struct {
+	ID    uint   `gorm:"primaryKey"`
+	JobID string `gorm:"uniqueIndex;not null;size:255"` // job_UUID
+
+	Kind   string `gorm:"index;not null;size:50"` // publish-roots, process-revocations, demo-generate-full
+	Status string `gorm:"index;not null;size:20"` // queued, running, succeeded, failed, interrupted
+
+	Params datatypes.JSON `gorm:"type:jsonb"` // Request the job was created from
+	Steps  datatypes.JSON `gorm:"type:jsonb"` // Progress, one entry per step
+	Result datatypes.JSON `gorm:"type:jsonb"`
+	Error  string         `gorm:"type:text"`
+
+	RequestedBy string `gorm:"size:255"` // Principal that started the job
+	Attempts    int    // Runs so far; more than one when the job was resumed after a restart
+
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// WebhookSubscription is an endpoint notified of credential lifecycle events
+type WebhookSubscription struct {
+	ID             uint   `gorm:"primaryKey"`
+	SubscriptionID string `gorm:"uniqueIndex;not null;size:255"` // whk_UUID
+
+	URL         string `gorm:"not null;size:2048"`
+	Events      string `gorm:"not null;size:255"` // Comma-separated event types, * for all
+	Secret      string `gorm:"not null;size:255"` // HMAC-SHA256 key the payloads are signed with
+	Description string `gorm:"type:text"`
+	Active      bool   `gorm:"index;default:true"`
+
+	CreatedBy string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is one event sent to a subscription, with the outcome of its last attempt
+type WebhookDelivery struct {
+	ID             uint   `gorm:"primaryKey"`
+	DeliveryID     string `gorm:"uniqueIndex;not null;size:255"` // dlv_UUID
+	SubscriptionID string `gorm:"index;not null;size:255"`
+
+	EventID   string         `gorm:"index;not null;size:255"` // Shared by the deliveries of one event
+	EventType string         `gorm:"index;not null;size:100"`
+	Payload   datatypes.JSON `gorm:"type:jsonb"` // Exact body that is signed and sent
+
+	Status        string `gorm:"index;not null;size:20"` // pending, delivered, failed
+	Attempts      int
+	NextAttemptAt *time.Time `gorm:"index"` // nil once delivered or failed
+	LastAttemptAt *time.Time
+	DeliveredAt   *time.Time
+
+	ResponseStatus int
+	ResponseBody   string `gorm:"type:text"` // Truncated
+	Error          string `gorm:"type:text"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -2,11 +2,13 @@ package database
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ReceiptRepository struct {
@@ -325,17 +327,42 @@ func GetAllRevocationRequests(db *gorm.DB, termID string, status string) ([]Revo
 	return requests, err
 }
 
-// UpdateRevocationStatus updates the status of a revocation request
-func UpdateRevocationStatus(db *gorm.DB, requestID string, status string, processedBy string) error {
-	updates := map[string]interface{}{
-		"status":       status,
-		"approved_by":  processedBy,
-		"approved_at":  time.Now(),
+// ErrRevocationNotPending is returned when a revocation request that is not pending is
+// approved or rejected
+var ErrRevocationNotPending = errors.New("revocation request is not pending")
+
+// UpdateRevocationStatus approves or rejects a pending revocation request, recording who
+// decided. It returns ErrRevocationNotPending when the request is missing or was decided.
+func UpdateRevocationStatus(db *gorm.DB, requestID string, status string, decidedBy string) error {
+	now := time.Now()
+	var updates map[string]interface{}
+	switch status {
+	case "approved":
+		updates = map[string]interface{}{
+			"status":      status,
+			"approved_by": decidedBy,
+			"approved_at": &now,
+		}
+	case "rejected":
+		updates = map[string]interface{}{
+			"status":      status,
+			"rejected_by": decidedBy,
+			"rejected_at": &now,
+		}
+	default:
+		return fmt.Errorf("cannot set revocation request status to %s", status)
 	}
 	
-	return db.Model(&RevocationRequest{}).
-		Where("request_id = ?", requestID).
-		Updates(updates).Error
+	result := db.Model(&RevocationRequest{}).
+		Where("request_id = ? AND status = ?", requestID, "pending").
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRevocationNotPending
+	}
+	return nil
 }
 
 // MarkRevocationProcessed marks revocations as processed after superseding
@@ -353,16 +380,22 @@ func MarkRevocationProcessed(db *gorm.DB, requestIDs []string, txHash string, ve
 		Updates(updates).Error
 }
 
-// CreateTermRootVersion records a new term root version
+// CreateTermRootVersion records a new term root version.
+// If the chain indexer already recorded the root from its event, the change summary is
+// added to that row instead.
 func CreateTermRootVersion(db *gorm.DB, version *TermRootVersion) error {
-	return db.Create(version).Error
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "root_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"credentials_revoked", "credentials_added", "change_description", "published_by", "updated_at"}),
+	}).Create(version).Error
 }
 
-// GetLatestTermVersion gets the latest version for a term
+// GetLatestTermVersion gets the latest version for a term, skipping versions whose
+// publication was reorged out
 // Returns nil, nil if no version exists (not an error)
 func GetLatestTermVersion(db *gorm.DB, termID string) (*TermRootVersion, error) {
 	var version TermRootVersion
-	err := db.Where("term_id = ?", termID).
+	err := db.Where("term_id = ? AND reorged = ?", termID, false).
 		Order("version DESC").
 		First(&version).Error
 
@@ -375,6 +408,20 @@ func GetLatestTermVersion(db *gorm.DB, termID string) (*TermRootVersion, error)
 	return &version, nil
 }
 
+// GetTermRootVersionByHash gets the version with the given root hash (0x + 64 hex chars)
+// Returns nil, nil if the root is not recorded
+func GetTermRootVersionByHash(db *gorm.DB, rootHash string) (*TermRootVersion, error) {
+	var version TermRootVersion
+	err := db.Where("root_hash = ?", rootHash).First(&version).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &version, nil
+}
+
 // GetTermVersionHistory gets all versions for a term
 func GetTermVersionHistory(db *gorm.DB, termID string) ([]TermRootVersion, error) {
 	var versions []TermRootVersion
@@ -411,6 +458,16 @@ func GetRevocationBatchHistory(db *gorm.DB, termID string) ([]RevocationBatch, e
 	return batches, err
 }
 
+// GetRevocationBatch gets a single revocation batch by its batch ID
+func GetRevocationBatch(db *gorm.DB, batchID string) (*RevocationBatch, error) {
+	var batch RevocationBatch
+	err := db.Where("batch_id = ?", batchID).First(&batch).Error
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
 // GetRevocationStats returns statistics about revocations
 func GetRevocationStats(db *gorm.DB) (map[string]interface{}, error) {
 	var pending, approved, processed, rejected int64
@@ -433,3 +490,269 @@ func GetRevocationStats(db *gorm.DB) (map[string]interface{}, error) {
 	
 	return stats, nil
 }
+
+// ========== CHAIN INDEXER ==========
+
+// GetIndexerCheckpoint gets the checkpoint of the indexer for a registry
+// Returns nil, nil if the registry has not been indexed yet
+func GetIndexerCheckpoint(db *gorm.DB, contractAddress string) (*ChainIndexerCheckpoint, error) {
+	var checkpoint ChainIndexerCheckpoint
+	err := db.Where("contract_address = ?", contractAddress).First(&checkpoint).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveIndexerCheckpoint stores the last block synced for a registry
+func SaveIndexerCheckpoint(db *gorm.DB, contractAddress string, lastBlock uint64) error {
+	checkpoint := &ChainIndexerCheckpoint{
+		ContractAddress: contractAddress,
+		LastBlock:       lastBlock,
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "contract_address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"last_block", "updated_at"}),
+	}).Create(checkpoint).Error
+}
+
+// UpsertTermRootVersionFromChain records a term root version seen on chain. Fields that only
+// the issuer knows (change summary, supersession) are left untouched on an existing row.
+func UpsertTermRootVersionFromChain(db *gorm.DB, version *TermRootVersion) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "root_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"term_id", "version", "total_students", "published_at", "tx_hash", "block_number", "reorged", "updated_at"}),
+	}).Create(version).Error
+}
+
+// MarkRootSuperseded marks the version with the given root hash as superseded
+func MarkRootSuperseded(db *gorm.DB, oldRootHash string, newRootHash string, reason string) error {
+	updates := map[string]interface{}{
+		"is_superseded":       true,
+		"superseded_by":       newRootHash,
+		"supersession_reason": reason,
+	}
+
+	return db.Model(&TermRootVersion{}).
+		Where("root_hash = ?", oldRootHash).
+		Updates(updates).Error
+}
+
+// UpsertBlockchainTransaction records a mined transaction, confirming an existing pending one
+func UpsertBlockchainTransaction(db *gorm.DB, transaction *BlockchainTransaction) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"term_id", "verkle_root", "block_number", "gas_used", "status", "confirmed_at", "updated_at"}),
+	}).Create(transaction).Error
+}
+
+// SaveTransactionAttempt records a transaction attempt or updates its state
+func SaveTransactionAttempt(db *gorm.DB, transaction *BlockchainTransaction) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"status", "replaced_by", "block_number", "gas_used", "confirmed_at", "updated_at"}),
+	}).Create(transaction).Error
+}
+
+// ========== REORG WATCHER ==========
+
+// GetWatchedTransactions gets the confirmed and reorged transactions mined at or after
+// fromBlock, the ones a reorg can still affect
+func GetWatchedTransactions(db *gorm.DB, fromBlock uint64) ([]BlockchainTransaction, error) {
+	var transactions []BlockchainTransaction
+	err := db.Where("status IN ? AND block_number >= ?", []string{"confirmed", "reorged"}, fromBlock).
+		Order("block_number ASC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// MarkTransactionReorged flags a transaction whose block left the canonical chain. The term
+// root versions it published are flagged, the versions they superseded count as current
+// again and term receipts anchored to it are no longer blockchain verified.
+func MarkTransactionReorged(db *gorm.DB, txHash string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&BlockchainTransaction{}).
+			Where("tx_hash = ?", txHash).
+			Update("status", "reorged").Error; err != nil {
+			return err
+		}
+
+		var roots []string
+		if err := tx.Model(&TermRootVersion{}).
+			Where("tx_hash = ?", txHash).
+			Pluck("root_hash", &roots).Error; err != nil {
+			return err
+		}
+		if len(roots) > 0 {
+			if err := tx.Model(&TermRootVersion{}).
+				Where("root_hash IN ?", roots).
+				Update("reorged", true).Error; err != nil {
+				return err
+			}
+			// superseded_by and the reason stay, so a restore can re-apply them
+			if err := tx.Model(&TermRootVersion{}).
+				Where("superseded_by IN ?", roots).
+				Update("is_superseded", false).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&TermReceipt{}).
+			Where("LOWER(blockchain_tx_hash) = LOWER(?)", txHash).
+			Update("blockchain_verified", false).Error
+	})
+}
+
+// RestoreReorgedTransaction records that a transaction is in the canonical chain at
+// blockNumber, undoing MarkTransactionReorged and moving its rows to the new block
+func RestoreReorgedTransaction(db *gorm.DB, txHash string, blockNumber uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&BlockchainTransaction{}).
+			Where("tx_hash = ?", txHash).
+			Updates(map[string]interface{}{"status": "confirmed", "block_number": blockNumber}).Error; err != nil {
+			return err
+		}
+
+		var roots []string
+		if err := tx.Model(&TermRootVersion{}).
+			Where("tx_hash = ?", txHash).
+			Pluck("root_hash", &roots).Error; err != nil {
+			return err
+		}
+		if len(roots) > 0 {
+			if err := tx.Model(&TermRootVersion{}).
+				Where("root_hash IN ?", roots).
+				Updates(map[string]interface{}{"reorged": false, "block_number": blockNumber}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&TermRootVersion{}).
+				Where("superseded_by IN ?", roots).
+				Update("is_superseded", true).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&TermReceipt{}).
+			Where("LOWER(blockchain_tx_hash) = LOWER(?)", txHash).
+			Updates(map[string]interface{}{"blockchain_verified": true, "blockchain_block": blockNumber}).Error
+	})
+}
+
+// ========== JOBS ==========
+
+// SaveJob creates a job or saves its status and progress
+func SaveJob(db *gorm.DB, job *Job) error {
+	if job.ID == 0 {
+		return db.Create(job).Error
+	}
+	return db.Save(job).Error
+}
+
+// GetJob gets a job by its ID
+func GetJob(db *gorm.DB, jobID string) (*Job, error) {
+	var job Job
+	err := db.Where("job_id = ?", jobID).First(&job).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetRecentJobs gets the latest jobs, newest first
+func GetRecentJobs(db *gorm.DB, limit int) ([]Job, error) {
+	var jobs []Job
+	err := db.Order("created_at DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// GetUnfinishedJobs gets the queued and running jobs in the order they were created
+func GetUnfinishedJobs(db *gorm.DB) ([]Job, error) {
+	var jobs []Job
+	err := db.Where("status IN ?", []string{"queued", "running"}).
+		Order("created_at ASC").
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// ========== WEBHOOKS ==========
+
+// CreateWebhookSubscription creates a webhook subscription
+func CreateWebhookSubscription(db *gorm.DB, sub *WebhookSubscription) error {
+	return db.Create(sub).Error
+}
+
+// GetWebhookSubscriptions gets all webhook subscriptions, oldest first
+func GetWebhookSubscriptions(db *gorm.DB, activeOnly bool) ([]WebhookSubscription, error) {
+	var subs []WebhookSubscription
+	query := db.Order("created_at ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Find(&subs).Error
+	return subs, err
+}
+
+// GetWebhookSubscription gets a webhook subscription by its ID
+func GetWebhookSubscription(db *gorm.DB, subscriptionID string) (*WebhookSubscription, error) {
+	var sub WebhookSubscription
+	err := db.Where("subscription_id = ?", subscriptionID).First(&sub).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription; its delivery log is kept
+func DeleteWebhookSubscription(db *gorm.DB, subscriptionID string) error {
+	result := db.Where("subscription_id = ?", subscriptionID).Delete(&WebhookSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveWebhookDelivery creates a delivery or saves the outcome of an attempt
+func SaveWebhookDelivery(db *gorm.DB, delivery *WebhookDelivery) error {
+	if delivery.ID == 0 {
+		return db.Create(delivery).Error
+	}
+	return db.Save(delivery).Error
+}
+
+// GetDueWebhookDeliveries gets pending deliveries whose next attempt is due, oldest first
+func GetDueWebhookDeliveries(db *gorm.DB, now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := db.Where("status = ? AND next_attempt_at <= ?", "pending", now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ClaimWebhookDelivery moves the next attempt of a due pending delivery to until and
+// reports whether it did. Only one sender claims a delivery, so it is not sent twice by
+// processes sharing the database.
+func ClaimWebhookDelivery(db *gorm.DB, deliveryID string, now, until time.Time) (bool, error) {
+	result := db.Model(&WebhookDelivery{}).
+		Where("delivery_id = ? AND status = ? AND next_attempt_at <= ?", deliveryID, "pending", now).
+		Update("next_attempt_at", until)
+	return result.RowsAffected == 1, result.Error
+}
+
+// GetRecentWebhookDeliveries gets the latest deliveries, newest first, optionally of one
+// subscription
+func GetRecentWebhookDeliveries(db *gorm.DB, subscriptionID string, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	query := db.Order("created_at DESC").Limit(limit)
+	if subscriptionID != "" {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
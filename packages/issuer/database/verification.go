@@ -0,0 +1,158 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Outcomes of a verification, as a list filter
+const (
+	VerificationSucceeded = "succeeded"
+	VerificationFailed    = "failed"
+)
+
+// VerificationModeLookup marks logged verifier calls that only read, such as fetching a
+// receipt or the published roots. Analytics count them apart from verifications.
+const VerificationModeLookup = "lookup"
+
+// verificationStatsTop is how many verifiers and errors the analytics rank
+const verificationStatsTop = 10
+
+var verificationList = listSpec[VerificationLog]{
+	fields: map[string]listField[VerificationLog]{
+		"verified_at": {"verified_at", func(v *VerificationLog) interface{} { return v.VerifiedAt }},
+		"student_id":  {"student_id", func(v *VerificationLog) interface{} { return v.StudentID }},
+		"verifier_id": {"verifier_id", func(v *VerificationLog) interface{} { return v.VerifierID }},
+	},
+	defaultSort: "-verified_at",
+	id:          func(v *VerificationLog) uint { return v.ID },
+}
+
+// ListVerifications gets a page of verification logs, filtered by student, term, outcome
+// and verification date
+func (r *ReceiptRepository) ListVerifications(opts ListOptions) ([]VerificationLog, string, error) {
+	query, err := r.verificationQuery(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return verificationList.page(query, opts)
+}
+
+// verificationQuery filters the verification logs by the student, term, status and dates of opts
+func (r *ReceiptRepository) verificationQuery(opts ListOptions) (*gorm.DB, error) {
+	query := r.db.Model(&VerificationLog{})
+	if opts.StudentID != "" {
+		query = query.Where("student_id = ?", opts.StudentID)
+	}
+	if opts.TermID != "" {
+		query = query.Where("term_id = ?", opts.TermID)
+	}
+	switch opts.Status {
+	case "":
+	case VerificationSucceeded:
+		query = query.Where("success = ?", true)
+	case VerificationFailed:
+		query = query.Where("success = ?", false)
+	default:
+		return nil, fmt.Errorf("%w: status %q, expected %s or %s", ErrInvalidFilter, opts.Status, VerificationSucceeded, VerificationFailed)
+	}
+	return dateRange(query, "verified_at", opts), nil
+}
+
+// VerificationCount counts the verifications sharing a key
+type VerificationCount struct {
+	Key       string `json:"key"`
+	Total     int64  `json:"total"`
+	Succeeded int64  `json:"succeeded"`
+}
+
+// VerificationStats summarises the verifications matching a filter. Lookups are only
+// counted in Lookups.
+type VerificationStats struct {
+	Total           int64               `json:"total"`
+	Succeeded       int64               `json:"succeeded"`
+	Failed          int64               `json:"failed"`
+	SuccessRate     float64             `json:"success_rate"` // 0 to 1, 0 without verifications
+	UniqueReceipts  int64               `json:"unique_receipts"`
+	UniqueStudents  int64               `json:"unique_students"`
+	UniqueVerifiers int64               `json:"unique_verifiers"` // Verifiers that sent an identity
+	Lookups         int64               `json:"lookups"`
+	ByMode          []VerificationCount `json:"by_mode"`
+	ByDay           []VerificationCount `json:"by_day"` // YYYY-MM-DD, oldest first
+	TopVerifiers    []VerificationCount `json:"top_verifiers"`
+	TopErrors       []VerificationCount `json:"top_errors"`
+}
+
+// succeededColumn counts the successful rows of a group
+const succeededColumn = "COALESCE(SUM(CASE WHEN success THEN 1 ELSE 0 END), 0)"
+
+// VerificationStats summarises the verifications matching the student, term, status and
+// dates of opts
+func (r *ReceiptRepository) VerificationStats(opts ListOptions) (*VerificationStats, error) {
+	query, err := r.verificationQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	query = query.Session(&gorm.Session{})
+	stats := &VerificationStats{}
+
+	if err := query.Where("verification_mode = ?", VerificationModeLookup).Count(&stats.Lookups).Error; err != nil {
+		return nil, err
+	}
+	verifications := query.Where("verification_mode <> ?", VerificationModeLookup).Session(&gorm.Session{})
+
+	var totals struct {
+		Total, Succeeded, Receipts, Students, Verifiers int64
+	}
+	err = verifications.Select("COUNT(*) AS total, " + succeededColumn + " AS succeeded, " +
+		"COUNT(DISTINCT NULLIF(receipt_id, '')) AS receipts, " +
+		"COUNT(DISTINCT NULLIF(student_id, '')) AS students, " +
+		"COUNT(DISTINCT NULLIF(verifier_id, '')) AS verifiers").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Total, stats.Succeeded, stats.Failed = totals.Total, totals.Succeeded, totals.Total-totals.Succeeded
+	stats.UniqueReceipts, stats.UniqueStudents, stats.UniqueVerifiers = totals.Receipts, totals.Students, totals.Verifiers
+	if stats.Total > 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(stats.Total)
+	}
+
+	if stats.ByMode, err = countVerificationsBy(verifications, "verification_mode", "total DESC", 0); err != nil {
+		return nil, err
+	}
+	if stats.ByDay, err = countVerificationsBy(verifications, r.dayOf("verified_at"), "key ASC", 0); err != nil {
+		return nil, err
+	}
+	if stats.TopVerifiers, err = countVerificationsBy(verifications.Where("verifier_id <> ''"), "verifier_id", "total DESC", verificationStatsTop); err != nil {
+		return nil, err
+	}
+	if stats.TopErrors, err = countVerificationsBy(verifications.Where("success = ? AND error_message <> ''", false), "error_message", "total DESC", verificationStatsTop); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// countVerificationsBy groups the verifications of query by the key expression
+func countVerificationsBy(query *gorm.DB, key, order string, limit int) ([]VerificationCount, error) {
+	counts := []VerificationCount{}
+	query = query.Select(key + " AS key, COUNT(*) AS total, " + succeededColumn + " AS succeeded").
+		Group(key).
+		Order(order)
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// dayOf formats a time column as YYYY-MM-DD in SQL
+func (r *ReceiptRepository) dayOf(column string) string {
+	if r.db.Dialector.Name() == "sqlite" {
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d', %s)", column)
+	}
+	return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", column)
+}
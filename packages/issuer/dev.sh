@@ -1,3 +1,4 @@
 #!/bin/bash
 echo "🚀 Starting IU-MiCert Issuer Development Server..."
-go run cmd/*.go serve --port 8080 --cors
\ No newline at end of file
+# The local dashboard sends no credentials; never set AUTH_DISABLED on a shared host
+AUTH_DISABLED=true go run cmd/*.go serve --port 8080 --cors
\ No newline at end of file
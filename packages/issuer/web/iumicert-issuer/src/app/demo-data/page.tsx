@@ -23,6 +23,7 @@ import {
 } from "@/components/ui/card";
 import { Alert, AlertDescription } from "@/components/ui/alert";
 import { Badge } from "@/components/ui/badge";
+import { apiService } from "@/lib/api";
 
 type OperationStatus = {
   status: "idle" | "running" | "success" | "error";
@@ -131,10 +132,21 @@ export default function DemoDataPage() {
         throw new Error(data.error || "Generation failed");
       }
 
+      // Generation runs as a job on the server; follow its steps
+      const job = await apiService.waitForJob(data.data.job_id, (job) => {
+        const step = job.steps.find((s) => s.status === "running");
+        setGenerateStatus({
+          status: "running",
+          message: step
+            ? `Step ${job.steps_done + 1}/${job.steps_total}: ${step.name}${step.message ? ` (${step.message})` : ""}`
+            : `Job ${job.status}...`,
+        });
+      }, data.data.events_url);
+
       setGenerateStatus({
         status: "success",
-        message: data.data?.message || "Full dataset generated successfully",
-        output: data.data?.output,
+        message: job.result?.message || "Full dataset generated successfully",
+        output: job.result?.output,
       });
     } catch (error: any) {
       setGenerateStatus({
@@ -159,7 +171,7 @@ export default function DemoDataPage() {
   const loadReceipts = async () => {
     setLoadingReceipts(true);
     try {
-      const response = await fetch("http://localhost:8080/api/issuer/receipts");
+      const response = await fetch("http://localhost:8080/api/issuer/receipts?sort=student_id&limit=500");
       const data = await response.json();
       if (data.success) {
         setReceipts(data.data || []);
@@ -594,7 +606,7 @@ export default function DemoDataPage() {
               <div className="grid grid-cols-1 gap-3 max-h-96 overflow-y-auto">
                 {receipts.map((receipt) => (
                   <div
-                    key={receipt.filename}
+                    key={receipt.receipt_id}
                     className="flex items-center justify-between p-4 bg-gradient-to-r from-gray-50 to-green-50/30 border-2 border-gray-200 hover:border-green-400 rounded-xl transition-all hover:shadow-md group"
                   >
                     <div className="flex items-center gap-3">
@@ -606,19 +618,19 @@ export default function DemoDataPage() {
                           {receipt.student_id}
                         </p>
                         <p className="text-xs text-gray-500">
-                          {receipt.filename}
+                          {receipt.term_id.replace(/_/g, " ")} · {receipt.course_count} courses
                         </p>
-                        {receipt.timestamp && (
+                        {receipt.generated_at && (
                           <p className="text-xs text-gray-400 mt-0.5 flex items-center gap-1">
                             {receipt.blockchain_published ? (
                               <>
                                 <span className="inline-block w-1.5 h-1.5 bg-green-500 rounded-full"></span>
-                                Published: {new Date(receipt.timestamp).toLocaleString()}
+                                Published: {new Date(receipt.published_at || receipt.generated_at).toLocaleString()}
                               </>
                             ) : (
                               <>
                                 <span className="inline-block w-1.5 h-1.5 bg-yellow-500 rounded-full"></span>
-                                Generated: {new Date(receipt.timestamp).toLocaleDateString()}
+                                Generated: {new Date(receipt.generated_at).toLocaleDateString()}
                               </>
                             )}
                           </p>
@@ -28,6 +28,7 @@ export interface TermReceipt {
 }
 
 export interface JourneyReceipt {
+  schema_version?: number;
   student_id: string;
   receipt_type: {
     selective_disclosure: boolean;
@@ -6,8 +6,9 @@ export interface Term {
   start_date: string;
   end_date: string;
   status: string;
+  verkle_root?: string;
   student_count?: number;
-  total_courses?: number;
+  course_completions?: number;
 }
 
 export interface Receipt {
@@ -63,17 +64,69 @@ export interface BlockchainHistory {
   data: any;
 }
 
+export interface PageInfo {
+  limit: number;
+  sort?: string;
+  next_cursor?: string;
+  has_more: boolean;
+}
+
 interface ApiResponse<T> {
   success: boolean;
   data?: T;
   error?: string;
+  page?: PageInfo;
+}
+
+export interface JobStep {
+  name: string;
+  status: "pending" | "running" | "succeeded" | "failed" | "skipped" | "interrupted";
+  message?: string;
+  started_at?: string;
+  finished_at?: string;
+}
+
+export interface Job<T = any> {
+  job_id: string;
+  kind: string;
+  status: "queued" | "running" | "succeeded" | "failed" | "interrupted";
+  steps: JobStep[];
+  steps_done: number;
+  steps_total: number;
+  result?: T;
+  error?: string;
+  requested_by: string;
+  attempts: number;
+  created_at: string;
+  started_at?: string;
+  finished_at?: string;
+  events_url?: string; // With a short-lived stream token, when fetched on its own
+}
+
+export interface QueuedJob {
+  message: string;
+  job_id: string;
+  kind: string;
+  status: string;
+  status_url: string;
+  events_url: string;
 }
 
+const jobFinished = (job: Job) =>
+  job.status === "succeeded" || job.status === "failed" || job.status === "interrupted";
+
 class ApiService {
   private async request<T>(
     endpoint: string,
     options?: RequestInit
   ): Promise<T> {
+    return (await this.requestPage<T>(endpoint, options)).data as T;
+  }
+
+  private async requestPage<T>(
+    endpoint: string,
+    options?: RequestInit
+  ): Promise<ApiResponse<T>> {
     const url = `${API_BASE_URL}${endpoint}`;
     const response = await fetch(url, {
       headers: {
@@ -93,12 +146,26 @@ class ApiService {
       throw new Error(result.error || "API request failed");
     }
 
-    return result.data as T;
+    return result;
+  }
+
+  // Follows the cursors of a paged list endpoint and returns every item
+  private async requestAll<T>(endpoint: string): Promise<T[]> {
+    const separator = endpoint.includes("?") ? "&" : "?";
+    const items: T[] = [];
+    let cursor = "";
+    do {
+      const query = `limit=500${cursor ? `&cursor=${encodeURIComponent(cursor)}` : ""}`;
+      const result = await this.requestPage<T[]>(`${endpoint}${separator}${query}`);
+      items.push(...(result.data || []));
+      cursor = result.page?.next_cursor || "";
+    } while (cursor);
+    return items;
   }
 
   // Term management
   async getTerms(): Promise<Term[]> {
-    return this.request<Term[]>("/api/terms");
+    return this.requestAll<Term>("/api/terms");
   }
 
   async createTerm(term: Omit<Term, "id">): Promise<Term> {
@@ -201,18 +268,74 @@ class ApiService {
     });
   }
 
-  async publishToBlockchain(data: {
-    term_id: string;
-    network: string;
-    gas_limit: number;
-  }): Promise<{ transaction_hash: string; status: string }> {
-    return this.request<{ transaction_hash: string; status: string }>(
-      "/api/blockchain/publish",
-      {
-        method: "POST",
-        body: JSON.stringify(data),
-      }
+  // Publishing runs as a job; an already published term is returned right away
+  async publishToBlockchain(
+    data: {
+      term_id: string;
+      network: string;
+      gas_limit: number;
+    },
+    onProgress?: (job: Job) => void
+  ): Promise<{ transaction_hash: string; status: string }> {
+    const response = await this.request<
+      QueuedJob | { transaction_hash: string; status: string }
+    >("/api/blockchain/publish", {
+      method: "POST",
+      body: JSON.stringify(data),
+    });
+    if (!("job_id" in response)) {
+      return response;
+    }
+    const job = await this.waitForJob<{ transaction_hash: string; status: string }>(
+      response.job_id,
+      onProgress,
+      response.events_url
     );
+    return job.result!;
+  }
+
+  // Jobs
+  async getJob<T = any>(jobId: string): Promise<Job<T>> {
+    return this.request<Job<T>>(`/api/issuer/jobs/${jobId}`);
+  }
+
+  // Follows a job over its event stream until it finishes; rejects when it fails.
+  // The events URL carries a short-lived stream token; without one it is fetched with the job.
+  async waitForJob<T = any>(
+    jobId: string,
+    onProgress?: (job: Job<T>) => void,
+    eventsUrl?: string
+  ): Promise<Job<T>> {
+    const url = eventsUrl ?? (await this.getJob<T>(jobId)).events_url;
+    return new Promise((resolve, reject) => {
+      const events = new EventSource(`${API_BASE_URL}${url}`);
+      events.onmessage = (event) => {
+        const job: Job<T> = JSON.parse(event.data);
+        onProgress?.(job);
+        if (!jobFinished(job)) {
+          return;
+        }
+        events.close();
+        if (job.status === "succeeded") {
+          resolve(job);
+        } else {
+          reject(new Error(job.error || `Job ${job.status}`));
+        }
+      };
+      events.onerror = () => {
+        // The stream closes once the job finished; check its final state
+        events.close();
+        this.getJob<T>(jobId)
+          .then((job) =>
+            jobFinished(job)
+              ? job.status === "succeeded"
+                ? resolve(job)
+                : reject(new Error(job.error || `Job ${job.status}`))
+              : resolve(this.waitForJob(jobId, onProgress, job.events_url))
+          )
+          .catch(reject);
+      };
+    });
   }
 
   async getBlockchainHistory(): Promise<BlockchainHistory[]> {
@@ -311,15 +434,13 @@ class ApiService {
       status: string;
     }>
   > {
-    return this.request<
-      Array<{
-        student_id: string;
-        name: string;
-        did: string;
-        enrollment_date: string;
-        status: string;
-      }>
-    >("/api/issuer/students");
+    return this.requestAll<{
+      student_id: string;
+      name: string;
+      did: string;
+      enrollment_date: string;
+      status: string;
+    }>("/api/issuer/students");
   }
 
   // Get all published roots from blockchain